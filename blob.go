@@ -1,15 +1,23 @@
 package regclient
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/opencontainers/go-digest"
+
 	"github.com/regclient/regclient/internal/pqueue"
+	"github.com/regclient/regclient/internal/ratelimit"
+	"github.com/regclient/regclient/internal/reghttp"
 	"github.com/regclient/regclient/internal/reqmeta"
 	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/types"
@@ -24,6 +32,10 @@ const blobCBFreq = time.Millisecond * 100
 
 type blobOpt struct {
 	callback func(kind types.CallbackKind, instance string, state types.CallbackState, cur, total int64)
+	force    bool
+	limiter  *ratelimit.Limiter
+	rcTgt    *RegClient
+	slog     *slog.Logger
 }
 
 // BlobOpts define options for the Image* commands.
@@ -36,9 +48,50 @@ func BlobWithCallback(callback func(kind types.CallbackKind, instance string, st
 	}
 }
 
+// BlobWithForce pushes the blob in BlobCopy even if it already exists in the target.
+// The existence check and cross repository mount are skipped.
+func BlobWithForce() BlobOpts {
+	return func(opts *blobOpt) {
+		opts.force = true
+	}
+}
+
+// BlobWithRateLimit limits the transfer rate of BlobCopy to bps bytes per second.
+func BlobWithRateLimit(bps int64) BlobOpts {
+	return func(opts *blobOpt) {
+		opts.limiter = ratelimit.New(bps)
+	}
+}
+
+// blobWithLimiter shares a limiter between multiple BlobCopy calls.
+func blobWithLimiter(l *ratelimit.Limiter) BlobOpts {
+	return func(opts *blobOpt) {
+		opts.limiter = l
+	}
+}
+
+// blobWithSlog replaces the logger used by BlobCopy, adding fields from the calling operation.
+func blobWithSlog(l *slog.Logger) BlobOpts {
+	return func(opts *blobOpt) {
+		opts.slog = l
+	}
+}
+
+// BlobWithTargetClient uses a separate RegClient to access the target of a BlobCopy.
+// This allows the source and target to be accessed with different credentials.
+func BlobWithTargetClient(rcTgt *RegClient) BlobOpts {
+	return func(opts *blobOpt) {
+		opts.rcTgt = rcTgt
+	}
+}
+
 // BlobCopy copies a blob between two locations.
-// If the blob already exists in the target, the copy is skipped.
+// If the blob already exists in the target, the copy is skipped, unless [BlobWithForce] is set.
 // A server side cross repository blob mount is attempted.
+// The blob is streamed from the source to the target without a temporary file or buffering the full blob.
+// Memory use is bounded by the upload method of the target:
+// a single PUT streams the content directly, while a chunked upload holds one chunk in memory (see [github.com/regclient/regclient/scheme/reg.WithBlobSize]).
+// The copy waits for the ReqConcurrent and BlobConcurrent limits of both the source and target registry, see [config.Host].
 func (rc *RegClient) BlobCopy(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, d descriptor.Descriptor, opts ...BlobOpts) error {
 	if !refSrc.IsSetRepo() {
 		return fmt.Errorf("refSrc is not set: %s%.0w", refSrc.CommonName(), errs.ErrInvalidReference)
@@ -50,6 +103,14 @@ func (rc *RegClient) BlobCopy(ctx context.Context, refSrc ref.Ref, refTgt ref.Re
 	for _, optFn := range opts {
 		optFn(&opt)
 	}
+	rcTgt := rc
+	if opt.rcTgt != nil {
+		rcTgt = opt.rcTgt
+	}
+	log := rc.slog
+	if opt.slog != nil {
+		log = opt.slog
+	}
 	// dedup warnings
 	if w := warning.FromContext(ctx); w == nil {
 		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
@@ -60,42 +121,42 @@ func (rc *RegClient) BlobCopy(ctx context.Context, refSrc ref.Ref, refTgt ref.Re
 		opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackStarted, 0, d.Size)
 	}
 	// for the same repository, there's nothing to copy
-	if ref.EqualRepository(refSrc, refTgt) {
+	if rcTgt == rc && ref.EqualRepository(refSrc, refTgt) {
 		if opt.callback != nil {
 			opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackSkipped, 0, d.Size)
 		}
-		rc.slog.Debug("Blob copy skipped, same repo",
+		log.Debug("Blob copy skipped, same repo",
 			slog.String("src", refSrc.Reference),
 			slog.String("tgt", refTgt.Reference),
 			slog.String("digest", string(d.Digest)))
 		return nil
 	}
 	// check if layer already exists
-	if _, err := rc.BlobHead(ctx, refTgt, tDesc); err == nil {
-		if opt.callback != nil {
-			opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackSkipped, 0, d.Size)
+	if !opt.force {
+		if _, err := rcTgt.BlobHead(ctx, refTgt, tDesc); err == nil {
+			if opt.callback != nil {
+				opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackSkipped, 0, d.Size)
+			}
+			log.Debug("Blob copy skipped, already exists",
+				slog.String("src", refSrc.Reference),
+				slog.String("tgt", refTgt.Reference),
+				slog.String("digest", string(d.Digest)))
+			return nil
 		}
-		rc.slog.Debug("Blob copy skipped, already exists",
-			slog.String("src", refSrc.Reference),
-			slog.String("tgt", refTgt.Reference),
-			slog.String("digest", string(d.Digest)))
-		return nil
 	}
 	// acquire throttle for both src and tgt to avoid deadlocks
-	tList := []*pqueue.Queue[reqmeta.Data]{}
-	schemeSrcAPI, err := rc.schemeGet(refSrc.Scheme)
+	tList, err := rc.blobThrottle(refSrc, false)
 	if err != nil {
 		return err
 	}
-	schemeTgtAPI, err := rc.schemeGet(refTgt.Scheme)
+	tTgtList, err := rcTgt.blobThrottle(refTgt, true)
 	if err != nil {
 		return err
 	}
-	if tSrc, ok := schemeSrcAPI.(scheme.Throttler); ok {
-		tList = append(tList, tSrc.Throttle(refSrc, false)...)
-	}
-	if tTgt, ok := schemeTgtAPI.(scheme.Throttler); ok {
-		tList = append(tList, tTgt.Throttle(refTgt, true)...)
+	tList = append(tList, tTgtList...)
+	schemeTgtAPI, err := rcTgt.schemeGet(refTgt.Scheme)
+	if err != nil {
+		return err
 	}
 	if len(tList) > 0 {
 		ctxMulti, done, err := pqueue.AcquireMulti[reqmeta.Data](ctx, reqmeta.Data{Kind: reqmeta.Blob, Size: d.Size}, tList...)
@@ -108,68 +169,113 @@ func (rc *RegClient) BlobCopy(ctx context.Context, refSrc ref.Ref, refTgt ref.Re
 		ctx = ctxMulti
 	}
 
-	// try mounting blob from the source repo is the registry is the same
-	if ref.EqualRegistry(refSrc, refTgt) {
+	// open the source blob only when it needs to be sent, reporting progress and applying any rate limit
+	var blobIO blob.Reader
+	var blobDone func()
+	defer func() {
+		if blobIO != nil {
+			_ = blobIO.Close()
+		}
+		if blobDone != nil {
+			blobDone()
+		}
+	}()
+	blobOpen := func() (io.Reader, descriptor.Descriptor, error) {
+		var err error
+		blobIO, err = rc.BlobGet(ctx, refSrc, d)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				log.Warn("Failed to retrieve blob",
+					slog.String("src", refSrc.Reference),
+					slog.String("digest", string(d.Digest)),
+					slog.String("err", err.Error()))
+			}
+			return nil, d, err
+		}
+		if opt.callback != nil {
+			opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackStarted, 0, d.Size)
+			ticker := time.NewTicker(blobCBFreq)
+			done := make(chan bool)
+			blobDone = func() {
+				close(done)
+				ticker.Stop()
+				if ctx.Err() == nil {
+					opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackFinished, d.Size, d.Size)
+				}
+			}
+			go func() {
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						offset, err := blobIO.Seek(0, io.SeekCurrent)
+						if err == nil && offset > 0 {
+							opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackActive, offset, d.Size)
+						}
+					}
+				}
+			}()
+		}
+		var rdr io.Reader = blobIO
+		if opt.limiter != nil {
+			rdr = &ratelimit.Reader{Ctx: ctx, Reader: blobIO, Limiter: opt.limiter}
+		}
+		return rdr, blobIO.GetDescriptor(), nil
+	}
+	pushFailed := func(err error) {
+		// retrieve failures are logged by blobOpen
+		if blobIO != nil && !errors.Is(err, context.Canceled) {
+			log.Warn("Failed to push blob",
+				slog.String("src", refSrc.Reference),
+				slog.String("tgt", refTgt.Reference),
+				slog.String("err", err.Error()))
+		}
+	}
+
+	// try mounting blob from the source repo is the registry and client are the same
+	if !opt.force && rcTgt == rc && ref.EqualRegistry(refSrc, refTgt) {
+		// when supported, a rejected mount continues with an upload using the session returned by the mount
+		if mp, ok := schemeTgtAPI.(scheme.BlobMountPutter); ok {
+			mounted, err := mp.BlobMountPut(ctx, refSrc, refTgt, tDesc, blobOpen)
+			if err != nil {
+				pushFailed(err)
+				return err
+			}
+			if mounted {
+				if opt.callback != nil {
+					opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackSkipped, 0, d.Size)
+				}
+				log.Debug("Blob copy performed server side with registry mount",
+					slog.String("src", refSrc.Reference),
+					slog.String("tgt", refTgt.Reference),
+					slog.String("digest", string(d.Digest)))
+			}
+			return nil
+		}
 		err := rc.BlobMount(ctx, refSrc, refTgt, d)
 		if err == nil {
 			if opt.callback != nil {
 				opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackSkipped, 0, d.Size)
 			}
-			rc.slog.Debug("Blob copy performed server side with registry mount",
+			log.Debug("Blob copy performed server side with registry mount",
 				slog.String("src", refSrc.Reference),
 				slog.String("tgt", refTgt.Reference),
 				slog.String("digest", string(d.Digest)))
 			return nil
 		}
-		rc.slog.Warn("Failed to mount blob",
+		log.Warn("Failed to mount blob",
 			slog.String("src", refSrc.Reference),
 			slog.String("tgt", refTgt.Reference),
 			slog.String("err", err.Error()))
 	}
 	// fast options failed, download layer from source and push to target
-	blobIO, err := rc.BlobGet(ctx, refSrc, d)
+	rdr, dPut, err := blobOpen()
 	if err != nil {
-		if !errors.Is(err, context.Canceled) {
-			rc.slog.Warn("Failed to retrieve blob",
-				slog.String("src", refSrc.Reference),
-				slog.String("digest", string(d.Digest)),
-				slog.String("err", err.Error()))
-		}
 		return err
 	}
-	if opt.callback != nil {
-		opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackStarted, 0, d.Size)
-		ticker := time.NewTicker(blobCBFreq)
-		done := make(chan bool)
-		defer func() {
-			close(done)
-			ticker.Stop()
-			if ctx.Err() == nil {
-				opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackFinished, d.Size, d.Size)
-			}
-		}()
-		go func() {
-			for {
-				select {
-				case <-done:
-					return
-				case <-ticker.C:
-					offset, err := blobIO.Seek(0, io.SeekCurrent)
-					if err == nil && offset > 0 {
-						opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackActive, offset, d.Size)
-					}
-				}
-			}
-		}()
-	}
-	defer blobIO.Close()
-	if _, err := rc.BlobPut(ctx, refTgt, blobIO.GetDescriptor(), blobIO); err != nil {
-		if !errors.Is(err, context.Canceled) {
-			rc.slog.Warn("Failed to push blob",
-				slog.String("src", refSrc.Reference),
-				slog.String("tgt", refTgt.Reference),
-				slog.String("err", err.Error()))
-		}
+	if _, err := rcTgt.BlobPut(ctx, refTgt, dPut, rdr); err != nil {
+		pushFailed(err)
 		return err
 	}
 	return nil
@@ -191,7 +297,20 @@ func (rc *RegClient) BlobDelete(ctx context.Context, r ref.Ref, d descriptor.Des
 
 // BlobGet retrieves a blob, returning a reader.
 // This reader must be closed to free up resources that limit concurrent pulls.
-func (rc *RegClient) BlobGet(ctx context.Context, r ref.Ref, d descriptor.Descriptor) (blob.Reader, error) {
+// With [BlobWithCallback], progress is reported as the returned reader is read.
+func (rc *RegClient) BlobGet(ctx context.Context, r ref.Ref, d descriptor.Descriptor, opts ...BlobOpts) (blob.Reader, error) {
+	var opt blobOpt
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+	br, err := rc.blobGet(ctx, r, d)
+	if err != nil || opt.callback == nil {
+		return br, err
+	}
+	return blobProgressNew(r, d, br, opt.callback), nil
+}
+
+func (rc *RegClient) blobGet(ctx context.Context, r ref.Ref, d descriptor.Descriptor) (blob.Reader, error) {
 	data, err := d.GetData()
 	if err == nil {
 		return blob.NewReader(blob.WithDesc(d), blob.WithRef(r), blob.WithReader(bytes.NewReader(data))), nil
@@ -203,7 +322,199 @@ func (rc *RegClient) BlobGet(ctx context.Context, r ref.Ref, d descriptor.Descri
 	if err != nil {
 		return nil, err
 	}
-	return schemeAPI.BlobGet(ctx, r, d)
+	if rc.blobCache == nil || r.Scheme != "reg" || d.Digest.Validate() != nil {
+		return schemeAPI.BlobGet(ctx, r, d)
+	}
+	// attempt to pull from the blob cache, falling back to the registry
+	br, err := rc.blobCacheGet(ctx, r, d)
+	if err == nil {
+		return br, nil
+	}
+	rc.slog.Debug("Blob cache miss",
+		slog.String("digest", d.Digest.String()),
+		slog.String("err", err.Error()))
+	br, err = schemeAPI.BlobGet(ctx, r, d)
+	if err != nil {
+		return nil, err
+	}
+	return rc.blobCachePut(ctx, r, d, br), nil
+}
+
+// blobCacheURL returns the URL of a blob in the blob cache.
+func (rc *RegClient) blobCacheURL(d descriptor.Descriptor) string {
+	return rc.blobCache.JoinPath("blobs", d.Digest.Algorithm().String(), d.Digest.Encoded()).String()
+}
+
+// blobCacheGet pulls a blob from the blob cache.
+func (rc *RegClient) blobCacheGet(ctx context.Context, r ref.Ref, d descriptor.Descriptor) (blob.Reader, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rc.blobCacheURL(d), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", rc.userAgent)
+	resp, err := rc.blobCacheHC.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, reghttp.HTTPError(resp.StatusCode)
+	}
+	rc.slog.Debug("Blob cache hit",
+		slog.String("digest", d.Digest.String()))
+	// the blob reader verifies the content matches the digest
+	return blob.NewReader(
+		blob.WithDesc(d),
+		blob.WithRef(r),
+		blob.WithReader(resp.Body),
+	), nil
+}
+
+// blobCachePut returns a reader that sends the blob to the blob cache as it is read.
+// The cache is only populated when the blob is completely read without errors.
+func (rc *RegClient) blobCachePut(ctx context.Context, r ref.Ref, d descriptor.Descriptor, br blob.Reader) blob.Reader {
+	if d.Size <= 0 {
+		d.Size = br.GetDescriptor().Size
+	}
+	if d.Size <= 0 {
+		return br
+	}
+	pr, pw := io.Pipe()
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPut, rc.blobCacheURL(d), pr)
+	if err != nil {
+		return br
+	}
+	req.ContentLength = d.Size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("User-Agent", rc.userAgent)
+	go func() {
+		resp, err := rc.blobCacheHC.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+				err = reghttp.HTTPError(resp.StatusCode)
+			}
+		}
+		// unblock the reader if the request failed before reading the body
+		_ = pr.CloseWithError(err)
+		if err != nil {
+			rc.slog.Warn("Failed to populate blob cache",
+				slog.String("digest", d.Digest.String()),
+				slog.String("err", err.Error()))
+		}
+	}()
+	desc := br.GetDescriptor()
+	if desc.MediaType == "" {
+		desc.MediaType = d.MediaType
+	}
+	return blob.NewReader(
+		blob.WithDesc(desc),
+		blob.WithHeader(br.RawHeaders()),
+		blob.WithRef(r),
+		blob.WithReader(&blobCacheTee{br: br, pw: pw}),
+	)
+}
+
+// blobCacheTee copies a blob to a pipe as it is read, and closes the pipe with an error when the read fails or is incomplete.
+type blobCacheTee struct {
+	br     blob.Reader
+	pw     *io.PipeWriter
+	failed bool
+	done   bool
+}
+
+func (t *blobCacheTee) Read(p []byte) (int, error) {
+	n, err := t.br.Read(p)
+	if n > 0 && !t.failed && !t.done {
+		// failures writing to the cache do not fail the read
+		if _, errW := t.pw.Write(p[:n]); errW != nil {
+			t.failed = true
+		}
+	}
+	if err != nil && !t.done {
+		t.done = true
+		if err == io.EOF {
+			_ = t.pw.Close()
+		} else {
+			_ = t.pw.CloseWithError(err)
+		}
+	}
+	return n, err
+}
+
+func (t *blobCacheTee) Close() error {
+	if !t.done {
+		t.done = true
+		_ = t.pw.CloseWithError(errs.ErrShortRead)
+	}
+	return t.br.Close()
+}
+
+// blobProgressReader reports the bytes read from a blob to a callback.
+type blobProgressReader struct {
+	br         blob.Reader
+	callback   func(kind types.CallbackKind, instance string, state types.CallbackState, cur, total int64)
+	instance   string
+	cur, total int64
+	last       time.Time
+	finished   bool
+}
+
+// blobProgressNew wraps a blob reader to report progress to the callback.
+func blobProgressNew(r ref.Ref, d descriptor.Descriptor, br blob.Reader, callback func(kind types.CallbackKind, instance string, state types.CallbackState, cur, total int64)) blob.Reader {
+	desc := br.GetDescriptor()
+	if desc.MediaType == "" {
+		desc.MediaType = d.MediaType
+	}
+	instance := d.Digest.String()
+	if instance == "" {
+		instance = desc.Digest.String()
+	}
+	bp := &blobProgressReader{
+		br:       br,
+		callback: callback,
+		instance: instance,
+		total:    desc.Size,
+		last:     time.Now(),
+	}
+	callback(types.CallbackBlob, instance, types.CallbackStarted, 0, desc.Size)
+	return blob.NewReader(
+		blob.WithDesc(desc),
+		blob.WithHeader(br.RawHeaders()),
+		blob.WithRef(r),
+		blob.WithReader(bp),
+	)
+}
+
+func (bp *blobProgressReader) Read(p []byte) (int, error) {
+	n, err := bp.br.Read(p)
+	bp.cur += int64(n)
+	if bp.finished {
+		return n, err
+	}
+	// readers of a known size may stop before reaching the EOF
+	if err == io.EOF || (err == nil && bp.total > 0 && bp.cur >= bp.total) {
+		bp.finished = true
+		bp.callback(types.CallbackBlob, bp.instance, types.CallbackFinished, bp.cur, bp.total)
+	} else if n > 0 && time.Since(bp.last) >= blobCBFreq {
+		bp.last = time.Now()
+		bp.callback(types.CallbackBlob, bp.instance, types.CallbackActive, bp.cur, bp.total)
+	}
+	return n, err
+}
+
+// Seek passes through to the blob reader, which only supports a seek to the start.
+func (bp *blobProgressReader) Seek(offset int64, whence int) (int64, error) {
+	o, err := bp.br.Seek(offset, whence)
+	if err == nil {
+		bp.cur = o
+		bp.finished = false
+	}
+	return o, err
+}
+
+func (bp *blobProgressReader) Close() error {
+	return bp.br.Close()
 }
 
 // BlobGetOCIConfig retrieves an OCI config from a blob, automatically extracting the JSON.
@@ -218,6 +529,89 @@ func (rc *RegClient) BlobGetOCIConfig(ctx context.Context, r ref.Ref, d descript
 	return b.ToOCIConfig()
 }
 
+// BlobGetToFile downloads a blob to a file, verifying the digest of the content.
+// The blob is written to a temporary file in the same directory and renamed to the file once verified.
+// An existing file is replaced, and no file is left behind on failure.
+func (rc *RegClient) BlobGetToFile(ctx context.Context, r ref.Ref, d descriptor.Descriptor, file string, opts ...BlobOpts) error {
+	if err := d.Digest.Validate(); err != nil {
+		return fmt.Errorf("invalid digest %s: %w", d.Digest.String(), err)
+	}
+	br, err := rc.BlobGet(ctx, r, d, opts...)
+	if err != nil {
+		return err
+	}
+	defer br.Close()
+	fh, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := fh.Name()
+	defer func() {
+		// cleanup the temp file on any failure, after the rename this is a noop
+		_ = fh.Close()
+		_ = os.Remove(tmpName)
+	}()
+	// the blob reader verifies the digest when the content has been fully read
+	_, err = io.Copy(fh, br)
+	if err != nil {
+		return fmt.Errorf("failed to download blob %s: %w", d.Digest.String(), err)
+	}
+	err = fh.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmpName, err)
+	}
+	err = os.Rename(tmpName, file)
+	if err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", tmpName, file, err)
+	}
+	return nil
+}
+
+// LayerFileGet streams a single file from a layer without extracting or downloading the rest of the layer.
+// The layer tar is read until the file is found, and the returned reader must be closed to release the blob.
+// Links and other non-regular files return [errs.ErrUnsupported], links are not followed.
+// A file that is not in the layer returns [errs.ErrFileNotFound], or [errs.ErrFileDeleted] when the layer includes a whiteout for the file.
+// The digest of the layer is only verified when the entire layer is read, which does not happen when the file is found.
+func (rc *RegClient) LayerFileGet(ctx context.Context, r ref.Ref, d digest.Digest, path string) (io.ReadCloser, error) {
+	b, err := rc.BlobGet(ctx, r, descriptor.Descriptor{Digest: d})
+	if err != nil {
+		return nil, err
+	}
+	tr, err := b.ToTarReader()
+	if err != nil {
+		_ = b.Close()
+		return nil, err
+	}
+	lfr := &layerFileReader{b: b, tr: tr}
+	th, rdr, err := tr.ReadFile(path)
+	if err != nil {
+		_ = lfr.Close()
+		return nil, fmt.Errorf("failed to read %s from layer %s: %w", path, d.String(), err)
+	}
+	if th.Typeflag != tar.TypeReg {
+		_ = lfr.Close()
+		return nil, fmt.Errorf("file %s in layer %s is not a regular file, type %c%.0w", path, d.String(), th.Typeflag, errs.ErrUnsupported)
+	}
+	lfr.Reader = rdr
+	return lfr, nil
+}
+
+// layerFileReader returns the content of a file in a layer, closing the layer blob on close.
+type layerFileReader struct {
+	io.Reader
+	b  blob.Reader
+	tr *blob.BTarReader
+}
+
+func (lfr *layerFileReader) Close() error {
+	errTR := lfr.tr.Close()
+	errB := lfr.b.Close()
+	if errTR != nil {
+		return errTR
+	}
+	return errB
+}
+
 // BlobHead is used to verify if a blob exists and is accessible.
 func (rc *RegClient) BlobHead(ctx context.Context, r ref.Ref, d descriptor.Descriptor) (blob.Reader, error) {
 	if !r.IsSetRepo() {
@@ -231,6 +625,10 @@ func (rc *RegClient) BlobHead(ctx context.Context, r ref.Ref, d descriptor.Descr
 }
 
 // BlobMount attempts to perform a server side copy/mount of the blob between repositories.
+// A nil error indicates the registry mounted the blob (201 Created).
+// When the registry requires an upload instead (202 Accepted), the upload session is cancelled
+// and [errs.ErrMountReturnedLocation] is returned so the caller can fall back to [RegClient.BlobCopy] or [RegClient.BlobPut].
+// Schemes without mount support return [errs.ErrUnsupported].
 func (rc *RegClient) BlobMount(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, d descriptor.Descriptor) error {
 	if !refSrc.IsSetRepo() {
 		return fmt.Errorf("ref is not set: %s%.0w", refSrc.CommonName(), errs.ErrInvalidReference)
@@ -262,3 +660,20 @@ func (rc *RegClient) BlobPut(ctx context.Context, r ref.Ref, d descriptor.Descri
 	}
 	return schemeAPI.BlobPut(ctx, r, d, rdr)
 }
+
+// blobThrottle returns the throttles for a blob transfer with r,
+// limiting the requests to the host and the concurrent blob transfers, see [config.Host].
+func (rc *RegClient) blobThrottle(r ref.Ref, put bool) ([]*pqueue.Queue[reqmeta.Data], error) {
+	schemeAPI, err := rc.schemeGet(r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	tList := []*pqueue.Queue[reqmeta.Data]{}
+	if t, ok := schemeAPI.(scheme.Throttler); ok {
+		tList = append(tList, t.Throttle(r, put)...)
+	}
+	if t := rc.transferThrottle(r); t != nil {
+		tList = append(tList, t)
+	}
+	return tList, nil
+}
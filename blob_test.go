@@ -1,7 +1,9 @@
 package regclient
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -11,16 +13,24 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/olareg/olareg"
+	oConfig "github.com/olareg/olareg/config"
 	"github.com/opencontainers/go-digest"
 
 	"github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/internal/copyfs"
 	"github.com/regclient/regclient/internal/reqresp"
 	"github.com/regclient/regclient/types"
 	"github.com/regclient/regclient/types/descriptor"
 	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/mediatype"
 	"github.com/regclient/regclient/types/ref"
 )
 
@@ -1320,3 +1330,613 @@ func TestBlobCopy(t *testing.T) {
 		}
 	})
 }
+
+func TestBlobCacheProxy(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	// count blob requests to the registry
+	var regMu sync.Mutex
+	regBlobGets := 0
+	tsReg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && strings.Contains(req.URL.Path, "/blobs/") {
+			regMu.Lock()
+			regBlobGets++
+			regMu.Unlock()
+		}
+		regHandler.ServeHTTP(w, req)
+	}))
+	// a minimal content addressable cache
+	var cacheMu sync.Mutex
+	cache := map[string][]byte{}
+	cacheHits := 0
+	tsCache := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cacheMu.Lock()
+		defer cacheMu.Unlock()
+		switch req.Method {
+		case http.MethodGet:
+			b, ok := cache[req.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			cacheHits++
+			_, _ = w.Write(b)
+		case http.MethodPut:
+			b, err := io.ReadAll(req.Body)
+			if err != nil || int64(len(b)) != req.ContentLength {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			cache[req.URL.Path] = b
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(func() {
+		tsReg.Close()
+		tsCache.Close()
+		_ = regHandler.Close()
+	})
+	tsRegURL, _ := url.Parse(tsReg.URL)
+	rc := New(
+		WithConfigHost(config.Host{
+			Name:     tsRegURL.Host,
+			Hostname: tsRegURL.Host,
+			TLS:      config.TLSDisabled,
+		}),
+		WithBlobCacheProxy(tsCache.URL+"/cache"),
+	)
+	r, err := ref.New(tsRegURL.Host + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	conf, err := rc.ImageConfig(ctx, r, ImageWithPlatform("linux/amd64"))
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	d := conf.GetDescriptor()
+	cachePath := "/cache/blobs/" + d.Digest.Algorithm().String() + "/" + d.Digest.Encoded()
+	regMu.Lock()
+	regGetsStart := regBlobGets
+	regMu.Unlock()
+	// the cache is populated in the background
+	populated := false
+	for i := 0; i < 100 && !populated; i++ {
+		cacheMu.Lock()
+		_, populated = cache[cachePath]
+		cacheMu.Unlock()
+		if !populated {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if !populated {
+		t.Fatalf("cache was not populated after pulling the config")
+	}
+
+	t.Run("hit", func(t *testing.T) {
+		br, err := rc.BlobGet(ctx, r, d)
+		if err != nil {
+			t.Fatalf("failed to get blob: %v", err)
+		}
+		b, err := io.ReadAll(br)
+		if err != nil {
+			t.Fatalf("failed to read blob: %v", err)
+		}
+		_ = br.Close()
+		if d.Digest.Algorithm().FromBytes(b) != d.Digest {
+			t.Errorf("unexpected content from cache")
+		}
+		regMu.Lock()
+		if regBlobGets != regGetsStart {
+			t.Errorf("blob was pulled from the registry on a cache hit")
+		}
+		regMu.Unlock()
+		cacheMu.Lock()
+		if cacheHits != 1 {
+			t.Errorf("unexpected cache hits, expected 1, received %d", cacheHits)
+		}
+		cacheMu.Unlock()
+	})
+	t.Run("corrupt", func(t *testing.T) {
+		cacheMu.Lock()
+		cache[cachePath] = bytes.Repeat([]byte("x"), int(d.Size))
+		cacheMu.Unlock()
+		br, err := rc.BlobGet(ctx, r, d)
+		if err != nil {
+			t.Fatalf("failed to get blob: %v", err)
+		}
+		_, err = io.ReadAll(br)
+		_ = br.Close()
+		if !errors.Is(err, errs.ErrDigestMismatch) {
+			t.Errorf("corrupt cache content did not return a digest mismatch: %v", err)
+		}
+	})
+}
+
+func TestBlobGetToFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	err := copyfs.Copy(tempDir+"/testrepo", "./testdata/testrepo")
+	if err != nil {
+		t.Fatalf("failed to setup tempDir: %v", err)
+	}
+	rc := New()
+	r, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	conf, err := rc.ImageConfig(ctx, r, ImageWithPlatform("linux/amd64"))
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	d := conf.GetDescriptor()
+	outDir := t.TempDir()
+	t.Run("valid", func(t *testing.T) {
+		file := filepath.Join(outDir, "valid")
+		err := rc.BlobGetToFile(ctx, r, d, file)
+		if err != nil {
+			t.Fatalf("failed to get blob: %v", err)
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if d.Digest.Algorithm().FromBytes(b) != d.Digest {
+			t.Errorf("unexpected file content")
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		file := filepath.Join(outDir, "missing")
+		err := rc.BlobGetToFile(ctx, r, descriptor.Descriptor{Digest: digest.FromString("missing")}, file)
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrNotFound, err)
+		}
+		if _, err := os.Stat(file); err == nil {
+			t.Errorf("file created for a missing blob")
+		}
+	})
+	t.Run("callback", func(t *testing.T) {
+		file := filepath.Join(outDir, "callback")
+		states := []types.CallbackState{}
+		var last int64
+		err := rc.BlobGetToFile(ctx, r, d, file,
+			BlobWithCallback(func(kind types.CallbackKind, instance string, state types.CallbackState, cur, total int64) {
+				if kind != types.CallbackBlob || instance != d.Digest.String() {
+					t.Errorf("unexpected callback, kind %d, instance %s", kind, instance)
+				}
+				if total != d.Size {
+					t.Errorf("unexpected total, expected %d, received %d", d.Size, total)
+				}
+				states = append(states, state)
+				last = cur
+			}))
+		if err != nil {
+			t.Fatalf("failed to get blob: %v", err)
+		}
+		if len(states) < 2 || states[0] != types.CallbackStarted || states[len(states)-1] != types.CallbackFinished {
+			t.Errorf("unexpected callback states: %v", states)
+		}
+		if last != d.Size {
+			t.Errorf("unexpected final size, expected %d, received %d", d.Size, last)
+		}
+	})
+	t.Run("corrupt", func(t *testing.T) {
+		blobFile := filepath.Join(tempDir, "testrepo", "blobs", d.Digest.Algorithm().String(), d.Digest.Encoded())
+		err := os.WriteFile(blobFile, bytes.Repeat([]byte("x"), int(d.Size)), 0600)
+		if err != nil {
+			t.Fatalf("failed to corrupt blob: %v", err)
+		}
+		file := filepath.Join(outDir, "corrupt")
+		err = rc.BlobGetToFile(ctx, r, d, file)
+		if !errors.Is(err, errs.ErrDigestMismatch) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrDigestMismatch, err)
+		}
+		entries, err := os.ReadDir(outDir)
+		if err != nil {
+			t.Fatalf("failed to read output dir: %v", err)
+		}
+		for _, e := range entries {
+			if e.Name() != "valid" && e.Name() != "callback" {
+				t.Errorf("unexpected file left in output dir: %s", e.Name())
+			}
+		}
+	})
+}
+
+func TestLayerFileGet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://" + t.TempDir() + "/testlayer")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	// build a layer with a directory, file, and symlink
+	fileName := "etc/os-release"
+	fileContent := []byte("NAME=\"regclient\"\n")
+	dirName := "etc/"
+	linkName := "etc/release-link"
+	layerBuf := &bytes.Buffer{}
+	gw := gzip.NewWriter(layerBuf)
+	tw := tar.NewWriter(gw)
+	for _, th := range []*tar.Header{
+		{Typeflag: tar.TypeDir, Name: dirName, Mode: 0o755},
+		{Typeflag: tar.TypeReg, Name: fileName, Mode: 0o644, Size: int64(len(fileContent))},
+		{Typeflag: tar.TypeSymlink, Name: linkName, Linkname: "os-release"},
+	} {
+		if err := tw.WriteHeader(th); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if th.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(fileContent); err != nil {
+				t.Fatalf("failed to write tar content: %v", err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to close gzip: %v", err)
+	}
+	d := descriptor.Descriptor{
+		MediaType: mediatype.OCI1LayerGzip,
+		Digest:    digest.FromBytes(layerBuf.Bytes()),
+		Size:      int64(layerBuf.Len()),
+	}
+	_, err = rc.BlobPut(ctx, r, d, bytes.NewReader(layerBuf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to push layer: %v", err)
+	}
+	t.Run("file", func(t *testing.T) {
+		rdr, err := rc.LayerFileGet(ctx, r, d.Digest, "/"+fileName)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", fileName, err)
+		}
+		b, err := io.ReadAll(rdr)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", fileName, err)
+		}
+		if err := rdr.Close(); err != nil {
+			t.Errorf("failed to close: %v", err)
+		}
+		if !bytes.Equal(b, fileContent) {
+			t.Errorf("unexpected content for %s", fileName)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, err := rc.LayerFileGet(ctx, r, d.Digest, "missing/file")
+		if !errors.Is(err, errs.ErrFileNotFound) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrFileNotFound, err)
+		}
+	})
+	t.Run("directory", func(t *testing.T) {
+		_, err := rc.LayerFileGet(ctx, r, d.Digest, dirName)
+		if !errors.Is(err, errs.ErrUnsupported) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupported, err)
+		}
+	})
+	t.Run("symlink", func(t *testing.T) {
+		_, err := rc.LayerFileGet(ctx, r, d.Digest, linkName)
+		if !errors.Is(err, errs.ErrUnsupported) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupported, err)
+		}
+	})
+	t.Run("missing layer", func(t *testing.T) {
+		_, err := rc.LayerFileGet(ctx, r, digest.FromString("missing"), fileName)
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrNotFound, err)
+		}
+	})
+}
+
+func TestBlobMount(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	ts := httptest.NewServer(regHandler)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	rSrc, err := ref.New(tsHost + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rTgt, err := ref.New(tsHost + "/testmount")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	conf, err := rc.ImageConfig(ctx, rSrc, ImageWithPlatform("linux/amd64"))
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	d := conf.GetDescriptor()
+	t.Run("mounted", func(t *testing.T) {
+		err := rc.BlobMount(ctx, rSrc, rTgt, d)
+		if err != nil {
+			t.Fatalf("failed to mount: %v", err)
+		}
+		_, err = rc.BlobHead(ctx, rTgt, d)
+		if err != nil {
+			t.Errorf("mounted blob not found: %v", err)
+		}
+	})
+	t.Run("upload needed", func(t *testing.T) {
+		dMissing := descriptor.Descriptor{Digest: digest.FromString("missing"), Size: 7}
+		err := rc.BlobMount(ctx, rSrc, rTgt, dMissing)
+		if !errors.Is(err, errs.ErrMountReturnedLocation) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrMountReturnedLocation, err)
+		}
+	})
+	t.Run("ocidir unsupported", func(t *testing.T) {
+		rDir, err := ref.New("ocidir://./testdata/testrepo:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		err = rc.BlobMount(ctx, rDir, rDir.SetTag("mount"), d)
+		if !errors.Is(err, errs.ErrUnsupported) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupported, err)
+		}
+	})
+}
+
+func TestBlobPutChunkedFallback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	// reject every one-chunk upload, a PUT that includes the blob content
+	var mu sync.Mutex
+	fullPuts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && req.ContentLength > 0 {
+			mu.Lock()
+			fullPuts++
+			mu.Unlock()
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		regHandler.ServeHTTP(w, req)
+	}))
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	r, err := ref.New(tsHost + "/testchunk")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	t.Run("seekable fallback", func(t *testing.T) {
+		blob := []byte("hello world, seekable")
+		d := descriptor.Descriptor{Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+		_, err := rc.BlobPut(ctx, r, d, bytes.NewReader(blob))
+		if err != nil {
+			t.Fatalf("failed to put blob: %v", err)
+		}
+		_, err = rc.BlobHead(ctx, r, d)
+		if err != nil {
+			t.Errorf("blob not found after put: %v", err)
+		}
+	})
+	t.Run("non-seekable chunked", func(t *testing.T) {
+		blob := []byte("hello world, not seekable")
+		d := descriptor.Descriptor{Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+		_, err := rc.BlobPut(ctx, r, d, struct{ io.Reader }{bytes.NewReader(blob)})
+		if err != nil {
+			t.Fatalf("failed to put blob: %v", err)
+		}
+		_, err = rc.BlobHead(ctx, r, d)
+		if err != nil {
+			t.Errorf("blob not found after put: %v", err)
+		}
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if fullPuts != 1 {
+		t.Errorf("one-chunk uploads attempted, expected 1, received %d", fullPuts)
+	}
+}
+
+func TestBlobCopyMountUpload(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	blob := bytes.Repeat([]byte("regclient mount fallback "), 100)
+	d := descriptor.Descriptor{Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	// the registry rejects every mount, returning an upload session instead
+	var posts, deletes atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost && req.URL.Query().Get("mount") != "" {
+			posts.Add(1)
+			req.URL.RawQuery = ""
+		}
+		if req.Method == http.MethodDelete {
+			deletes.Add(1)
+		}
+		regHandler.ServeHTTP(w, req)
+	}))
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	rA, err := ref.New(tsHost + "/testmount/repo-a")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rB, err := ref.New(tsHost + "/testmount/repo-b")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	_, err = rc.BlobPut(ctx, rA, d, bytes.NewReader(blob))
+	if err != nil {
+		t.Fatalf("failed to push blob: %v", err)
+	}
+	posts.Store(0)
+	deletes.Store(0)
+	err = rc.BlobCopy(ctx, rA, rB, d)
+	if err != nil {
+		t.Fatalf("failed to copy blob: %v", err)
+	}
+	if posts.Load() != 1 {
+		t.Errorf("unexpected number of mount requests, expected 1, received %d", posts.Load())
+	}
+	if deletes.Load() != 0 {
+		t.Errorf("upload session from the mount was canceled, received %d deletes", deletes.Load())
+	}
+	br, err := rc.BlobGet(ctx, rB, d)
+	if err != nil {
+		t.Fatalf("failed to get copied blob: %v", err)
+	}
+	got, err := io.ReadAll(br)
+	_ = br.Close()
+	if err != nil {
+		t.Fatalf("failed to read copied blob: %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("copied blob does not match")
+	}
+}
+
+func TestBlobCopyStream(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	blob := bytes.Repeat([]byte("regclient streaming blob "), 20000)
+	d := descriptor.Descriptor{Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+	blobPath := "/v2/teststream/blobs/" + d.Digest.String()
+	// the source sends half of the blob and waits for the target to begin receiving it
+	uploadStarted := make(chan struct{})
+	var uploadOnce sync.Once
+	var srcFinished atomic.Bool
+	srcHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	tsSrc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != blobPath || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+			srcHandler.ServeHTTP(w, req)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(blob)))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Docker-Content-Digest", d.Digest.String())
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodHead {
+			return
+		}
+		half := len(blob) / 2
+		_, _ = w.Write(blob[:half])
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		select {
+		case <-uploadStarted:
+		case <-time.After(5 * time.Second):
+			srcFinished.Store(true)
+		}
+		_, _ = w.Write(blob[half:])
+	}))
+	tgtHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	tsTgt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if (req.Method == http.MethodPut || req.Method == http.MethodPatch) && req.Body != nil {
+			req.Body = &streamSignalReader{ReadCloser: req.Body, fn: func() { uploadOnce.Do(func() { close(uploadStarted) }) }}
+		}
+		tgtHandler.ServeHTTP(w, req)
+	}))
+	t.Cleanup(func() {
+		tsSrc.Close()
+		tsTgt.Close()
+		_ = srcHandler.Close()
+		_ = tgtHandler.Close()
+	})
+	tsSrcURL, _ := url.Parse(tsSrc.URL)
+	tsTgtURL, _ := url.Parse(tsTgt.URL)
+	rc := New(WithConfigHost(
+		config.Host{
+			Name:     tsSrcURL.Host,
+			Hostname: tsSrcURL.Host,
+			TLS:      config.TLSDisabled,
+		},
+		config.Host{
+			Name:     tsTgtURL.Host,
+			Hostname: tsTgtURL.Host,
+			TLS:      config.TLSDisabled,
+		},
+	))
+	rSrc, err := ref.New(tsSrcURL.Host + "/teststream")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rTgt, err := ref.New(tsTgtURL.Host + "/teststream")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	err = rc.BlobCopy(ctx, rSrc, rTgt, d)
+	if err != nil {
+		t.Fatalf("failed to copy blob: %v", err)
+	}
+	if srcFinished.Load() {
+		t.Errorf("upload to target did not start before the source blob was fully read")
+	}
+	_, err = rc.BlobHead(ctx, rTgt, d)
+	if err != nil {
+		t.Errorf("blob not found on target: %v", err)
+	}
+}
+
+// streamSignalReader calls fn on the first read of the request body.
+type streamSignalReader struct {
+	io.ReadCloser
+	fn func()
+}
+
+func (s *streamSignalReader) Read(p []byte) (int, error) {
+	s.fn()
+	return s.ReadCloser.Read(p)
+}
@@ -67,6 +67,7 @@ type artifactCmd struct {
 	artifactFileMT   []string
 	artifactTitle    bool
 	byDigest         bool
+	dataMax          int64
 	digestTags       bool
 	externalRepo     string
 	filterAT         string
@@ -129,7 +130,11 @@ regctl artifact list ghcr.io/regclient/regctl --platform local
 regctl artifact list registry.example.com/repo:v1 --format body
 
 # pretty print the referrers response
-regctl artifact list registry.example.com/repo:v1 --format '{{jsonPretty .Manifest}}'`,
+regctl artifact list registry.example.com/repo:v1 --format '{{jsonPretty .Manifest}}'
+
+# show the digest, artifact type, and size of each SBOM
+regctl artifact ls registry.example.com/repo:v1 --filter-artifact-type application/spdx+json \
+  --format '{{range .Descriptors}}{{printf "%s\t%s\t%d\n" .Digest .ArtifactType .Size}}{{end}}'`,
 		Args:      cobra.ExactArgs(1),
 		ValidArgs: []string{}, // do not auto complete repository/tag
 		RunE:      artifactOpts.runArtifactList,
@@ -222,6 +227,7 @@ regctl artifact tree --digest-tags ghcr.io/regclient/regsync:latest`,
 	_ = artifactPutCmd.RegisterFlagCompletionFunc("config-type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return configKnownTypes, cobra.ShellCompDirectiveNoFileComp
 	})
+	artifactPutCmd.Flags().Int64Var(&artifactOpts.dataMax, "data-max", 0, "Include the data field on config and file descriptors up to this size in bytes")
 	artifactPutCmd.Flags().StringVar(&artifactOpts.externalRepo, "external", "", "Push referrers to a separate repository")
 	artifactPutCmd.Flags().StringArrayVarP(&artifactOpts.artifactFile, "file", "f", []string{}, "Artifact filename")
 	artifactPutCmd.Flags().StringArrayVarP(&artifactOpts.artifactFileMT, "file-media-type", "m", []string{}, "Set the mediaType for the individual files")
@@ -836,6 +842,9 @@ func (artifactOpts *artifactCmd) runArtifactPut(cmd *cobra.Command, args []strin
 			Digest:    configDigest,
 			Size:      int64(len(configBytes)),
 		}
+		if artifactOpts.dataMax > 0 && confDesc.Size <= artifactOpts.dataMax {
+			confDesc.Data = configBytes
+		}
 	}
 
 	blobs := []descriptor.Descriptor{}
@@ -901,6 +910,17 @@ func (artifactOpts *artifactCmd) runArtifactPut(cmd *cobra.Command, args []strin
 						ociAnnotTitle: af,
 					}
 				}
+				// small files are included inline with the data field
+				if artifactOpts.dataMax > 0 && desc.Size <= artifactOpts.dataMax {
+					_, err = rdr.Seek(0, 0)
+					if err != nil {
+						return err
+					}
+					desc.Data, err = io.ReadAll(rdr)
+					if err != nil {
+						return err
+					}
+				}
 				blobs = append(blobs, desc)
 				// if blob already exists, skip Put
 				bRdr, err := rc.BlobHead(ctx, r, desc)
@@ -134,6 +134,12 @@ func TestArtifactList(t *testing.T) {
 			args:      []string{"artifact", "list", "ocidir://../../testdata/testrepo:v2", "--filter-artifact-type", "application/example.sbom", "--format", "{{ ( index .Descriptors 0 ).ArtifactType }}"},
 			expectOut: "application/example.sbom",
 		},
+		{
+			name:        "Filter and List Fields",
+			args:        []string{"artifact", "ls", "ocidir://../../testdata/testrepo:v2", "--filter-artifact-type", "application/example.sbom", "--format", `{{range .Descriptors}}{{printf "%s %s %d\n" .Digest .ArtifactType .Size}}{{end}}`},
+			expectOut:   " application/example.sbom ",
+			outContains: true,
+		},
 		{
 			name:        "External referrers",
 			args:        []string{"artifact", "list", "ocidir://../../testdata/testrepo:v2", "--external", "ocidir://../../testdata/external"},
@@ -226,6 +232,16 @@ func TestArtifactPut(t *testing.T) {
 			args: []string{"artifact", "put", "--config-type", "application/vnd.example", "--config-file", testConfName, "--file", testFileName, "--file-title", "--strip-dirs", "ocidir://" + testDir + ":put-example-file-data"},
 			in:   testData,
 		},
+		{
+			name:      "Put artifact with data field",
+			args:      []string{"artifact", "put", "--config-type", "application/vnd.example", "--config-file", testConfName, "--file", testFileName, "--data-max", "1024", "--format", `{{ printf "%s %s" .Manifest.GetConfig.Data (index .Manifest.GetLayers 0).Data }}`, "ocidir://" + testDir + ":put-data-field"},
+			expectOut: `{"hello": "world"} example test file`,
+		},
+		{
+			name:      "Put artifact above data max",
+			args:      []string{"artifact", "put", "--config-type", "application/vnd.example", "--config-file", testConfName, "--file", testFileName, "--data-max", "10", "--format", `{{ len .Manifest.GetConfig.Data }} {{ len (index .Manifest.GetLayers 0).Data }}`, "ocidir://" + testDir + ":put-data-field-max"},
+			expectOut: `0 0`,
+		},
 		{
 			name: "Put subject",
 			args: []string{"artifact", "put", "--artifact-type", "application/vnd.example", "--subject", "ocidir://" + testDir + ":put-example-at"},
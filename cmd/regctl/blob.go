@@ -9,7 +9,9 @@ import (
 	"log/slog"
 	"math"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	// crypto libraries included for go-digest
@@ -19,8 +21,12 @@ import (
 	"github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
 
+	"github.com/regclient/regclient"
+	"github.com/regclient/regclient/internal/ascii"
 	"github.com/regclient/regclient/internal/diff"
+	"github.com/regclient/regclient/internal/units"
 	"github.com/regclient/regclient/pkg/template"
+	"github.com/regclient/regclient/types"
 	"github.com/regclient/regclient/types/descriptor"
 	"github.com/regclient/regclient/types/ref"
 	"github.com/regclient/regclient/types/warning"
@@ -34,9 +40,11 @@ type blobCmd struct {
 	formatGet      string
 	formatFile     string
 	formatHead     string
+	formatList     string
 	formatPut      string
 	mt             string
 	digest         string
+	output         string
 }
 
 func NewBlobCmd(rootOpts *rootCmd) *cobra.Command {
@@ -97,12 +105,19 @@ regctl blob diff-layer \
 		Short:   "download a blob/layer",
 		Long: `Download a blob from the registry. The output is the blob itself which may
 be a compressed tar file, a json config, or any other blob supported by the
-registry. The blob or layer digest can be found in the image manifest.`,
+registry. The blob or layer digest can be found in the image manifest.
+With "--output", the blob is saved to a file after the digest is verified.
+When the output is a directory, the file is named with the digest.`,
 		Example: `
 # inspect the layer contents of a busybox image
 regctl blob get busybox \
   sha256:a58ecd4f0c864650a4286c3c2d49c7219a3f2fc8d7a0bf478aa9834acfe14ae7 \
-  | tar -tvzf -`,
+  | tar -tvzf -
+
+# save a layer into the layers directory
+regctl blob get busybox \
+  sha256:a58ecd4f0c864650a4286c3c2d49c7219a3f2fc8d7a0bf478aa9834acfe14ae7 \
+  --output layers/`,
 		Args:      cobra.ExactArgs(2),
 		ValidArgs: []string{}, // do not auto complete repository or digest
 		RunE:      blobOpts.runBlobGet,
@@ -121,6 +136,25 @@ regctl blob get-file alpine \
 		ValidArgs: []string{}, // do not auto complete repository, digest, or filenames
 		RunE:      blobOpts.runBlobGetFile,
 	}
+	var blobListFilesCmd = &cobra.Command{
+		Use:     "list-files <repository> <digest>",
+		Aliases: []string{"ls"},
+		Short:   "list the files in a layer",
+		Long: `Lists the tar headers of every file in a layer without extracting the content.
+Whiteout entries, showing files deleted from lower layers, are included with a ".wh." prefix.`,
+		Example: `
+# list the files in a layer
+regctl blob list-files alpine \
+  sha256:9123ac7c32f74759e6283f04dbf571f18246abe5bb2c779efcb32cd50f3ff13c
+
+# list only the filenames
+regctl blob list-files alpine \
+  sha256:9123ac7c32f74759e6283f04dbf571f18246abe5bb2c779efcb32cd50f3ff13c \
+  --format '{{range .}}{{println .Name}}{{end}}'`,
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{}, // do not auto complete repository or digest
+		RunE:      blobOpts.runBlobListFiles,
+	}
 	var blobHeadCmd = &cobra.Command{
 		Use:     "head <repository> <digest>",
 		Aliases: []string{"digest"},
@@ -172,6 +206,7 @@ regctl blob copy alpine registry.example.org/library/alpine \
 
 	blobGetCmd.Flags().StringVarP(&blobOpts.formatGet, "format", "", "{{printPretty .}}", "Format output with go template syntax")
 	blobGetCmd.Flags().StringVarP(&blobOpts.mt, "media-type", "", "", "Set the requested mediaType (deprecated)")
+	blobGetCmd.Flags().StringVarP(&blobOpts.output, "output", "o", "", "Save the verified blob to a file or directory")
 	_ = blobGetCmd.RegisterFlagCompletionFunc("format", completeArgNone)
 	_ = blobGetCmd.RegisterFlagCompletionFunc("media-type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{
@@ -182,6 +217,9 @@ regctl blob copy alpine registry.example.org/library/alpine \
 
 	blobGetFileCmd.Flags().StringVarP(&blobOpts.formatFile, "format", "", "", "Format output with go template syntax")
 
+	blobListFilesCmd.Flags().StringVarP(&blobOpts.formatList, "format", "", `{{range .}}{{printf "%s %d/%d %8d %s\n" .FileInfo.Mode .Uid .Gid .Size .Name}}{{end}}`, "Format output with go template syntax")
+	_ = blobListFilesCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+
 	blobHeadCmd.Flags().StringVarP(&blobOpts.formatHead, "format", "", "", "Format output with go template syntax")
 	_ = blobHeadCmd.RegisterFlagCompletionFunc("format", completeArgNone)
 
@@ -202,6 +240,7 @@ regctl blob copy alpine registry.example.org/library/alpine \
 	blobTopCmd.AddCommand(blobGetCmd)
 	blobTopCmd.AddCommand(blobGetFileCmd)
 	blobTopCmd.AddCommand(blobHeadCmd)
+	blobTopCmd.AddCommand(blobListFilesCmd)
 	blobTopCmd.AddCommand(blobPutCmd)
 	blobTopCmd.AddCommand(blobCopyCmd)
 
@@ -389,7 +428,20 @@ func (blobOpts *blobCmd) runBlobGet(cmd *cobra.Command, args []string) error {
 		slog.String("host", r.Registry),
 		slog.String("repository", r.Repository),
 		slog.String("digest", args[1]))
-	blob, err := rc.BlobGet(ctx, r, descriptor.Descriptor{Digest: d})
+	bOpts := []regclient.BlobOpts{}
+	progress := blobProgressNew(cmd)
+	if progress != nil {
+		defer progress.stop()
+		bOpts = append(bOpts, regclient.BlobWithCallback(progress.callback))
+	}
+	if blobOpts.output != "" {
+		file := blobOpts.output
+		if fi, err := os.Stat(file); err == nil && fi.IsDir() {
+			file = filepath.Join(file, d.Encoded())
+		}
+		return rc.BlobGetToFile(ctx, r, descriptor.Descriptor{Digest: d}, file, bOpts...)
+	}
+	blob, err := rc.BlobGet(ctx, r, descriptor.Descriptor{Digest: d}, bOpts...)
 	if err != nil {
 		return err
 	}
@@ -474,6 +526,38 @@ func (blobOpts *blobCmd) runBlobGetFile(cmd *cobra.Command, args []string) error
 	return nil
 }
 
+func (blobOpts *blobCmd) runBlobListFiles(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	r, err := ref.New(args[0])
+	if err != nil {
+		return err
+	}
+	d, err := digest.Parse(args[1])
+	if err != nil {
+		return err
+	}
+	rc := blobOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, r)
+
+	blobOpts.rootOpts.log.Debug("List files",
+		slog.String("host", r.Registry),
+		slog.String("repository", r.Repository),
+		slog.String("digest", args[1]))
+	blob, err := rc.BlobGet(ctx, r, descriptor.Descriptor{Digest: d})
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+	tr, err := blob.ToTarReader()
+	if err != nil {
+		return err
+	}
+	headers, err := tr.ListFiles()
+	if err != nil {
+		return err
+	}
+	return template.Writer(cmd.OutOrStdout(), blobOpts.formatList, headers)
+}
 func (blobOpts *blobCmd) runBlobHead(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	r, err := ref.New(args[0])
@@ -495,6 +579,9 @@ func (blobOpts *blobCmd) runBlobHead(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if blobOpts.rootOpts.outputFormat == "json" {
+		return template.Writer(cmd.OutOrStdout(), blobOpts.formatHead, blob.GetDescriptor())
+	}
 
 	switch blobOpts.formatHead {
 	case "", "rawHeaders", "raw-headers", "headers":
@@ -558,13 +645,111 @@ func (blobOpts *blobCmd) runBlobCopy(cmd *cobra.Command, args []string) error {
 		slog.String("source", rSrc.CommonName()),
 		slog.String("target", rTgt.CommonName()),
 		slog.String("digest", args[2]))
-	err = rc.BlobCopy(ctx, rSrc, rTgt, descriptor.Descriptor{Digest: d})
+	bOpts := []regclient.BlobOpts{}
+	progress := blobProgressNew(cmd)
+	if progress != nil {
+		bOpts = append(bOpts, regclient.BlobWithCallback(progress.callback))
+	}
+	err = rc.BlobCopy(ctx, rSrc, rTgt, descriptor.Descriptor{Digest: d}, bOpts...)
+	if progress != nil {
+		progress.stop()
+	}
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// blobProgress displays the progress and transfer speed of a single blob.
+type blobProgress struct {
+	mu         sync.Mutex
+	start      time.Time
+	state      types.CallbackState
+	cur, total int64
+	asciiOut   *ascii.Lines
+	bar        *ascii.ProgressBar
+	changed    bool
+	done       chan bool
+	stopOnce   sync.Once
+}
+
+// blobProgressNew starts a progress display on stderr, returning nil when stderr is not a terminal.
+func blobProgressNew(cmd *cobra.Command) *blobProgress {
+	if flagChanged(cmd, "verbosity") || !ascii.IsWriterTerminal(cmd.ErrOrStderr()) {
+		return nil
+	}
+	bp := &blobProgress{
+		start:    time.Now(),
+		asciiOut: ascii.NewLines(cmd.ErrOrStderr()),
+		bar:      ascii.NewProgressBar(cmd.ErrOrStderr()),
+		done:     make(chan bool),
+	}
+	ticker := time.NewTicker(progressFreq)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bp.done:
+				return
+			case <-ticker.C:
+				bp.display(false)
+			}
+		}
+	}()
+	return bp
+}
+
+func (bp *blobProgress) callback(kind types.CallbackKind, instance string, state types.CallbackState, cur, total int64) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if state == types.CallbackStarted {
+		// restart the timer when the transfer begins
+		bp.start = time.Now()
+	}
+	bp.state = state
+	bp.cur = cur
+	bp.total = total
+	bp.changed = true
+}
+
+// stop ends the display, showing the final result.
+func (bp *blobProgress) stop() {
+	bp.stopOnce.Do(func() {
+		close(bp.done)
+		bp.display(true)
+	})
+}
+
+func (bp *blobProgress) display(final bool) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if !bp.changed && !final {
+		return
+	}
+	bp.changed = false
+	elapsed := time.Since(bp.start)
+	if bp.state == types.CallbackSkipped {
+		bp.asciiOut.Add([]byte(fmt.Sprintf("Blob skipped, %s already exists\n", units.HumanSize(float64(bp.total)))))
+	} else {
+		pct := float64(0)
+		if bp.total > 0 {
+			pct = float64(bp.cur) / float64(bp.total)
+		}
+		speed := float64(0)
+		if elapsed.Seconds() > 0 {
+			speed = float64(bp.cur) / elapsed.Seconds()
+		}
+		post := fmt.Sprintf(" %4.2f%% %s/%s %s/s | Elapsed: %ds",
+			pct*100, units.HumanSize(float64(bp.cur)), units.HumanSize(float64(bp.total)),
+			units.HumanSize(speed), int64(elapsed.Seconds()))
+		bp.asciiOut.Add(bp.bar.Generate(pct, "", post))
+	}
+	bp.asciiOut.Flush()
+	if !final {
+		bp.asciiOut.Return()
+	}
+}
+
 func (blobOpts *blobCmd) blobReportLayer(tr *tar.Reader) ([]string, error) {
 	report := []string{}
 	if tr == nil {
@@ -2,7 +2,11 @@ package main
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/opencontainers/go-digest"
 )
 
 func TestBlob(t *testing.T) {
@@ -49,6 +53,45 @@ func TestBlob(t *testing.T) {
 		if out != "A" {
 			t.Errorf("unexpected blob get-file output, expected A, received %s", out)
 		}
+		// list the files in the blob
+		out, err = cobraTest(t, nil, "blob", "list-files", repo, digBaseA, "--format", "{{range .}}{{println .Name}}{{end}}")
+		if err != nil {
+			t.Errorf("failed to blob list-files: %v", err)
+		}
+		if out != "base.txt" {
+			t.Errorf("unexpected blob list-files output, expected base.txt, received %s", out)
+		}
+	})
+
+	t.Run("Get output", func(t *testing.T) {
+		dir := t.TempDir()
+		dig, err := digest.Parse(digBaseA)
+		if err != nil {
+			t.Fatalf("failed to parse digest: %v", err)
+		}
+		// output to a directory names the file with the digest
+		out, err := cobraTest(t, nil, "blob", "get", repo, digBaseA, "--output", dir)
+		if err != nil {
+			t.Fatalf("failed to blob get: %v", err)
+		}
+		if out != "" {
+			t.Errorf("unexpected output: %s", out)
+		}
+		b, err := os.ReadFile(filepath.Join(dir, dig.Encoded()))
+		if err != nil {
+			t.Fatalf("failed to read output: %v", err)
+		}
+		if dig.Algorithm().FromBytes(b) != dig {
+			t.Errorf("digest mismatch on output file")
+		}
+		// output to a named file
+		_, err = cobraTest(t, nil, "blob", "get", repo, digBaseA, "--output", filepath.Join(dir, "layer.tgz"))
+		if err != nil {
+			t.Fatalf("failed to blob get: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "layer.tgz")); err != nil {
+			t.Errorf("output file missing: %v", err)
+		}
 	})
 
 	t.Run("Put and Delete", func(t *testing.T) {
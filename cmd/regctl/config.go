@@ -33,6 +33,7 @@ type Config struct {
 	BlobLimit     int64                   `json:"blobLimit,omitempty"`
 	IncDockerCert *bool                   `json:"incDockerCert,omitempty"`
 	IncDockerCred *bool                   `json:"incDockerCred,omitempty"`
+	IncEnvCred    *bool                   `json:"incEnvCred,omitempty"`
 }
 
 type configCmd struct {
@@ -41,6 +42,7 @@ type configCmd struct {
 	defCredHelper string
 	dockerCert    bool
 	dockerCred    bool
+	envCred       bool
 	format        string
 }
 
@@ -74,7 +76,10 @@ regctl config get --format '{{.Filename}}'`,
 regctl config set --docker-cred=false
 
 # enable loading credentials from docker
-regctl config set --docker-cred`,
+regctl config set --docker-cred
+
+# enable loading credentials from environment variables
+regctl config set --env-cred`,
 		Args: cobra.ExactArgs(0),
 		RunE: configOpts.runConfigSet,
 	}
@@ -84,6 +89,7 @@ regctl config set --docker-cred`,
 	configSetCmd.Flags().Int64Var(&configOpts.blobLimit, "blob-limit", 0, "limit for blob chunks, this is stored in memory")
 	configSetCmd.Flags().BoolVar(&configOpts.dockerCert, "docker-cert", false, "load certificates from docker")
 	configSetCmd.Flags().BoolVar(&configOpts.dockerCred, "docker-cred", false, "load credentials from docker")
+	configSetCmd.Flags().BoolVar(&configOpts.envCred, "env-cred", false, "load credentials from environment variables")
 	configSetCmd.Flags().StringVar(&configOpts.defCredHelper, "default-cred-helper", "", "default credential helper")
 
 	configTopCmd.AddCommand(configGetCmd)
@@ -137,6 +143,13 @@ func (configOpts *configCmd) runConfigSet(cmd *cobra.Command, args []string) err
 			c.IncDockerCred = nil
 		}
 	}
+	if flagChanged(cmd, "env-cred") {
+		if configOpts.envCred {
+			c.IncEnvCred = &configOpts.envCred
+		} else {
+			c.IncEnvCred = nil
+		}
+	}
 
 	if c.HostDefault != nil && c.HostDefault.IsZero() {
 		c.HostDefault = nil
@@ -21,7 +21,7 @@ func TestConfig(t *testing.T) {
 
 	// set options
 	testLimit := "420000000"
-	out, err = cobraTest(t, nil, "config", "set", "--blob-limit", testLimit, "--docker-cert=false", "--docker-cred=false")
+	out, err = cobraTest(t, nil, "config", "set", "--blob-limit", testLimit, "--docker-cert=false", "--docker-cred=false", "--env-cred")
 	if err != nil {
 		t.Errorf("failed to set config: %v", err)
 	}
@@ -51,6 +51,13 @@ func TestConfig(t *testing.T) {
 	if out != "false" {
 		t.Errorf("unexpected output for docker-cred, expected: false, received: %s", out)
 	}
+	out, err = cobraTest(t, nil, "config", "get", "--format", "{{ .IncEnvCred }}")
+	if err != nil {
+		t.Errorf("failed to run config get on env-cred: %v", err)
+	}
+	if out != "true" {
+		t.Errorf("unexpected output for env-cred, expected: true, received: %s", out)
+	}
 
 	// set a default credential helper
 	out, err = cobraTest(t, nil, "config", "set", "--default-cred-helper", "test-helper")
@@ -70,7 +77,7 @@ func TestConfig(t *testing.T) {
 	}
 
 	// reset back to zero values
-	out, err = cobraTest(t, nil, "config", "set", "--blob-limit", "0", "--docker-cert", "--docker-cred", "--default-cred-helper", "")
+	out, err = cobraTest(t, nil, "config", "set", "--blob-limit", "0", "--docker-cert", "--docker-cred", "--env-cred=false", "--default-cred-helper", "")
 	if err != nil {
 		t.Errorf("failed to set default values: %v", err)
 	}
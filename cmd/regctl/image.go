@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/opencontainers/go-digest"
@@ -47,27 +48,40 @@ type imageCmd struct {
 	checkBaseRef    string
 	checkBaseDigest string
 	checkSkipConfig bool
+	checkDeep       bool
+	configOnly      bool
 	create          string
 	created         string
+	digestAlgo      string
 	digestTags      bool
+	expectDigest    string
 	exportCompress  bool
+	exportCompType  string
+	exportLayerSkip []string
+	exportTime      string
 	exportRef       string
+	externalURLsRm  bool
 	fastCheck       bool
+	force           bool
 	forceRecursive  bool
 	format          string
 	formatCreate    string
 	formatFile      string
 	importName      string
+	importValidate  bool
 	includeExternal bool
 	labels          []string
 	mediaType       string
 	modOpts         []mod.Opts
+	noOverwrite     bool
 	platform        string
 	platforms       []string
+	rateLimit       int64
 	referrers       bool
 	referrerSrc     string
 	referrerTgt     string
 	replace         bool
+	tags            []string
 }
 
 var imageKnownTypes = []string{
@@ -87,6 +101,24 @@ func NewImageCmd(rootOpts *rootCmd) *cobra.Command {
 		Use:   "image <cmd>",
 		Short: "manage images",
 	}
+	var imageAgeCmd = &cobra.Command{
+		Use:   "age <image_ref>",
+		Short: "show the created time of an image",
+		Long: `Shows the created time from the image config, without pulling any of the
+image layers. The age of the image is also available with "--format", which can
+be used to find stale images before they are pruned. The command fails when the
+config does not include a created time.`,
+		Example: `
+# show the created time of an image
+regctl image age registry.example.org/repo:v1
+
+# output the image name when it was created more than 30 days ago
+regctl image age registry.example.org/repo:v1 \
+  --format '{{if gt .Age.Hours 720.0}}{{println .Ref.CommonName}}{{end}}'`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: rootOpts.completeArgTag,
+		RunE:              imageOpts.runImageAge,
+	}
 	var imageCheckBaseCmd = &cobra.Command{
 		Use:     "check-base <image_ref>",
 		Aliases: []string{},
@@ -105,6 +137,27 @@ regctl image check-base ghcr.io/regclient/regctl:alpine -v info`,
 		ValidArgsFunction: rootOpts.completeArgTag,
 		RunE:              imageOpts.runImageCheckBase,
 	}
+	var imageCheckCmd = &cobra.Command{
+		Use:   "check <image_ref>",
+		Short: "verify the content of an image",
+		Long: `Verifies every manifest and blob of an image exists in the registry, reporting
+the result for each. With "--deep", every blob is pulled and the computed digest
+is compared to the expected digest, reporting all corrupted content rather than
+stopping on the first failure. The command fails when any check fails.`,
+		Example: `
+# verify the blobs of an image exist
+regctl image check registry.example.org/repo:v1
+
+# pull and digest every blob of the local platform
+regctl image check --deep --platform local registry.example.org/repo:v1
+
+# list only the failed checks
+regctl image check --deep registry.example.org/repo:v1 \
+  --format '{{range .Results}}{{if .Error}}{{.Desc.Digest}} {{.Error}}{{println}}{{end}}{{end}}'`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: rootOpts.completeArgTag,
+		RunE:              imageOpts.runImageCheck,
+	}
 	var imageCopyCmd = &cobra.Command{
 		Use:     "copy <src_image_ref> <dst_image_ref>",
 		Aliases: []string{"cp"},
@@ -129,12 +182,20 @@ regctl image copy --platform local \
 # retag an image
 regctl image copy registry.example.org/repo:v1.2.3 registry.example.org/repo:v1
 
+# copy an image and apply additional release tags
+regctl image copy --add-tag v1.2 --add-tag v1 \
+  ghcr.io/regclient/regctl:v1.2.3 registry.example.org/regclient/regctl:v1.2.3
+
 # copy an image to an OCI Layout including referrers
 regctl image copy --referrers \
   ghcr.io/regclient/regctl:edge ocidir://regctl:edge
 
 # copy a windows image, including foreign layers
 regctl image copy --platform windows/amd64,osver=10.0.17763.4974 --include-external \
+  golang:latest registry.example.org/library/golang:windows
+
+# copy a windows image, converting foreign layers to regular layers on the target
+regctl image copy --platform windows/amd64,osver=10.0.17763.4974 --external-urls-rm \
   golang:latest registry.example.org/library/golang:windows`,
 		Args:              cobra.ExactArgs(2),
 		ValidArgsFunction: rootOpts.completeArgTag,
@@ -183,15 +244,38 @@ regctl image digest ghcr.io/regclient/regctl`,
 		ValidArgsFunction: rootOpts.completeArgTag,
 		RunE:              manifestOpts.runManifestHead,
 	}
+	var imageDiffCmd = &cobra.Command{
+		Use:   "diff <image_ref> <image_ref>",
+		Short: "compare two images",
+		Long: `Compares the manifests and configs of two images. This reports the layers
+shared between the images, the layers unique to each image, changes to the env,
+entrypoint, cmd, and labels in the config, and the difference in layer size.
+Changes are reported from the first image to the second.`,
+		Example: `
+# compare two tags of an image
+regctl image diff --platform linux/amd64 registry.example.org/repo:v1 registry.example.org/repo:v2
+
+# list the layers added in the second image
+regctl image diff registry.example.org/repo:v1 registry.example.org/repo:v2 \
+  --format '{{range .LayersOnlyB}}{{println .Digest}}{{end}}'`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeArgList([]completeFunc{rootOpts.completeArgTag, rootOpts.completeArgTag}),
+		RunE:              imageOpts.runImageDiff,
+	}
 	var imageExportCmd = &cobra.Command{
 		Use:   "export <image_ref> [filename]",
 		Short: "export image",
 		Long: `Exports an image into a tar file that can be later loaded into a docker
 engine with "docker load". The tar file is output to stdout by default.
-Compression is typically not useful since layers are already compressed.`,
+Compression is typically not useful since layers are already compressed.
+The "--compression" flag selects gzip or zstd for the outer tar, layers are
+always exported unmodified.`,
 		Example: `
 # export an image
-regctl image export registry.example.org/repo:v1 >image-v1.tar`,
+regctl image export registry.example.org/repo:v1 >image-v1.tar
+
+# export an image to a zstd compressed tar
+regctl image export --compression zstd registry.example.org/repo:v1 image-v1.tar.zst`,
 		Args:              cobra.RangeArgs(1, 2),
 		ValidArgsFunction: rootOpts.completeArgTag,
 		RunE:              imageOpts.runImageExport,
@@ -208,15 +292,51 @@ regctl image get-file --platform local alpine /etc/alpine-release`,
 		ValidArgsFunction: completeArgList([]completeFunc{rootOpts.completeArgTag, completeArgNone, completeArgNone}),
 		RunE:              imageOpts.runImageGetFile,
 	}
+	var imageHistoryCmd = &cobra.Command{
+		Use:   "history <image_ref>",
+		Short: "show the history of an image",
+		Long: `Shows the history entries from the image config aligned with the layers of the
+image manifest, similar to "docker history". History entries that did not
+create a layer are marked as empty. When the config does not include a
+history, only the layers are listed.`,
+		Example: `
+# show the history of the alpine image
+regctl image history --platform local alpine
+
+# show the commands used to build each layer
+regctl image history --platform linux/amd64 alpine \
+  --format '{{range .History}}{{if not .EmptyLayer}}{{.CreatedBy}}{{println}}{{end}}{{end}}'`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: rootOpts.completeArgTag,
+		RunE:              imageOpts.runImageHistory,
+	}
 	var imageImportCmd = &cobra.Command{
-		Use:   "import <image_ref> <filename>",
-		Short: "import image",
+		Use:     "import <image_ref> <filename>",
+		Aliases: []string{"load"},
+		Short:   "import image",
 		Long: `Imports an image from a tar file. This must be either a docker formatted tar
 from "docker save" or an OCI Layout compatible tar. The output from
-"regctl image export" can be used. Stdin is not permitted for the tar file.`,
+"regctl image export" can be used. Stdin is only permitted for an OCI Layout
+tar, using "-" for the filename, and the content must follow the manifests
+that reference it, which is the order written by "regctl image export".
+Uncompressed layers from a docker formatted tar are compressed with gzip, and
+the image manifest is generated, so no docker engine is needed to push the
+image to a registry. The "--validate" flag checks the tar and outputs the
+content that would be pushed without pushing anything.`,
 		Example: `
 # import an image saved from docker
-regctl image import registry.example.org/repo:v1 image-v1.tar`,
+regctl image import registry.example.org/repo:v1 image-v1.tar
+
+# check a tar and show the digest of the image it would push
+regctl image import registry.example.org/repo:v1 image-v1.tar \
+  --validate --format '{{println .Desc.Digest}}'
+
+# load an image saved from docker on a disconnected host
+docker save -o image-v1.tar repo:v1
+regctl image load registry.example.org/repo:v1 image-v1.tar
+
+# pipe an export from one registry into another
+regctl image export registry-a.example.org/repo:v1 | regctl image import registry-b.example.org/repo:v1 -`,
 		Args:              cobra.ExactArgs(2),
 		ValidArgsFunction: completeArgList([]completeFunc{rootOpts.completeArgTag, completeArgDefault}),
 		RunE:              imageOpts.runImageImport,
@@ -234,6 +354,23 @@ regctl image inspect --platform local nginx`,
 		ValidArgsFunction: rootOpts.completeArgTag,
 		RunE:              imageOpts.runImageInspect,
 	}
+	var imageLabelsCmd = &cobra.Command{
+		Use:   "labels <image_ref>",
+		Short: "show the labels of an image",
+		Long: `Shows the labels from the image config as key=value lines, sorted by key,
+without pulling any of the image layers. Nothing is output when the image does
+not have any labels. Use "--output-format json" to output the labels as a json object.`,
+		Example: `
+# show the labels of an image
+regctl image labels registry.example.org/repo:v1
+
+# show a single label
+regctl image labels registry.example.org/repo:v1 \
+  --format '{{ index . "org.opencontainers.image.revision" }}'`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: rootOpts.completeArgTag,
+		RunE:              imageOpts.runImageLabels,
+	}
 	var imageManifestCmd = &cobra.Command{
 		Use:   "manifest <image_ref>",
 		Short: "show manifest or manifest list, same as \"manifest get\"",
@@ -314,19 +451,38 @@ regctl image ratelimit alpine --format '{{.Remain}}'`,
 
 	imageOpts.modOpts = []mod.Opts{}
 
+	imageAgeCmd.Flags().StringVar(&imageOpts.format, "format", "{{printPretty .}}", "Format output with go template syntax")
+	imageAgeCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
+	_ = imageAgeCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+	_ = imageAgeCmd.RegisterFlagCompletionFunc("platform", completeArgPlatform)
+
 	imageCheckBaseCmd.Flags().StringVar(&imageOpts.checkBaseRef, "base", "", "Base image reference (including tag)")
 	imageCheckBaseCmd.Flags().StringVar(&imageOpts.checkBaseDigest, "digest", "", "Base image digest (checks if digest matches base)")
 	imageCheckBaseCmd.Flags().BoolVar(&imageOpts.checkSkipConfig, "no-config", false, "Skip check of config history")
 	imageCheckBaseCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
 
+	imageCheckCmd.Flags().BoolVar(&imageOpts.checkDeep, "deep", false, "Pull and digest every blob")
+	imageCheckCmd.Flags().StringVar(&imageOpts.format, "format", "{{printPretty .}}", "Format output with go template syntax")
+	imageCheckCmd.Flags().BoolVar(&imageOpts.includeExternal, "include-external", false, "Include external layers")
+	imageCheckCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
+	_ = imageCheckCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+	_ = imageCheckCmd.RegisterFlagCompletionFunc("platform", completeArgPlatform)
+
+	imageCopyCmd.Flags().StringArrayVar(&imageOpts.tags, "add-tag", []string{}, "Additional tags to apply to the target image")
+	imageCopyCmd.Flags().BoolVar(&imageOpts.configOnly, "config-only", false, "Copy the manifests and config without the layers, for mirroring image metadata")
+	imageCopyCmd.Flags().StringVar(&imageOpts.expectDigest, "expect-digest", "", "Fail if the source does not resolve to this digest")
+	imageCopyCmd.Flags().BoolVar(&imageOpts.externalURLsRm, "external-urls-rm", false, "Copy external layers and remove the urls, changes the digest of the image")
 	imageCopyCmd.Flags().BoolVar(&imageOpts.fastCheck, "fast", false, "Fast check, skip referrers and digest tag checks when image exists, overrides force-recursive")
+	imageCopyCmd.Flags().BoolVar(&imageOpts.force, "force", false, "Force push of every manifest and blob even if they exist, repairs corrupt content in the target")
 	imageCopyCmd.Flags().BoolVar(&imageOpts.forceRecursive, "force-recursive", false, "Force recursive copy of image, repairs missing nested blobs and manifests")
 	imageCopyCmd.Flags().StringVar(&imageOpts.format, "format", "", "Format output with go template syntax")
 	imageCopyCmd.Flags().BoolVar(&imageOpts.includeExternal, "include-external", false, "Include external layers")
+	imageCopyCmd.Flags().BoolVar(&imageOpts.noOverwrite, "no-overwrite", false, "Fail if a target tag exists with a different digest")
 	imageCopyCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
 	imageCopyCmd.Flags().StringArrayVar(&imageOpts.platforms, "platforms", []string{}, "Copy only specific platforms, registry validation must be disabled")
 	// platforms should be treated as experimental since it will break many registries
 	_ = imageCopyCmd.Flags().MarkHidden("platforms")
+	imageCopyCmd.Flags().Int64Var(&imageOpts.rateLimit, "rate-limit", 0, "Limit blob transfers to bytes per second")
 	imageCopyCmd.Flags().BoolVar(&imageOpts.digestTags, "digest-tags", false, "Include digest tags (\"sha256-<digest>.*\") when copying manifests")
 	imageCopyCmd.Flags().BoolVar(&imageOpts.referrers, "referrers", false, "Include referrers")
 	imageCopyCmd.Flags().StringVar(&imageOpts.referrerSrc, "referrers-src", "", "External source for referrers")
@@ -345,6 +501,8 @@ regctl image ratelimit alpine --format '{{.Remain}}'`,
 
 	imageDeleteCmd.Flags().BoolVar(&manifestOpts.forceTagDeref, "force-tag-dereference", false, "Dereference the a tag to a digest, this is unsafe")
 
+	imageDigestCmd.Flags().StringVarP(&manifestOpts.formatHead, "format", "", "", "Format output with go template syntax")
+	_ = imageDigestCmd.RegisterFlagCompletionFunc("format", completeArgNone)
 	imageDigestCmd.Flags().BoolVar(&manifestOpts.list, "list", true, "Do not resolve platform from manifest list (enabled by default)")
 	imageDigestCmd.Flags().StringVarP(&manifestOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local, requires a get request)")
 	imageDigestCmd.Flags().BoolVar(&manifestOpts.requireList, "require-list", false, "Fail if manifest list is not received")
@@ -354,17 +512,46 @@ regctl image ratelimit alpine --format '{{.Remain}}'`,
 	imageGetFileCmd.Flags().StringVar(&imageOpts.formatFile, "format", "", "Format output with go template syntax")
 	imageGetFileCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
 
+	imageDiffCmd.Flags().StringVar(&imageOpts.format, "format", "{{printPretty .}}", "Format output with go template syntax")
+	imageDiffCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
+	_ = imageDiffCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+	_ = imageDiffCmd.RegisterFlagCompletionFunc("platform", completeArgPlatform)
+
 	imageExportCmd.Flags().BoolVar(&imageOpts.exportCompress, "compress", false, "Compress output with gzip")
+	imageExportCmd.Flags().StringVar(&imageOpts.exportCompType, "compression", "", "Compress output with the selected algorithm (none, gzip, zstd)")
+	imageExportCmd.Flags().StringVar(&imageOpts.expectDigest, "expect-digest", "", "Fail if the image does not resolve to this digest")
+	imageExportCmd.Flags().StringArrayVar(&imageOpts.exportLayerSkip, "layer-skip", []string{}, "Skip a layer by index or digest, producing a modified image for debugging")
 	imageExportCmd.Flags().StringVar(&imageOpts.exportRef, "name", "", "Name of image to embed for docker load")
 	imageExportCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
+	imageExportCmd.Flags().Int64Var(&imageOpts.rateLimit, "rate-limit", 0, "Limit blob transfers to bytes per second")
+	imageExportCmd.Flags().StringVar(&imageOpts.exportTime, "time", "epoch", "Modification time of files in the tar (\"epoch\", \"created\", or RFC3339 syntax)")
+	_ = imageExportCmd.RegisterFlagCompletionFunc("compression", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"none", "gzip", "zstd"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	imageHistoryCmd.Flags().StringVar(&imageOpts.format, "format", "{{printPretty .}}", "Format output with go template syntax")
+	imageHistoryCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
+	_ = imageHistoryCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+	_ = imageHistoryCmd.RegisterFlagCompletionFunc("platform", completeArgPlatform)
 
+	imageImportCmd.Flags().StringVar(&imageOpts.digestAlgo, "digest-algo", "", "Digest algorithm for content created from a docker tar (sha256, sha512)")
+	imageImportCmd.Flags().StringVar(&imageOpts.format, "format", "{{printPretty .}}", "Format output of --validate with go template syntax")
 	imageImportCmd.Flags().StringVar(&imageOpts.importName, "name", "", "Name of image or tag to import when multiple images are packaged in the tar")
+	imageImportCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Platform to set when the config of a docker tar is missing the os or architecture (defaults to local)")
+	imageImportCmd.Flags().BoolVar(&imageOpts.importValidate, "validate", false, "Check the tar and output the content that would be pushed, without pushing anything")
+	_ = imageImportCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+	_ = imageImportCmd.RegisterFlagCompletionFunc("platform", completeArgPlatform)
 
 	imageInspectCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
 	imageInspectCmd.Flags().StringVar(&imageOpts.format, "format", "{{printPretty .}}", "Format output with go template syntax")
 	_ = imageInspectCmd.RegisterFlagCompletionFunc("platform", completeArgPlatform)
 	_ = imageInspectCmd.RegisterFlagCompletionFunc("format", completeArgNone)
 
+	imageLabelsCmd.Flags().StringVar(&imageOpts.format, "format", "{{printPretty .}}", "Format output with go template syntax")
+	imageLabelsCmd.Flags().StringVarP(&imageOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
+	_ = imageLabelsCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+	_ = imageLabelsCmd.RegisterFlagCompletionFunc("platform", completeArgPlatform)
+
 	imageManifestCmd.Flags().BoolVar(&manifestOpts.list, "list", true, "Output manifest list if available (enabled by default)")
 	imageManifestCmd.Flags().StringVarP(&manifestOpts.platform, "platform", "p", "", "Specify platform (e.g. linux/amd64 or local)")
 	imageManifestCmd.Flags().BoolVarP(&manifestOpts.requireList, "require-list", "", false, "Fail if manifest list is not received")
@@ -479,6 +666,23 @@ regctl image ratelimit alpine --format '{{.Remain}}'`,
 			return nil
 		},
 	}, "config-cmd", `set command in the config (json array or string, empty string to delete)`)
+	imageModCmd.Flags().Var(&modFlagFunc{
+		t: "string",
+		f: func(val string) error {
+			t := time.Time{}
+			if val != "now" {
+				var err error
+				t, err = time.Parse(time.RFC3339, val)
+				if err != nil {
+					return fmt.Errorf("time must be \"now\" or formatted %s: %w", time.RFC3339, err)
+				}
+			}
+			imageOpts.modOpts = append(imageOpts.modOpts,
+				mod.WithConfigCreated(t),
+			)
+			return nil
+		},
+	}, "config-created", `set the created timestamp in the config ("now" or RFC3339 time), default preserves the source value`)
 	imageModCmd.Flags().Var(&modFlagFunc{
 		t: "string",
 		f: func(val string) error {
@@ -741,6 +945,18 @@ regctl image ratelimit alpine --format '{{.Remain}}'`,
 			return nil
 		},
 	}, "layer-compress", `change layer compression (gzip, none, zstd)`)
+	imageModCmd.Flags().Var(&modFlagFunc{
+		t: "int",
+		f: func(val string) error {
+			level, err := strconv.Atoi(val)
+			if err != nil {
+				return fmt.Errorf("gzip level invalid: %w", err)
+			}
+			imageOpts.modOpts = append(imageOpts.modOpts,
+				mod.WithLayerGzipLevel(level))
+			return nil
+		},
+	}, "layer-gzip-level", `gzip level for recompressed layers (1 fastest to 9 smallest)`)
 	imageModCmd.Flags().Var(&modFlagFunc{
 		t: "string",
 		f: func(val string) error {
@@ -958,15 +1174,20 @@ regctl image ratelimit alpine --format '{{.Remain}}'`,
 	imageRateLimitCmd.Flags().StringVar(&imageOpts.format, "format", "{{printPretty .}}", "Format output with go template syntax")
 	_ = imageRateLimitCmd.RegisterFlagCompletionFunc("format", completeArgNone)
 
+	imageTopCmd.AddCommand(imageAgeCmd)
+	imageTopCmd.AddCommand(imageCheckCmd)
 	imageTopCmd.AddCommand(imageCheckBaseCmd)
 	imageTopCmd.AddCommand(imageCopyCmd)
 	imageTopCmd.AddCommand(imageCreateCmd)
 	imageTopCmd.AddCommand(imageDeleteCmd)
+	imageTopCmd.AddCommand(imageDiffCmd)
 	imageTopCmd.AddCommand(imageDigestCmd)
 	imageTopCmd.AddCommand(imageExportCmd)
 	imageTopCmd.AddCommand(imageGetFileCmd)
+	imageTopCmd.AddCommand(imageHistoryCmd)
 	imageTopCmd.AddCommand(imageImportCmd)
 	imageTopCmd.AddCommand(imageInspectCmd)
+	imageTopCmd.AddCommand(imageLabelsCmd)
 	imageTopCmd.AddCommand(imageManifestCmd)
 	imageTopCmd.AddCommand(imageModCmd)
 	imageTopCmd.AddCommand(imageRateLimitCmd)
@@ -1015,6 +1236,46 @@ func imageParseOptTime(s string) (mod.OptTime, map[string]string, error) {
 	return ot, otherFields, nil
 }
 
+// imageAge is the output of the image age command.
+type imageAge struct {
+	Ref     ref.Ref       `json:"reference"`
+	Created time.Time     `json:"created"`
+	Age     time.Duration `json:"age"`
+}
+
+func (ia imageAge) MarshalPretty() ([]byte, error) {
+	return []byte(ia.Created.Format(time.RFC3339) + "\n"), nil
+}
+
+func (imageOpts *imageCmd) runImageAge(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	r, err := ref.New(args[0])
+	if err != nil {
+		return err
+	}
+	rc := imageOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, r)
+
+	imageOpts.rootOpts.log.Debug("Image age",
+		slog.String("ref", r.CommonName()),
+		slog.String("platform", imageOpts.platform))
+
+	opts := []regclient.ImageOpts{}
+	if imageOpts.platform != "" {
+		opts = append(opts, regclient.ImageWithPlatform(imageOpts.platform))
+	}
+	created, err := rc.ImageAge(ctx, r, opts...)
+	if err != nil {
+		return err
+	}
+	result := imageAge{
+		Ref:     r,
+		Created: created,
+		Age:     time.Since(created),
+	}
+	return template.Writer(cmd.OutOrStdout(), imageOpts.format, result)
+}
+
 func (imageOpts *imageCmd) runImageCheckBase(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	r, err := ref.New(args[0])
@@ -1052,6 +1313,96 @@ func (imageOpts *imageCmd) runImageCheckBase(cmd *cobra.Command, args []string)
 	}
 }
 
+// imageCheck is the output of the image check command.
+type imageCheck struct {
+	Ref     ref.Ref           `json:"reference"`
+	Results []imageCheckEntry `json:"results"`
+}
+
+// imageCheckEntry is the result of checking a single manifest or blob.
+type imageCheckEntry struct {
+	regclient.ImageCheckResult
+	Error string `json:"error,omitempty"`
+}
+
+func (ic imageCheck) MarshalPretty() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "TYPE\tDIGEST\tSIZE\tSTATUS\n")
+	for _, res := range ic.Results {
+		status := "ok"
+		if res.Error != "" {
+			status = "failed: " + res.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", res.Type, res.Desc.Digest.String(), units.HumanSize(float64(res.Desc.Size)), status)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (imageOpts *imageCmd) runImageCheck(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	r, err := ref.New(args[0])
+	if err != nil {
+		return err
+	}
+	rc := imageOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, r)
+
+	imageOpts.rootOpts.log.Debug("Image check",
+		slog.String("ref", r.CommonName()),
+		slog.Bool("deep", imageOpts.checkDeep),
+		slog.String("platform", imageOpts.platform))
+
+	opts := []regclient.ImageOpts{}
+	if imageOpts.checkDeep {
+		opts = append(opts, regclient.ImageWithCheckDeep())
+	}
+	if imageOpts.includeExternal {
+		opts = append(opts, regclient.ImageWithIncludeExternal())
+	}
+	if imageOpts.platform != "" {
+		opts = append(opts, regclient.ImageWithPlatform(imageOpts.platform))
+	}
+	results, errCheck := rc.ImageCheck(ctx, r, opts...)
+	if len(results) == 0 && errCheck != nil {
+		return errCheck
+	}
+	out := imageCheck{
+		Ref:     r,
+		Results: make([]imageCheckEntry, len(results)),
+	}
+	for i, res := range results {
+		out.Results[i] = imageCheckEntry{ImageCheckResult: res}
+		if res.Err != nil {
+			out.Results[i].Error = res.Err.Error()
+		}
+	}
+	err = template.Writer(cmd.OutOrStdout(), imageOpts.format, out)
+	if err != nil {
+		return err
+	}
+	return errCheck
+}
+
+// expectDigestOpts returns the options to verify the digest of the source image.
+func (imageOpts *imageCmd) expectDigestOpts() ([]regclient.ImageOpts, error) {
+	if imageOpts.expectDigest == "" {
+		return []regclient.ImageOpts{}, nil
+	}
+	if imageOpts.platform != "" {
+		return nil, fmt.Errorf("expect-digest cannot be combined with platform%.0w", errs.ErrUnsupported)
+	}
+	d, err := digest.Parse(imageOpts.expectDigest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse digest %s: %w", imageOpts.expectDigest, err)
+	}
+	return []regclient.ImageOpts{regclient.ImageWithExpectDigest(d)}, nil
+}
+
 func (imageOpts *imageCmd) runImageCopy(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	rSrc, err := ref.New(args[0])
@@ -1065,6 +1416,10 @@ func (imageOpts *imageCmd) runImageCopy(cmd *cobra.Command, args []string) error
 	if (imageOpts.referrerSrc != "" || imageOpts.referrerTgt != "") && !imageOpts.referrers {
 		return fmt.Errorf("referrers must be enabled to specify an external referrers source or target%.0w", errs.ErrUnsupported)
 	}
+	expectOpts, err := imageOpts.expectDigestOpts()
+	if err != nil {
+		return err
+	}
 	rc := imageOpts.rootOpts.newRegClient()
 	defer rc.Close(ctx, rSrc)
 	defer rc.Close(ctx, rTgt)
@@ -1084,16 +1439,25 @@ func (imageOpts *imageCmd) runImageCopy(cmd *cobra.Command, args []string) error
 		slog.String("target", rTgt.CommonName()),
 		slog.Bool("recursive", imageOpts.forceRecursive),
 		slog.Bool("digest-tags", imageOpts.digestTags))
-	opts := []regclient.ImageOpts{}
+	opts := expectOpts
 	if imageOpts.fastCheck {
 		opts = append(opts, regclient.ImageWithFastCheck())
 	}
+	if imageOpts.force {
+		opts = append(opts, regclient.ImageWithForce())
+	}
 	if imageOpts.forceRecursive {
 		opts = append(opts, regclient.ImageWithForceRecursive())
 	}
 	if imageOpts.includeExternal {
 		opts = append(opts, regclient.ImageWithIncludeExternal())
 	}
+	if imageOpts.externalURLsRm {
+		opts = append(opts, regclient.ImageWithExternalURLsRm())
+	}
+	if imageOpts.noOverwrite {
+		opts = append(opts, regclient.ImageWithNoOverwrite())
+	}
 	if imageOpts.digestTags {
 		opts = append(opts, regclient.ImageWithDigestTags())
 	}
@@ -1117,6 +1481,12 @@ func (imageOpts *imageCmd) runImageCopy(cmd *cobra.Command, args []string) error
 	if len(imageOpts.platforms) > 0 {
 		opts = append(opts, regclient.ImageWithPlatforms(imageOpts.platforms))
 	}
+	if imageOpts.rateLimit > 0 {
+		opts = append(opts, regclient.ImageWithRateLimit(imageOpts.rateLimit))
+	}
+	if len(imageOpts.tags) > 0 {
+		opts = append(opts, regclient.ImageWithTags(imageOpts.tags...))
+	}
 	// check for a tty and attach progress reporter
 	done := make(chan bool)
 	var progress *imageProgress
@@ -1142,7 +1512,11 @@ func (imageOpts *imageCmd) runImageCopy(cmd *cobra.Command, args []string) error
 		}()
 		opts = append(opts, regclient.ImageWithCallback(progress.callback))
 	}
-	err = rc.ImageCopy(ctx, rSrc, rTgt, opts...)
+	if imageOpts.configOnly {
+		err = rc.ImageCopyConfigOnly(ctx, rSrc, rTgt, opts...)
+	} else {
+		err = rc.ImageCopy(ctx, rSrc, rTgt, opts...)
+	}
 	if progress != nil {
 		close(done)
 		progress.display(true)
@@ -1422,6 +1796,119 @@ func (imageOpts *imageCmd) runImageCreate(cmd *cobra.Command, args []string) err
 	return template.Writer(cmd.OutOrStdout(), imageOpts.formatCreate, result)
 }
 
+// imageDiff is the output of the image diff command.
+type imageDiff struct {
+	regclient.DiffResult
+}
+
+func (id imageDiff) MarshalPretty() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "Image A: %s (%s)\n", id.RefA.CommonName(), id.ManifestA.Digest.String())
+	fmt.Fprintf(buf, "Image B: %s (%s)\n", id.RefB.CommonName(), id.ManifestB.Digest.String())
+	sign, delta := "+", id.SizeDelta
+	if delta < 0 {
+		sign, delta = "-", -delta
+	}
+	fmt.Fprintf(buf, "Size:    %s -> %s (%s%s)\n", units.HumanSize(float64(id.SizeA)), units.HumanSize(float64(id.SizeB)), sign, units.HumanSize(float64(delta)))
+	fmt.Fprintf(buf, "\nLayers:\n")
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	for _, l := range id.LayersShared {
+		fmt.Fprintf(tw, "  =\t%s\t%s\n", l.Digest.String(), units.HumanSize(float64(l.Size)))
+	}
+	for _, l := range id.LayersOnlyA {
+		fmt.Fprintf(tw, "  -\t%s\t%s\n", l.Digest.String(), units.HumanSize(float64(l.Size)))
+	}
+	for _, l := range id.LayersOnlyB {
+		fmt.Fprintf(tw, "  +\t%s\t%s\n", l.Digest.String(), units.HumanSize(float64(l.Size)))
+	}
+	err := tw.Flush()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(buf, "\nConfig:\n")
+	if id.Config.IsEmpty() {
+		fmt.Fprintf(buf, "  no changes\n")
+		return buf.Bytes(), nil
+	}
+	for _, e := range id.Config.EnvRemoved {
+		fmt.Fprintf(buf, "  - env: %s\n", e)
+	}
+	for _, e := range id.Config.EnvAdded {
+		fmt.Fprintf(buf, "  + env: %s\n", e)
+	}
+	if id.Config.Entrypoint != nil {
+		fmt.Fprintf(buf, "  - entrypoint: %s\n", imageDiffJSON(id.Config.Entrypoint.A))
+		fmt.Fprintf(buf, "  + entrypoint: %s\n", imageDiffJSON(id.Config.Entrypoint.B))
+	}
+	if id.Config.Cmd != nil {
+		fmt.Fprintf(buf, "  - cmd: %s\n", imageDiffJSON(id.Config.Cmd.A))
+		fmt.Fprintf(buf, "  + cmd: %s\n", imageDiffJSON(id.Config.Cmd.B))
+	}
+	labels := []string{}
+	for k := range id.Config.LabelsRemoved {
+		labels = append(labels, k)
+	}
+	for k := range id.Config.LabelsChanged {
+		labels = append(labels, k)
+	}
+	for k := range id.Config.LabelsAdded {
+		labels = append(labels, k)
+	}
+	sort.Strings(labels)
+	for _, k := range labels {
+		if v, ok := id.Config.LabelsRemoved[k]; ok {
+			fmt.Fprintf(buf, "  - label: %s=%s\n", k, v)
+		}
+		if v, ok := id.Config.LabelsChanged[k]; ok {
+			fmt.Fprintf(buf, "  - label: %s=%s\n", k, v.A)
+			fmt.Fprintf(buf, "  + label: %s=%s\n", k, v.B)
+		}
+		if v, ok := id.Config.LabelsAdded[k]; ok {
+			fmt.Fprintf(buf, "  + label: %s=%s\n", k, v)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// imageDiffJSON formats a string slice for the pretty output.
+func imageDiffJSON(s []string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("%v", s)
+	}
+	return string(b)
+}
+
+func (imageOpts *imageCmd) runImageDiff(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	rA, err := ref.New(args[0])
+	if err != nil {
+		return err
+	}
+	rB, err := ref.New(args[1])
+	if err != nil {
+		return err
+	}
+	rc := imageOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, rA)
+	defer rc.Close(ctx, rB)
+
+	imageOpts.rootOpts.log.Debug("Image diff",
+		slog.String("ref1", rA.CommonName()),
+		slog.String("ref2", rB.CommonName()),
+		slog.String("platform", imageOpts.platform))
+
+	opts := []regclient.ImageOpts{}
+	if imageOpts.platform != "" {
+		opts = append(opts, regclient.ImageWithPlatform(imageOpts.platform))
+	}
+	result, err := rc.ImageDiff(ctx, rA, rB, opts...)
+	if err != nil {
+		return err
+	}
+	return template.Writer(cmd.OutOrStdout(), imageOpts.format, imageDiff{DiffResult: result})
+}
+
 func (imageOpts *imageCmd) runImageExport(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	// dedup warnings
@@ -1433,31 +1920,38 @@ func (imageOpts *imageCmd) runImageExport(cmd *cobra.Command, args []string) err
 		return err
 	}
 	var w io.Writer
+	var fh *os.File
 	if len(args) == 2 {
-		w, err = os.Create(args[1])
+		fh, err = os.Create(args[1])
 		if err != nil {
 			return err
 		}
+		// close errors are checked after a successful export
+		defer fh.Close()
+		w = fh
 	} else {
 		w = cmd.OutOrStdout()
 	}
+	opts, err := imageOpts.expectDigestOpts()
+	if err != nil {
+		return err
+	}
 	rc := imageOpts.rootOpts.newRegClient()
 	defer rc.Close(ctx, r)
-	opts := []regclient.ImageOpts{}
 	if imageOpts.platform != "" {
-		p, err := platform.Parse(imageOpts.platform)
-		if err != nil {
-			return err
-		}
-		m, err := rc.ManifestGet(ctx, r, regclient.WithManifestPlatform(p))
-		if err != nil {
-			return err
-		}
-		r = r.SetDigest(m.GetDescriptor().Digest.String())
+		opts = append(opts, regclient.ImageWithPlatform(imageOpts.platform))
 	}
 	if imageOpts.exportCompress {
 		opts = append(opts, regclient.ImageWithExportCompress())
 	}
+	if imageOpts.exportCompType != "" {
+		var ct archive.CompressType
+		err := ct.UnmarshalText([]byte(imageOpts.exportCompType))
+		if err != nil {
+			return err
+		}
+		opts = append(opts, regclient.ImageWithExportCompression(ct))
+	}
 	if imageOpts.exportRef != "" {
 		eRef, err := ref.New(imageOpts.exportRef)
 		if err != nil {
@@ -1465,9 +1959,54 @@ func (imageOpts *imageCmd) runImageExport(cmd *cobra.Command, args []string) err
 		}
 		opts = append(opts, regclient.ImageWithExportRef(eRef))
 	}
+	switch imageOpts.exportTime {
+	case "", "epoch":
+	case "created":
+		opts = append(opts, regclient.ImageWithExportTimeCreated())
+	default:
+		t, err := time.Parse(time.RFC3339, imageOpts.exportTime)
+		if err != nil {
+			return fmt.Errorf("time must be \"epoch\", \"created\", or formatted %s: %w", time.RFC3339, err)
+		}
+		opts = append(opts, regclient.ImageWithExportTime(t))
+	}
+	if len(imageOpts.exportLayerSkip) > 0 {
+		skipIdx := map[int]bool{}
+		skipDig := map[digest.Digest]bool{}
+		for _, entry := range imageOpts.exportLayerSkip {
+			if i, err := strconv.Atoi(entry); err == nil {
+				skipIdx[i] = true
+			} else if d, err := digest.Parse(entry); err == nil {
+				skipDig[d] = true
+			} else {
+				return fmt.Errorf("layer to skip must be an index or digest: %s", entry)
+			}
+		}
+		opts = append(opts, regclient.ImageWithExportLayerSkip(func(i int, d descriptor.Descriptor) bool {
+			return skipIdx[i] || skipDig[d.Digest]
+		}))
+	}
+	if imageOpts.rateLimit > 0 {
+		opts = append(opts, regclient.ImageWithRateLimit(imageOpts.rateLimit))
+	}
 	imageOpts.rootOpts.log.Debug("Image export",
 		slog.String("ref", r.CommonName()))
-	return rc.ImageExport(ctx, r, w, opts...)
+	err = rc.ImageExport(ctx, r, w, opts...)
+	if err != nil {
+		// remove a partial export, including one stopped by an interrupt
+		if fh != nil {
+			_ = fh.Close()
+			_ = os.Remove(args[1])
+		}
+		return err
+	}
+	if fh != nil {
+		err = fh.Close()
+		if err != nil {
+			return fmt.Errorf("failed to close %s: %w", args[1], err)
+		}
+	}
+	return nil
 }
 
 func (imageOpts *imageCmd) runImageGetFile(cmd *cobra.Command, args []string) error {
@@ -1569,6 +2108,115 @@ func (imageOpts *imageCmd) runImageGetFile(cmd *cobra.Command, args []string) er
 	return errs.ErrNotFound
 }
 
+// imageHistory is the output of the image history command.
+type imageHistory struct {
+	Ref     ref.Ref             `json:"reference"`
+	History []imageHistoryEntry `json:"history"`
+}
+
+// imageHistoryEntry is a single history entry, with the layer created by that entry.
+type imageHistoryEntry struct {
+	v1.History
+	Layer *descriptor.Descriptor `json:"layer,omitempty"`
+}
+
+func (ih imageHistory) MarshalPretty() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "LAYER\tCREATED\tSIZE\tCREATED BY\tCOMMENT\n")
+	for _, h := range ih.History {
+		layer := "<empty>"
+		size := units.HumanSize(0)
+		if h.Layer != nil {
+			layer = h.Layer.Digest.String()
+			size = units.HumanSize(float64(h.Layer.Size))
+		} else if !h.EmptyLayer {
+			layer = "<missing>"
+			size = ""
+		}
+		created := ""
+		if h.Created != nil {
+			created = h.Created.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", layer, created, size, strings.ReplaceAll(h.CreatedBy, "\n", " "), h.Comment)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (imageOpts *imageCmd) runImageHistory(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	r, err := ref.New(args[0])
+	if err != nil {
+		return err
+	}
+	rc := imageOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, r)
+
+	imageOpts.rootOpts.log.Debug("Image history",
+		slog.String("ref", r.CommonName()),
+		slog.String("platform", imageOpts.platform))
+
+	if imageOpts.platform == "" {
+		imageOpts.platform = "local"
+	}
+	p, err := platform.Parse(imageOpts.platform)
+	if err != nil {
+		return fmt.Errorf("failed to parse platform %s: %w", imageOpts.platform, err)
+	}
+	m, err := rc.ManifestGet(ctx, r, regclient.WithManifestPlatform(p))
+	if err != nil {
+		return err
+	}
+	mi, ok := m.(manifest.Imager)
+	if !ok {
+		return fmt.Errorf("reference is not a known image media type%.0w", errs.ErrUnsupportedMediaType)
+	}
+	layers, err := mi.GetLayers()
+	if err != nil {
+		return err
+	}
+	cd, err := mi.GetConfig()
+	if err != nil {
+		return err
+	}
+	if cd.MediaType != mediatype.OCI1ImageConfig && cd.MediaType != mediatype.Docker2ImageConfig {
+		return fmt.Errorf("artifacts are not supported with \"regctl image history\", config media type %s: %w", cd.MediaType, errs.ErrUnsupportedMediaType)
+	}
+	conf, err := rc.BlobGetOCIConfig(ctx, r, cd)
+	if err != nil {
+		return err
+	}
+	result := imageHistory{
+		Ref:     r,
+		History: []imageHistoryEntry{},
+	}
+	// non-empty history entries are matched to layers in order
+	i := 0
+	for _, h := range conf.GetConfig().History {
+		entry := imageHistoryEntry{History: h}
+		if !h.EmptyLayer && i < len(layers) {
+			entry.Layer = &layers[i]
+			i++
+		}
+		result.History = append(result.History, entry)
+	}
+	if i < len(layers) {
+		if len(result.History) > 0 {
+			imageOpts.rootOpts.log.Warn("Image history does not match the number of layers",
+				slog.Int("layers", len(layers)),
+				slog.Int("history", i))
+		}
+		for ; i < len(layers); i++ {
+			result.History = append(result.History, imageHistoryEntry{Layer: &layers[i]})
+		}
+	}
+	return template.Writer(cmd.OutOrStdout(), imageOpts.format, result)
+}
+
 func (imageOpts *imageCmd) runImageImport(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	r, err := ref.New(args[0])
@@ -1579,18 +2227,39 @@ func (imageOpts *imageCmd) runImageImport(cmd *cobra.Command, args []string) err
 	if imageOpts.importName != "" {
 		opts = append(opts, regclient.ImageWithImportName(imageOpts.importName))
 	}
-	rs, err := os.Open(args[1])
-	if err != nil {
-		return err
+	if imageOpts.platform != "" {
+		opts = append(opts, regclient.ImageWithImportPlatform(imageOpts.platform))
+	}
+	if imageOpts.digestAlgo != "" {
+		opts = append(opts, regclient.ImageWithDigestAlgo(digest.Algorithm(imageOpts.digestAlgo)))
+	}
+	res := regclient.ImageImportResult{}
+	if imageOpts.importValidate {
+		opts = append(opts, regclient.ImageWithImportValidate(&res))
 	}
-	defer rs.Close()
 	rc := imageOpts.rootOpts.newRegClient()
 	defer rc.Close(ctx, r)
 	imageOpts.rootOpts.log.Debug("Image import",
 		slog.String("ref", r.CommonName()),
 		slog.String("file", args[1]))
-
-	return rc.ImageImport(ctx, r, rs, opts...)
+	if args[1] == "-" {
+		err = rc.ImageImportOCITar(ctx, r, cmd.InOrStdin(), opts...)
+	} else {
+		var rs *os.File
+		rs, err = os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer rs.Close()
+		err = rc.ImageImport(ctx, r, rs, opts...)
+	}
+	if err != nil {
+		return err
+	}
+	if imageOpts.importValidate {
+		return template.Writer(cmd.OutOrStdout(), imageOpts.format, res)
+	}
+	return nil
 }
 
 func (imageOpts *imageCmd) runImageInspect(cmd *cobra.Command, args []string) error {
@@ -1637,6 +2306,51 @@ func (imageOpts *imageCmd) runImageInspect(cmd *cobra.Command, args []string) er
 	return template.Writer(cmd.OutOrStdout(), imageOpts.format, result)
 }
 
+// imageLabels is the output of the image labels command.
+type imageLabels map[string]string
+
+func (il imageLabels) MarshalPretty() ([]byte, error) {
+	keys := make([]string, 0, len(il))
+	for k := range il {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buf := &bytes.Buffer{}
+	for _, k := range keys {
+		fmt.Fprintf(buf, "%s=%s\n", k, il[k])
+	}
+	return buf.Bytes(), nil
+}
+
+func (imageOpts *imageCmd) runImageLabels(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	r, err := ref.New(args[0])
+	if err != nil {
+		return err
+	}
+	rc := imageOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, r)
+
+	imageOpts.rootOpts.log.Debug("Image labels",
+		slog.String("ref", r.CommonName()),
+		slog.String("platform", imageOpts.platform))
+
+	opts := []regclient.ImageOpts{}
+	if imageOpts.platform != "" {
+		opts = append(opts, regclient.ImageWithPlatform(imageOpts.platform))
+	}
+	blobConfig, err := rc.ImageConfig(ctx, r, opts...)
+	if err != nil {
+		return err
+	}
+	// an image without labels outputs an empty json object rather than null
+	result := imageLabels{}
+	for k, v := range blobConfig.GetConfig().Config.Labels {
+		result[k] = v
+	}
+	return template.Writer(cmd.OutOrStdout(), imageOpts.format, result)
+}
+
 func (imageOpts *imageCmd) runImageMod(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	rSrc, err := ref.New(args[0])
@@ -1685,6 +2399,7 @@ func (imageOpts *imageCmd) runImageRateLimit(cmd *cobra.Command, args []string)
 		return err
 	}
 	rc := imageOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, r)
 
 	imageOpts.rootOpts.log.Debug("Image rate limit",
 		slog.String("host", r.Registry),
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -15,6 +18,80 @@ import (
 	"github.com/regclient/regclient/types/errs"
 )
 
+func TestImageAge(t *testing.T) {
+	srcRef := "ocidir://../../testdata/testrepo:v1"
+	out, err := cobraTest(t, nil, "image", "age", "--platform", "linux/amd64", srcRef)
+	if err != nil {
+		t.Fatalf("failed to run image age: %v", err)
+	}
+	if out != "2021-01-01T00:00:00Z" {
+		t.Errorf("unexpected output: %s", out)
+	}
+	out, err = cobraTest(t, nil, "image", "age", "--platform", "linux/amd64", srcRef, "--format", `{{if gt .Age.Hours 24.0}}stale{{end}}`)
+	if err != nil {
+		t.Fatalf("failed to run image age: %v", err)
+	}
+	if out != "stale" {
+		t.Errorf("unexpected output: %s", out)
+	}
+	_, err = cobraTest(t, nil, "image", "age", "ocidir://../../testdata/testrepo:a1")
+	if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+		t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+	}
+}
+
+func TestImageCheck(t *testing.T) {
+	srcRef := "ocidir://../../testdata/testrepo:v1"
+	tt := []struct {
+		name        string
+		cmd         []string
+		expectOut   string
+		expectErr   error
+		outContains bool
+	}{
+		{
+			name:        "default",
+			cmd:         []string{"image", "check", srcRef},
+			expectOut:   "layer",
+			outContains: true,
+		},
+		{
+			name:      "deep platform",
+			cmd:       []string{"image", "check", "--deep", "--platform", "linux/amd64", srcRef, "--format", `{{range .Results}}{{.Type}} {{if .Error}}{{.Error}}{{else}}ok{{end}}{{println}}{{end}}`},
+			expectOut: "index ok\nmanifest ok\nconfig ok\nlayer ok\nlayer ok",
+		},
+		{
+			name:      "missing",
+			cmd:       []string{"image", "check", "ocidir://../../testdata/testrepo:missing"},
+			expectErr: errs.ErrNotFound,
+		},
+		{
+			name:      "invalid ref",
+			cmd:       []string{"image", "check", "invalid://ref*format"},
+			expectErr: errs.ErrInvalidReference,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cobraTest(t, nil, tc.cmd...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) && err.Error() != tc.expectErr.Error() {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if (!tc.outContains && out != tc.expectOut) || (tc.outContains && !strings.Contains(out, tc.expectOut)) {
+				t.Errorf("unexpected output, expected %s, received %s", tc.expectOut, out)
+			}
+		})
+	}
+}
+
 func TestImageCopy(t *testing.T) {
 	tempDir := t.TempDir()
 	srcRef := "ocidir://../../testdata/testrepo:v2"
@@ -67,6 +144,32 @@ func TestImageCopy(t *testing.T) {
 			args:      []string{"image", "copy", srcRef, tsHost + "/newrepo:v4", "--referrers", "--referrers-src", "ocidir://../../testdata/external", "--referrers-tgt", tsHost + "/external"},
 			expectOut: tsHost + "/newrepo:v4",
 		},
+		{
+			name:      "ocidir-to-reg-add-tag",
+			args:      []string{"image", "copy", srcRef, tsHost + "/newrepo:v5.0.0", "--add-tag", "v5.0", "--add-tag", "v5"},
+			expectOut: tsHost + "/newrepo:v5.0.0",
+		},
+		{
+			name:      "ocidir-to-ocidir-config-only",
+			args:      []string{"image", "copy", "--config-only", srcRef, "ocidir://" + tempDir + "metadata:v2"},
+			expectOut: "ocidir://" + tempDir + "metadata:v2",
+		},
+		{
+			name:      "ocidir-to-ocidir-expect-digest",
+			args:      []string{"image", "copy", "--expect-digest", "sha256:dfae8f425735a5e3a72e40d6609e03079995511d48157c74d54801ff4430491e", srcRef, "ocidir://" + tempDir + "expect:v2"},
+			expectOut: "ocidir://" + tempDir + "expect:v2",
+		},
+		{
+			name:      "ocidir-to-ocidir-expect-digest-mismatch",
+			args:      []string{"image", "copy", "--expect-digest", "sha256:190c9253f7a319f0d7f7b8cdd8c63894051be55aeb0c319555e5d075b229cf09", srcRef, "ocidir://" + tempDir + "expect:v2"},
+			expectErr: errs.ErrDigestMismatch,
+		},
+		{
+			name:        "reg-added-tag",
+			args:        []string{"image", "digest", tsHost + "/newrepo:v5"},
+			expectOut:   "sha256:",
+			outContains: true,
+		},
 	}
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -125,6 +228,24 @@ func TestImageExportImport(t *testing.T) {
 		t.Errorf("unexpected output: %v", out)
 	}
 
+	// validating reports the digest of the imported image without pushing
+	outDigest, err := cobraTest(t, nil, "image", "digest", importRefA)
+	if err != nil {
+		t.Fatalf("failed to get digest of imported image: %v", err)
+	}
+	importRefValidate := fmt.Sprintf("ocidir://%s/repo:validate", tmpDir)
+	out, err = cobraTest(t, nil, "image", "import", "--validate", "--format", "{{.Desc.Digest}}", importRefValidate, exportFile)
+	if err != nil {
+		t.Fatalf("failed to run image import --validate: %v", err)
+	}
+	if out != outDigest {
+		t.Errorf("unexpected validate output, expected %s, received %s", outDigest, out)
+	}
+	_, err = cobraTest(t, nil, "image", "digest", importRefValidate)
+	if err == nil {
+		t.Errorf("validated image was pushed")
+	}
+
 	out, err = cobraTest(t, nil, "image", "export", "--name", exportName, "--platform", "linux/amd64", srcRef, exportFile)
 	if err != nil {
 		t.Fatalf("failed to run image export: %v", err)
@@ -132,6 +253,218 @@ func TestImageExportImport(t *testing.T) {
 	if out != "" {
 		t.Errorf("unexpected output: %v", out)
 	}
+
+	importRefB := fmt.Sprintf("ocidir://%s/repo:load", tmpDir)
+	out, err = cobraTest(t, nil, "image", "load", importRefB, exportFile)
+	if err != nil {
+		t.Fatalf("failed to run image load: %v", err)
+	}
+	if out != "" {
+		t.Errorf("unexpected output: %v", out)
+	}
+	out, err = cobraTest(t, nil, "image", "inspect", importRefB, "--format", "{{.Platform}}")
+	if err != nil {
+		t.Fatalf("failed to inspect loaded image: %v", err)
+	}
+	if out != "linux/amd64" {
+		t.Errorf("unexpected platform for loaded image: %s", out)
+	}
+
+	exportBytes, err := os.ReadFile(exportFile)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	importRefC := fmt.Sprintf("ocidir://%s/repo:stdin", tmpDir)
+	out, err = cobraTest(t, &cobraTestOpts{stdin: bytes.NewReader(exportBytes)}, "image", "import", importRefC, "-")
+	if err != nil {
+		t.Fatalf("failed to run image import from stdin: %v", err)
+	}
+	if out != "" {
+		t.Errorf("unexpected output: %v", out)
+	}
+	out, err = cobraTest(t, nil, "image", "inspect", importRefC, "--format", "{{.Platform}}")
+	if err != nil {
+		t.Fatalf("failed to inspect imported image: %v", err)
+	}
+	if out != "linux/amd64" {
+		t.Errorf("unexpected platform for imported image: %s", out)
+	}
+
+	// skipping a layer updates the config to match the remaining layers
+	skipFile := tmpDir + "/skip.tar"
+	out, err = cobraTest(t, nil, "image", "export", "--layer-skip", "0", "--platform", "linux/amd64", srcRef, skipFile)
+	if err != nil {
+		t.Fatalf("failed to run image export with a skipped layer: %v", err)
+	}
+	if out != "" {
+		t.Errorf("unexpected output: %v", out)
+	}
+	importRefD := fmt.Sprintf("ocidir://%s/repo:skip", tmpDir)
+	_, err = cobraTest(t, nil, "image", "import", importRefD, skipFile)
+	if err != nil {
+		t.Fatalf("failed to import image with a skipped layer: %v", err)
+	}
+	outLayers, err := cobraTest(t, nil, "manifest", "get", "--platform", "linux/amd64", srcRef, "--format", "{{len .Layers}}")
+	if err != nil {
+		t.Fatalf("failed to get source manifest: %v", err)
+	}
+	out, err = cobraTest(t, nil, "image", "inspect", importRefD, "--format", "{{len .RootFS.DiffIDs}}")
+	if err != nil {
+		t.Fatalf("failed to inspect image with a skipped layer: %v", err)
+	}
+	srcLayers, err := strconv.Atoi(outLayers)
+	if err != nil {
+		t.Fatalf("failed to parse layer count %s: %v", outLayers, err)
+	}
+	if out != fmt.Sprintf("%d", srcLayers-1) {
+		t.Errorf("unexpected diff_ids, expected %d, received %s", srcLayers-1, out)
+	}
+	_, err = cobraTest(t, nil, "image", "export", "--layer-skip", "invalid", srcRef, skipFile)
+	if err == nil {
+		t.Errorf("export with an invalid layer to skip did not fail")
+	}
+
+	// a compressed export is detected by the import
+	zstdFile := tmpDir + "/export.tar.zst"
+	_, err = cobraTest(t, nil, "image", "export", "--compression", "zstd", srcRef, zstdFile)
+	if err != nil {
+		t.Fatalf("failed to run image export with zstd: %v", err)
+	}
+	importRefE := fmt.Sprintf("ocidir://%s/repo:zstd", tmpDir)
+	_, err = cobraTest(t, nil, "image", "import", importRefE, zstdFile)
+	if err != nil {
+		t.Fatalf("failed to import zstd export: %v", err)
+	}
+	_, err = cobraTest(t, nil, "image", "export", "--compression", "invalid", srcRef, zstdFile)
+	if err == nil {
+		t.Errorf("export with an invalid compression did not fail")
+	}
+
+	// a failed export removes the partial output file
+	missingFile := tmpDir + "/missing.tar"
+	_, err = cobraTest(t, nil, "image", "export", "ocidir://../../testdata/testrepo:missing", missingFile)
+	if err == nil {
+		t.Errorf("export of a missing image did not fail")
+	}
+	if _, err := os.Stat(missingFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("partial export was not removed: %v", err)
+	}
+}
+
+func TestImageDiff(t *testing.T) {
+	refV1 := "ocidir://../../testdata/testrepo:v1"
+	refV2 := "ocidir://../../testdata/testrepo:v2"
+	tt := []struct {
+		name        string
+		cmd         []string
+		expectOut   string
+		expectErr   error
+		outContains bool
+	}{
+		{
+			name:        "default",
+			cmd:         []string{"image", "diff", "--platform", "linux/amd64", refV1, refV2},
+			expectOut:   "  - label: version=1\n  + label: version=2",
+			outContains: true,
+		},
+		{
+			name:      "format layers",
+			cmd:       []string{"image", "diff", "--platform", "linux/amd64", refV1, refV2, "--format", `{{len .LayersShared}} {{len .LayersOnlyA}} {{len .LayersOnlyB}}`},
+			expectOut: "2 0 1",
+		},
+		{
+			name:        "same image",
+			cmd:         []string{"image", "diff", "--platform", "linux/amd64", refV1, refV1},
+			expectOut:   "  no changes",
+			outContains: true,
+		},
+		{
+			name:      "artifact",
+			cmd:       []string{"image", "diff", refV1, "ocidir://../../testdata/testrepo:a1"},
+			expectErr: errs.ErrUnsupportedMediaType,
+		},
+		{
+			name:      "invalid ref",
+			cmd:       []string{"image", "diff", refV1, "invalid://ref*format"},
+			expectErr: errs.ErrInvalidReference,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cobraTest(t, nil, tc.cmd...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) && err.Error() != tc.expectErr.Error() {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if (!tc.outContains && out != tc.expectOut) || (tc.outContains && !strings.Contains(out, tc.expectOut)) {
+				t.Errorf("unexpected output, expected %s, received %s", tc.expectOut, out)
+			}
+		})
+	}
+}
+
+func TestImageHistory(t *testing.T) {
+	srcRef := "ocidir://../../testdata/testrepo:v1"
+	tt := []struct {
+		name        string
+		cmd         []string
+		expectOut   string
+		expectErr   error
+		outContains bool
+	}{
+		{
+			name:        "default",
+			cmd:         []string{"image", "history", "--platform", "linux/amd64", srcRef},
+			expectOut:   "COPY layer1.txt /layer1",
+			outContains: true,
+		},
+		{
+			name:      "format layers",
+			cmd:       []string{"image", "history", "--platform", "linux/amd64", srcRef, "--format", `{{range .History}}{{if .Layer}}{{.CreatedBy}}{{println}}{{end}}{{end}}`},
+			expectOut: "COPY base-a.txt /base.txt # buildkit\nCOPY layer1.txt /layer1 # buildkit",
+		},
+		{
+			name:      "format empty",
+			cmd:       []string{"image", "history", "--platform", "linux/amd64", srcRef, "--format", `{{range .History}}{{if .EmptyLayer}}{{.CreatedBy}}{{println}}{{end}}{{end}}`},
+			expectOut: "LABEL base=a\nARG arg=value\nARG arg_label\nLABEL arg_label=arg_for_label\nLABEL version=1\nVOLUME [/volume]",
+		},
+		{
+			name:      "artifact",
+			cmd:       []string{"image", "history", "ocidir://../../testdata/testrepo:a1"},
+			expectErr: errs.ErrUnsupportedMediaType,
+		},
+		{
+			name:      "invalid ref",
+			cmd:       []string{"image", "history", "invalid://ref*format"},
+			expectErr: errs.ErrInvalidReference,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cobraTest(t, nil, tc.cmd...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) && err.Error() != tc.expectErr.Error() {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if (!tc.outContains && out != tc.expectOut) || (tc.outContains && !strings.Contains(out, tc.expectOut)) {
+				t.Errorf("unexpected output, expected %s, received %s", tc.expectOut, out)
+			}
+		})
+	}
 }
 
 func TestImageInspect(t *testing.T) {
@@ -211,6 +544,72 @@ func TestImageInspect(t *testing.T) {
 	}
 }
 
+func TestImageLabels(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcRef := "ocidir://../../testdata/testrepo:v3"
+	noLabelRef := fmt.Sprintf("ocidir://%s/repo:nolabel", tmpDir)
+	_, err := cobraTest(t, nil, "image", "mod", "ocidir://../../testdata/testrepo:b1", "--create", noLabelRef, "--label", "base")
+	if err != nil {
+		t.Fatalf("failed to create image without labels: %v", err)
+	}
+	tt := []struct {
+		name      string
+		cmd       []string
+		expectOut string
+		expectErr error
+	}{
+		{
+			name:      "default",
+			cmd:       []string{"image", "labels", "--platform", "linux/amd64", srcRef},
+			expectOut: "arg_label=arg_for_label\nversion=3",
+		},
+		{
+			name:      "format",
+			cmd:       []string{"image", "labels", "--platform", "linux/amd64", srcRef, "--format", `{{ index . "version" }}`},
+			expectOut: "3",
+		},
+		{
+			name:      "json",
+			cmd:       []string{"image", "labels", "--platform", "linux/amd64", srcRef, "--output-format", "json"},
+			expectOut: `{"arg_label":"arg_for_label","version":"3"}`,
+		},
+		{
+			name:      "no labels",
+			cmd:       []string{"image", "labels", "--platform", "linux/amd64", noLabelRef},
+			expectOut: "",
+		},
+		{
+			name:      "no labels json",
+			cmd:       []string{"image", "labels", "--platform", "linux/amd64", noLabelRef, "--output-format", "json"},
+			expectOut: "{}",
+		},
+		{
+			name:      "artifact",
+			cmd:       []string{"image", "labels", "ocidir://../../testdata/testrepo:a1"},
+			expectErr: errs.ErrUnsupportedMediaType,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cobraTest(t, nil, tc.cmd...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) && err.Error() != tc.expectErr.Error() {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if out != tc.expectOut {
+				t.Errorf("unexpected output, expected %s, received %s", tc.expectOut, out)
+			}
+		})
+	}
+}
+
 func TestImageMod(t *testing.T) {
 	tmpDir := t.TempDir()
 	srcRef := "ocidir://../../testdata/testrepo:v3"
@@ -243,6 +642,21 @@ func TestImageMod(t *testing.T) {
 			cmd:       []string{"image", "mod", srcRef, "--create", modRef, "--time", "set=2000-01-01T00:00:00Z,base-ref=" + baseRef},
 			expectOut: modRef,
 		},
+		{
+			name:      "config-created",
+			cmd:       []string{"image", "mod", srcRef, "--create", modRef, "--config-created", "2000-01-01T00:00:00Z"},
+			expectOut: modRef,
+		},
+		{
+			name:      "config-created-now",
+			cmd:       []string{"image", "mod", srcRef, "--create", modRef, "--config-created", "now"},
+			expectOut: modRef,
+		},
+		{
+			name:      "config-created-invalid",
+			cmd:       []string{"image", "mod", srcRef, "--create", modRef, "--config-created", "yesterday"},
+			expectErr: fmt.Errorf(`invalid argument "yesterday" for "--config-created" flag: time must be "now" or formatted 2006-01-02T15:04:05Z07:00: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`),
+		},
 	}
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,6 +10,7 @@ import (
 	"syscall"
 
 	"github.com/regclient/regclient/internal/godbg"
+	"github.com/regclient/regclient/types/errs"
 )
 
 func main() {
@@ -31,6 +33,8 @@ func main() {
 		switch {
 		case strings.Contains(err.Error(), "http: server gave HTTP response to HTTPS client"):
 			fmt.Fprintf(os.Stderr, "Try updating your registry with \"regctl registry set --tls disabled <registry>\"\n")
+		case errors.Is(err, errs.ErrDeleteDisabled):
+			fmt.Fprintf(os.Stderr, "This registry has deletion disabled, for distribution/registry enable REGISTRY_STORAGE_DELETE_ENABLED\n")
 		}
 		os.Exit(1)
 	}
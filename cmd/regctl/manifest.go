@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -29,11 +30,13 @@ type manifestCmd struct {
 	formatGet     string
 	formatHead    string
 	formatPut     string
+	formatResolve string
 	list          bool
 	platform      string
 	referrers     bool
 	requireDigest bool
 	requireList   bool
+	validate      bool
 }
 
 func NewManifestCmd(rootOpts *rootCmd) *cobra.Command {
@@ -126,6 +129,28 @@ regctl manifest head alpine --format raw-headers`,
 		RunE:              manifestOpts.runManifestHead,
 	}
 
+	var manifestResolveCmd = &cobra.Command{
+		Use:   "resolve <image_ref>",
+		Short: "resolve a reference to a tag and digest",
+		Long: `Resolves a reference to the full name including the registry, repository, tag, and digest.
+This pinned reference is useful for deployments that should not change when a tag is updated.
+With "--platform", the output also includes the Index and Manifest descriptors,
+and PlatformRef is the reference pinned to the platform specific manifest.`,
+		Example: `
+# show the pinned reference for an image
+regctl manifest resolve alpine:3.14
+
+# show the digest and tag separately
+regctl manifest resolve alpine:3.14 --format '{{ .Tag }} {{ .Digest }}'
+
+# show the index digest and the digest of the linux/arm64 image
+regctl manifest resolve alpine:3.14 --platform linux/arm64 \
+  --format '{{ .Index.Digest }} {{ .Manifest.Digest }}'`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: rootOpts.completeArgTag,
+		RunE:              manifestOpts.runManifestResolve,
+	}
+
 	var manifestPutCmd = &cobra.Command{
 		Use:     "put <image_ref>",
 		Aliases: []string{"push"},
@@ -135,6 +160,10 @@ regctl manifest head alpine --format raw-headers`,
 # push an image manifest
 regctl manifest put \
   --content-type application/vnd.oci.image.manifest.v1+json \
+  registry.example.org/repo:v1 <manifest.json
+
+# validate a hand built manifest before pushing
+regctl manifest put --validate \
   registry.example.org/repo:v1 <manifest.json`,
 		Args:              cobra.ExactArgs(1),
 		ValidArgsFunction: rootOpts.completeArgTag,
@@ -167,12 +196,19 @@ regctl manifest put \
 	manifestPutCmd.Flags().StringVarP(&manifestOpts.contentType, "content-type", "t", "", "Specify content-type (e.g. application/vnd.docker.distribution.manifest.v2+json)")
 	_ = manifestPutCmd.RegisterFlagCompletionFunc("content-type", completeArgMediaTypeManifest)
 	manifestPutCmd.Flags().StringVarP(&manifestOpts.formatPut, "format", "", "", "Format output with go template syntax")
+	manifestPutCmd.Flags().BoolVarP(&manifestOpts.validate, "validate", "", false, "Validate the manifest against the schema of its media type before pushing")
+
+	manifestResolveCmd.Flags().StringVarP(&manifestOpts.formatResolve, "format", "", "{{ printf \"%s\\n\" .CommonName }}", "Format output with go template syntax")
+	manifestResolveCmd.Flags().StringVarP(&manifestOpts.platform, "platform", "p", "", "Specify platform to also resolve the platform specific manifest (e.g. linux/amd64 or local)")
+	_ = manifestResolveCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+	_ = manifestResolveCmd.RegisterFlagCompletionFunc("platform", completeArgPlatform)
 
 	manifestTopCmd.AddCommand(manifestDeleteCmd)
 	manifestTopCmd.AddCommand(manifestDiffCmd)
 	manifestTopCmd.AddCommand(manifestHeadCmd)
 	manifestTopCmd.AddCommand(manifestGetCmd)
 	manifestTopCmd.AddCommand(manifestPutCmd)
+	manifestTopCmd.AddCommand(manifestResolveCmd)
 	return manifestTopCmd
 }
 
@@ -292,7 +328,7 @@ func (manifestOpts *manifestCmd) runManifestHead(cmd *cobra.Command, args []stri
 		slog.String("tag", r.Tag))
 
 	mOpts := []regclient.ManifestOpts{}
-	if manifestOpts.requireDigest || (!flagChanged(cmd, "require-digest") && !flagChanged(cmd, "format")) {
+	if manifestOpts.requireDigest || (!flagChanged(cmd, "require-digest") && (!flagChanged(cmd, "format") || manifestOpts.rootOpts.outputFormat == "json")) {
 		mOpts = append(mOpts, regclient.WithManifestRequireDigest())
 	}
 	if manifestOpts.platform != "" {
@@ -307,6 +343,9 @@ func (manifestOpts *manifestCmd) runManifestHead(cmd *cobra.Command, args []stri
 	if err != nil {
 		return err
 	}
+	if manifestOpts.rootOpts.outputFormat == "json" {
+		return template.Writer(cmd.OutOrStdout(), manifestOpts.formatHead, m.GetDescriptor())
+	}
 
 	switch manifestOpts.formatHead {
 	case "", "digest":
@@ -389,6 +428,11 @@ func (manifestOpts *manifestCmd) runManifestPut(cmd *cobra.Command, args []strin
 	if err != nil {
 		return err
 	}
+	if manifestOpts.validate {
+		if errList := manifest.Validate(rcM); len(errList) > 0 {
+			return errors.Join(errList...)
+		}
+	}
 	if manifestOpts.byDigest {
 		r.Tag = ""
 		r.Digest = rcM.GetDescriptor().Digest.String()
@@ -409,3 +453,36 @@ func (manifestOpts *manifestCmd) runManifestPut(cmd *cobra.Command, args []strin
 	}
 	return template.Writer(cmd.OutOrStdout(), manifestOpts.formatPut, result)
 }
+
+func (manifestOpts *manifestCmd) runManifestResolve(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	r, err := ref.New(args[0])
+	if err != nil {
+		return err
+	}
+	rc := manifestOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, r)
+
+	manifestOpts.rootOpts.log.Debug("Manifest resolve",
+		slog.String("host", r.Registry),
+		slog.String("repo", r.Repository),
+		slog.String("tag", r.Tag),
+		slog.String("platform", manifestOpts.platform))
+
+	if manifestOpts.platform != "" {
+		p, err := platform.Parse(manifestOpts.platform)
+		if err != nil {
+			return fmt.Errorf("failed to parse platform %s: %w", manifestOpts.platform, err)
+		}
+		rp, err := rc.ResolvePlatform(ctx, r, p)
+		if err != nil {
+			return err
+		}
+		return template.Writer(cmd.OutOrStdout(), manifestOpts.formatResolve, rp)
+	}
+	rOut, err := rc.ResolveRef(ctx, r)
+	if err != nil {
+		return err
+	}
+	return template.Writer(cmd.OutOrStdout(), manifestOpts.formatResolve, rOut)
+}
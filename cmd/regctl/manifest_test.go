@@ -71,3 +71,110 @@ func TestManifestHead(t *testing.T) {
 	}
 
 }
+
+func TestManifestResolve(t *testing.T) {
+	tt := []struct {
+		name        string
+		args        []string
+		expectErr   error
+		expectOut   string
+		outContains bool
+	}{
+		{
+			name:      "Missing arg",
+			args:      []string{"manifest", "resolve"},
+			expectErr: fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name:      "Missing manifest",
+			args:      []string{"manifest", "resolve", "ocidir://../../testdata/testrepo:missing"},
+			expectErr: errs.ErrNotFound,
+		},
+		{
+			name:        "Tag and digest",
+			args:        []string{"manifest", "resolve", "ocidir://../../testdata/testrepo:v1"},
+			expectOut:   "ocidir://../../testdata/testrepo:v1@sha256:",
+			outContains: true,
+		},
+		{
+			name:      "Missing digest",
+			args:      []string{"manifest", "resolve", "ocidir://../../testdata/testrepo:v1@sha256:0000000000000000000000000000000000000000000000000000000000000000"},
+			expectErr: errs.ErrNotFound,
+		},
+		{
+			name:        "Platform",
+			args:        []string{"manifest", "resolve", "ocidir://../../testdata/testrepo:v1", "--platform", "linux/arm64", "--format", "{{ .PlatformRef.CommonName }}"},
+			expectOut:   "ocidir://../../testdata/testrepo@sha256:",
+			outContains: true,
+		},
+		{
+			name:      "Missing platform",
+			args:      []string{"manifest", "resolve", "ocidir://../../testdata/testrepo:v1", "--platform", "linux/s390x"},
+			expectErr: errs.ErrNotFound,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cobraTest(t, nil, tc.args...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) && err.Error() != tc.expectErr.Error() {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if (!tc.outContains && out != tc.expectOut) || (tc.outContains && !strings.Contains(out, tc.expectOut)) {
+				t.Errorf("unexpected output, expected %s, received %s", tc.expectOut, out)
+			}
+		})
+	}
+}
+
+func TestManifestPut(t *testing.T) {
+	tempDir := t.TempDir()
+	mValid := `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","artifactType":"application/example","config":{"mediaType":"application/vnd.oci.empty.v1+json","digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","size":2},"layers":[]}`
+	mInvalid := `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.empty.v1+json","digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","size":2},"layers":[{"digest":"sha256:invalid","size":-1}]}`
+	tt := []struct {
+		name      string
+		args      []string
+		stdin     string
+		expectErr error
+	}{
+		{
+			name:  "Valid",
+			args:  []string{"manifest", "put", "--validate", "ocidir://" + tempDir + "/testrepo:valid"},
+			stdin: mValid,
+		},
+		{
+			name:      "Invalid",
+			args:      []string{"manifest", "put", "--validate", "ocidir://" + tempDir + "/testrepo:invalid"},
+			stdin:     mInvalid,
+			expectErr: errs.ErrInvalidManifest,
+		},
+		{
+			name:  "Invalid without validate",
+			args:  []string{"manifest", "put", "ocidir://" + tempDir + "/testrepo:invalid"},
+			stdin: mInvalid,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := cobraTest(t, &cobraTestOpts{stdin: strings.NewReader(tc.stdin)}, tc.args...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+		})
+	}
+}
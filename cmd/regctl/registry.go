@@ -16,17 +16,25 @@ import (
 	"github.com/regclient/regclient"
 	"github.com/regclient/regclient/config"
 	"github.com/regclient/regclient/pkg/template"
+	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/types/ref"
 )
 
+// registryRateLimitRef is the image Docker Hub provides for checking the rate limit.
+const registryRateLimitRef = "docker.io/ratelimitpreview/test:latest"
+
 type registryCmd struct {
 	rootOpts             *rootCmd
+	formatCapabilities   string
 	formatConf           string
+	formatRateLimit      string
 	user, pass           string // login opts
 	passStdin            bool
+	probeDelete          bool
 	credHelper           string
 	hostname, pathPrefix string
 	cacert, tls          string // set opts
+	protocol             string
 	clientCert           string
 	clientKey            string
 	mirrors              []string
@@ -35,6 +43,7 @@ type registryCmd struct {
 	blobChunk, blobMax   int64
 	reqPerSec            float64
 	reqConcurrent        int64
+	blobConcurrent       int64
 	skipCheck            bool
 	apiOpts              []string
 	scheme               string   // TODO: remove
@@ -102,6 +111,50 @@ regctl registry logout registry.example.org`,
 		ValidArgsFunction: registryArgListReg,
 		RunE:              registryOpts.runRegistryLogout,
 	}
+	var registryCapabilitiesCmd = &cobra.Command{
+		Use:     "capabilities <image_ref>",
+		Aliases: []string{"caps"},
+		Short:   "probe the features supported by a registry",
+		Long: `Probes a registry for support of OCI manifests, the referrers API, and the delete API.
+There is no standard discovery API, so this is a best effort check and each value may be "unknown".
+OCI manifest support is only detected when the image is an OCI manifest or index.
+The delete API is only checked with --probe-delete, which sends a delete request for a digest that does not exist.
+Registries returning a 404 for that request are reported as "unknown".`,
+		Example: `
+# probe a registry using an existing image
+regctl registry capabilities registry.example.org/repo:v1
+
+# check if the referrers API is supported
+regctl registry capabilities --format '{{.Referrers}}' registry.example.org/repo:v1
+
+# include the delete API in the probe
+regctl registry capabilities --probe-delete registry.example.org/repo:v1`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: rootOpts.completeArgTag,
+		RunE:              registryOpts.runRegistryCapabilities,
+	}
+	var registryRateLimitCmd = &cobra.Command{
+		Use:     "ratelimit [image_ref]",
+		Aliases: []string{"rate-limit"},
+		Short:   "show the remaining rate limit",
+		Long: `Shows the rate limit of a registry using an http head request, which does not count against the limit.
+Without an image, the Docker Hub rate limit is checked with the ` + registryRateLimitRef + ` image.
+If Set is false, the Remain value was not provided.
+The other values may be 0 if not provided by the registry.
+Policies include the window for the limit in seconds (e.g. "100;w=21600").`,
+		Example: `
+# show the Docker Hub rate limit
+regctl registry ratelimit
+
+# show the number of pulls remaining before starting a large job
+regctl registry ratelimit --format '{{.Remain}}'
+
+# show the rate limit for pulling a specific image
+regctl registry ratelimit registry.example.org/repo:v1`,
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: rootOpts.completeArgTag,
+		RunE:              registryOpts.runRegistryRateLimit,
+	}
 	var registrySetCmd = &cobra.Command{
 		Use:   "set <registry>",
 		Short: "set options on a registry",
@@ -117,7 +170,10 @@ regctl registry set registry.example.org --cacert "$(cat reg-ca.crt)"
 regctl registry set docker.io --mirror hub-mirror.example.org
 
 # specify the requests per sec throttle
-regctl registry set quay.io --req-per-sec 10`,
+regctl registry set quay.io --req-per-sec 10
+
+# disable HTTP/2 for a registry
+regctl registry set registry.example.org --protocol http1`,
 		Args:              cobra.RangeArgs(0, 1),
 		ValidArgsFunction: registryArgListReg,
 		RunE:              registryOpts.runRegistrySet,
@@ -132,11 +188,19 @@ regctl registry set quay.io --req-per-sec 10`,
 	_ = registryLoginCmd.RegisterFlagCompletionFunc("user", completeArgNone)
 	_ = registryLoginCmd.RegisterFlagCompletionFunc("pass", completeArgNone)
 
+	registryCapabilitiesCmd.Flags().StringVar(&registryOpts.formatCapabilities, "format", "{{printPretty .}}", "Format output with go template syntax")
+	_ = registryCapabilitiesCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+	registryCapabilitiesCmd.Flags().BoolVar(&registryOpts.probeDelete, "probe-delete", false, "Send a delete request to detect support for the delete API")
+
+	registryRateLimitCmd.Flags().StringVar(&registryOpts.formatRateLimit, "format", "{{printPretty .}}", "Format output with go template syntax")
+	_ = registryRateLimitCmd.RegisterFlagCompletionFunc("format", completeArgNone)
+
 	registrySetCmd.Flags().StringVar(&registryOpts.credHelper, "cred-helper", "", "Credential helper (full binary name, including docker-credential- prefix)")
 	registrySetCmd.Flags().StringVar(&registryOpts.cacert, "cacert", "", "CA Certificate (not a filename, use \"$(cat ca.pem)\" to use a file)")
 	registrySetCmd.Flags().StringVar(&registryOpts.clientCert, "client-cert", "", "Client certificate for mTLS (not a filename, use \"$(cat client.pem)\" to use a file)")
 	registrySetCmd.Flags().StringVar(&registryOpts.clientKey, "client-key", "", "Client key for mTLS (not a filename, use \"$(cat client.key)\" to use a file)")
 	registrySetCmd.Flags().StringVar(&registryOpts.tls, "tls", "", "TLS (enabled, insecure, disabled)")
+	registrySetCmd.Flags().StringVar(&registryOpts.protocol, "protocol", "", "HTTP protocol (auto, http1, http2)")
 	registrySetCmd.Flags().StringVar(&registryOpts.hostname, "hostname", "", "Hostname or ip with port")
 	registrySetCmd.Flags().StringVar(&registryOpts.pathPrefix, "path-prefix", "", "Prefix to all repositories")
 	registrySetCmd.Flags().StringArrayVar(&registryOpts.mirrors, "mirror", nil, "List of mirrors (registry names)")
@@ -146,6 +210,7 @@ regctl registry set quay.io --req-per-sec 10`,
 	registrySetCmd.Flags().Int64Var(&registryOpts.blobMax, "blob-max", 0, "Blob size before switching to chunked push, -1 to disable")
 	registrySetCmd.Flags().Float64Var(&registryOpts.reqPerSec, "req-per-sec", 0, "Requests per second")
 	registrySetCmd.Flags().Int64Var(&registryOpts.reqConcurrent, "req-concurrent", 0, "Concurrent requests")
+	registrySetCmd.Flags().Int64Var(&registryOpts.blobConcurrent, "blob-concurrent", 0, "Concurrent blob transfers")
 	registrySetCmd.Flags().BoolVar(&registryOpts.skipCheck, "skip-check", false, "Skip checking connectivity to the registry")
 	registrySetCmd.Flags().StringArrayVar(&registryOpts.apiOpts, "api-opts", nil, "List of options (key=value))")
 	_ = registrySetCmd.RegisterFlagCompletionFunc("cacert", completeArgNone)
@@ -156,6 +221,13 @@ regctl registry set quay.io --req-per-sec 10`,
 			"disabled",
 		}, cobra.ShellCompDirectiveNoFileComp
 	})
+	_ = registrySetCmd.RegisterFlagCompletionFunc("protocol", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{
+			"auto",
+			"http1",
+			"http2",
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
 	_ = registrySetCmd.RegisterFlagCompletionFunc("hostname", completeArgNone)
 	_ = registrySetCmd.RegisterFlagCompletionFunc("path-prefix", completeArgNone)
 	_ = registrySetCmd.RegisterFlagCompletionFunc("mirror", completeArgNone)
@@ -169,9 +241,11 @@ regctl registry set quay.io --req-per-sec 10`,
 	_ = registrySetCmd.Flags().MarkHidden("scheme")
 	_ = registrySetCmd.Flags().MarkHidden("dns")
 
+	registryTopCmd.AddCommand(registryCapabilitiesCmd)
 	registryTopCmd.AddCommand(registryConfigCmd)
 	registryTopCmd.AddCommand(registryLoginCmd)
 	registryTopCmd.AddCommand(registryLogoutCmd)
+	registryTopCmd.AddCommand(registryRateLimitCmd)
 	registryTopCmd.AddCommand(registrySetCmd)
 	return registryTopCmd
 }
@@ -351,6 +425,41 @@ func (registryOpts *registryCmd) runRegistryLogout(cmd *cobra.Command, args []st
 	return nil
 }
 
+func (registryOpts *registryCmd) runRegistryCapabilities(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	r, err := ref.New(args[0])
+	if err != nil {
+		return err
+	}
+	rc := registryOpts.rootOpts.newRegClient()
+	defer rc.Close(ctx, r)
+
+	registryOpts.rootOpts.log.Debug("Registry capabilities",
+		slog.String("host", r.Registry),
+		slog.String("repo", r.Repository))
+
+	opts := []scheme.CapabilityOpts{}
+	if registryOpts.probeDelete {
+		opts = append(opts, scheme.WithCapabilityProbeDelete())
+	}
+	c, err := rc.PingCapabilities(ctx, r, opts...)
+	if err != nil {
+		return err
+	}
+	return template.Writer(cmd.OutOrStdout(), registryOpts.formatCapabilities, c)
+}
+
+func (registryOpts *registryCmd) runRegistryRateLimit(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		args = []string{registryRateLimitRef}
+	}
+	imageOpts := imageCmd{
+		rootOpts: registryOpts.rootOpts,
+		format:   registryOpts.formatRateLimit,
+	}
+	return imageOpts.runImageRateLimit(cmd, args)
+}
+
 func (registryOpts *registryCmd) runRegistrySet(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	c, err := ConfigLoadDefault()
@@ -384,6 +493,11 @@ func (registryOpts *registryCmd) runRegistrySet(cmd *cobra.Command, args []strin
 			return err
 		}
 	}
+	if flagChanged(cmd, "protocol") {
+		if err := h.Protocol.UnmarshalText([]byte(registryOpts.protocol)); err != nil {
+			return err
+		}
+	}
 	if flagChanged(cmd, "cacert") {
 		h.RegCert = registryOpts.cacert
 	}
@@ -420,6 +534,9 @@ func (registryOpts *registryCmd) runRegistrySet(cmd *cobra.Command, args []strin
 	if flagChanged(cmd, "req-concurrent") {
 		h.ReqConcurrent = registryOpts.reqConcurrent
 	}
+	if flagChanged(cmd, "blob-concurrent") {
+		h.BlobConcurrent = registryOpts.blobConcurrent
+	}
 	if flagChanged(cmd, "api-opts") {
 		if h.APIOpts == nil {
 			h.APIOpts = map[string]string{}
@@ -179,3 +179,144 @@ func TestRegistry(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryRateLimit(t *testing.T) {
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "../../testdata",
+		},
+	})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && strings.Contains(r.URL.Path, "/manifests/") {
+			w.Header().Set("RateLimit-Limit", "100;w=21600")
+			w.Header().Set("RateLimit-Remaining", "42;w=21600")
+		}
+		regHandler.ServeHTTP(w, r)
+	}))
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tempDir := t.TempDir()
+	t.Setenv(ConfigEnv, filepath.Join(tempDir, "config.json"))
+	_, err := cobraTest(t, nil, "registry", "set", tsHost, "--tls", "disabled")
+	if err != nil {
+		t.Fatalf("failed to disable TLS for internal registry")
+	}
+	tt := []struct {
+		name      string
+		args      []string
+		expectErr error
+		expectOut string
+	}{
+		{
+			name:      "remaining",
+			args:      []string{"registry", "ratelimit", tsHost + "/testrepo:v1", "--format", "{{.Set}} {{.Remain}} {{.Limit}} {{index .Policies 0}}"},
+			expectOut: "true 42 100 100;w=21600",
+		},
+		{
+			name:      "not provided",
+			args:      []string{"registry", "ratelimit", "ocidir://../../testdata/testrepo:v1", "--format", "{{.Set}}"},
+			expectOut: "false",
+		},
+		{
+			name:      "missing",
+			args:      []string{"registry", "ratelimit", tsHost + "/testrepo:missing"},
+			expectErr: errs.ErrNotFound,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cobraTest(t, nil, tc.args...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) && err.Error() != tc.expectErr.Error() {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if out != tc.expectOut {
+				t.Errorf("unexpected output, expected %s, received %s", tc.expectOut, out)
+			}
+		})
+	}
+}
+
+func TestRegistryCapabilities(t *testing.T) {
+	boolT := true
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "../../testdata",
+		},
+		API: oConfig.ConfigAPI{
+			DeleteEnabled: &boolT,
+		},
+	})
+	ts := httptest.NewServer(regHandler)
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tempDir := t.TempDir()
+	t.Setenv(ConfigEnv, filepath.Join(tempDir, "config.json"))
+	_, err := cobraTest(t, nil, "registry", "set", tsHost, "--tls", "disabled")
+	if err != nil {
+		t.Fatalf("failed to disable TLS for internal registry")
+	}
+	tt := []struct {
+		name      string
+		args      []string
+		expectErr error
+		expectOut string
+	}{
+		{
+			name:      "probe",
+			args:      []string{"registry", "capabilities", tsHost + "/testrepo:v1", "--format", "{{.OCIManifest}} {{.Referrers}} {{.Delete}}"},
+			expectOut: "yes yes unknown",
+		},
+		{
+			name:      "json",
+			args:      []string{"registry", "capabilities", tsHost + "/testrepo:missing", "--format", "{{json .}}"},
+			expectOut: `{"ociManifest":"unknown","referrers":"yes","delete":"unknown"}`,
+		},
+		{
+			name:      "probe delete",
+			args:      []string{"registry", "capabilities", tsHost + "/testrepo:v1", "--probe-delete", "--format", "{{.Delete}}"},
+			expectOut: "unknown",
+		},
+		{
+			name:      "ocidir",
+			args:      []string{"registry", "capabilities", "ocidir://../../testdata/testrepo:v1"},
+			expectErr: errs.ErrUnsupported,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cobraTest(t, nil, tc.args...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if out != tc.expectOut {
+				t.Errorf("unexpected output, expected %s, received %s", tc.expectOut, out)
+			}
+		})
+	}
+}
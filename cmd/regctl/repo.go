@@ -8,6 +8,7 @@ import (
 
 	"github.com/regclient/regclient/pkg/template"
 	"github.com/regclient/regclient/scheme"
+	"github.com/regclient/regclient/types/repo"
 )
 
 type repoCmd struct {
@@ -77,8 +78,23 @@ func (repoOpts *repoCmd) runRepoLs(cmd *cobra.Command, args []string) error {
 	}
 	rl, err := rc.RepoList(ctx, host, opts...)
 	if err != nil {
+		if rl == nil || rl.GetLast() == "" {
+			return err
+		}
+		// output the partial list and report the value to resume the listing
+		repoOpts.rootOpts.log.Warn("Repository listing interrupted, resume with --last",
+			slog.String("last", rl.GetLast()),
+			slog.String("err", err.Error()))
+		errOut := repoOpts.output(cmd, rl)
+		if errOut != nil {
+			return errOut
+		}
 		return err
 	}
+	return repoOpts.output(cmd, rl)
+}
+
+func (repoOpts *repoCmd) output(cmd *cobra.Command, rl *repo.RepoList) error {
 	switch repoOpts.format {
 	case "raw":
 		repoOpts.format = "{{ range $key,$vals := .RawHeaders}}{{range $val := $vals}}{{printf \"%s: %s\\n\" $key $val }}{{end}}{{end}}{{printf \"\\n%s\" .RawBody}}"
@@ -15,6 +15,7 @@ import (
 	"github.com/regclient/regclient/pkg/template"
 	"github.com/regclient/regclient/scheme/reg"
 	"github.com/regclient/regclient/types"
+	"github.com/regclient/regclient/types/errs"
 )
 
 const (
@@ -24,13 +25,14 @@ const (
 )
 
 type rootCmd struct {
-	name      string
-	verbosity string
-	logopts   []string
-	log       *slog.Logger
-	format    string // for Go template formatting of various commands
-	hosts     []string
-	userAgent string
+	name         string
+	verbosity    string
+	logopts      []string
+	log          *slog.Logger
+	format       string // for Go template formatting of various commands
+	outputFormat string // output mode applied to the format of each command
+	hosts        []string
+	userAgent    string
 }
 
 func NewRootCmd() (*cobra.Command, *rootCmd) {
@@ -82,12 +84,16 @@ regctl version --format '{{.VCSTag}}'`,
 	rootTopCmd.PersistentFlags().StringArrayVar(&rootOpts.logopts, "logopt", []string{}, "Log options")
 	rootTopCmd.PersistentFlags().StringArrayVar(&rootOpts.hosts, "host", []string{}, "Registry hosts to add (reg=registry,user=username,pass=password,tls=enabled)")
 	rootTopCmd.PersistentFlags().StringVarP(&rootOpts.userAgent, "user-agent", "", "", "Override user agent")
+	rootTopCmd.PersistentFlags().StringVarP(&rootOpts.outputFormat, "output-format", "", "", "Output mode (text, json), json replaces the --format of the command")
 
 	_ = rootTopCmd.RegisterFlagCompletionFunc("verbosity", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"debug", "info", "warn", "error", "fatal", "panic"}, cobra.ShellCompDirectiveNoFileComp
 	})
 	_ = rootTopCmd.RegisterFlagCompletionFunc("logopt", completeArgNone)
 	_ = rootTopCmd.RegisterFlagCompletionFunc("host", completeArgNone)
+	_ = rootTopCmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
 
 	versionCmd.Flags().StringVarP(&rootOpts.format, "format", "", "{{printPretty .}}", "Format output with go template syntax")
 	_ = versionCmd.RegisterFlagCompletionFunc("format", completeArgNone)
@@ -133,7 +139,25 @@ func (rootOpts *rootCmd) rootPreRun(cmd *cobra.Command, args []string) error {
 	} else {
 		rootOpts.log = slog.New(slog.NewTextHandler(cmd.ErrOrStderr(), &slog.HandlerOptions{Level: lvl}))
 	}
-	return nil
+	return rootOpts.outputSet(cmd)
+}
+
+// outputSet applies the output mode to the format flag of the command.
+func (rootOpts *rootCmd) outputSet(cmd *cobra.Command) error {
+	switch rootOpts.outputFormat {
+	case "", "text":
+		return nil
+	case "json":
+		if flagChanged(cmd, "format") {
+			return fmt.Errorf("--format cannot be combined with --output-format json%.0w", errs.ErrUnsupported)
+		}
+		if cmd.Flags().Lookup("format") == nil {
+			return fmt.Errorf("command %s does not support --output-format json%.0w", cmd.CommandPath(), errs.ErrUnsupported)
+		}
+		return cmd.Flags().Set("format", "{{json .}}")
+	default:
+		return fmt.Errorf("unknown output mode %s, must be text or json%.0w", rootOpts.outputFormat, errs.ErrUnsupported)
+	}
 }
 
 func (rootOpts *rootCmd) runVersion(cmd *cobra.Command, args []string) error {
@@ -171,6 +195,9 @@ func (rootOpts *rootCmd) newRegClient() *regclient.RegClient {
 	if conf.IncDockerCred == nil || *conf.IncDockerCred {
 		rcOpts = append(rcOpts, regclient.WithDockerCreds())
 	}
+	if conf.IncEnvCred != nil && *conf.IncEnvCred {
+		rcOpts = append(rcOpts, regclient.WithEnvCreds())
+	}
 	if conf.IncDockerCert == nil || *conf.IncDockerCert {
 		rcOpts = append(rcOpts, regclient.WithDockerCerts())
 	}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
+
+	"github.com/regclient/regclient/types/errs"
 )
 
 func TestRootConfigDir(t *testing.T) {
@@ -18,3 +22,79 @@ func TestRootConfigDir(t *testing.T) {
 		t.Errorf("missing output")
 	}
 }
+
+func TestRootOutput(t *testing.T) {
+	testRepo := "ocidir://../../testdata/testrepo"
+	tt := []struct {
+		name        string
+		args        []string
+		expectErr   error
+		expectOut   string
+		expectField string
+	}{
+		{
+			name:      "tag ls",
+			args:      []string{"tag", "ls", "--output-format", "json", "--include", "v[12]", testRepo},
+			expectOut: `{"name":"../../testdata/testrepo","tags":["v1","v2"]}`,
+		},
+		{
+			name:        "manifest head",
+			args:        []string{"manifest", "head", "--output-format", "json", testRepo + ":v1"},
+			expectField: "digest",
+		},
+		{
+			name:        "image inspect",
+			args:        []string{"image", "inspect", "--output-format", "json", "--platform", "linux/amd64", testRepo + ":v1"},
+			expectField: "architecture",
+		},
+		{
+			name:      "text",
+			args:      []string{"image", "digest", "--output-format", "text", testRepo + ":v1"},
+			expectOut: "sha256:190c9253f7a319f0d7f7b8cdd8c63894051be55aeb0c319555e5d075b229cf09",
+		},
+		{
+			name:      "format conflict",
+			args:      []string{"tag", "ls", "--output-format", "json", "--format", "{{.Tags}}", testRepo},
+			expectErr: errs.ErrUnsupported,
+		},
+		{
+			name:      "no format flag",
+			args:      []string{"tag", "rm", "--output-format", "json", testRepo + ":missing"},
+			expectErr: errs.ErrUnsupported,
+		},
+		{
+			name:      "unknown mode",
+			args:      []string{"tag", "ls", "--output-format", "yaml", testRepo},
+			expectErr: errs.ErrUnsupported,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cobraTest(t, nil, tc.args...)
+			if tc.expectErr != nil {
+				if err == nil {
+					t.Errorf("did not receive expected error: %v", tc.expectErr)
+				} else if !errors.Is(err, tc.expectErr) && err.Error() != tc.expectErr.Error() {
+					t.Errorf("unexpected error, received %v, expected %v", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if tc.expectOut != "" && out != tc.expectOut {
+				t.Errorf("unexpected output, expected %s, received %s", tc.expectOut, out)
+			}
+			if tc.expectField != "" {
+				result := map[string]any{}
+				err = json.Unmarshal([]byte(out), &result)
+				if err != nil {
+					t.Fatalf("failed to parse json output: %v, %s", err, out)
+				}
+				if _, ok := result[tc.expectField]; !ok {
+					t.Errorf("field %s missing from output: %s", tc.expectField, out)
+				}
+			}
+		})
+	}
+}
@@ -160,6 +160,10 @@ func (tagOpts *tagCmd) runTagLs(cmd *cobra.Command, args []string) error {
 		}
 		tl.Tags = filtered
 	}
+	if tagOpts.rootOpts.outputFormat == "json" {
+		// the raw body varies by scheme and does not include the filtering
+		return template.Writer(cmd.OutOrStdout(), tagOpts.format, tl.DockerList)
+	}
 	switch tagOpts.format {
 	case "raw":
 		tagOpts.format = "{{ range $key,$vals := .RawHeaders}}{{range $val := $vals}}{{printf \"%s: %s\\n\" $key $val }}{{end}}{{end}}{{printf \"\\n%s\" .RawBody}}"
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// envAuthPrefix is the prefix of environment variables containing a user:pass for a registry.
+	// The registry name follows the prefix, e.g. REGCLIENT_AUTH_REGISTRY_EXAMPLE_COM for registry.example.com.
+	envAuthPrefix = "REGCLIENT_AUTH_"
+	// envRegistryHost is the registry used with envRegistryUser and envRegistryPass, those are ignored when this is not set.
+	envRegistryHost = "REGISTRY_HOST"
+	// envRegistryUser is the username for the default registry.
+	envRegistryUser = "REGISTRY_USERNAME"
+	// envRegistryPass is the password for the default registry.
+	envRegistryPass = "REGISTRY_PASSWORD"
+)
+
+// EnvLoad returns a slice of hosts with credentials from environment variables.
+// Each REGCLIENT_AUTH_<host>=user:pass variable defines a login for a host.
+// The host is encoded with "_" for ".", "__" for "-", and "___" for ":", e.g. REGCLIENT_AUTH_LOCALHOST___5000 for localhost:5000.
+// REGISTRY_USERNAME and REGISTRY_PASSWORD define a login for REGISTRY_HOST, and are ignored when REGISTRY_HOST is not set.
+func EnvLoad() ([]Host, error) {
+	return envParse(os.Environ())
+}
+
+// envParse parses a list of key=value environment variables into a slice of Hosts.
+func envParse(environ []string) ([]Host, error) {
+	hosts := []Host{}
+	env := map[string]string{}
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if ok {
+			env[k] = v
+		}
+	}
+	// sort the variables for a consistent order
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		if strings.HasPrefix(k, envAuthPrefix) && len(k) > len(envAuthPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := envDecodeHost(strings.TrimPrefix(k, envAuthPrefix))
+		user, pass, ok := strings.Cut(env[k], ":")
+		if !ok || user == "" || pass == "" {
+			return nil, fmt.Errorf("invalid credentials in %s, expected user:pass", k)
+		}
+		h := HostNewName(name)
+		h.User = user
+		h.Pass = pass
+		hosts = append(hosts, *h)
+	}
+	// generic CI credentials are only used when the registry is explicitly named
+	if env[envRegistryHost] != "" && (env[envRegistryUser] != "" || env[envRegistryPass] != "") {
+		if env[envRegistryUser] == "" || env[envRegistryPass] == "" {
+			return nil, fmt.Errorf("both %s and %s must be set", envRegistryUser, envRegistryPass)
+		}
+		h := HostNewName(env[envRegistryHost])
+		h.User = env[envRegistryUser]
+		h.Pass = env[envRegistryPass]
+		hosts = append(hosts, *h)
+	}
+	return hosts, nil
+}
+
+// envDecodeHost converts the host portion of a variable name into a registry name.
+func envDecodeHost(s string) string {
+	s = strings.ReplaceAll(s, "___", ":")
+	s = strings.ReplaceAll(s, "__", "-")
+	s = strings.ReplaceAll(s, "_", ".")
+	return strings.ToLower(s)
+}
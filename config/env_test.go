@@ -0,0 +1,88 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		environ     []string
+		expectHosts []Host
+		expectErr   bool
+	}{
+		{
+			name:        "empty",
+			environ:     []string{"HOME=/home/user", "REGCLIENT_AUTH_="},
+			expectHosts: []Host{},
+		},
+		{
+			name: "auth hosts",
+			environ: []string{
+				"REGCLIENT_AUTH_REGISTRY_EXAMPLE_COM=user:pass:with:colons",
+				"REGCLIENT_AUTH_LOCALHOST___5000=local:secret",
+				"REGCLIENT_AUTH_MY__REG_EXAMPLE_ORG=dash:pass",
+			},
+			expectHosts: []Host{
+				{Name: "localhost:5000", Hostname: "localhost:5000", User: "local", Pass: "secret"},
+				{Name: "my-reg.example.org", Hostname: "my-reg.example.org", User: "dash", Pass: "pass"},
+				{Name: "registry.example.com", Hostname: "registry.example.com", User: "user", Pass: "pass:with:colons"},
+			},
+		},
+		{
+			name:        "default without host",
+			environ:     []string{"REGISTRY_USERNAME=ciuser", "REGISTRY_PASSWORD=cipass"},
+			expectHosts: []Host{},
+		},
+		{
+			name:    "default hub",
+			environ: []string{"REGISTRY_HOST=docker.io", "REGISTRY_USERNAME=hubuser", "REGISTRY_PASSWORD=hubpass"},
+			expectHosts: []Host{
+				{Name: DockerRegistry, Hostname: DockerRegistryDNS, User: "hubuser", Pass: "hubpass"},
+			},
+		},
+		{
+			name:    "default host",
+			environ: []string{"REGISTRY_HOST=ghcr.io", "REGISTRY_USERNAME=ghuser", "REGISTRY_PASSWORD=ghpass"},
+			expectHosts: []Host{
+				{Name: "ghcr.io", Hostname: "ghcr.io", User: "ghuser", Pass: "ghpass"},
+			},
+		},
+		{
+			name:      "missing pass",
+			environ:   []string{"REGCLIENT_AUTH_EXAMPLE_COM=user"},
+			expectErr: true,
+		},
+		{
+			name:      "missing default pass",
+			environ:   []string{"REGISTRY_HOST=ghcr.io", "REGISTRY_USERNAME=ghuser"},
+			expectErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hosts, err := envParse(tc.environ)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("parse did not fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if len(hosts) != len(tc.expectHosts) {
+				t.Fatalf("unexpected number of hosts, expected %d, received %d", len(tc.expectHosts), len(hosts))
+			}
+			for i, expect := range tc.expectHosts {
+				h := hosts[i]
+				if h.Name != expect.Name || h.Hostname != expect.Hostname || h.User != expect.User || h.Pass != expect.Pass {
+					t.Errorf("host %d mismatch, expected %s/%s/%s/%s, received %s/%s/%s/%s", i,
+						expect.Name, expect.Hostname, expect.User, expect.Pass,
+						h.Name, h.Hostname, h.User, h.Pass)
+				}
+			}
+		})
+	}
+}
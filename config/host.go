@@ -26,6 +26,20 @@ const (
 	TLSDisabled
 )
 
+// ProtocolConf specifies the HTTP protocol version used for a host.
+type ProtocolConf int
+
+const (
+	// ProtocolUndefined indicates the protocol is not passed, defaults to Auto.
+	ProtocolUndefined ProtocolConf = iota
+	// ProtocolAuto negotiates HTTP/2 with the registry when supported, falling back to HTTP/1.1.
+	ProtocolAuto
+	// ProtocolHTTP1 forces HTTP/1.1, disabling HTTP/2.
+	ProtocolHTTP1
+	// ProtocolHTTP2 attempts HTTP/2 even when the transport has a custom TLS or dial configuration.
+	ProtocolHTTP2
+)
+
 const (
 	// DockerRegistry is the name resolved in docker images on Hub.
 	DockerRegistry = "docker.io"
@@ -96,32 +110,85 @@ func (t *TLSConf) UnmarshalText(b []byte) error {
 	return nil
 }
 
+// MarshalJSON converts ProtocolConf to a json string using MarshalText.
+func (p ProtocolConf) MarshalJSON() ([]byte, error) {
+	s, err := p.MarshalText()
+	if err != nil {
+		return []byte(""), err
+	}
+	return json.Marshal(string(s))
+}
+
+// MarshalText converts ProtocolConf to a string.
+func (p ProtocolConf) MarshalText() ([]byte, error) {
+	var s string
+	switch p {
+	default:
+		s = ""
+	case ProtocolAuto:
+		s = "auto"
+	case ProtocolHTTP1:
+		s = "http1"
+	case ProtocolHTTP2:
+		s = "http2"
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalJSON converts ProtocolConf from a json string.
+func (p *ProtocolConf) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return p.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText converts ProtocolConf from a string.
+func (p *ProtocolConf) UnmarshalText(b []byte) error {
+	switch strings.ToLower(string(b)) {
+	default:
+		return fmt.Errorf("unknown protocol value \"%s\"", b)
+	case "":
+		*p = ProtocolUndefined
+	case "auto":
+		*p = ProtocolAuto
+	case "http1", "http/1.1":
+		*p = ProtocolHTTP1
+	case "http2", "h2":
+		*p = ProtocolHTTP2
+	}
+	return nil
+}
+
 // Host defines settings for connecting to a registry.
 type Host struct {
-	Name          string            `json:"-" yaml:"registry,omitempty"`                  // Name of the registry (required) (yaml configs pass this as a field, json provides this from the object key)
-	TLS           TLSConf           `json:"tls,omitempty" yaml:"tls"`                     // TLS setting: enabled (default), disabled, insecure
-	RegCert       string            `json:"regcert,omitempty" yaml:"regcert"`             // public pem cert of registry
-	ClientCert    string            `json:"clientCert,omitempty" yaml:"clientCert"`       // public pem cert for client (mTLS)
-	ClientKey     string            `json:"clientKey,omitempty" yaml:"clientKey"`         // private pem cert for client (mTLS)
-	Hostname      string            `json:"hostname,omitempty" yaml:"hostname"`           // hostname of registry, default is the registry name
-	User          string            `json:"user,omitempty" yaml:"user"`                   // username, not used with credHelper
-	Pass          string            `json:"pass,omitempty" yaml:"pass"`                   // password, not used with credHelper
-	Token         string            `json:"token,omitempty" yaml:"token"`                 // token, experimental for specific APIs
-	CredHelper    string            `json:"credHelper,omitempty" yaml:"credHelper"`       // credential helper command for requesting logins
-	CredExpire    timejson.Duration `json:"credExpire,omitempty" yaml:"credExpire"`       // time until credential expires
-	CredHost      string            `json:"credHost,omitempty" yaml:"credHost"`           // used when a helper hostname doesn't match Hostname
-	PathPrefix    string            `json:"pathPrefix,omitempty" yaml:"pathPrefix"`       // used for mirrors defined within a repository namespace
-	Mirrors       []string          `json:"mirrors,omitempty" yaml:"mirrors"`             // list of other Host Names to use as mirrors
-	Priority      uint              `json:"priority,omitempty" yaml:"priority"`           // priority when sorting mirrors, higher priority attempted first
-	RepoAuth      bool              `json:"repoAuth,omitempty" yaml:"repoAuth"`           // tracks a separate auth per repo
-	API           string            `json:"api,omitempty" yaml:"api"`                     // Deprecated: registry API to use
-	APIOpts       map[string]string `json:"apiOpts,omitempty" yaml:"apiOpts"`             // options for APIs
-	BlobChunk     int64             `json:"blobChunk,omitempty" yaml:"blobChunk"`         // size of each blob chunk
-	BlobMax       int64             `json:"blobMax,omitempty" yaml:"blobMax"`             // threshold to switch to chunked upload, -1 to disable, 0 for regclient.blobMaxPut
-	ReqPerSec     float64           `json:"reqPerSec,omitempty" yaml:"reqPerSec"`         // requests per second
-	ReqConcurrent int64             `json:"reqConcurrent,omitempty" yaml:"reqConcurrent"` // concurrent requests, default is defaultConcurrent(3)
-	Scheme        string            `json:"scheme,omitempty" yaml:"scheme"`               // Deprecated: use TLS instead
-	credRefresh   time.Time         `json:"-" yaml:"-"`                                   // internal use, when to refresh credentials
+	Name           string            `json:"-" yaml:"registry,omitempty"`                    // Name of the registry (required) (yaml configs pass this as a field, json provides this from the object key)
+	TLS            TLSConf           `json:"tls,omitempty" yaml:"tls"`                       // TLS setting: enabled (default), disabled, insecure
+	RegCert        string            `json:"regcert,omitempty" yaml:"regcert"`               // public pem cert of registry
+	ClientCert     string            `json:"clientCert,omitempty" yaml:"clientCert"`         // public pem cert for client (mTLS)
+	ClientKey      string            `json:"clientKey,omitempty" yaml:"clientKey"`           // private pem cert for client (mTLS)
+	Hostname       string            `json:"hostname,omitempty" yaml:"hostname"`             // hostname of registry, default is the registry name
+	User           string            `json:"user,omitempty" yaml:"user"`                     // username, not used with credHelper
+	Pass           string            `json:"pass,omitempty" yaml:"pass"`                     // password, not used with credHelper
+	Token          string            `json:"token,omitempty" yaml:"token"`                   // token, experimental for specific APIs
+	CredHelper     string            `json:"credHelper,omitempty" yaml:"credHelper"`         // credential helper command for requesting logins
+	CredExpire     timejson.Duration `json:"credExpire,omitempty" yaml:"credExpire"`         // time until credential expires
+	CredHost       string            `json:"credHost,omitempty" yaml:"credHost"`             // used when a helper hostname doesn't match Hostname
+	PathPrefix     string            `json:"pathPrefix,omitempty" yaml:"pathPrefix"`         // used for mirrors defined within a repository namespace
+	Mirrors        []string          `json:"mirrors,omitempty" yaml:"mirrors"`               // list of other Host Names to use as mirrors
+	Priority       uint              `json:"priority,omitempty" yaml:"priority"`             // priority when sorting mirrors, higher priority attempted first
+	RepoAuth       bool              `json:"repoAuth,omitempty" yaml:"repoAuth"`             // tracks a separate auth per repo
+	API            string            `json:"api,omitempty" yaml:"api"`                       // Deprecated: registry API to use
+	APIOpts        map[string]string `json:"apiOpts,omitempty" yaml:"apiOpts"`               // options for APIs
+	BlobChunk      int64             `json:"blobChunk,omitempty" yaml:"blobChunk"`           // size of each blob chunk
+	BlobMax        int64             `json:"blobMax,omitempty" yaml:"blobMax"`               // threshold to switch to chunked upload, -1 to disable, 0 for regclient.blobMaxPut
+	ReqPerSec      float64           `json:"reqPerSec,omitempty" yaml:"reqPerSec"`           // requests per second
+	ReqConcurrent  int64             `json:"reqConcurrent,omitempty" yaml:"reqConcurrent"`   // concurrent requests, default is defaultConcurrent(3)
+	BlobConcurrent int64             `json:"blobConcurrent,omitempty" yaml:"blobConcurrent"` // concurrent blob transfers, 0 to only limit by reqConcurrent
+	Protocol       ProtocolConf      `json:"protocol,omitempty" yaml:"protocol"`             // HTTP protocol: auto (default), http1, http2
+	Scheme         string            `json:"scheme,omitempty" yaml:"scheme"`                 // Deprecated: use TLS instead
+	credRefresh    time.Time         `json:"-" yaml:"-"`                                     // internal use, when to refresh credentials
 }
 
 // Cred defines a user credential for accessing a registry.
@@ -259,6 +326,8 @@ func (host Host) IsZero() bool {
 		host.BlobMax != 0 ||
 		(host.ReqPerSec != 0 && host.ReqPerSec != float64(defaultReqPerSec)) ||
 		(host.ReqConcurrent != 0 && host.ReqConcurrent != int64(defaultConcurrent)) ||
+		host.BlobConcurrent != 0 ||
+		(host.Protocol != ProtocolUndefined && host.Protocol != ProtocolAuto) ||
 		!host.credRefresh.IsZero() {
 		return false
 	}
@@ -361,6 +430,18 @@ func (host *Host) Merge(newHost Host, log *slog.Logger) error {
 		host.TLS = newHost.TLS
 	}
 
+	if newHost.Protocol != ProtocolUndefined {
+		if host.Protocol != ProtocolUndefined && host.Protocol != newHost.Protocol {
+			protoOrig, _ := host.Protocol.MarshalText()
+			protoNew, _ := newHost.Protocol.MarshalText()
+			log.Warn("Changing protocol settings for registry",
+				slog.String("orig", string(protoOrig)),
+				slog.String("new", string(protoNew)),
+				slog.String("host", name))
+		}
+		host.Protocol = newHost.Protocol
+	}
+
 	if newHost.RegCert != "" {
 		if host.RegCert != "" && host.RegCert != newHost.RegCert {
 			log.Warn("Changing certificate settings for registry",
@@ -500,6 +581,16 @@ func (host *Host) Merge(newHost Host, log *slog.Logger) error {
 		host.ReqConcurrent = newHost.ReqConcurrent
 	}
 
+	if newHost.BlobConcurrent > 0 {
+		if host.BlobConcurrent != 0 && host.BlobConcurrent != newHost.BlobConcurrent {
+			log.Warn("Changing blobConcurrent settings for registry",
+				slog.Int64("orig", host.BlobConcurrent),
+				slog.Int64("new", newHost.BlobConcurrent),
+				slog.String("host", name))
+		}
+		host.BlobConcurrent = newHost.BlobConcurrent
+	}
+
 	return nil
 }
 
@@ -119,7 +119,8 @@ func TestConfig(t *testing.T) {
 		"priority": 42,
 		"apiOpts": {"disableHead": "true"},
 		"blobChunk": 123456,
-		"blobMax": 999999
+		"blobMax": 999999,
+		"blobConcurrent": 2
 	}
 	`
 	exJSON2 := `
@@ -136,7 +137,8 @@ func TestConfig(t *testing.T) {
 		"priority": 42,
 		"apiOpts": {"disableHead": "false", "unknownOpt": "3"},
 		"blobChunk": 333333,
-		"blobMax": 333333
+		"blobMax": 333333,
+		"protocol": "http1"
 	}
 	`
 	exJSONCredHelper := `
@@ -262,16 +264,17 @@ func TestConfig(t *testing.T) {
 			name: "exHost",
 			host: exHost,
 			hostExpect: Host{
-				TLS:        TLSEnabled,
-				Hostname:   "host.example.com",
-				User:       "user-ex",
-				Pass:       "secret",
-				Priority:   42,
-				BlobChunk:  123456,
-				BlobMax:    999999,
-				APIOpts:    map[string]string{"disableHead": "true"},
-				PathPrefix: "hub",
-				Mirrors:    []string{"host1.example.com", "host2.example.com"},
+				TLS:            TLSEnabled,
+				Hostname:       "host.example.com",
+				User:           "user-ex",
+				Pass:           "secret",
+				Priority:       42,
+				BlobChunk:      123456,
+				BlobMax:        999999,
+				BlobConcurrent: 2,
+				APIOpts:        map[string]string{"disableHead": "true"},
+				PathPrefix:     "hub",
+				Mirrors:        []string{"host1.example.com", "host2.example.com"},
 			},
 			credExpect: Cred{
 				User:     "user-ex",
@@ -283,6 +286,7 @@ func TestConfig(t *testing.T) {
 			host: exHost2,
 			hostExpect: Host{
 				TLS:        TLSDisabled,
+				Protocol:   ProtocolHTTP1,
 				Hostname:   "host2.example.com",
 				User:       "user-ex3",
 				Pass:       "secret3",
@@ -320,16 +324,17 @@ func TestConfig(t *testing.T) {
 			name: "mergeBlank",
 			host: exMergeBlank,
 			hostExpect: Host{
-				TLS:        TLSEnabled,
-				Hostname:   "host.example.com",
-				User:       "user-ex",
-				Pass:       "secret",
-				Priority:   42,
-				BlobChunk:  123456,
-				BlobMax:    999999,
-				APIOpts:    map[string]string{"disableHead": "true"},
-				PathPrefix: "hub",
-				Mirrors:    []string{"host1.example.com", "host2.example.com"},
+				TLS:            TLSEnabled,
+				Hostname:       "host.example.com",
+				User:           "user-ex",
+				Pass:           "secret",
+				Priority:       42,
+				BlobChunk:      123456,
+				BlobMax:        999999,
+				BlobConcurrent: 2,
+				APIOpts:        map[string]string{"disableHead": "true"},
+				PathPrefix:     "hub",
+				Mirrors:        []string{"host1.example.com", "host2.example.com"},
 			},
 			credExpect: Cred{
 				User:     "user-ex",
@@ -340,19 +345,21 @@ func TestConfig(t *testing.T) {
 			name: "mergeHost2",
 			host: exMergeHost2,
 			hostExpect: Host{
-				TLS:        TLSDisabled,
-				Hostname:   "host2.example.com",
-				User:       "user-ex3",
-				Pass:       "secret3",
-				RegCert:    caCert,
-				ClientCert: clientCert,
-				ClientKey:  clientKey,
-				PathPrefix: "hub3",
-				Mirrors:    []string{"testhost.example.com"},
-				Priority:   42,
-				APIOpts:    map[string]string{"disableHead": "false", "unknownOpt": "3"},
-				BlobChunk:  333333,
-				BlobMax:    333333,
+				TLS:            TLSDisabled,
+				Protocol:       ProtocolHTTP1,
+				Hostname:       "host2.example.com",
+				User:           "user-ex3",
+				Pass:           "secret3",
+				RegCert:        caCert,
+				ClientCert:     clientCert,
+				ClientKey:      clientKey,
+				PathPrefix:     "hub3",
+				Mirrors:        []string{"testhost.example.com"},
+				Priority:       42,
+				APIOpts:        map[string]string{"disableHead": "false", "unknownOpt": "3"},
+				BlobChunk:      333333,
+				BlobMax:        333333,
+				BlobConcurrent: 2,
 			},
 			credExpect: Cred{
 				User:     "user-ex3",
@@ -378,16 +385,17 @@ func TestConfig(t *testing.T) {
 			name: "exMergeHostHelper",
 			host: exMergeHostHelper,
 			hostExpect: Host{
-				TLS:        TLSInsecure,
-				Hostname:   "testhost.example.com",
-				CredHelper: "docker-credential-test",
-				CredExpire: timejson.Duration(time.Hour),
-				Priority:   42,
-				BlobChunk:  123456,
-				BlobMax:    999999,
-				APIOpts:    map[string]string{"disableHead": "true"},
-				PathPrefix: "hub",
-				Mirrors:    []string{"host1.example.com", "host2.example.com"},
+				TLS:            TLSInsecure,
+				Hostname:       "testhost.example.com",
+				CredHelper:     "docker-credential-test",
+				CredExpire:     timejson.Duration(time.Hour),
+				Priority:       42,
+				BlobChunk:      123456,
+				BlobMax:        999999,
+				BlobConcurrent: 2,
+				APIOpts:        map[string]string{"disableHead": "true"},
+				PathPrefix:     "hub",
+				Mirrors:        []string{"host1.example.com", "host2.example.com"},
 			},
 			credExpect: Cred{
 				User:     "hello",
@@ -398,16 +406,17 @@ func TestConfig(t *testing.T) {
 			name: "exMergeHelperHost",
 			host: exMergeHelperHost,
 			hostExpect: Host{
-				TLS:        TLSEnabled,
-				Hostname:   "host.example.com",
-				User:       "user-ex",
-				Pass:       "secret",
-				Priority:   42,
-				BlobChunk:  123456,
-				BlobMax:    999999,
-				APIOpts:    map[string]string{"disableHead": "true"},
-				PathPrefix: "hub",
-				Mirrors:    []string{"host1.example.com", "host2.example.com"},
+				TLS:            TLSEnabled,
+				Hostname:       "host.example.com",
+				User:           "user-ex",
+				Pass:           "secret",
+				Priority:       42,
+				BlobChunk:      123456,
+				BlobMax:        999999,
+				BlobConcurrent: 2,
+				APIOpts:        map[string]string{"disableHead": "true"},
+				PathPrefix:     "hub",
+				Mirrors:        []string{"host1.example.com", "host2.example.com"},
 			},
 			credExpect: Cred{
 				User:     "user-ex",
@@ -427,6 +436,11 @@ func TestConfig(t *testing.T) {
 				found, _ := tc.host.TLS.MarshalText()
 				t.Errorf("tls field mismatch, expected %s, found %s", expect, found)
 			}
+			if tc.host.Protocol != tc.hostExpect.Protocol {
+				expect, _ := tc.hostExpect.Protocol.MarshalText()
+				found, _ := tc.host.Protocol.MarshalText()
+				t.Errorf("protocol field mismatch, expected %s, found %s", expect, found)
+			}
 			if tc.host.RegCert != tc.hostExpect.RegCert {
 				t.Errorf("regCert field mismatch, expected %s, found %s", tc.hostExpect.RegCert, tc.host.RegCert)
 			}
@@ -466,6 +480,9 @@ func TestConfig(t *testing.T) {
 			if tc.host.BlobMax != tc.hostExpect.BlobMax {
 				t.Errorf("blobMax field mismatch, expected %d, found %d", tc.hostExpect.BlobMax, tc.host.BlobMax)
 			}
+			if tc.host.BlobConcurrent != tc.hostExpect.BlobConcurrent {
+				t.Errorf("blobConcurrent field mismatch, expected %d, found %d", tc.hostExpect.BlobConcurrent, tc.host.BlobConcurrent)
+			}
 			if len(tc.host.Mirrors) != len(tc.hostExpect.Mirrors) {
 				t.Errorf("mirrors length mismatch, expected %v, found %v", tc.hostExpect.Mirrors, tc.host.Mirrors)
 			} else {
@@ -3,6 +3,7 @@ package regclient_test
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/regclient/regclient"
 	"github.com/regclient/regclient/config"
@@ -46,3 +47,38 @@ func ExampleNew() {
 	fmt.Println(m.GetDescriptor().MediaType)
 	// Output: application/vnd.oci.image.index.v1+json
 }
+
+func ExampleRegClient_ImageCopy() {
+	ctx := context.Background()
+	// ocidir refs implement the same manifest and blob methods as a registry using a local directory,
+	// allowing copy and export logic to be tested without running a registry
+	tempDir, err := os.MkdirTemp("", "regclient-example")
+	if err != nil {
+		fmt.Printf("failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+	rc := regclient.New()
+	rSrc, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		fmt.Printf("failed to create ref: %v\n", err)
+		return
+	}
+	rTgt, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
+	if err != nil {
+		fmt.Printf("failed to create ref: %v\n", err)
+		return
+	}
+	err = rc.ImageCopy(ctx, rSrc, rTgt)
+	if err != nil {
+		fmt.Printf("failed to copy image: %v\n", err)
+		return
+	}
+	m, err := rc.ManifestHead(ctx, rTgt)
+	if err != nil {
+		fmt.Printf("failed to get manifest: %v\n", err)
+		return
+	}
+	fmt.Println(m.GetDescriptor().MediaType)
+	// Output: application/vnd.oci.image.index.v1+json
+}
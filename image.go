@@ -2,6 +2,7 @@ package regclient
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/json"
@@ -10,7 +11,9 @@ import (
 	"io"
 	"log/slog"
 	"net/url"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,8 +22,12 @@ import (
 	_ "crypto/sha256"
 	_ "crypto/sha512"
 
+	"github.com/klauspost/compress/zstd"
 	digest "github.com/opencontainers/go-digest"
 
+	"github.com/regclient/regclient/internal/pqueue"
+	"github.com/regclient/regclient/internal/ratelimit"
+	"github.com/regclient/regclient/internal/reqmeta"
 	"github.com/regclient/regclient/pkg/archive"
 	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/types"
@@ -43,6 +50,8 @@ const (
 	ociLayoutFilename      = "oci-layout"
 	annotationRefName      = "org.opencontainers.image.ref.name"
 	annotationImageName    = "io.containerd.image.name"
+	// envPlatform overrides the default platform selected from an index.
+	envPlatform = "REGCLIENT_PLATFORM"
 )
 
 // used by import/export to match docker tar expected format
@@ -63,6 +72,7 @@ type tarReadData struct {
 	links       map[string][]string
 	processed   map[string]bool
 	finish      []func() error
+	digestAlgo  digest.Algorithm
 	// data processed from various handlers
 	manifests           map[digest.Digest]manifest.Manifest
 	ociIndex            v1.Index
@@ -70,38 +80,73 @@ type tarReadData struct {
 	dockerManifestFound bool
 	dockerManifestList  []dockerTarManifest
 	dockerManifest      schema2.Manifest
+	dockerConfDiffIDs   []digest.Digest
+	dockerDiffIDs       []digest.Digest
+	dockerPlatform      platform.Platform
+	validate            *ImageImportResult // set when validating the tar without pushing content
 }
 type tarWriteData struct {
-	tw    *tar.Writer
-	dirs  map[string]bool
-	files map[string]bool
+	tw        *tar.Writer
+	dirs      map[string]bool
+	files     map[string]bool
+	limiter   *ratelimit.Limiter
+	blobs     map[digest.Digest][]byte            // blobs generated for the export, e.g. configs with skipped layers
+	manifests map[digest.Digest]manifest.Manifest // manifests generated for the export
 	// uid, gid  int
 	mode      int64
 	timestamp time.Time
 }
 
 type imageOpt struct {
+	blobTransform   BlobTransform
 	callback        func(kind types.CallbackKind, instance string, state types.CallbackState, cur, total int64)
 	checkBaseDigest string
 	checkBaseRef    string
+	checkDeep       bool
 	checkSkipConfig bool
 	child           bool
-	exportCompress  bool
+	configOnly      bool
+	copyResult      *ImageCopyResult
+	deltaBase       ref.Ref
+	deltaBlobs      map[digest.Digest]bool
+	digestAlgo      digest.Algorithm
+	expectDigest    digest.Digest
+	exportCompress  archive.CompressType
+	exportLayerSkip func(i int, d descriptor.Descriptor) bool
+	updatedDigests  map[digest.Digest]descriptor.Descriptor
+	updatedDiffIDs  map[digest.Digest]digest.Digest
+	externalURLsRm  bool
 	exportRef       ref.Ref
+	exportTime      time.Time
+	exportTimeConf  bool
 	fastCheck       bool
+	force           bool
 	forceRecursive  bool
 	importName      string
+	importPlatform  string
+	importResult    *ImageImportResult
+	importValidate  bool
 	includeExternal bool
+	noOverwrite     bool
+	noOverwriteRef  ref.Ref
+	limiter         *ratelimit.Limiter
+	logID           string
 	digestTags      bool
 	platform        string
 	platforms       []string
+	pushHook        PushHook
+	rcTgt           *RegClient
 	referrerConfs   []scheme.ReferrerConfig
 	referrerSrc     ref.Ref
 	referrerTgt     ref.Ref
 	tagList         []string
+	tags            []string
+	verify          Verifier
 	mu              sync.Mutex
-	seen            map[string]*imageSeen
+	seen            *imageSeenMap
+	slog            *slog.Logger
 	finalFn         []func(context.Context) error
+	subjects        map[string]ref.Ref
 }
 
 type imageSeen struct {
@@ -109,9 +154,49 @@ type imageSeen struct {
 	err  error
 }
 
+// imageSeenMap tracks the manifests and blobs copied to each target, it may be shared by multiple copies.
+type imageSeenMap struct {
+	mu   sync.Mutex
+	seen map[string]*imageSeen
+}
+
 // ImageOpts define options for the Image* commands.
 type ImageOpts func(*imageOpt)
 
+// ImageCopyResult reports the target of an ImageCopy, see [ImageWithCopyResult].
+type ImageCopyResult struct {
+	Ref  ref.Ref               `json:"ref"`  // target reference
+	Desc descriptor.Descriptor `json:"desc"` // descriptor of the top level manifest on the target, including the digest
+	Tags []string              `json:"tags"` // tags pointing to the copied manifest, including the target tag and any ImageWithTags
+}
+
+// ImageImportResult reports the content of a tar checked by ImageImport, see [ImageWithImportValidate].
+type ImageImportResult struct {
+	Desc      descriptor.Descriptor   `json:"desc"`      // descriptor of the manifest that would be pushed to the reference
+	Manifests []descriptor.Descriptor `json:"manifests"` // manifests that would be pushed, child manifests before the index referencing them
+	Blobs     []descriptor.Descriptor `json:"blobs"`     // configs and layers that would be pushed
+}
+
+// BlobTransform replaces the content of a blob copied by ImageCopy, see [ImageWithBlobTransform].
+// The transform receives the source descriptor and a reader for the source content,
+// and returns the descriptor and reader for the content to push to the target.
+// The returned digest and size may be left empty to have them computed while pushing.
+// The returned media type, when set, replaces the media type of the source descriptor.
+type BlobTransform func(ctx context.Context, d descriptor.Descriptor, rdr io.Reader) (descriptor.Descriptor, io.Reader, error)
+
+// ImageWithBlobTransform passes every config and layer copied by ImageCopy through the transform.
+// Manifests are rebuilt with the digest, size, and media type of each transformed blob,
+// changing the digest of the copied manifests and any index referencing them.
+// Layers are transformed before the config, and the diff_ids of the config are updated with the uncompressed digest of each transformed layer.
+// The diff_id is not changed for layers with a media type that does not declare the compression, e.g. encrypted layers.
+// Content in the same repository is also transformed rather than skipped.
+// Referrers to the source digests will not be associated with the modified manifests.
+func ImageWithBlobTransform(fn BlobTransform) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.blobTransform = fn
+	}
+}
+
 // ImageWithCallback provides progress data to a callback function.
 func ImageWithCallback(callback func(kind types.CallbackKind, instance string, state types.CallbackState, cur, total int64)) ImageOpts {
 	return func(opts *imageOpt) {
@@ -133,6 +218,13 @@ func ImageWithCheckBaseRef(r string) ImageOpts {
 	}
 }
 
+// ImageWithCheckDeep pulls and digests every blob in ImageCheck instead of only checking that it exists.
+func ImageWithCheckDeep() ImageOpts {
+	return func(opts *imageOpt) {
+		opts.checkDeep = true
+	}
+}
+
 // ImageWithCheckSkipConfig skips the configuration check in ImageCheckBase.
 func ImageWithCheckSkipConfig() ImageOpts {
 	return func(opts *imageOpt) {
@@ -147,10 +239,46 @@ func ImageWithChild() ImageOpts {
 	}
 }
 
+// ImageWithCopyResult sets res to the digest and tags of the target after a successful ImageCopy.
+func ImageWithCopyResult(res *ImageCopyResult) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.copyResult = res
+	}
+}
+
+// ImageWithExpectDigest verifies the source image resolves to the expected digest in ImageCopy and ImageExport.
+// An [errs.ErrDigestMismatch] is returned when a tag has been moved to a different digest.
+// After the check, the image is pulled by digest to avoid a race with another change to the tag.
+func ImageWithExpectDigest(d digest.Digest) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.expectDigest = d
+	}
+}
+
 // ImageWithExportCompress adds gzip compression to tar export output in ImageExport.
 func ImageWithExportCompress() ImageOpts {
 	return func(opts *imageOpt) {
-		opts.exportCompress = true
+		opts.exportCompress = archive.CompressGzip
+	}
+}
+
+// ImageWithExportCompression compresses the tar export output in ImageExport with gzip, zstd, or none.
+// This only applies to the outer tar, layers are always exported unmodified.
+// Both compression types are detected by ImageImport, and "docker load" accepts a gzip compressed tar.
+func ImageWithExportCompression(ct archive.CompressType) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.exportCompress = ct
+	}
+}
+
+// ImageWithExportLayerSkip removes layers from the image in ImageExport when fn returns true.
+// The fn is called with the index and descriptor of each layer in the manifest.
+// The diff_ids and history of the config are updated to match the remaining layers, resulting in a new config and manifest digest.
+// This is intended for debugging, e.g. to bisect which layer introduced a problem, since the exported image is not the original image.
+// Only single platform images are supported, include the digest of the platform specific manifest in the ref of a multi-platform image.
+func ImageWithExportLayerSkip(fn func(i int, d descriptor.Descriptor) bool) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.exportLayerSkip = fn
 	}
 }
 
@@ -161,6 +289,23 @@ func ImageWithExportRef(r ref.Ref) ImageOpts {
 	}
 }
 
+// ImageWithExportTime sets the modification time of files in the tar output from ImageExport.
+// The default is the Unix epoch, making the output only depend on the exported content.
+func ImageWithExportTime(t time.Time) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.exportTime = t
+	}
+}
+
+// ImageWithExportTimeCreated sets the modification time of files in the tar output from ImageExport to the created time in the image config.
+// When multiple images are exported, the most recent created time is used.
+// Indexes and configs without a created time are skipped, falling back to the time from [ImageWithExportTime].
+func ImageWithExportTimeCreated() ImageOpts {
+	return func(opts *imageOpt) {
+		opts.exportTimeConf = true
+	}
+}
+
 // ImageWithFastCheck skips check for referrers when manifest has already been copied in ImageCopy.
 func ImageWithFastCheck() ImageOpts {
 	return func(opts *imageOpt) {
@@ -168,6 +313,16 @@ func ImageWithFastCheck() ImageOpts {
 	}
 }
 
+// ImageWithForce pushes every manifest and blob in ImageCopy and ImageFanout even if they already exist in the target.
+// This implies [ImageWithForceRecursive] and overrides [ImageWithFastCheck].
+// Use this to repair a target with corrupt content.
+func ImageWithForce() ImageOpts {
+	return func(opts *imageOpt) {
+		opts.force = true
+		opts.forceRecursive = true
+	}
+}
+
 // ImageWithForceRecursive attempts to copy every manifest and blob even if parent manifests already exist in ImageCopy.
 func ImageWithForceRecursive() ImageOpts {
 	return func(opts *imageOpt) {
@@ -182,6 +337,53 @@ func ImageWithImportName(name string) ImageOpts {
 	}
 }
 
+// ImageWithImportPlatform sets the platform of an image imported from a docker save tar in ImageImport.
+// This is only used when the config in the tar is missing the os or architecture, which defaults to the local platform.
+// The config of an OCI Layout is never modified since it is referenced by the included manifests.
+func ImageWithImportPlatform(p string) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.importPlatform = p
+	}
+}
+
+// ImageWithImportValidate checks a tar in ImageImport and ImageImportOCITar without pushing any content.
+// The tar is fully read, verifying the digest and size of every manifest, config, and layer,
+// and the content that would be pushed is reported in res, which may be nil.
+// The registry is not contacted, so content already in the repository is included in the result.
+func ImageWithImportValidate(res *ImageImportResult) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.importValidate = true
+		opts.importResult = res
+	}
+}
+
+// ImageWithLogID sets the value of the "op" field added to every log message of an ImageCopy.
+// This correlates the log messages of concurrent copies, e.g. from ImageCopyBatch.
+// The value defaults to the source and target refs, e.g. "registry.example.com/repo:v1 -> registry.example.com/mirror:v1".
+func ImageWithLogID(id string) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.logID = id
+	}
+}
+
+// imageWithSeen shares the manifests and blobs copied by multiple calls to ImageCopy.
+// Concurrent copies wait for a single transfer of content they have in common.
+func imageWithSeen(seen *imageSeenMap) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.seen = seen
+	}
+}
+
+// ImageWithExternalURLsRm copies external layers into the target and removes the URLs in ImageCopy.
+// Foreign layer media types are converted to regular layers, changing the digest of the copied manifests.
+// Referrers to the source digests will not be associated with the modified manifests.
+func ImageWithExternalURLsRm() ImageOpts {
+	return func(opts *imageOpt) {
+		opts.externalURLsRm = true
+		opts.includeExternal = true
+	}
+}
+
 // ImageWithIncludeExternal attempts to copy every manifest and blob even if parent manifests already exist in ImageCopy.
 func ImageWithIncludeExternal() ImageOpts {
 	return func(opts *imageOpt) {
@@ -189,6 +391,15 @@ func ImageWithIncludeExternal() ImageOpts {
 	}
 }
 
+// ImageWithDigestAlgo sets the digest algorithm for content created by ImageImport and ImageExport, defaulting to sha256.
+// Layers are verified against the config diff ids using the algorithm of each diff id.
+// ImageExport only creates content when layers are skipped, and existing blobs are always exported with their original digest.
+func ImageWithDigestAlgo(algo digest.Algorithm) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.digestAlgo = algo
+	}
+}
+
 // ImageWithDigestTags looks for "sha-<digest>.*" tags in the repo to copy with any manifest in ImageCopy.
 // These are used by some artifact systems like sigstore/cosign.
 func ImageWithDigestTags() ImageOpts {
@@ -197,13 +408,23 @@ func ImageWithDigestTags() ImageOpts {
 	}
 }
 
-// ImageWithPlatform requests specific platforms from a manifest list in ImageCheckBase.
+// ImageWithPlatform requests specific platforms from a manifest list in ImageCheckBase, ImageConfig, ImageDiff, and ImageExport.
+// This overrides the default platform from the REGCLIENT_PLATFORM environment variable.
 func ImageWithPlatform(p string) ImageOpts {
 	return func(opts *imageOpt) {
 		opts.platform = p
 	}
 }
 
+// ImageWithNoOverwrite prevents ImageCopy from changing an existing target tag.
+// If the target tag, or any tag added with [ImageWithTags], points to a different digest, ErrTagExists is returned.
+// Copying the same image to an existing tag is allowed.
+func ImageWithNoOverwrite() ImageOpts {
+	return func(opts *imageOpt) {
+		opts.noOverwrite = true
+	}
+}
+
 // ImageWithPlatforms only copies specific platforms from a manifest list in ImageCopy.
 // This will result in a failure on many registries that validate manifests.
 // Use the empty string to indicate images without a platform definition should be copied.
@@ -213,7 +434,25 @@ func ImageWithPlatforms(p []string) ImageOpts {
 	}
 }
 
-// ImageWithReferrers recursively recursively includes referrer images in ImageCopy.
+// ImageWithPushHook calls hook with the target ref and descriptor of the top level manifest after ImageCopy or ImageFanout completes.
+// This is called even when the target was already up to date, allowing scans to be triggered after every mirror.
+func ImageWithPushHook(hook PushHook) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.pushHook = hook
+	}
+}
+
+// ImageWithRateLimit limits the transfer rate of blobs in ImageCopy and ImageExport to bps bytes per second.
+// The limit is shared by all blobs copied concurrently.
+func ImageWithRateLimit(bps int64) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.limiter = ratelimit.New(bps)
+	}
+}
+
+// ImageWithReferrers recursively includes referrer images in ImageCopy.
+// Referrers of each copied referrer are also copied, preserving the full tree (e.g. a signature of an SBOM).
+// Manifests already seen in the copy are skipped, so loops do not recurse indefinitely.
 func ImageWithReferrers(rOpts ...scheme.ReferrerOpts) ImageOpts {
 	return func(opts *imageOpt) {
 		if opts.referrerConfs == nil {
@@ -241,6 +480,32 @@ func ImageWithReferrerTgt(tgt ref.Ref) ImageOpts {
 	}
 }
 
+// ImageWithTags applies additional tags to the target of an ImageCopy in the same repository.
+// The copied manifest is pushed once for each tag, without copying any other content.
+func ImageWithTags(tags ...string) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.tags = append(opts.tags, tags...)
+	}
+}
+
+// ImageWithTargetClient uses a separate RegClient to access the target of an ImageCopy.
+// This allows the source and target to be accessed with different credentials or host configurations.
+func ImageWithTargetClient(rcTgt *RegClient) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.rcTgt = rcTgt
+	}
+}
+
+// ImageWithVerify verifies the cosign signatures of the source image before ImageCopy pushes any content.
+// Signatures are found with the "sha256-<digest>.sig" tag and the referrers of the source image.
+// An [errs.ErrSignatureVerification] is returned when no signatures are found or the verifier returns an error.
+// After the signatures are verified, the image is copied by digest to avoid a race with a change to the tag.
+func ImageWithVerify(v Verifier) ImageOpts {
+	return func(opts *imageOpt) {
+		opts.verify = v
+	}
+}
+
 // ImageCheckBase returns nil if the base image is unchanged.
 // A base image mismatch returns an error that wraps errs.ErrMismatch.
 func (rc *RegClient) ImageCheckBase(ctx context.Context, r ref.Ref, opts ...ImageOpts) error {
@@ -452,15 +717,24 @@ func (rc *RegClient) ImageCheckBase(ctx context.Context, r ref.Ref, opts ...Imag
 	return nil
 }
 
+// imagePlatformDefault returns the platform from REGCLIENT_PLATFORM used when one is not requested, or an empty string when not set.
+func imagePlatformDefault() string {
+	return os.Getenv(envPlatform)
+}
+
 // ImageConfig returns the OCI config of a given image.
 // Use [ImageWithPlatform] to select a platform from an Index or Manifest List.
+// The default platform is the value of REGCLIENT_PLATFORM, or the local platform when that is not set.
 func (rc *RegClient) ImageConfig(ctx context.Context, r ref.Ref, opts ...ImageOpts) (*blob.BOCIConfig, error) {
 	opt := imageOpt{
-		platform: "local",
+		platform: imagePlatformDefault(),
 	}
 	for _, optFn := range opts {
 		optFn(&opt)
 	}
+	if opt.platform == "" {
+		opt.platform = "local"
+	}
 	// dedup warnings
 	if w := warning.FromContext(ctx); w == nil {
 		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
@@ -469,10 +743,11 @@ func (rc *RegClient) ImageConfig(ctx context.Context, r ref.Ref, opts ...ImageOp
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse platform %s: %w", opt.platform, err)
 	}
-	m, err := rc.ManifestGet(ctx, r, WithManifestPlatform(p))
+	m, err := rc.ManifestGet(ctx, r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get manifest: %w", err)
 	}
+	// resolve the platform from any index, listing the available platforms when not found
 	for m.IsList() {
 		mi, ok := m.(manifest.Indexer)
 		if !ok {
@@ -484,7 +759,7 @@ func (rc *RegClient) ImageConfig(ctx context.Context, r ref.Ref, opts ...ImageOp
 		}
 		d, err := descriptor.DescriptorListSearch(ml, descriptor.MatchOpt{Platform: &p})
 		if err != nil {
-			return nil, fmt.Errorf("failed to find platform in manifest list: %w", err)
+			return nil, fmt.Errorf("platform %s not found in %s, select a platform from: %s: %w", p.String(), r.CommonName(), imagePlatformList(ml), err)
 		}
 		m, err = rc.ManifestGet(ctx, r, WithManifestDesc(d))
 		if err != nil {
@@ -499,116 +774,1316 @@ func (rc *RegClient) ImageConfig(ctx context.Context, r ref.Ref, opts ...ImageOp
 	if err != nil {
 		return nil, fmt.Errorf("failed to get image config: %w", err)
 	}
-	if d.MediaType != mediatype.OCI1ImageConfig && d.MediaType != mediatype.Docker2ImageConfig {
-		return nil, fmt.Errorf("unsupported config media type %s: %w", d.MediaType, errs.ErrUnsupportedMediaType)
+	err = imageConfigCheck(d)
+	if err != nil {
+		return nil, err
 	}
 	return rc.BlobGetOCIConfig(ctx, r, d)
 }
 
-// ImageCopy copies an image.
-// This will retag an image in the same repository, only pushing and pulling the top level manifest.
-// On the same registry, it will attempt to use cross-repository blob mounts to avoid pulling blobs.
-// Blobs are only pulled when they don't exist on the target and a blob mount fails.
-// Referrers are optionally copied recursively.
-func (rc *RegClient) ImageCopy(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, opts ...ImageOpts) error {
-	opt := imageOpt{
-		seen:    map[string]*imageSeen{},
-		finalFn: []func(context.Context) error{},
+// ImageAge returns the created time from the config of an image.
+// Use [ImageWithPlatform] to select a platform from an Index or Manifest List.
+// An [errs.ErrNotFound] is returned when the config does not include the created time.
+func (rc *RegClient) ImageAge(ctx context.Context, r ref.Ref, opts ...ImageOpts) (time.Time, error) {
+	conf, err := rc.ImageConfig(ctx, r, opts...)
+	if err != nil {
+		return time.Time{}, err
 	}
-	for _, optFn := range opts {
-		optFn(&opt)
+	created := conf.GetConfig().Created
+	if created == nil || created.IsZero() {
+		return time.Time{}, fmt.Errorf("created time is not set in the config of %s%.0w", r.CommonName(), errs.ErrNotFound)
+	}
+	return *created, nil
+}
+
+// imageConfigCheck returns an error when the config descriptor is not an image config, e.g. the empty config of an artifact.
+func imageConfigCheck(d descriptor.Descriptor) error {
+	switch d.MediaType {
+	case mediatype.OCI1ImageConfig, mediatype.Docker2ImageConfig:
+		return nil
+	case mediatype.OCI1Empty:
+		return fmt.Errorf("manifest is an artifact with an empty config, not an image: %w", errs.ErrUnsupportedMediaType)
+	default:
+		return fmt.Errorf("unsupported config media type %s: %w", d.MediaType, errs.ErrUnsupportedMediaType)
 	}
+}
+
+// ImagePlatforms returns the platforms of an image.
+// For an Index or Manifest List, each platform in the list is returned without pulling the child manifests.
+// For a single image, the platform is read from the config and a single entry is returned.
+func (rc *RegClient) ImagePlatforms(ctx context.Context, r ref.Ref) ([]platform.Platform, error) {
 	// dedup warnings
 	if w := warning.FromContext(ctx); w == nil {
 		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
 	}
-	// block GC from running (in OCIDir) during the copy
-	schemeTgtAPI, err := rc.schemeGet(refTgt.Scheme)
+	m, err := rc.ManifestGet(ctx, r)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get manifest: %w", err)
 	}
-	if tgtGCLocker, isGCLocker := schemeTgtAPI.(scheme.GCLocker); isGCLocker {
-		tgtGCLocker.GCLock(refTgt)
-		defer tgtGCLocker.GCUnlock(refTgt)
+	if m.IsList() {
+		pl, err := manifest.GetPlatformList(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get platform list: %w", err)
+		}
+		result := make([]platform.Platform, 0, len(pl))
+		for _, p := range pl {
+			if p != nil {
+				result = append(result, *p)
+			}
+		}
+		return result, nil
 	}
-	// run the copy of manifests and blobs recursively
-	err = rc.imageCopyOpt(ctx, refSrc, refTgt, descriptor.Descriptor{}, opt.child, []digest.Digest{}, &opt)
+	mi, ok := m.(manifest.Imager)
+	if !ok {
+		return nil, fmt.Errorf("unsupported manifest type: %s", m.GetDescriptor().MediaType)
+	}
+	d, err := mi.GetConfig()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get image config: %w", err)
 	}
-	// run any final functions, digest-tags and referrers that detected loops are retried here
-	for _, fn := range opt.finalFn {
-		err := fn(ctx)
-		if err != nil {
-			return err
-		}
+	err = imageConfigCheck(d)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	conf, err := rc.BlobGetOCIConfig(ctx, r, d)
+	if err != nil {
+		return nil, err
+	}
+	return []platform.Platform{conf.GetConfig().Platform}, nil
 }
 
-// imageCopyOpt is a thread safe copy of a manifest and nested content.
-func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, d descriptor.Descriptor, child bool, parents []digest.Digest, opt *imageOpt) (err error) {
-	var mSrc, mTgt manifest.Manifest
-	var sDig digest.Digest
-	refTgtRepo := refTgt.SetTag("").CommonName()
-	seenCB := func(error) {}
-	defer func() {
-		if seenCB != nil {
-			seenCB(err)
+// DiffResult describes the differences between two images, see [RegClient.ImageDiff].
+type DiffResult struct {
+	RefA         ref.Ref                 `json:"refA"`
+	RefB         ref.Ref                 `json:"refB"`
+	ManifestA    descriptor.Descriptor   `json:"manifestA"`
+	ManifestB    descriptor.Descriptor   `json:"manifestB"`
+	LayersShared []descriptor.Descriptor `json:"layersShared"`
+	LayersOnlyA  []descriptor.Descriptor `json:"layersOnlyA"`
+	LayersOnlyB  []descriptor.Descriptor `json:"layersOnlyB"`
+	SizeA        int64                   `json:"sizeA"`     // total size of the layers in image A
+	SizeB        int64                   `json:"sizeB"`     // total size of the layers in image B
+	SizeDelta    int64                   `json:"sizeDelta"` // SizeB - SizeA
+	Config       DiffConfig              `json:"config"`
+}
+
+// DiffConfig contains the differences between two image configs.
+// Env and labels list the values added or removed in image B, other fields are only set when the value changed.
+type DiffConfig struct {
+	EnvAdded      []string              `json:"envAdded,omitempty"`
+	EnvRemoved    []string              `json:"envRemoved,omitempty"`
+	Entrypoint    *DiffStrings          `json:"entrypoint,omitempty"`
+	Cmd           *DiffStrings          `json:"cmd,omitempty"`
+	LabelsAdded   map[string]string     `json:"labelsAdded,omitempty"`
+	LabelsRemoved map[string]string     `json:"labelsRemoved,omitempty"`
+	LabelsChanged map[string]DiffString `json:"labelsChanged,omitempty"`
+}
+
+// DiffString is a string value that differs between image A and B.
+type DiffString struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// DiffStrings is a string slice value that differs between image A and B.
+type DiffStrings struct {
+	A []string `json:"a"`
+	B []string `json:"b"`
+}
+
+// IsEmpty returns true when the image configs have no differences.
+func (dc DiffConfig) IsEmpty() bool {
+	return len(dc.EnvAdded) == 0 && len(dc.EnvRemoved) == 0 && dc.Entrypoint == nil && dc.Cmd == nil &&
+		len(dc.LabelsAdded) == 0 && len(dc.LabelsRemoved) == 0 && len(dc.LabelsChanged) == 0
+}
+
+// ImageDiff compares the manifests and configs of two images.
+// Layers are compared by digest, and the config comparison includes the env, entrypoint, cmd, and labels.
+// For an index, the platform is selected with [ImageWithPlatform], defaulting to REGCLIENT_PLATFORM or the local platform.
+func (rc *RegClient) ImageDiff(ctx context.Context, rA, rB ref.Ref, opts ...ImageOpts) (DiffResult, error) {
+	opt := imageOpt{
+		platform: imagePlatformDefault(),
+	}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+	if opt.platform == "" {
+		opt.platform = "local"
+	}
+	p, err := platform.Parse(opt.platform)
+	if err != nil {
+		return DiffResult{}, fmt.Errorf("failed to parse platform %s: %w", opt.platform, err)
+	}
+	result := DiffResult{
+		RefA:         rA,
+		RefB:         rB,
+		LayersShared: []descriptor.Descriptor{},
+		LayersOnlyA:  []descriptor.Descriptor{},
+		LayersOnlyB:  []descriptor.Descriptor{},
+	}
+	mA, layersA, confA, err := rc.imageDiffGet(ctx, rA, p)
+	if err != nil {
+		return result, err
+	}
+	mB, layersB, confB, err := rc.imageDiffGet(ctx, rB, p)
+	if err != nil {
+		return result, err
+	}
+	result.ManifestA = mA.GetDescriptor()
+	result.ManifestB = mB.GetDescriptor()
+	// compare layers by digest
+	digestsA := map[digest.Digest]bool{}
+	for _, l := range layersA {
+		digestsA[l.Digest] = true
+		result.SizeA += l.Size
+	}
+	digestsB := map[digest.Digest]bool{}
+	for _, l := range layersB {
+		digestsB[l.Digest] = true
+		result.SizeB += l.Size
+	}
+	for _, l := range layersA {
+		if digestsB[l.Digest] {
+			result.LayersShared = append(result.LayersShared, l)
+		} else {
+			result.LayersOnlyA = append(result.LayersOnlyA, l)
 		}
-	}()
-	// if digest is provided and we are already copying it, wait
-	if d.Digest != "" {
-		sDig = d.Digest
-	} else if refSrc.Digest != "" {
-		sDig = digest.Digest(refSrc.Digest)
 	}
-	if sDig != "" {
-		if seenCB, err = imageSeenOrWait(ctx, opt, refTgtRepo, refTgt.Tag, sDig, parents); seenCB == nil {
-			return err
+	for _, l := range layersB {
+		if !digestsA[l.Digest] {
+			result.LayersOnlyB = append(result.LayersOnlyB, l)
 		}
 	}
-	// check target with head request
-	mTgt, err = rc.ManifestHead(ctx, refTgt, WithManifestRequireDigest())
-	var urlError *url.Error
-	if err != nil && errors.As(err, &urlError) {
-		return fmt.Errorf("failed to access target registry: %w", err)
+	result.SizeDelta = result.SizeB - result.SizeA
+	// compare the config
+	ccA := confA.GetConfig().Config
+	ccB := confB.GetConfig().Config
+	result.Config.EnvAdded, result.Config.EnvRemoved = imageDiffList(ccA.Env, ccB.Env)
+	if !slices.Equal(ccA.Entrypoint, ccB.Entrypoint) {
+		result.Config.Entrypoint = &DiffStrings{A: ccA.Entrypoint, B: ccB.Entrypoint}
 	}
-	// for non-recursive copies, compare to source digest
-	if err == nil && (opt.fastCheck || (!opt.forceRecursive && opt.referrerConfs == nil && !opt.digestTags)) {
-		if sDig == "" {
-			mSrc, err = rc.ManifestHead(ctx, refSrc, WithManifestRequireDigest())
-			if err != nil {
-				return fmt.Errorf("copy failed, error getting source: %w", err)
+	if !slices.Equal(ccA.Cmd, ccB.Cmd) {
+		result.Config.Cmd = &DiffStrings{A: ccA.Cmd, B: ccB.Cmd}
+	}
+	for k, vA := range ccA.Labels {
+		vB, ok := ccB.Labels[k]
+		if !ok {
+			if result.Config.LabelsRemoved == nil {
+				result.Config.LabelsRemoved = map[string]string{}
 			}
-			sDig = mSrc.GetDescriptor().Digest
-			if seenCB, err = imageSeenOrWait(ctx, opt, refTgtRepo, refTgt.Tag, sDig, parents); seenCB == nil {
-				return err
+			result.Config.LabelsRemoved[k] = vA
+		} else if vA != vB {
+			if result.Config.LabelsChanged == nil {
+				result.Config.LabelsChanged = map[string]DiffString{}
 			}
+			result.Config.LabelsChanged[k] = DiffString{A: vA, B: vB}
 		}
-		if sDig == mTgt.GetDescriptor().Digest {
-			if opt.callback != nil {
-				opt.callback(types.CallbackManifest, d.Digest.String(), types.CallbackSkipped, mTgt.GetDescriptor().Size, mTgt.GetDescriptor().Size)
+	}
+	for k, vB := range ccB.Labels {
+		if _, ok := ccA.Labels[k]; !ok {
+			if result.Config.LabelsAdded == nil {
+				result.Config.LabelsAdded = map[string]string{}
 			}
-			return nil
+			result.Config.LabelsAdded[k] = vB
 		}
 	}
-	// when copying/updating digest tags or referrers, only the source digest is needed for an image
-	if mTgt != nil && mSrc == nil && !opt.forceRecursive && sDig == "" {
-		mSrc, err = rc.ManifestHead(ctx, refSrc, WithManifestRequireDigest())
-		if err != nil {
-			return fmt.Errorf("copy failed, error getting source: %w", err)
+	return result, nil
+}
+
+// imageDiffGet returns the platform specific manifest, layers, and config of an image.
+func (rc *RegClient) imageDiffGet(ctx context.Context, r ref.Ref, p platform.Platform) (manifest.Manifest, []descriptor.Descriptor, *blob.BOCIConfig, error) {
+	m, err := rc.ManifestGet(ctx, r, WithManifestPlatform(p))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get manifest %s: %w", r.CommonName(), err)
+	}
+	mi, ok := m.(manifest.Imager)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unsupported manifest type %s for %s: %w", m.GetDescriptor().MediaType, r.CommonName(), errs.ErrUnsupportedMediaType)
+	}
+	layers, err := mi.GetLayers()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get layers for %s: %w", r.CommonName(), err)
+	}
+	cd, err := mi.GetConfig()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get image config for %s: %w", r.CommonName(), err)
+	}
+	err = imageConfigCheck(cd)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get image config for %s: %w", r.CommonName(), err)
+	}
+	conf, err := rc.BlobGetOCIConfig(ctx, r, cd)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get image config for %s: %w", r.CommonName(), err)
+	}
+	return m, layers, conf, nil
+}
+
+// imageDiffList returns the entries added and removed from a to b.
+func imageDiffList(a, b []string) ([]string, []string) {
+	added, removed := []string{}, []string{}
+	for _, s := range b {
+		if !slices.Contains(a, s) {
+			added = append(added, s)
 		}
-		sDig = mSrc.GetDescriptor().Digest
-		if seenCB, err = imageSeenOrWait(ctx, opt, refTgtRepo, refTgt.Tag, sDig, parents); seenCB == nil {
-			return err
+	}
+	for _, s := range a {
+		if !slices.Contains(b, s) {
+			removed = append(removed, s)
 		}
 	}
-	// get the source manifest when a copy is needed or recursion into the content is needed
-	if sDig == "" || mTgt == nil || sDig != mTgt.GetDescriptor().Digest || opt.forceRecursive || mTgt.IsList() {
-		mSrc, err = rc.ManifestGet(ctx, refSrc, WithManifestDesc(d))
+	return added, removed
+}
+
+// ImageCheckResult is the verification of a single manifest or blob from [RegClient.ImageCheck].
+type ImageCheckResult struct {
+	Ref    ref.Ref               `json:"ref"`              // reference of the manifest containing the descriptor
+	Type   string                `json:"type"`             // "index", "manifest", "config", or "layer"
+	Desc   descriptor.Descriptor `json:"desc"`             // expected descriptor
+	Digest digest.Digest         `json:"digest,omitempty"` // computed digest, set when the content was pulled
+	Size   int64                 `json:"size,omitempty"`   // computed size, set when the content was pulled
+	Err    error                 `json:"-"`                // error from the check, nil when the check passed
+}
+
+// ImageCheck verifies the manifests and blobs of an image exist, returning a result for each.
+// Blobs are checked with a HEAD request by default, and [ImageWithCheckDeep] pulls and digests each blob.
+// Every manifest in an index is checked, unless limited with [ImageWithPlatform].
+// Failed checks do not stop the remaining checks, and an error wrapping errs.ErrMismatch is returned when any check fails.
+func (rc *RegClient) ImageCheck(ctx context.Context, r ref.Ref, opts ...ImageOpts) ([]ImageCheckResult, error) {
+	var opt imageOpt
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+	// dedup warnings
+	if w := warning.FromContext(ctx); w == nil {
+		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
+	}
+	var p *platform.Platform
+	if opt.platform != "" {
+		plat, err := platform.Parse(opt.platform)
 		if err != nil {
-			return fmt.Errorf("copy failed, error getting source: %w", err)
+			return nil, fmt.Errorf("failed to parse platform %s: %w", opt.platform, err)
+		}
+		p = &plat
+	}
+	results := []ImageCheckResult{}
+	m, err := rc.ManifestGet(ctx, r)
+	if err != nil {
+		return results, err
+	}
+	rc.imageCheckManifest(ctx, r, m, p, &opt, &results)
+	failed := 0
+	for _, res := range results {
+		if res.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d checks failed for %s%.0w", failed, len(results), r.CommonName(), errs.ErrMismatch)
+	}
+	return results, nil
+}
+
+// imageCheckManifest verifies a manifest and recursively checks the referenced content.
+func (rc *RegClient) imageCheckManifest(ctx context.Context, r ref.Ref, m manifest.Manifest, p *platform.Platform, opt *imageOpt, results *[]ImageCheckResult) {
+	d := m.GetDescriptor()
+	res := ImageCheckResult{Ref: r, Type: "manifest", Desc: d}
+	if m.IsList() {
+		res.Type = "index"
+	}
+	// verify the digest of the manifest
+	raw, err := m.RawBody()
+	if err == nil {
+		res.Digest = d.DigestAlgo().FromBytes(raw)
+		res.Size = int64(len(raw))
+		if d.Digest != "" && res.Digest != d.Digest {
+			err = fmt.Errorf("%w, expected %s, computed %s", errs.ErrDigestMismatch, d.Digest.String(), res.Digest.String())
+		} else if d.Size > 0 && res.Size != d.Size {
+			err = fmt.Errorf("size mismatch, expected %d, computed %d%.0w", d.Size, res.Size, errs.ErrMismatch)
+		}
+	}
+	res.Err = err
+	*results = append(*results, res)
+	if err != nil {
+		return
+	}
+
+	switch mm := m.(type) {
+	case manifest.Indexer:
+		dl, err := mm.GetManifestList()
+		if err != nil {
+			*results = append(*results, ImageCheckResult{Ref: r, Type: "index", Desc: d, Err: err})
+			return
+		}
+		if p != nil {
+			dp, err := descriptor.DescriptorListSearch(dl, descriptor.MatchOpt{Platform: p})
+			if err != nil {
+				*results = append(*results, ImageCheckResult{Ref: r, Type: "index", Desc: d, Err: fmt.Errorf("failed to find platform %s: %w", p.String(), err)})
+				return
+			}
+			dl = []descriptor.Descriptor{dp}
+		}
+		for _, dc := range dl {
+			rChild := r.SetDigest(dc.Digest.String())
+			mc, err := rc.ManifestGet(ctx, rChild, WithManifestDesc(dc))
+			if err != nil {
+				*results = append(*results, ImageCheckResult{Ref: rChild, Type: "manifest", Desc: dc, Err: err})
+				continue
+			}
+			rc.imageCheckManifest(ctx, rChild, mc, p, opt, results)
+		}
+	case manifest.Imager:
+		cd, err := mm.GetConfig()
+		if err == nil {
+			*results = append(*results, rc.imageCheckBlob(ctx, r, cd, "config", opt))
+		} else if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+			// docker schema v1 does not have a config object
+			*results = append(*results, ImageCheckResult{Ref: r, Type: "config", Desc: d, Err: err})
+		}
+		layers, err := mm.GetLayers()
+		if err != nil {
+			*results = append(*results, ImageCheckResult{Ref: r, Type: "layer", Desc: d, Err: err})
+			return
+		}
+		for _, dl := range layers {
+			if len(dl.URLs) > 0 && !opt.includeExternal {
+				continue
+			}
+			*results = append(*results, rc.imageCheckBlob(ctx, r, dl, "layer", opt))
+		}
+	}
+}
+
+// imageCheckBlob verifies a blob exists, and with a deep check, pulls and digests the content.
+func (rc *RegClient) imageCheckBlob(ctx context.Context, r ref.Ref, d descriptor.Descriptor, kind string, opt *imageOpt) ImageCheckResult {
+	res := ImageCheckResult{Ref: r, Type: kind, Desc: d}
+	if !opt.checkDeep {
+		bh, err := rc.BlobHead(ctx, r, d)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		_ = bh.Close()
+		if size := bh.GetDescriptor().Size; d.Size > 0 && size > 0 && size != d.Size {
+			res.Err = fmt.Errorf("size mismatch, expected %d, received %d%.0w", d.Size, size, errs.ErrMismatch)
+		}
+		return res
+	}
+	br, err := rc.BlobGet(ctx, r, d)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer br.Close()
+	digester := d.DigestAlgo().Digester()
+	// the reader may also return a digest mismatch, the computed digest is reported either way
+	res.Size, err = io.Copy(digester.Hash(), br)
+	res.Digest = digester.Digest()
+	if err == nil && res.Digest != d.Digest {
+		err = fmt.Errorf("%w, expected %s, computed %s", errs.ErrDigestMismatch, d.Digest.String(), res.Digest.String())
+	} else if err == nil && d.Size > 0 && res.Size != d.Size {
+		err = fmt.Errorf("size mismatch, expected %d, computed %d%.0w", d.Size, res.Size, errs.ErrMismatch)
+	}
+	res.Err = err
+	return res
+}
+
+// ImageCopy copies an image.
+// This will retag an image in the same repository, only pushing and pulling the top level manifest.
+// On the same registry, it will attempt to use cross-repository blob mounts to avoid pulling blobs.
+// Blobs are only pulled when they don't exist on the target and a blob mount fails.
+// Referrers are optionally copied recursively.
+// Blobs are streamed between the source and target, see [RegClient.BlobCopy] for the memory usage.
+// The platforms of an Index and the blobs of each image are copied concurrently,
+// with the number of requests in flight to each registry limited by the ReqConcurrent setting of the host.
+func (rc *RegClient) ImageCopy(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, opts ...ImageOpts) error {
+	opt := imageOpt{
+		finalFn:        []func(context.Context) error{},
+		subjects:       map[string]ref.Ref{},
+		updatedDigests: map[digest.Digest]descriptor.Descriptor{},
+		updatedDiffIDs: map[digest.Digest]digest.Digest{},
+	}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+	if opt.rcTgt == nil {
+		opt.rcTgt = rc
+	}
+	if opt.seen == nil {
+		opt.seen = &imageSeenMap{seen: map[string]*imageSeen{}}
+	}
+	// correlate the log messages of this copy
+	if opt.logID == "" {
+		opt.logID = refSrc.CommonName() + " -> " + refTgt.CommonName()
+	}
+	opt.slog = rc.slog.With(slog.String("op", opt.logID))
+	// dedup warnings
+	if w := warning.FromContext(ctx); w == nil {
+		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
+	}
+	if opt.expectDigest != "" {
+		var err error
+		refSrc, err = rc.imageExpectDigest(ctx, refSrc, opt.expectDigest)
+		if err != nil {
+			return err
+		}
+	}
+	if opt.verify != nil {
+		var err error
+		refSrc, err = rc.imageVerify(ctx, refSrc, opt.verify)
+		if err != nil {
+			return err
+		}
+	}
+	// block GC from running (in OCIDir) during the copy
+	schemeTgtAPI, err := opt.rcTgt.schemeGet(refTgt.Scheme)
+	if err != nil {
+		return err
+	}
+	if tgtGCLocker, isGCLocker := schemeTgtAPI.(scheme.GCLocker); isGCLocker {
+		tgtGCLocker.GCLock(refTgt)
+		defer tgtGCLocker.GCUnlock(refTgt)
+	}
+	// fail early when a target tag would be changed, the digest is checked again when each tag is pushed
+	if opt.noOverwrite {
+		opt.noOverwriteRef = refTgt
+		tags := slices.Clone(opt.tags)
+		if refTgt.Tag != "" {
+			tags = append(tags, refTgt.Tag)
+		}
+		if len(opt.platforms) == 0 && len(tags) > 0 {
+			mh, err := rc.ManifestHead(ctx, refSrc, WithManifestRequireDigest())
+			if err != nil {
+				return fmt.Errorf("copy failed, error getting source: %w", err)
+			}
+			for _, tag := range tags {
+				err = opt.rcTgt.manifestTagCheck(ctx, refTgt.SetTag(tag), mh.GetDescriptor().Digest)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// collect the blobs already on the target from the base image
+	if opt.deltaBase.IsSet() {
+		opt.deltaBlobs, err = opt.rcTgt.imageCopyDeltaBlobs(ctx, opt.deltaBase)
+		if err != nil {
+			return err
+		}
+	}
+	// run the copy of manifests and blobs recursively
+	err = rc.imageCopyOpt(ctx, refSrc, refTgt, descriptor.Descriptor{}, opt.child, []digest.Digest{}, &opt)
+	if err != nil {
+		return err
+	}
+	// run any final functions, digest-tags and referrers that detected loops are retried here
+	for _, fn := range opt.finalFn {
+		err := fn(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	// verify the subject of every copied referrer resolves on the target
+	rc.imageCopySubjectCheck(ctx, &opt)
+	// apply additional tags and report the result
+	if len(opt.tags) > 0 || opt.copyResult != nil {
+		err = rc.imageCopyTags(ctx, refTgt, &opt)
+		if err != nil {
+			return err
+		}
+	}
+	// notify the caller of the pushed image
+	if opt.pushHook != nil {
+		mh, err := opt.rcTgt.ManifestHead(ctx, refTgt, WithManifestRequireDigest())
+		if err != nil {
+			return fmt.Errorf("failed to get copied manifest %s: %w", refTgt.CommonName(), err)
+		}
+		d := mh.GetDescriptor()
+		err = opt.pushHook(ctx, refTgt, d)
+		if err != nil {
+			return fmt.Errorf("push hook failed for %s: %w", refTgt.CommonName(), err)
+		}
+	}
+	return nil
+}
+
+// ImageCopyConfigOnly copies the manifests and config of an image without the layers.
+// The pushed manifests reference the layer digests from the source, which are expected to already exist or be resolvable elsewhere.
+// This is useful for mirroring image metadata, but registries that verify the referenced blobs will reject the manifest.
+// Layers of any copied referrers are also skipped.
+func (rc *RegClient) ImageCopyConfigOnly(ctx context.Context, refSrc, refTgt ref.Ref, opts ...ImageOpts) error {
+	opts = append(opts, func(opt *imageOpt) {
+		opt.configOnly = true
+	})
+	return rc.ImageCopy(ctx, refSrc, refTgt, opts...)
+}
+
+// ImageCopyDelta copies an image, skipping any blobs found in a base image already on the target.
+// The base must be on the same registry as the target, and its manifests are used to compute the set of blobs to skip.
+// Blobs from a base in the target repository are trusted to exist without a check,
+// and blobs from a base in a different repository are mounted from that repository.
+func (rc *RegClient) ImageCopyDelta(ctx context.Context, refSrc, refTgt, refBase ref.Ref, opts ...ImageOpts) error {
+	if !refBase.IsSet() {
+		return fmt.Errorf("base is not set: %s%.0w", refBase.CommonName(), errs.ErrInvalidReference)
+	}
+	if !ref.EqualRegistry(refBase, refTgt) {
+		return fmt.Errorf("base %s must be on the same registry as the target %s%.0w", refBase.CommonName(), refTgt.CommonName(), errs.ErrInvalidReference)
+	}
+	opts = append(opts, func(opt *imageOpt) {
+		opt.deltaBase = refBase
+	})
+	return rc.ImageCopy(ctx, refSrc, refTgt, opts...)
+}
+
+// imageCopyDeltaBlobs returns the config and layer digests for every image in the base.
+func (rc *RegClient) imageCopyDeltaBlobs(ctx context.Context, refBase ref.Ref) (map[digest.Digest]bool, error) {
+	blobs := map[digest.Digest]bool{}
+	m, err := rc.ManifestGet(ctx, refBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base %s: %w", refBase.CommonName(), err)
+	}
+	mList := []manifest.Manifest{m}
+	if mi, ok := m.(manifest.Indexer); ok {
+		dl, err := mi.GetManifestList()
+		if err != nil {
+			return nil, err
+		}
+		mList = []manifest.Manifest{}
+		for _, d := range dl {
+			mEntry, err := rc.ManifestGet(ctx, refBase.SetDigest(d.Digest.String()), WithManifestDesc(d))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get base %s: %w", refBase.CommonName(), err)
+			}
+			mList = append(mList, mEntry)
+		}
+	}
+	for _, mEntry := range mList {
+		mi, ok := mEntry.(manifest.Imager)
+		if !ok {
+			continue
+		}
+		if cd, err := mi.GetConfig(); err == nil {
+			blobs[cd.Digest] = true
+		}
+		layers, err := mi.GetLayers()
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range layers {
+			blobs[l.Digest] = true
+		}
+	}
+	return blobs, nil
+}
+
+// ImageFanout copies an image from one source to multiple targets, reading each blob from the source once.
+// Each blob missing from any target is read from the source into a temporary file and pushed from there to every target that needs it,
+// and manifests are pushed to each target after the content they reference.
+// The blobs are spooled to local disk rather than streamed to the targets.
+// Each temporary file is the size of its blob and is created in [os.TempDir], which may be changed with TMPDIR.
+// A file is removed once every target has the blob, and while pushes are pending, the disk used may reach the total size of the blobs missing from the targets.
+// Transfers are limited per registry by the ReqConcurrent and BlobConcurrent settings of each host configuration.
+// Each push waits only for the limits of its own target, so a slow target or a target with a low limit does not hold up the other targets.
+// Manifests that already exist on a target are not pushed again unless [ImageWithForce] is set.
+// Options supported include [ImageWithPlatforms], [ImageWithIncludeExternal], [ImageWithCallback], [ImageWithForce], and [ImageWithPushHook].
+func (rc *RegClient) ImageFanout(ctx context.Context, refSrc ref.Ref, refTgts []ref.Ref, opts ...ImageOpts) error {
+	if !refSrc.IsSet() {
+		return fmt.Errorf("source is not set: %s%.0w", refSrc.CommonName(), errs.ErrInvalidReference)
+	}
+	if len(refTgts) == 0 {
+		return fmt.Errorf("no targets provided%.0w", errs.ErrInvalidReference)
+	}
+	for _, rTgt := range refTgts {
+		if !rTgt.IsSet() {
+			return fmt.Errorf("target is not set: %s%.0w", rTgt.CommonName(), errs.ErrInvalidReference)
+		}
+	}
+	opt := imageOpt{}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+	// dedup warnings
+	if w := warning.FromContext(ctx); w == nil {
+		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
+	}
+	// block GC from running (in OCIDir) during the copy
+	for _, rTgt := range refTgts {
+		schemeTgtAPI, err := rc.schemeGet(rTgt.Scheme)
+		if err != nil {
+			return err
+		}
+		if tgtGCLocker, isGCLocker := schemeTgtAPI.(scheme.GCLocker); isGCLocker {
+			tgtGCLocker.GCLock(rTgt)
+			defer tgtGCLocker.GCUnlock(rTgt)
+		}
+	}
+	// walk the source once to find every manifest and blob
+	fo := imageFanout{
+		blobs: map[digest.Digest]descriptor.Descriptor{},
+		seen:  map[digest.Digest]bool{},
+	}
+	err := rc.imageFanoutWalk(ctx, refSrc, descriptor.Descriptor{}, &fo, &opt)
+	if err != nil {
+		return err
+	}
+	// limit the blobs read from the source concurrently, the pushes of a blob to the targets continue after it is read
+	var wg sync.WaitGroup
+	errList := make([]error, 0, len(fo.blobs))
+	var errMu sync.Mutex
+	sem := make(chan struct{}, fanoutConcurrencyDefault)
+	for _, d := range fo.blobs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(d descriptor.Descriptor) {
+			defer wg.Done()
+			err := rc.imageFanoutBlob(ctx, refSrc, refTgts, d, &opt, sync.OnceFunc(func() { <-sem }))
+			if err != nil {
+				errMu.Lock()
+				errList = append(errList, err)
+				errMu.Unlock()
+			}
+		}(d)
+	}
+	wg.Wait()
+	if len(errList) > 0 {
+		return errors.Join(errList...)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	// push manifests, children were added to the list before their parents
+	for _, rTgt := range refTgts {
+		for i, m := range fo.manifests {
+			top := i == len(fo.manifests)-1
+			rPut := rTgt
+			if !top {
+				rPut = rTgt.SetDigest(m.GetDescriptor().Digest.String())
+			}
+			// skip manifests that already exist on the target
+			if !opt.force {
+				mh, err := rc.ManifestHead(ctx, rPut, WithManifestRequireDigest())
+				if err == nil && mh.GetDescriptor().Digest == m.GetDescriptor().Digest {
+					if top && opt.pushHook != nil {
+						err = opt.pushHook(ctx, rPut, mh.GetDescriptor())
+						if err != nil {
+							return fmt.Errorf("push hook failed for %s: %w", rPut.CommonName(), err)
+						}
+					}
+					continue
+				}
+			}
+			if top {
+				mOpts := []ManifestOpts{}
+				if opt.pushHook != nil {
+					mOpts = append(mOpts, WithManifestPushHook(opt.pushHook))
+				}
+				err = rc.ManifestPut(ctx, rPut, m, mOpts...)
+			} else {
+				err = rc.ManifestPut(ctx, rPut, m, WithManifestChild())
+			}
+			if err != nil {
+				return fmt.Errorf("failed to push manifest to %s: %w", rTgt.CommonName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// fanoutConcurrencyDefault is the number of blobs copied concurrently by ImageFanout.
+const fanoutConcurrencyDefault = 3
+
+type imageFanout struct {
+	manifests []manifest.Manifest
+	blobs     map[digest.Digest]descriptor.Descriptor
+	seen      map[digest.Digest]bool
+}
+
+// imageFanoutWalk adds a manifest and its content to the fanout, child manifests are added before the parent.
+func (rc *RegClient) imageFanoutWalk(ctx context.Context, r ref.Ref, d descriptor.Descriptor, fo *imageFanout, opt *imageOpt) error {
+	m, err := rc.ManifestGet(ctx, r, WithManifestDesc(d))
+	if err != nil {
+		return fmt.Errorf("failed to get source %s: %w", r.CommonName(), err)
+	}
+	dig := m.GetDescriptor().Digest
+	if fo.seen[dig] {
+		return nil
+	}
+	fo.seen[dig] = true
+	if mi, ok := m.(manifest.Indexer); ok {
+		dl, err := mi.GetManifestList()
+		if err != nil {
+			return err
+		}
+		for _, dEntry := range dl {
+			if len(opt.platforms) > 0 {
+				match, err := imagePlatformInList(dEntry.Platform, opt.platforms)
+				if err != nil {
+					return err
+				}
+				if !match {
+					continue
+				}
+			}
+			err = rc.imageFanoutWalk(ctx, r.SetDigest(dEntry.Digest.String()), dEntry, fo, opt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if mi, ok := m.(manifest.Imager); ok {
+		if cd, err := mi.GetConfig(); err == nil {
+			fo.blobs[cd.Digest] = cd
+		} else if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+			return fmt.Errorf("failed to get config digest for %s: %w", r.CommonName(), err)
+		}
+		layers, err := mi.GetLayers()
+		if err != nil {
+			return err
+		}
+		for _, l := range layers {
+			if len(l.URLs) > 0 && !opt.includeExternal {
+				continue
+			}
+			fo.blobs[l.Digest] = l
+		}
+	}
+	fo.manifests = append(fo.manifests, m)
+	return nil
+}
+
+// imageFanoutBlob reads a blob from the source once into a temporary file and pushes it to every target missing the blob.
+// The release function is called once the source has been read, before the pushes to the targets finish.
+func (rc *RegClient) imageFanoutBlob(ctx context.Context, refSrc ref.Ref, refTgts []ref.Ref, d descriptor.Descriptor, opt *imageOpt, release func()) error {
+	defer release()
+	if opt.callback != nil {
+		opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackStarted, 0, d.Size)
+	}
+	d.URLs = []string{}
+	need := []ref.Ref{}
+	for _, rTgt := range refTgts {
+		if !opt.force {
+			if _, err := rc.BlobHead(ctx, rTgt, d); err == nil {
+				continue
+			}
+		}
+		need = append(need, rTgt)
+	}
+	if len(need) == 0 {
+		if opt.callback != nil {
+			opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackSkipped, 0, d.Size)
+		}
+		return nil
+	}
+	fh, size, err := rc.imageFanoutSpool(ctx, refSrc, d)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fh.Close()
+		_ = os.Remove(fh.Name())
+	}()
+	release()
+	// each target waits on its own throttles, so a slow or limited target does not block the others
+	errList := make([]error, len(need))
+	var wg sync.WaitGroup
+	for i, rTgt := range need {
+		wg.Add(1)
+		go func(i int, rTgt ref.Ref) {
+			defer wg.Done()
+			errList[i] = rc.imageFanoutPut(ctx, rTgt, d, io.NewSectionReader(fh, 0, size))
+		}(i, rTgt)
+	}
+	wg.Wait()
+	if err := errors.Join(errList...); err != nil {
+		return err
+	}
+	if opt.callback != nil {
+		opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackFinished, d.Size, d.Size)
+	}
+	return nil
+}
+
+// imageFanoutSpool reads a blob from the source into a temporary file, holding only the throttles of the source.
+// The digest of the blob is verified, and the caller must close and remove the returned file.
+func (rc *RegClient) imageFanoutSpool(ctx context.Context, refSrc ref.Ref, d descriptor.Descriptor) (*os.File, int64, error) {
+	tList, err := rc.blobThrottle(refSrc, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx, done, err := pqueue.AcquireMulti[reqmeta.Data](ctx, reqmeta.Data{Kind: reqmeta.Blob, Size: d.Size}, tList...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer done()
+	br, err := rc.BlobGet(ctx, refSrc, d)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get blob %s: %w", d.Digest.String(), err)
+	}
+	defer br.Close()
+	fh, err := os.CreateTemp("", "regclient-fanout-*")
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(fh, br)
+	if err != nil {
+		_ = fh.Close()
+		_ = os.Remove(fh.Name())
+		return nil, 0, fmt.Errorf("failed to read blob %s: %w", d.Digest.String(), err)
+	}
+	return fh, size, nil
+}
+
+// imageFanoutPut pushes a blob to a single target, holding only the throttles of the target.
+func (rc *RegClient) imageFanoutPut(ctx context.Context, rTgt ref.Ref, d descriptor.Descriptor, rdr io.Reader) error {
+	tList, err := rc.blobThrottle(rTgt, true)
+	if err != nil {
+		return err
+	}
+	ctx, done, err := pqueue.AcquireMulti[reqmeta.Data](ctx, reqmeta.Data{Kind: reqmeta.Blob, Size: d.Size}, tList...)
+	if err != nil {
+		return err
+	}
+	defer done()
+	_, err = rc.BlobPut(ctx, rTgt, d, rdr)
+	if err != nil {
+		return fmt.Errorf("failed to put blob %s to %s: %w", d.Digest.String(), rTgt.CommonName(), err)
+	}
+	return nil
+}
+
+// ImageIndexEntry is a single platform image included in the index created by ImageIndexCreate.
+type ImageIndexEntry struct {
+	Ref      ref.Ref           // source of the image, an index or manifest list is resolved to the platform
+	Platform platform.Platform // platform of the image, read from the image config when unset
+}
+
+// ImageIndexCreate copies each entry to the repository of rTgt and pushes an OCI index referencing the copied images to rTgt.
+// This assembles a multi-platform image from sources that were pushed separately for each platform, e.g. per arch tags.
+// Images are copied by digest, and opts are passed to each ImageCopy.
+// The pushed index is returned.
+func (rc *RegClient) ImageIndexCreate(ctx context.Context, rTgt ref.Ref, entries []ImageIndexEntry, opts ...ImageOpts) (manifest.Manifest, error) {
+	if !rTgt.IsSet() {
+		return nil, fmt.Errorf("target is not set: %s%.0w", rTgt.CommonName(), errs.ErrInvalidReference)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries provided%.0w", errs.ErrInvalidReference)
+	}
+	opt := imageOpt{}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+	// dedup warnings
+	if w := warning.FromContext(ctx); w == nil {
+		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
+	}
+	copyOpts := append([]ImageOpts{ImageWithChild()}, opts...)
+	descList := make([]descriptor.Descriptor, 0, len(entries))
+	for _, e := range entries {
+		if !e.Ref.IsSet() {
+			return nil, fmt.Errorf("source is not set: %s%.0w", e.Ref.CommonName(), errs.ErrInvalidReference)
+		}
+		mOpts := []ManifestOpts{WithManifestRequireDigest()}
+		if e.Platform.OS != "" {
+			mOpts = append(mOpts, WithManifestPlatform(e.Platform))
+		}
+		m, err := rc.ManifestHead(ctx, e.Ref, mOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source %s: %w", e.Ref.CommonName(), err)
+		}
+		if m.IsList() {
+			return nil, fmt.Errorf("platform is required to select an image from %s%.0w", e.Ref.CommonName(), errs.ErrUnsupportedMediaType)
+		}
+		desc := m.GetDescriptor()
+		rSrc := e.Ref.SetDigest(desc.Digest.String())
+		p := e.Platform
+		if p.OS == "" {
+			conf, err := rc.ImageConfig(ctx, rSrc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get platform of %s: %w", e.Ref.CommonName(), err)
+			}
+			p = conf.GetConfig().Platform
+			if p.OS == "" {
+				return nil, fmt.Errorf("platform is not set in the config of %s%.0w", e.Ref.CommonName(), errs.ErrNotFound)
+			}
+		}
+		err = rc.ImageCopy(ctx, rSrc, rTgt.SetDigest(desc.Digest.String()), copyOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy %s: %w", e.Ref.CommonName(), err)
+		}
+		descList = append(descList, descriptor.Descriptor{
+			MediaType: desc.MediaType,
+			Digest:    desc.Digest,
+			Size:      desc.Size,
+			Platform:  &p,
+		})
+	}
+	mi, err := manifest.New(manifest.WithOrig(v1.Index{
+		Versioned: v1.IndexSchemaVersion,
+		MediaType: mediatype.OCI1ManifestList,
+		Manifests: descList,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	mOpts := []ManifestOpts{}
+	if opt.pushHook != nil {
+		mOpts = append(mOpts, WithManifestPushHook(opt.pushHook))
+	}
+	err = rc.ManifestPut(ctx, rTgt, mi, mOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return mi, nil
+}
+
+// imageCopyTags pushes the copied manifest to each additional tag and populates the copy result.
+func (rc *RegClient) imageCopyTags(ctx context.Context, refTgt ref.Ref, opt *imageOpt) error {
+	tags := []string{}
+	if refTgt.Tag != "" {
+		tags = append(tags, refTgt.Tag)
+	}
+	mTgt, err := opt.rcTgt.ManifestGet(ctx, refTgt)
+	if err != nil {
+		return fmt.Errorf("failed to get copied manifest %s: %w", refTgt.CommonName(), err)
+	}
+	for _, tag := range opt.tags {
+		if slices.Contains(tags, tag) {
+			continue
+		}
+		rTag, err := ref.New(refTgt.SetTag(tag).CommonName())
+		if err != nil {
+			return fmt.Errorf("invalid tag %s: %w", tag, err)
+		}
+		mOpts := []ManifestOpts{}
+		if opt.noOverwrite {
+			mOpts = append(mOpts, WithManifestNoOverwrite())
+		}
+		err = opt.rcTgt.ManifestPut(ctx, rTag, mTgt, mOpts...)
+		if err != nil {
+			return fmt.Errorf("failed to tag %s: %w", rTag.CommonName(), err)
+		}
+		tags = append(tags, tag)
+	}
+	if opt.copyResult != nil {
+		*opt.copyResult = ImageCopyResult{
+			Ref:  refTgt,
+			Desc: mTgt.GetDescriptor(),
+			Tags: tags,
+		}
+	}
+	return nil
+}
+
+// imageCopySubjectCheck warns when a copied manifest has a subject that is missing from the target.
+func (rc *RegClient) imageCopySubjectCheck(ctx context.Context, opt *imageOpt) {
+	opt.mu.Lock()
+	subjects := make([]ref.Ref, 0, len(opt.subjects))
+	for _, rSubject := range opt.subjects {
+		subjects = append(subjects, rSubject)
+	}
+	opt.mu.Unlock()
+	for _, rSubject := range subjects {
+		_, err := opt.rcTgt.ManifestHead(ctx, rSubject)
+		if err != nil {
+			opt.slog.Warn("Subject of copied manifest not found on target",
+				slog.String("subject", rSubject.CommonName()),
+				slog.String("err", err.Error()))
+		}
+	}
+}
+
+// CopyPair is a source and target image for ImageCopyBatch.
+type CopyPair struct {
+	Src  ref.Ref
+	Tgt  ref.Ref
+	Opts []ImageOpts // options applied to this pair, after BatchOpts.ImageOpts
+}
+
+// CopyStatus indicates the outcome of a single copy in ImageCopyBatch.
+type CopyStatus int
+
+const (
+	// CopySkipped is returned for pairs that were never started because the batch was aborted.
+	CopySkipped CopyStatus = iota
+	// CopySuccess is returned for pairs copied without error.
+	CopySuccess
+	// CopyFailed is returned for pairs that failed after all retries.
+	CopyFailed
+	// CopyFiltered is returned for pairs where the source manifest was rejected by BatchOpts.Filter.
+	CopyFiltered
+)
+
+// String returns the name of the copy status.
+func (s CopyStatus) String() string {
+	switch s {
+	case CopySkipped:
+		return "skipped"
+	case CopySuccess:
+		return "success"
+	case CopyFailed:
+		return "failed"
+	case CopyFiltered:
+		return "filtered"
+	default:
+		return "unknown"
+	}
+}
+
+// CopyResult is the outcome of one CopyPair in ImageCopyBatch.
+type CopyResult struct {
+	Pair     CopyPair
+	Status   CopyStatus
+	Attempts int   // number of times the copy was attempted
+	Err      error // error from the last attempt when the copy failed
+}
+
+// BatchOpts configures ImageCopyBatch.
+type BatchOpts struct {
+	Concurrency   int           // number of images copied concurrently, defaults to 3
+	Retries       int           // number of retries for each failed copy, defaults to 0
+	RetryDelay    time.Duration // delay before the first retry, doubled after each retry, defaults to 1 second
+	RetryDelayMax time.Duration // maximum delay between retries, defaults to 30 seconds
+	FailFast      bool          // cancel the batch on the first failed copy
+	ImageOpts     []ImageOpts   // options applied to every copy
+	// Filter is called with the source manifest of each pair before the copy, pairs are skipped when it returns false.
+	Filter func(m manifest.Manifest) bool
+}
+
+// CopyFilterAnnotation returns a BatchOpts.Filter that matches source manifests with the annotation key set to val.
+// An empty val matches any manifest with the annotation key.
+func CopyFilterAnnotation(key, val string) func(m manifest.Manifest) bool {
+	return func(m manifest.Manifest) bool {
+		annotations, err := manifest.GetAnnotations(m)
+		if err != nil {
+			return false
+		}
+		cur, ok := annotations[key]
+		return ok && (val == "" || cur == val)
+	}
+}
+
+const (
+	batchConcurrencyDefault   = 3
+	batchRetryDelayDefault    = time.Second
+	batchRetryDelayMaxDefault = time.Second * 30
+)
+
+// ImageCopyBatch copies a list of images with a limited concurrency.
+// Each failed copy is retried with an exponential backoff, up to opts.Retries times.
+// A failed copy does not stop the other copies unless opts.FailFast is set.
+// When opts.Filter is set, the source manifest of each pair is pulled first and pairs it rejects are not copied.
+// The copies share the manifests and blobs pushed to each target, a blob common to concurrent copies is transferred once while the other copies wait for it.
+// The returned results have the same order as pairs, and an error is returned when any copy fails.
+func (rc *RegClient) ImageCopyBatch(ctx context.Context, pairs []CopyPair, opts BatchOpts) ([]CopyResult, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = batchConcurrencyDefault
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = batchRetryDelayDefault
+	}
+	if opts.RetryDelayMax <= 0 {
+		opts.RetryDelayMax = batchRetryDelayMaxDefault
+	}
+	seen := &imageSeenMap{seen: map[string]*imageSeen{}}
+	opts.ImageOpts = append(slices.Clone(opts.ImageOpts), imageWithSeen(seen))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]CopyResult, len(pairs))
+	sem := make(chan struct{}, opts.Concurrency)
+	wg := sync.WaitGroup{}
+	for i, pair := range pairs {
+		results[i] = CopyResult{Pair: pair, Status: CopySkipped}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, pair CopyPair) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res := &results[i]
+			iOpts := make([]ImageOpts, 0, len(opts.ImageOpts)+len(pair.Opts))
+			iOpts = append(iOpts, opts.ImageOpts...)
+			iOpts = append(iOpts, pair.Opts...)
+			// log with the same "op" value as the ImageCopy
+			log := rc.slog.With(slog.String("op", imageCopyLogID(pair, iOpts)))
+			if opts.Filter != nil {
+				match, err := rc.imageCopyFilter(ctx, pair, opts.Filter, log)
+				if err == nil && !match {
+					res.Status = CopyFiltered
+					return
+				}
+				res.Err = err
+			}
+			if res.Err == nil {
+				res.Attempts, res.Err = rc.imageCopyRetry(ctx, pair, iOpts, opts, log)
+			}
+			if res.Err == nil {
+				res.Status = CopySuccess
+				return
+			}
+			res.Status = CopyFailed
+			log.Warn("Failed to copy image",
+				slog.String("src", pair.Src.CommonName()),
+				slog.String("tgt", pair.Tgt.CommonName()),
+				slog.Int("attempts", res.Attempts),
+				slog.String("err", res.Err.Error()))
+			if opts.FailFast {
+				cancel()
+			}
+		}(i, pair)
+	}
+	wg.Wait()
+	errList := []error{}
+	for _, res := range results {
+		if res.Status == CopyFailed {
+			errList = append(errList, fmt.Errorf("failed to copy %s to %s: %w", res.Pair.Src.CommonName(), res.Pair.Tgt.CommonName(), res.Err))
+		}
+	}
+	if len(errList) > 0 {
+		return results, fmt.Errorf("%d of %d image copies failed: %w", len(errList), len(pairs), errors.Join(errList...))
+	}
+	return results, nil
+}
+
+// imageCopyFilter pulls the source manifest of a batch pair and returns the result of the filter.
+func (rc *RegClient) imageCopyFilter(ctx context.Context, pair CopyPair, filter func(m manifest.Manifest) bool, log *slog.Logger) (bool, error) {
+	m, err := rc.ManifestGet(ctx, pair.Src)
+	if err != nil {
+		return false, fmt.Errorf("failed to get source for filter: %w", err)
+	}
+	if !filter(m) {
+		log.Debug("Image copy filtered",
+			slog.String("src", pair.Src.CommonName()),
+			slog.String("tgt", pair.Tgt.CommonName()))
+		return false, nil
+	}
+	return true, nil
+}
+
+// imageCopyRetry runs a single copy from a batch, retrying with an exponential backoff.
+func (rc *RegClient) imageCopyRetry(ctx context.Context, pair CopyPair, iOpts []ImageOpts, opts BatchOpts, log *slog.Logger) (int, error) {
+	delay := opts.RetryDelay
+	for attempt := 1; ; attempt++ {
+		err := rc.ImageCopy(ctx, pair.Src, pair.Tgt, iOpts...)
+		if err == nil || attempt > opts.Retries || !imageCopyRetryable(err) || ctx.Err() != nil {
+			return attempt, err
+		}
+		log.Info("Retrying image copy",
+			slog.String("src", pair.Src.CommonName()),
+			slog.String("tgt", pair.Tgt.CommonName()),
+			slog.Int("attempt", attempt),
+			slog.String("delay", delay.String()),
+			slog.String("err", err.Error()))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return attempt, err
+		}
+		delay = min(delay*2, opts.RetryDelayMax)
+	}
+}
+
+// imageCopyLogID returns the "op" value logged by ImageCopy for a batch pair, see [ImageWithLogID].
+func imageCopyLogID(pair CopyPair, iOpts []ImageOpts) string {
+	opt := imageOpt{}
+	for _, optFn := range iOpts {
+		optFn(&opt)
+	}
+	if opt.logID != "" {
+		return opt.logID
+	}
+	return pair.Src.CommonName() + " -> " + pair.Tgt.CommonName()
+}
+
+// imageCopyRetryable returns false for errors that will not change with a retry.
+func imageCopyRetryable(err error) bool {
+	for _, e := range []error{
+		errs.ErrNotFound,
+		errs.ErrHTTPUnauthorized,
+		errs.ErrInvalidReference,
+		errs.ErrNotRetryable,
+		context.Canceled,
+	} {
+		if errors.Is(err, e) {
+			return false
+		}
+	}
+	return true
+}
+
+// imageCopyOpt is a thread safe copy of a manifest and nested content.
+func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, d descriptor.Descriptor, child bool, parents []digest.Digest, opt *imageOpt) (err error) {
+	var mSrc, mTgt manifest.Manifest
+	var sDig digest.Digest
+	refTgtRepo := refTgt.SetTag("").CommonName()
+	seenCB := func(error) {}
+	defer func() {
+		if seenCB != nil {
+			seenCB(err)
+		}
+	}()
+	// if digest is provided and we are already copying it, wait
+	if d.Digest != "" {
+		sDig = d.Digest
+	} else if refSrc.Digest != "" {
+		sDig = digest.Digest(refSrc.Digest)
+	}
+	if sDig != "" {
+		if seenCB, err = imageSeenOrWait(ctx, opt, refTgtRepo, refTgt.Tag, sDig, parents); seenCB == nil {
+			return err
+		}
+	}
+	// check target with head request
+	mTgt, err = opt.rcTgt.ManifestHead(ctx, refTgt, WithManifestRequireDigest())
+	var urlError *url.Error
+	if err != nil && errors.As(err, &urlError) {
+		return fmt.Errorf("failed to access target registry: %w", err)
+	}
+	// for non-recursive copies, compare to source digest
+	if err == nil && !opt.force && (opt.fastCheck || (!opt.forceRecursive && opt.referrerConfs == nil && !opt.digestTags)) {
+		if sDig == "" {
+			mSrc, err = rc.ManifestHead(ctx, refSrc, WithManifestRequireDigest())
+			if err != nil {
+				return fmt.Errorf("copy failed, error getting source: %w", err)
+			}
+			sDig = mSrc.GetDescriptor().Digest
+			if seenCB, err = imageSeenOrWait(ctx, opt, refTgtRepo, refTgt.Tag, sDig, parents); seenCB == nil {
+				return err
+			}
+		}
+		if sDig == mTgt.GetDescriptor().Digest {
+			if opt.callback != nil {
+				opt.callback(types.CallbackManifest, d.Digest.String(), types.CallbackSkipped, mTgt.GetDescriptor().Size, mTgt.GetDescriptor().Size)
+			}
+			return nil
+		}
+	}
+	// when copying/updating digest tags or referrers, only the source digest is needed for an image
+	if mTgt != nil && mSrc == nil && !opt.forceRecursive && sDig == "" {
+		mSrc, err = rc.ManifestHead(ctx, refSrc, WithManifestRequireDigest())
+		if err != nil {
+			return fmt.Errorf("copy failed, error getting source: %w", err)
+		}
+		sDig = mSrc.GetDescriptor().Digest
+		if seenCB, err = imageSeenOrWait(ctx, opt, refTgtRepo, refTgt.Tag, sDig, parents); seenCB == nil {
+			return err
+		}
+	}
+	// get the source manifest when a copy is needed or recursion into the content is needed
+	if sDig == "" || mTgt == nil || sDig != mTgt.GetDescriptor().Digest || opt.forceRecursive || mTgt.IsList() {
+		mSrc, err = rc.ManifestGet(ctx, refSrc, WithManifestDesc(d))
+		if err != nil {
+			return fmt.Errorf("copy failed, error getting source: %w", err)
 		}
 		if sDig == "" {
 			sDig = mSrc.GetDescriptor().Digest
@@ -622,10 +2097,25 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 	if child {
 		mOpts = append(mOpts, WithManifestChild())
 	}
+	if opt.noOverwrite && refTgt.Tag != "" && ref.EqualRepository(refTgt, opt.noOverwriteRef) && refTgt.Tag == opt.noOverwriteRef.Tag {
+		mOpts = append(mOpts, WithManifestNoOverwrite())
+	}
 	bOpt := []BlobOpts{}
 	if opt.callback != nil {
 		bOpt = append(bOpt, BlobWithCallback(opt.callback))
 	}
+	if opt.force {
+		bOpt = append(bOpt, BlobWithForce())
+	}
+	if opt.limiter != nil {
+		bOpt = append(bOpt, blobWithLimiter(opt.limiter))
+	}
+	if opt.rcTgt != rc {
+		bOpt = append(bOpt, BlobWithTargetClient(opt.rcTgt))
+	}
+	bOpt = append(bOpt, blobWithSlog(opt.slog))
+	// content in the same repository only needs to be copied when accessed with a different client or transformed
+	sameRepo := opt.rcTgt == rc && ref.EqualRepository(refSrc, refTgt) && opt.blobTransform == nil
 	waitCh := make(chan error)
 	waitCount := 0
 	ctx, cancel := context.WithCancel(ctx)
@@ -637,7 +2127,7 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 		opt.callback(types.CallbackManifest, d.Digest.String(), types.CallbackStarted, 0, d.Size)
 	}
 	// process entries in an index
-	if mSrcIndex, ok := mSrc.(manifest.Indexer); ok && mSrc.IsSet() && !ref.EqualRepository(refSrc, refTgt) {
+	if mSrcIndex, ok := mSrc.(manifest.Indexer); ok && mSrc.IsSet() && !sameRepo {
 		// manifest lists need to recursively copy nested images by digest
 		dList, err := mSrcIndex.GetManifestList()
 		if err != nil {
@@ -651,7 +2141,7 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 					return err
 				}
 				if !match {
-					rc.slog.Debug("Platform excluded from copy",
+					opt.slog.Debug("Platform excluded from copy",
 						slog.Any("platform", dEntry.Platform))
 					continue
 				}
@@ -660,7 +2150,7 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 			waitCount++
 			go func() {
 				var err error
-				rc.slog.Debug("Copy platform",
+				opt.slog.Debug("Copy platform",
 					slog.Any("platform", dEntry.Platform),
 					slog.String("digest", dEntry.Digest.String()))
 				entrySrc := refSrc.SetDigest(dEntry.Digest.String())
@@ -691,27 +2181,32 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 	}
 
 	// If source is image, copy blobs
-	if mSrcImg, ok := mSrc.(manifest.Imager); ok && mSrc.IsSet() && !ref.EqualRepository(refSrc, refTgt) {
+	var confTransform *descriptor.Descriptor
+	var layersSrc []descriptor.Descriptor
+	if mSrcImg, ok := mSrc.(manifest.Imager); ok && mSrc.IsSet() && !sameRepo {
 		// copy the config
 		cd, err := mSrcImg.GetConfig()
 		if err != nil {
 			// docker schema v1 does not have a config object, ignore if it's missing
 			if !errors.Is(err, errs.ErrUnsupportedMediaType) {
-				rc.slog.Warn("Failed to get config digest from manifest",
+				opt.slog.Warn("Failed to get config digest from manifest",
 					slog.String("ref", refSrc.Reference),
 					slog.String("err", err.Error()))
 				return fmt.Errorf("failed to get config digest for %s: %w", refSrc.CommonName(), err)
 			}
+		} else if opt.blobTransform != nil {
+			// transformed layers change the diff_ids, so the config is copied after the layers
+			confTransform = &cd
 		} else {
 			waitCount++
 			go func() {
-				rc.slog.Info("Copy config",
+				opt.slog.Info("Copy config",
 					slog.String("source", refSrc.Reference),
 					slog.String("target", refTgt.Reference),
 					slog.String("digest", cd.Digest.String()))
 				err := rc.imageCopyBlob(ctx, refSrc, refTgt, cd, opt, bOpt...)
 				if err != nil && !errors.Is(err, context.Canceled) {
-					rc.slog.Warn("Failed to copy config",
+					opt.slog.Warn("Failed to copy config",
 						slog.String("source", refSrc.Reference),
 						slog.String("target", refTgt.Reference),
 						slog.String("digest", cd.Digest.String()),
@@ -726,10 +2221,18 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 		if err != nil {
 			return err
 		}
+		layersSrc = l
 		for _, layerSrc := range l {
+			if opt.configOnly {
+				opt.slog.Debug("Skipping layer for config only copy",
+					slog.String("source", refSrc.Reference),
+					slog.String("target", refTgt.Reference),
+					slog.String("layer", layerSrc.Digest.String()))
+				continue
+			}
 			if len(layerSrc.URLs) > 0 && !opt.includeExternal {
 				// skip blobs where the URLs are defined, these aren't hosted and won't be pulled from the source
-				rc.slog.Debug("Skipping external layer",
+				opt.slog.Debug("Skipping external layer",
 					slog.String("source", refSrc.Reference),
 					slog.String("target", refTgt.Reference),
 					slog.String("layer", layerSrc.Digest.String()),
@@ -739,13 +2242,13 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 			waitCount++
 			layerSrc := layerSrc
 			go func() {
-				rc.slog.Info("Copy layer",
+				opt.slog.Info("Copy layer",
 					slog.String("source", refSrc.Reference),
 					slog.String("target", refTgt.Reference),
 					slog.String("layer", layerSrc.Digest.String()))
 				err := rc.imageCopyBlob(ctx, refSrc, refTgt, layerSrc, opt, bOpt...)
 				if err != nil && !errors.Is(err, context.Canceled) {
-					rc.slog.Warn("Failed to copy layer",
+					opt.slog.Warn("Failed to copy layer",
 						slog.String("source", refSrc.Reference),
 						slog.String("target", refTgt.Reference),
 						slog.String("layer", layerSrc.Digest.String()),
@@ -782,7 +2285,7 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 		}
 	}
 	if err != nil {
-		rc.slog.Debug("child manifest copy failed",
+		opt.slog.Debug("child manifest copy failed",
 			slog.String("err", err.Error()),
 			slog.String("sDig", sDig.String()))
 		return err
@@ -822,9 +2325,9 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 			}
 		}
 		for _, rDesc := range descList {
-			opt.mu.Lock()
-			seen := opt.seen[":"+rDesc.Digest.String()]
-			opt.mu.Unlock()
+			opt.seen.mu.Lock()
+			seen := opt.seen.seen[":"+rDesc.Digest.String()]
+			opt.seen.mu.Unlock()
 			if seen != nil {
 				continue // skip referrers that have been seen
 			}
@@ -844,7 +2347,7 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 					waitCh <- nil
 				} else {
 					if err != nil && !errors.Is(err, context.Canceled) {
-						rc.slog.Warn("Failed to copy referrer",
+						opt.slog.Warn("Failed to copy referrer",
 							slog.String("digest", rDesc.Digest.String()),
 							slog.String("src", referrerSrc.CommonName()),
 							slog.String("tgt", referrerTgt.CommonName()))
@@ -863,7 +2366,7 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 			tl, err := rc.TagList(ctx, refSrc)
 			if err != nil {
 				opt.mu.Unlock()
-				rc.slog.Warn("Failed to list tags for digest-tag copy",
+				opt.slog.Warn("Failed to list tags for digest-tag copy",
 					slog.String("source", refSrc.Reference),
 					slog.String("err", err.Error()))
 				return err
@@ -871,7 +2374,7 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 			tags, err := tl.GetTags()
 			if err != nil {
 				opt.mu.Unlock()
-				rc.slog.Warn("Failed to list tags for digest-tag copy",
+				opt.slog.Warn("Failed to list tags for digest-tag copy",
 					slog.String("source", refSrc.Reference),
 					slog.String("err", err.Error()))
 				return err
@@ -912,7 +2415,7 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 						waitCh <- nil
 					} else {
 						if err != nil && !errors.Is(err, context.Canceled) {
-							rc.slog.Warn("Failed to copy digest-tag",
+							opt.slog.Warn("Failed to copy digest-tag",
 								slog.String("tag", tag),
 								slog.String("src", refTagSrc.CommonName()),
 								slog.String("tgt", refTagTgt.CommonName()))
@@ -946,12 +2449,54 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 		return err
 	}
 
+	// copy a transformed config after the layers to include the updated diff_ids
+	if confTransform != nil {
+		opt.slog.Info("Copy config",
+			slog.String("source", refSrc.Reference),
+			slog.String("target", refTgt.Reference),
+			slog.String("digest", confTransform.Digest.String()))
+		err = rc.imageCopyConfigTransform(ctx, refSrc, refTgt, *confTransform, layersSrc, opt)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				opt.slog.Warn("Failed to copy config",
+					slog.String("source", refSrc.Reference),
+					slog.String("target", refTgt.Reference),
+					slog.String("digest", confTransform.Digest.String()),
+					slog.String("err", err.Error()))
+			}
+			return err
+		}
+	}
+
+	// update descriptors of modified content and convert external layers, the new digest is tracked to update any parent index
+	if (opt.externalURLsRm || opt.blobTransform != nil) && mSrc != nil && mSrc.IsSet() {
+		err = imageCopyUpdateDigests(mSrc, opt)
+		if err != nil {
+			return err
+		}
+		if opt.externalURLsRm {
+			err = imageCopyExternalRm(mSrc)
+			if err != nil {
+				return err
+			}
+		}
+		if dNew := mSrc.GetDescriptor(); dNew.Digest != sDig {
+			opt.mu.Lock()
+			opt.updatedDigests[sDig] = dNew
+			opt.mu.Unlock()
+			sDig = dNew.Digest
+			if refTgt.Digest != "" {
+				refTgt = refTgt.SetDigest(sDig.String())
+			}
+		}
+	}
+
 	// push manifest
 	if mTgt == nil || sDig != mTgt.GetDescriptor().Digest || opt.forceRecursive {
-		err = rc.ManifestPut(ctx, refTgt, mSrc, mOpts...)
+		err = opt.rcTgt.ManifestPut(ctx, refTgt, mSrc, mOpts...)
 		if err != nil {
 			if !errors.Is(err, context.Canceled) {
-				rc.slog.Warn("Failed to push manifest",
+				opt.slog.Warn("Failed to push manifest",
 					slog.String("target", refTgt.Reference),
 					slog.String("err", err.Error()))
 			}
@@ -960,6 +2505,19 @@ func (rc *RegClient) imageCopyOpt(ctx context.Context, refSrc ref.Ref, refTgt re
 		if opt.callback != nil {
 			opt.callback(types.CallbackManifest, d.Digest.String(), types.CallbackFinished, d.Size, d.Size)
 		}
+		// track the subject to verify it exists on the target after the copy completes
+		// referrers pushed to an external repository are expected to have a subject elsewhere
+		if ms, ok := mSrc.(manifest.Subjecter); ok && (!opt.referrerTgt.IsSet() || !ref.EqualRepository(refTgt, opt.referrerTgt)) {
+			sDesc, err := ms.GetSubject()
+			if err == nil && sDesc != nil && sDesc.Digest != "" {
+				rSubject := refTgt.SetDigest(sDesc.Digest.String())
+				opt.mu.Lock()
+				if opt.subjects != nil {
+					opt.subjects[rSubject.CommonName()] = rSubject
+				}
+				opt.mu.Unlock()
+			}
+		}
 	} else {
 		if opt.callback != nil {
 			opt.callback(types.CallbackManifest, d.Digest.String(), types.CallbackSkipped, d.Size, d.Size)
@@ -978,25 +2536,321 @@ func (rc *RegClient) imageCopyBlob(ctx context.Context, refSrc ref.Ref, refTgt r
 	if seenCB == nil {
 		return err
 	}
+	if opt.deltaBlobs[d.Digest] {
+		if ref.EqualRepository(opt.deltaBase, refTgt) {
+			// blob is in the base image on the target
+			if opt.callback != nil {
+				opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackSkipped, 0, d.Size)
+			}
+			seenCB(nil)
+			return nil
+		}
+		// mount the blob from the base repository
+		err = opt.rcTgt.BlobCopy(ctx, opt.deltaBase, refTgt, d, bOpt...)
+		seenCB(err)
+		return err
+	}
+	if opt.blobTransform != nil {
+		err = rc.imageCopyBlobTransform(ctx, refSrc, refTgt, d, nil, opt)
+		seenCB(err)
+		return err
+	}
 	err = rc.BlobCopy(ctx, refSrc, refTgt, d, bOpt...)
 	seenCB(err)
 	return err
 }
 
+// imageCopyConfigTransform pushes the transformed config of an image after the layers, see [RegClient.imageCopyBlobTransform].
+func (rc *RegClient) imageCopyConfigTransform(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, d descriptor.Descriptor, layers []descriptor.Descriptor, opt *imageOpt) error {
+	seenCB, err := imageSeenOrWait(ctx, opt, refTgt.SetTag("").CommonName(), "", d.Digest, []digest.Digest{})
+	if seenCB == nil {
+		return err
+	}
+	err = rc.imageCopyBlobTransform(ctx, refSrc, refTgt, d, layers, opt)
+	seenCB(err)
+	return err
+}
+
+// imageCopyBlobTransform pushes the transformed content of a blob, tracking the new descriptor to update the manifest.
+// When layers is set, the blob is a config, and the diff_ids of any transformed layers are updated before the transform.
+// For layers with a known compression, the digest of the uncompressed content is tracked to update the config.
+func (rc *RegClient) imageCopyBlobTransform(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, d descriptor.Descriptor, layers []descriptor.Descriptor, opt *imageOpt) error {
+	if opt.callback != nil {
+		opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackStarted, 0, d.Size)
+	}
+	// the blob is streamed from the source to the target, acquire the throttles of both like BlobCopy
+	tList, err := rc.blobThrottle(refSrc, false)
+	if err != nil {
+		return err
+	}
+	tTgtList, err := opt.rcTgt.blobThrottle(refTgt, true)
+	if err != nil {
+		return err
+	}
+	ctx, done, err := pqueue.AcquireMulti[reqmeta.Data](ctx, reqmeta.Data{Kind: reqmeta.Blob, Size: d.Size}, append(tList, tTgtList...)...)
+	if err != nil {
+		return err
+	}
+	defer done()
+	blobIO, err := rc.BlobGet(ctx, refSrc, d)
+	if err != nil {
+		return err
+	}
+	defer blobIO.Close()
+	var rdr io.Reader = blobIO
+	if opt.limiter != nil {
+		rdr = &ratelimit.Reader{Ctx: ctx, Reader: blobIO, Limiter: opt.limiter}
+	}
+	dSrc := d
+	if layers != nil {
+		confBytes, err := io.ReadAll(rdr)
+		if err != nil {
+			return fmt.Errorf("failed to read config %s: %w", d.Digest.String(), err)
+		}
+		confBytes, err = imageConfDiffIDs(d, confBytes, layers, opt)
+		if err != nil {
+			return fmt.Errorf("failed to update config %s: %w", d.Digest.String(), err)
+		}
+		dSrc.Digest = dSrc.DigestAlgo().FromBytes(confBytes)
+		dSrc.Size = int64(len(confBytes))
+		rdr = bytes.NewReader(confBytes)
+	}
+	dTransform, rdr, err := opt.blobTransform(ctx, dSrc, rdr)
+	if err != nil {
+		return fmt.Errorf("failed to transform blob %s: %w", d.Digest.String(), err)
+	}
+	dNew := d
+	if dTransform.MediaType != "" {
+		dNew.MediaType = dTransform.MediaType
+	}
+	// digest the uncompressed content of a layer while pushing it
+	var diffPW *io.PipeWriter
+	var diffCh chan digest.Digest
+	if ct, ok := archive.MediaTypeCompression(dNew.MediaType); ok && layers == nil {
+		diffPR, pw := io.Pipe()
+		diffPW = pw
+		diffCh = make(chan digest.Digest, 1)
+		rdr = io.TeeReader(rdr, diffPW)
+		go func() {
+			var dig digest.Digest
+			dr, err := archive.Decompress(diffPR, archive.DecompressWithType(ct))
+			if err == nil {
+				digester := d.DigestAlgo().Digester()
+				if _, err = io.Copy(digester.Hash(), dr); err == nil {
+					dig = digester.Digest()
+				}
+			}
+			// drain the pipe so the push is never blocked
+			_, _ = io.Copy(io.Discard, diffPR)
+			diffCh <- dig
+		}()
+	}
+	dPut, err := opt.rcTgt.BlobPut(ctx, refTgt, descriptor.Descriptor{Digest: dTransform.Digest, Size: dTransform.Size}, rdr)
+	if err == nil && diffPW != nil {
+		// include any content not read by the push in the diff_id
+		_, err = io.Copy(io.Discard, rdr)
+	}
+	if diffPW != nil {
+		_ = diffPW.CloseWithError(err)
+	}
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			opt.slog.Warn("Failed to push transformed blob",
+				slog.String("src", refSrc.Reference),
+				slog.String("tgt", refTgt.Reference),
+				slog.String("digest", d.Digest.String()),
+				slog.String("err", err.Error()))
+		}
+		return err
+	}
+	if diffCh != nil {
+		diffID := <-diffCh
+		if diffID == "" {
+			return fmt.Errorf("failed to compute the diff_id of transformed layer %s%.0w", d.Digest.String(), errs.ErrParsingFailed)
+		}
+		opt.mu.Lock()
+		opt.updatedDiffIDs[d.Digest] = diffID
+		opt.mu.Unlock()
+	}
+	if opt.callback != nil {
+		opt.callback(types.CallbackBlob, d.Digest.String(), types.CallbackFinished, d.Size, d.Size)
+	}
+	dNew.Digest = dPut.Digest
+	dNew.Size = dPut.Size
+	if dNew.MediaType != d.MediaType || dNew.Digest != d.Digest || dNew.Size != d.Size {
+		opt.slog.Debug("Blob transformed",
+			slog.String("src", d.Digest.String()),
+			slog.String("tgt", dNew.Digest.String()))
+		opt.mu.Lock()
+		opt.updatedDigests[d.Digest] = dNew
+		opt.mu.Unlock()
+	}
+	return nil
+}
+
+// imageConfDiffIDs replaces the diff_ids of transformed layers in an image config.
+// The config is returned unmodified when no diff_ids change or the diff_ids do not match the layers, e.g. for an artifact.
+// Unknown fields in the config are preserved.
+func imageConfDiffIDs(d descriptor.Descriptor, confBytes []byte, layers []descriptor.Descriptor, opt *imageOpt) ([]byte, error) {
+	if d.MediaType != mediatype.OCI1ImageConfig && d.MediaType != mediatype.Docker2ImageConfig {
+		return confBytes, nil
+	}
+	opt.mu.Lock()
+	updated := map[int]digest.Digest{}
+	for i, l := range layers {
+		if diffID, ok := opt.updatedDiffIDs[l.Digest]; ok {
+			updated[i] = diffID
+		}
+	}
+	opt.mu.Unlock()
+	if len(updated) == 0 {
+		return confBytes, nil
+	}
+	conf := map[string]json.RawMessage{}
+	err := json.Unmarshal(confBytes, &conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	rootFS := map[string]json.RawMessage{}
+	if raw, ok := conf["rootfs"]; ok {
+		err = json.Unmarshal(raw, &rootFS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config rootfs: %w", err)
+		}
+	}
+	diffIDs := []digest.Digest{}
+	if raw, ok := rootFS["diff_ids"]; ok {
+		err = json.Unmarshal(raw, &diffIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config diff_ids: %w", err)
+		}
+	}
+	if len(diffIDs) != len(layers) {
+		return confBytes, nil
+	}
+	changed := false
+	for i, diffID := range updated {
+		if diffIDs[i] != diffID {
+			diffIDs[i] = diffID
+			changed = true
+		}
+	}
+	if !changed {
+		return confBytes, nil
+	}
+	rootFS["diff_ids"], err = json.Marshal(diffIDs)
+	if err != nil {
+		return nil, err
+	}
+	conf["rootfs"], err = json.Marshal(rootFS)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(conf)
+}
+
+// imageCopyUpdateDigests replaces index entries, configs, and layers that were modified earlier in the copy.
+func imageCopyUpdateDigests(m manifest.Manifest, opt *imageOpt) error {
+	opt.mu.Lock()
+	defer opt.mu.Unlock()
+	update := func(d *descriptor.Descriptor) bool {
+		dNew, ok := opt.updatedDigests[d.Digest]
+		if !ok {
+			return false
+		}
+		d.MediaType = dNew.MediaType
+		d.Digest = dNew.Digest
+		d.Size = dNew.Size
+		d.Data = nil
+		return true
+	}
+	if mi, ok := m.(manifest.Indexer); ok {
+		dl, err := mi.GetManifestList()
+		if err != nil {
+			return err
+		}
+		changed := false
+		for i := range dl {
+			if update(&dl[i]) {
+				changed = true
+			}
+		}
+		if changed {
+			return mi.SetManifestList(dl)
+		}
+		return nil
+	}
+	if mi, ok := m.(manifest.Imager); ok {
+		// schema1 manifests do not have a config
+		if cd, err := mi.GetConfig(); err == nil && update(&cd) {
+			err = mi.SetConfig(cd)
+			if err != nil {
+				return err
+			}
+		}
+		dl, err := mi.GetLayers()
+		if err != nil {
+			return err
+		}
+		changed := false
+		for i := range dl {
+			if update(&dl[i]) {
+				changed = true
+			}
+		}
+		if changed {
+			return mi.SetLayers(dl)
+		}
+	}
+	return nil
+}
+
+// imageCopyExternalRm removes external URLs from image layers.
+func imageCopyExternalRm(m manifest.Manifest) error {
+	if mi, ok := m.(manifest.Imager); ok {
+		dl, err := mi.GetLayers()
+		if err != nil {
+			return err
+		}
+		changed := false
+		for i := range dl {
+			if len(dl[i].URLs) == 0 {
+				continue
+			}
+			dl[i].URLs = nil
+			switch dl[i].MediaType {
+			case mediatype.Docker2ForeignLayer:
+				dl[i].MediaType = mediatype.Docker2LayerGzip
+			case mediatype.OCI1ForeignLayer:
+				dl[i].MediaType = mediatype.OCI1Layer
+			case mediatype.OCI1ForeignLayerGzip:
+				dl[i].MediaType = mediatype.OCI1LayerGzip
+			case mediatype.OCI1ForeignLayerZstd:
+				dl[i].MediaType = mediatype.OCI1LayerZstd
+			}
+			changed = true
+		}
+		if changed {
+			return mi.SetLayers(dl)
+		}
+	}
+	return nil
+}
+
 // imageSeenOrWait returns either a callback to report the error when the digest hasn't been seen before
 // or it will wait for the previous copy to run and return the error from that copy
 func imageSeenOrWait(ctx context.Context, opt *imageOpt, repo, tag string, dig digest.Digest, parents []digest.Digest) (func(error), error) {
 	var seenNew *imageSeen
 	key := repo + "/" + tag + ":" + dig.String()
-	opt.mu.Lock()
-	seen := opt.seen[key]
+	opt.seen.mu.Lock()
+	seen := opt.seen.seen[key]
 	if seen == nil {
 		seenNew = &imageSeen{
 			done: make(chan struct{}),
 		}
-		opt.seen[key] = seenNew
+		opt.seen.seen[key] = seenNew
 	}
-	opt.mu.Unlock()
+	opt.seen.mu.Unlock()
 	if seen != nil {
 		// quick check for the previous copy already done
 		select {
@@ -1024,9 +2878,9 @@ func imageSeenOrWait(ctx context.Context, opt *imageOpt, repo, tag string, dig d
 			close(seenNew.done)
 			// on failures, delete the history to allow a retry
 			if err != nil {
-				opt.mu.Lock()
-				delete(opt.seen, key)
-				opt.mu.Unlock()
+				opt.seen.mu.Lock()
+				delete(opt.seen.seen, key)
+				opt.seen.mu.Unlock()
 			}
 		}, nil
 	}
@@ -1037,6 +2891,15 @@ func imageSeenOrWait(ctx context.Context, opt *imageOpt, repo, tag string, dig d
 // The ref must include a tag for exporting to docker (defaults to latest), and may also include a digest.
 // The export is also formatted according to [OCI Layout] which supports multi-platform images.
 // A tar file will be sent to outStream.
+// Any write error, including a short write, is returned.
+// The outStream is not flushed or closed, callers using a buffered writer like [bufio.Writer] must flush it after ImageExport returns.
+// Content is streamed to outStream without creating temporary files.
+// Layers are written as they are stored in the registry, compressed blobs are never decompressed, and every blob is verified against its digest.
+// Uncompressed variants of a layer are not requested since the registry API has no negotiation for them and they could not be verified against the descriptor.
+// Files in the tar use the Unix epoch for the modification time, see [ImageWithExportTime] and [ImageWithExportTimeCreated] to change this.
+// Canceling ctx, e.g. on an interrupt, stops the export and returns an error, leaving any cleanup of a partial output to the caller.
+// Use [ImageWithPlatform] to export a single platform from an Index or Manifest List, defaulting to REGCLIENT_PLATFORM when set.
+// Without a platform, the entire Index or Manifest List is exported.
 //
 // Resulting filesystem:
 //   - oci-layout: created at top level, can be done at the start
@@ -1049,70 +2912,226 @@ func (rc *RegClient) ImageExport(ctx context.Context, r ref.Ref, outStream io.Wr
 	if !r.IsSet() {
 		return fmt.Errorf("ref is not set: %s%.0w", r.CommonName(), errs.ErrInvalidReference)
 	}
-	var ociIndex v1.Index
-
-	var opt imageOpt
+	opt := imageOpt{
+		platform: imagePlatformDefault(),
+	}
 	for _, optFn := range opts {
 		optFn(&opt)
 	}
 	if opt.exportRef.IsZero() {
 		opt.exportRef = r
 	}
+	if opt.expectDigest != "" {
+		var err error
+		r, err = rc.imageExpectDigest(ctx, r, opt.expectDigest)
+		if err != nil {
+			return err
+		}
+	}
+	if opt.platform != "" {
+		p, err := platform.Parse(opt.platform)
+		if err != nil {
+			return fmt.Errorf("failed to parse platform %s: %w", opt.platform, err)
+		}
+		m, err := rc.ManifestGet(ctx, r, WithManifestPlatform(p))
+		if err != nil {
+			return fmt.Errorf("failed to get manifest for platform %s: %w", opt.platform, err)
+		}
+		r = r.SetDigest(m.GetDescriptor().Digest.String())
+	}
+	return rc.imageExport(ctx, []ref.Ref{r}, []ref.Ref{opt.exportRef}, outStream, &opt)
+}
+
+// imageExpectDigest verifies a ref resolves to the expected digest, returning the ref pinned to that digest.
+func (rc *RegClient) imageExpectDigest(ctx context.Context, r ref.Ref, expect digest.Digest) (ref.Ref, error) {
+	mh, err := rc.ManifestHead(ctx, r, WithManifestRequireDigest())
+	if err != nil {
+		return r, fmt.Errorf("failed to get the digest of %s: %w", r.CommonName(), err)
+	}
+	if dig := mh.GetDescriptor().Digest; dig != expect {
+		return r, fmt.Errorf("%s resolved to %s, expected %s%.0w", r.CommonName(), dig.String(), expect.String(), errs.ErrDigestMismatch)
+	}
+	r.Digest = expect.String()
+	return r, nil
+}
+
+// ImageExportMulti exports multiple images to a single output stream, similar to "docker save img1 img2".
+// The index.json includes a descriptor for each ref, and the manifest.json includes an entry for each single platform image.
+// Refs resolving to the same manifest are combined into one manifest.json entry with multiple RepoTags.
+// Manifests and blobs shared between the images are only included once.
+// Each ref is used as the name of its image, so [ImageWithExportRef] is not supported.
+// [ImageWithExpectDigest] is also not supported, include the digest in each ref to pin the images.
+// See [RegClient.ImageExport] for details on the output format.
+func (rc *RegClient) ImageExportMulti(ctx context.Context, refs []ref.Ref, outStream io.Writer, opts ...ImageOpts) error {
+	if len(refs) == 0 {
+		return fmt.Errorf("no images to export%.0w", errs.ErrInvalidReference)
+	}
+	for _, r := range refs {
+		if !r.IsSet() {
+			return fmt.Errorf("ref is not set: %s%.0w", r.CommonName(), errs.ErrInvalidReference)
+		}
+	}
+	var opt imageOpt
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+	if !opt.exportRef.IsZero() {
+		return fmt.Errorf("export ref is not supported when exporting multiple images%.0w", errs.ErrUnsupported)
+	}
+	if opt.expectDigest != "" {
+		return fmt.Errorf("expected digest is not supported when exporting multiple images%.0w", errs.ErrUnsupported)
+	}
+	return rc.imageExport(ctx, refs, refs, outStream, &opt)
+}
 
+// imageExport writes each ref to a tar, named with the matching entry in exportRefs.
+func (rc *RegClient) imageExport(ctx context.Context, refs, exportRefs []ref.Ref, outStream io.Writer, opt *imageOpt) error {
 	// dedup warnings
 	if w := warning.FromContext(ctx); w == nil {
 		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
 	}
 	// create tar writer object
 	out := outStream
-	if opt.exportCompress {
-		gzOut := gzip.NewWriter(out)
-		defer gzOut.Close()
-		out = gzOut
+	var compOut io.WriteCloser
+	switch opt.exportCompress {
+	case archive.CompressNone:
+	case archive.CompressGzip:
+		gzOut, err := gzip.NewWriterLevel(out, rc.gzipLevel)
+		if err != nil {
+			return err
+		}
+		compOut = gzOut
+	case archive.CompressZstd:
+		zOut, err := zstd.NewWriter(out)
+		if err != nil {
+			return err
+		}
+		compOut = zOut
+	default:
+		return fmt.Errorf("unsupported export compression: %s%.0w", opt.exportCompress.String(), errs.ErrUnsupported)
+	}
+	if compOut != nil {
+		defer compOut.Close()
+		out = compOut
 	}
 	tw := tar.NewWriter(out)
 	defer tw.Close()
 	twd := &tarWriteData{
-		tw:    tw,
-		dirs:  map[string]bool{},
-		files: map[string]bool{},
-		mode:  0644,
+		tw:        tw,
+		dirs:      map[string]bool{},
+		files:     map[string]bool{},
+		limiter:   opt.limiter,
+		blobs:     map[digest.Digest][]byte{},
+		manifests: map[digest.Digest]manifest.Manifest{},
+		mode:      0644,
+		timestamp: opt.exportTime,
 	}
 
-	// retrieve image manifest
-	m, err := rc.ManifestGet(ctx, r)
-	if err != nil {
-		rc.slog.Warn("Failed to get manifest",
-			slog.String("ref", r.CommonName()),
-			slog.String("err", err.Error()))
-		return err
+	// retrieve image manifests
+	mList := make([]manifest.Manifest, len(refs))
+	for i, r := range refs {
+		m, err := rc.ManifestGet(ctx, r)
+		if err != nil {
+			rc.slog.Warn("Failed to get manifest",
+				slog.String("ref", r.CommonName()),
+				slog.String("err", err.Error()))
+			return err
+		}
+		mList[i] = m
+	}
+
+	// use the most recent created time from the image configs
+	if opt.exportTimeConf {
+		var created *time.Time
+		for i, m := range mList {
+			mi, ok := m.(manifest.Imager)
+			if !ok {
+				continue
+			}
+			confDesc, err := mi.GetConfig()
+			if err != nil {
+				return err
+			}
+			// artifacts do not have a created time
+			if imageConfigCheck(confDesc) != nil {
+				continue
+			}
+			conf, err := rc.BlobGetOCIConfig(ctx, refs[i], confDesc)
+			if err != nil {
+				return err
+			}
+			c := conf.GetConfig().Created
+			if c != nil && (created == nil || c.After(*created)) {
+				created = c
+			}
+		}
+		if created != nil {
+			twd.timestamp = *created
+		}
+	}
+
+	// remove skipped layers, generating a new config and manifest for each image
+	if opt.exportLayerSkip != nil {
+		for i, m := range mList {
+			mNew, err := rc.imageExportLayerSkip(ctx, refs[i], m, opt.exportLayerSkip, opt.digestAlgo, twd)
+			if err != nil {
+				return err
+			}
+			mList[i] = mNew
+		}
 	}
 
 	// build/write oci-layout
 	ociLayout := v1.ImageLayout{Version: ociLayoutVersion}
-	err = twd.tarWriteFileJSON(ociLayoutFilename, ociLayout)
+	err := twd.tarWriteFileJSON(ociLayoutFilename, ociLayout)
 	if err != nil {
 		return err
 	}
 
-	// create a manifest descriptor
-	mDesc := m.GetDescriptor()
-	if mDesc.Annotations == nil {
-		mDesc.Annotations = map[string]string{}
+	// create a manifest descriptor for each image
+	descList := make([]descriptor.Descriptor, len(refs))
+	for i, m := range mList {
+		mDesc := m.GetDescriptor()
+		if mDesc.Annotations == nil {
+			mDesc.Annotations = map[string]string{}
+		}
+		mDesc.Annotations[annotationImageName] = exportRefs[i].CommonName()
+		mDesc.Annotations[annotationRefName] = exportRefs[i].Tag
+		descList[i] = mDesc
 	}
-	mDesc.Annotations[annotationImageName] = opt.exportRef.CommonName()
-	mDesc.Annotations[annotationRefName] = opt.exportRef.Tag
 
 	// generate/write an OCI index
-	ociIndex.Versioned = v1.IndexSchemaVersion
-	ociIndex.Manifests = []descriptor.Descriptor{mDesc} // initialize with the descriptor to the manifest list
+	ociIndex := v1.Index{
+		Versioned: v1.IndexSchemaVersion,
+		Manifests: descList, // initialize with the descriptor to each manifest
+	}
 	err = twd.tarWriteFileJSON(ociIndexFilename, ociIndex)
 	if err != nil {
 		return err
 	}
 
 	// append to docker manifest with tag, config filename, each layer filename, and layer descriptors
-	if mi, ok := m.(manifest.Imager); ok {
+	dockerManifestList := []dockerTarManifest{}
+	dockerManifestIdx := map[digest.Digest]int{}
+	for i, m := range mList {
+		mi, ok := m.(manifest.Imager)
+		if !ok {
+			continue
+		}
+		refTag := exportRefs[i].ToReg()
+		if refTag.Digest != "" {
+			refTag.Digest = ""
+		}
+		if refTag.Tag == "" {
+			refTag.Tag = "latest"
+		}
+		// the same image exported with multiple names shares an entry
+		if idx, ok := dockerManifestIdx[descList[i].Digest]; ok {
+			if !slices.Contains(dockerManifestList[idx].RepoTags, refTag.CommonName()) {
+				dockerManifestList[idx].RepoTags = append(dockerManifestList[idx].RepoTags, refTag.CommonName())
+			}
+			continue
+		}
 		conf, err := mi.GetConfig()
 		if err != nil {
 			return err
@@ -1120,12 +3139,9 @@ func (rc *RegClient) ImageExport(ctx context.Context, r ref.Ref, outStream io.Wr
 		if err = conf.Digest.Validate(); err != nil {
 			return err
 		}
-		refTag := opt.exportRef.ToReg()
-		if refTag.Digest != "" {
-			refTag.Digest = ""
-		}
-		if refTag.Tag == "" {
-			refTag.Tag = "latest"
+		// artifacts cannot be loaded by docker, they are only included in the OCI Layout
+		if imageConfigCheck(conf) != nil {
+			continue
 		}
 		dockerManifest := dockerTarManifest{
 			RepoTags:     []string{refTag.CommonName()},
@@ -1144,20 +3160,36 @@ func (rc *RegClient) ImageExport(ctx context.Context, r ref.Ref, outStream io.Wr
 			dockerManifest.Layers = append(dockerManifest.Layers, tarOCILayoutDescPath(d))
 			dockerManifest.LayerSources[d.Digest] = d
 		}
-
+		dockerManifestIdx[descList[i].Digest] = len(dockerManifestList)
+		dockerManifestList = append(dockerManifestList, dockerManifest)
+	}
+	if len(dockerManifestList) > 0 {
 		// marshal manifest and write manifest.json
-		err = twd.tarWriteFileJSON(dockerManifestFilename, []dockerTarManifest{dockerManifest})
+		err = twd.tarWriteFileJSON(dockerManifestFilename, dockerManifestList)
 		if err != nil {
 			return err
 		}
 	}
 
-	// recursively include manifests and nested blobs
-	err = rc.imageExportDescriptor(ctx, r, mDesc, twd)
-	if err != nil {
-		return err
+	// recursively include manifests and nested blobs, content already in the tar is skipped
+	for i, r := range refs {
+		err = rc.imageExportDescriptor(ctx, r, descList[i], twd)
+		if err != nil {
+			return err
+		}
 	}
 
+	// close the writers to output the end of the tar, errors here indicate a truncated export
+	err = tw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to finish tar: %w", err)
+	}
+	if compOut != nil {
+		err = compOut.Close()
+		if err != nil {
+			return fmt.Errorf("failed to finish compression: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -1174,10 +3206,14 @@ func (rc *RegClient) imageExportDescriptor(ctx context.Context, r ref.Ref, desc
 	switch desc.MediaType {
 	case mediatype.Docker1Manifest, mediatype.Docker1ManifestSigned, mediatype.Docker2Manifest, mediatype.OCI1Manifest:
 		// Handle single platform manifests
-		// retrieve manifest
-		m, err := rc.ManifestGet(ctx, r, WithManifestDesc(desc))
-		if err != nil {
-			return err
+		// retrieve manifest, using any manifest generated for the export
+		m, ok := twd.manifests[desc.Digest]
+		if !ok {
+			var err error
+			m, err = rc.ManifestGet(ctx, r, WithManifestDesc(desc))
+			if err != nil {
+				return err
+			}
 		}
 		mi, ok := m.(manifest.Imager)
 		if !ok {
@@ -1262,6 +3298,15 @@ func (rc *RegClient) imageExportDescriptor(ctx context.Context, r ref.Ref, desc
 		}
 
 	default:
+		// write any blob generated for the export
+		if b, ok := twd.blobs[desc.Digest]; ok {
+			err := twd.tarWriteHeader(tarFilename, int64(len(b)))
+			if err != nil {
+				return err
+			}
+			_, err = twd.tw.Write(b)
+			return err
+		}
 		// get blob
 		blobR, err := rc.BlobGet(ctx, r, desc)
 		if err != nil {
@@ -1273,7 +3318,11 @@ func (rc *RegClient) imageExportDescriptor(ctx context.Context, r ref.Ref, desc
 		if err != nil {
 			return err
 		}
-		size, err := io.Copy(twd.tw, blobR)
+		var rdr io.Reader = blobR
+		if twd.limiter != nil {
+			rdr = &ratelimit.Reader{Ctx: ctx, Reader: blobR, Limiter: twd.limiter}
+		}
+		size, err := io.Copy(twd.tw, rdr)
 		if err != nil {
 			return fmt.Errorf("failed to export blob %s: %w", desc.Digest.String(), err)
 		}
@@ -1285,6 +3334,162 @@ func (rc *RegClient) imageExportDescriptor(ctx context.Context, r ref.Ref, desc
 	return nil
 }
 
+// imageExportLayerSkip removes the layers selected by skip from an image, returning the modified manifest.
+// The generated config and manifest are added to twd to be included in the export.
+// When algo is set, the generated config and manifest are digested with that algorithm.
+func (rc *RegClient) imageExportLayerSkip(ctx context.Context, r ref.Ref, m manifest.Manifest, skip func(int, descriptor.Descriptor) bool, algo digest.Algorithm, twd *tarWriteData) (manifest.Manifest, error) {
+	if _, ok := m.(manifest.Imager); !ok {
+		return nil, fmt.Errorf("skipping layers requires a single platform image, %s is a %s%.0w", r.CommonName(), m.GetDescriptor().MediaType, errs.ErrUnsupportedMediaType)
+	}
+	// copy the manifest to avoid modifying a cached value
+	mBody, err := m.RawBody()
+	if err != nil {
+		return nil, err
+	}
+	mDesc := m.GetDescriptor()
+	if algo != "" {
+		mDesc.Digest = ""
+		err = mDesc.DigestAlgoPrefer(algo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mNew, err := manifest.New(manifest.WithDesc(mDesc), manifest.WithRaw(mBody))
+	if err != nil {
+		return nil, err
+	}
+	mi, ok := mNew.(manifest.Imager)
+	if !ok {
+		return nil, fmt.Errorf("manifest doesn't support image methods%.0w", errs.ErrUnsupportedMediaType)
+	}
+	confDesc, err := mi.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	err = imageConfigCheck(confDesc)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := mi.GetLayers()
+	if err != nil {
+		return nil, err
+	}
+	keep := []descriptor.Descriptor{}
+	skipped := map[int]bool{}
+	for i, d := range layers {
+		if skip(i, d) {
+			skipped[i] = true
+		} else {
+			keep = append(keep, d)
+		}
+	}
+	if len(skipped) == 0 {
+		return m, nil
+	}
+	confBlob, err := rc.BlobGet(ctx, r, confDesc)
+	if err != nil {
+		return nil, err
+	}
+	confBytes, err := confBlob.RawBody()
+	_ = confBlob.Close()
+	if err != nil {
+		return nil, err
+	}
+	confBytes, err = imageConfLayerSkip(confBytes, len(layers), skipped)
+	if err != nil {
+		return nil, err
+	}
+	if algo != "" {
+		confDesc.Digest = ""
+		err = confDesc.DigestAlgoPrefer(algo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	confDesc.Digest = confDesc.DigestAlgo().FromBytes(confBytes)
+	confDesc.Size = int64(len(confBytes))
+	err = mi.SetConfig(confDesc)
+	if err != nil {
+		return nil, err
+	}
+	err = mi.SetLayers(keep)
+	if err != nil {
+		return nil, err
+	}
+	twd.blobs[confDesc.Digest] = confBytes
+	twd.manifests[mNew.GetDescriptor().Digest] = mNew
+	return mNew, nil
+}
+
+// imageConfLayerSkip removes the diff_ids and history entries of skipped layers from a config.
+// Unknown fields in the config are preserved.
+func imageConfLayerSkip(confBytes []byte, layerCount int, skipped map[int]bool) ([]byte, error) {
+	conf := map[string]json.RawMessage{}
+	err := json.Unmarshal(confBytes, &conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	rootFS := map[string]json.RawMessage{}
+	if raw, ok := conf["rootfs"]; ok {
+		err = json.Unmarshal(raw, &rootFS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config rootfs: %w", err)
+		}
+	}
+	diffIDs := []digest.Digest{}
+	if raw, ok := rootFS["diff_ids"]; ok {
+		err = json.Unmarshal(raw, &diffIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config diff_ids: %w", err)
+		}
+	}
+	if len(diffIDs) != layerCount {
+		return nil, fmt.Errorf("config has %d diff_ids for %d layers%.0w", len(diffIDs), layerCount, errs.ErrMismatch)
+	}
+	diffIDsKeep := []digest.Digest{}
+	for i, d := range diffIDs {
+		if !skipped[i] {
+			diffIDsKeep = append(diffIDsKeep, d)
+		}
+	}
+	rootFS["diff_ids"], err = json.Marshal(diffIDsKeep)
+	if err != nil {
+		return nil, err
+	}
+	conf["rootfs"], err = json.Marshal(rootFS)
+	if err != nil {
+		return nil, err
+	}
+	// history entries without empty_layer each correspond to the next layer
+	if raw, ok := conf["history"]; ok {
+		history := []map[string]json.RawMessage{}
+		err = json.Unmarshal(raw, &history)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config history: %w", err)
+		}
+		historyKeep := []map[string]json.RawMessage{}
+		layer := 0
+		for _, h := range history {
+			empty := false
+			if rawEmpty, ok := h["empty_layer"]; ok {
+				_ = json.Unmarshal(rawEmpty, &empty)
+			}
+			if !empty {
+				layer++
+				if skipped[layer-1] {
+					continue
+				}
+			}
+			historyKeep = append(historyKeep, h)
+		}
+		conf["history"], err = json.Marshal(historyKeep)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(conf)
+}
+
 // ImageImport pushes an image from a tar file (ImageExport) to a registry.
 func (rc *RegClient) ImageImport(ctx context.Context, r ref.Ref, rs io.ReadSeeker, opts ...ImageOpts) error {
 	if !r.IsSetRepo() {
@@ -1299,13 +3504,20 @@ func (rc *RegClient) ImageImport(ctx context.Context, r ref.Ref, rs io.ReadSeeke
 	if w := warning.FromContext(ctx); w == nil {
 		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
 	}
-	trd := &tarReadData{
-		name:      opt.importName,
-		handlers:  map[string]tarFileHandler{},
-		links:     map[string][]string{},
-		processed: map[string]bool{},
-		finish:    []func() error{},
-		manifests: map[digest.Digest]manifest.Manifest{},
+	if opt.digestAlgo != "" && !opt.digestAlgo.Available() {
+		return fmt.Errorf("digest algorithm is not available: %s%.0w", opt.digestAlgo, errs.ErrUnsupported)
+	}
+	trd := tarReadDataNew(opt.importName, opt.digestAlgo)
+	if opt.importValidate {
+		trd.validate = &ImageImportResult{}
+	}
+	trd.dockerPlatform = platform.Local()
+	if opt.importPlatform != "" {
+		p, err := platform.Parse(opt.importPlatform)
+		if err != nil {
+			return fmt.Errorf("failed to parse platform %s: %w", opt.importPlatform, err)
+		}
+		trd.dockerPlatform = p
 	}
 
 	// add handler for oci-layout, index.json, and manifest.json
@@ -1324,12 +3536,24 @@ func (rc *RegClient) ImageImport(ctx context.Context, r ref.Ref, rs io.ReadSeeke
 		if err != nil {
 			return fmt.Errorf("failed to import layers from docker tar: %w", err)
 		}
+		// verify the uncompressed layers match the config
+		if len(trd.dockerConfDiffIDs) != len(trd.dockerDiffIDs) {
+			return fmt.Errorf("config has %d diff ids, tar contains %d layers%.0w", len(trd.dockerConfDiffIDs), len(trd.dockerDiffIDs), errs.ErrMismatch)
+		}
+		for i := range trd.dockerDiffIDs {
+			if trd.dockerConfDiffIDs[i] != trd.dockerDiffIDs[i] {
+				return fmt.Errorf("layer %d diff id mismatch, expected %s, computed %s%.0w", i, trd.dockerConfDiffIDs[i], trd.dockerDiffIDs[i], errs.ErrDigestMismatch)
+			}
+		}
 		// push docker manifest
 		m, err := manifest.New(manifest.WithOrig(trd.dockerManifest))
 		if err != nil {
 			return err
 		}
-		err = rc.ManifestPut(ctx, r, m)
+		if trd.validate != nil {
+			trd.validate.Desc = m.GetDescriptor()
+		}
+		err = rc.imageImportManifestPut(ctx, r, m, trd)
 		if err != nil {
 			return err
 		}
@@ -1343,20 +3567,129 @@ func (rc *RegClient) ImageImport(ctx context.Context, r ref.Ref, rs io.ReadSeeke
 			return err
 		}
 	}
+	if opt.importResult != nil {
+		*opt.importResult = *trd.validate
+	}
+	return nil
+}
+
+// ImageImportOCITar pushes an image from an OCI Layout tar stream to a registry.
+// The stream is read once without seeking, allowing the output of ImageExport to be piped directly into the import.
+// Manifests must appear in the tar before the content they reference, which is the order written by ImageExport.
+// Docker formatted tar files require ImageImport.
+func (rc *RegClient) ImageImportOCITar(ctx context.Context, r ref.Ref, rdr io.Reader, opts ...ImageOpts) error {
+	if !r.IsSetRepo() {
+		return fmt.Errorf("ref is not set: %s%.0w", r.CommonName(), errs.ErrInvalidReference)
+	}
+	var opt imageOpt
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+
+	// dedup warnings
+	if w := warning.FromContext(ctx); w == nil {
+		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
+	}
+	if opt.digestAlgo != "" && !opt.digestAlgo.Available() {
+		return fmt.Errorf("digest algorithm is not available: %s%.0w", opt.digestAlgo, errs.ErrUnsupported)
+	}
+	trd := tarReadDataNew(opt.importName, opt.digestAlgo)
+	if opt.importValidate {
+		trd.validate = &ImageImportResult{}
+	}
+	rc.imageImportOCIAddHandler(ctx, r, trd)
+
+	done, err := trd.tarReadPass(rdr)
+	if err != nil {
+		return err
+	}
+	if !done {
+		missing := make([]string, 0, len(trd.handlers))
+		for name := range trd.handlers {
+			missing = append(missing, name)
+		}
+		slices.Sort(missing)
+		return fmt.Errorf("unable to read all files from the tar stream, content must follow the manifests that reference it, missing %s%.0w", strings.Join(missing, ", "), errs.ErrNotFound)
+	}
+	err = rc.imageImportOCIPushManifests(ctx, r, trd)
+	if err != nil {
+		return err
+	}
+	if opt.importResult != nil {
+		*opt.importResult = *trd.validate
+	}
 	return nil
 }
 
 func (rc *RegClient) imageImportBlob(ctx context.Context, r ref.Ref, desc descriptor.Descriptor, trd *tarReadData) error {
 	// skip if blob already exists
-	_, err := rc.BlobHead(ctx, r, desc)
-	if err == nil {
-		return nil
+	if trd.validate == nil {
+		_, err := rc.BlobHead(ctx, r, desc)
+		if err == nil {
+			return nil
+		}
 	}
 	// upload blob
-	_, err = rc.BlobPut(ctx, r, desc, trd.tr)
+	_, err := rc.imageImportBlobPut(ctx, r, desc, trd.tr, trd)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// imageImportBlobPut pushes a blob from the tar.
+// When validating, the content is only read to verify or compute the digest and size.
+func (rc *RegClient) imageImportBlobPut(ctx context.Context, r ref.Ref, d descriptor.Descriptor, rdr io.Reader, trd *tarReadData) (descriptor.Descriptor, error) {
+	if trd.validate == nil {
+		return rc.BlobPut(ctx, r, d, rdr)
+	}
+	digester := d.DigestAlgo().Digester()
+	size, err := io.Copy(digester.Hash(), rdr)
+	if err != nil {
+		return d, err
+	}
+	if d.Size > 0 && size != d.Size {
+		return d, fmt.Errorf("blob content size does not match descriptor, expected %d, received %d%.0w", d.Size, size, errs.ErrMismatch)
+	}
+	if d.Digest != "" && digester.Digest() != d.Digest {
+		return d, fmt.Errorf("blob digest mismatch, expected %s, computed %s%.0w", d.Digest, digester.Digest(), errs.ErrDigestMismatch)
+	}
+	d.Digest = digester.Digest()
+	d.Size = size
+	return d, nil
+}
+
+// imageImportManifestPut pushes a manifest from the tar.
+// When validating, the manifest and the blobs it references are added to the result.
+func (rc *RegClient) imageImportManifestPut(ctx context.Context, r ref.Ref, m manifest.Manifest, trd *tarReadData, opts ...ManifestOpts) error {
+	if trd.validate == nil {
+		return rc.ManifestPut(ctx, r, m, opts...)
+	}
+	md := m.GetDescriptor()
+	for _, cur := range trd.validate.Manifests {
+		if cur.Digest == md.Digest {
+			return nil
+		}
+	}
+	trd.validate.Manifests = append(trd.validate.Manifests, md)
+	mi, ok := m.(manifest.Imager)
+	if !ok {
+		return nil
+	}
+	dl := []descriptor.Descriptor{}
+	if cd, err := mi.GetConfig(); err == nil {
+		dl = append(dl, cd)
+	}
+	layers, err := mi.GetLayers()
 	if err != nil {
 		return err
 	}
+	dl = append(dl, layers...)
+	for _, d := range dl {
+		if !slices.ContainsFunc(trd.validate.Blobs, func(cur descriptor.Descriptor) bool { return cur.Digest == d.Digest }) {
+			trd.validate.Blobs = append(trd.validate.Blobs, d)
+		}
+	}
 	return nil
 }
 
@@ -1407,11 +3740,30 @@ func (rc *RegClient) imageImportDockerAddLayerHandlers(ctx context.Context, r re
 	trd.dockerManifest.SchemaVersion = 2
 	trd.dockerManifest.MediaType = mediatype.Docker2Manifest
 	trd.dockerManifest.Layers = make([]descriptor.Descriptor, len(trd.dockerManifestList[index].Layers))
+	trd.dockerDiffIDs = make([]digest.Digest, len(trd.dockerManifestList[index].Layers))
 
 	// add handler for config
 	trd.handlers[filepath.ToSlash(filepath.Clean(trd.dockerManifestList[index].Config))] = func(header *tar.Header, trd *tarReadData) error {
-		// upload blob, digest is unknown
-		d, err := rc.BlobPut(ctx, r, descriptor.Descriptor{Size: header.Size}, trd.tr)
+		// the config is read into memory to extract the diff ids used to verify each layer
+		confBytes, err := io.ReadAll(trd.tr)
+		if err != nil {
+			return err
+		}
+		conf := v1.Image{}
+		err = json.Unmarshal(confBytes, &conf)
+		if err != nil {
+			return fmt.Errorf("failed to parse config: %w", err)
+		}
+		trd.dockerConfDiffIDs = conf.RootFS.DiffIDs
+		if conf.OS == "" || conf.Architecture == "" {
+			rc.slog.Info("Setting missing platform in config",
+				slog.String("platform", trd.dockerPlatform.String()))
+			confBytes, err = imageImportConfPlatform(confBytes, trd.dockerPlatform)
+			if err != nil {
+				return err
+			}
+		}
+		d, err := rc.imageImportBlobPut(ctx, r, descriptor.Descriptor{Digest: trd.digestAlgo.FromBytes(confBytes), Size: int64(len(confBytes))}, bytes.NewReader(confBytes), trd)
 		if err != nil {
 			return err
 		}
@@ -1422,39 +3774,110 @@ func (rc *RegClient) imageImportDockerAddLayerHandlers(ctx context.Context, r re
 			d.MediaType = mediatype.Docker2ImageConfig
 			trd.dockerManifest.Config = d
 		}
+		// layers are handled after the config so the diff ids can be used to skip duplicate content
+		rc.imageImportDockerAddLayerFileHandlers(ctx, r, trd, index)
+		trd.handleAdded = true
 		return nil
 	}
-	// add handlers for each layer
+	trd.handleAdded = true
+}
+
+// imageImportConfPlatform sets the os and architecture fields that are missing from a config.
+// The variant is only set with a missing architecture, and other fields in the config are preserved.
+func imageImportConfPlatform(confBytes []byte, p platform.Platform) ([]byte, error) {
+	conf := map[string]json.RawMessage{}
+	err := json.Unmarshal(confBytes, &conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	missing := func(k string) bool {
+		cur, ok := conf[k]
+		return !ok || string(cur) == `""` || string(cur) == "null"
+	}
+	set := map[string]string{}
+	if missing("os") {
+		set["os"] = p.OS
+	}
+	if missing("architecture") {
+		set["architecture"] = p.Architecture
+		if p.Variant != "" && missing("variant") {
+			set["variant"] = p.Variant
+		}
+	}
+	for k, v := range set {
+		vJSON, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		conf[k] = vJSON
+	}
+	return json.Marshal(conf)
+}
+
+// imageImportDockerAddLayerFileHandlers adds a handler for each layer file in the docker manifest.
+// Layers with the same content are only uploaded once, whether they are listed with the same filename or have a matching diff id.
+func (rc *RegClient) imageImportDockerAddLayerFileHandlers(ctx context.Context, r ref.Ref, trd *tarReadData, index int) {
+	layerIndexes := map[string][]int{}
 	for i, layerFile := range trd.dockerManifestList[index].Layers {
-		func(i int) {
-			trd.handlers[filepath.ToSlash(filepath.Clean(layerFile))] = func(header *tar.Header, trd *tarReadData) error {
-				// ensure blob is compressed
+		name := filepath.ToSlash(filepath.Clean(layerFile))
+		layerIndexes[name] = append(layerIndexes[name], i)
+	}
+	for name, indexes := range layerIndexes {
+		func(indexes []int) {
+			trd.handlers[name] = func(header *tar.Header, trd *tarReadData) error {
+				// reuse a previously uploaded layer with the same diff id
+				if prev := trd.dockerLayerPrev(indexes[0]); prev >= 0 {
+					rc.slog.Debug("Skipping duplicate layer",
+						slog.String("file", header.Name),
+						slog.String("digest", trd.dockerManifest.Layers[prev].Digest.String()))
+					for _, i := range indexes {
+						trd.dockerManifest.Layers[i] = trd.dockerManifest.Layers[prev]
+						trd.dockerDiffIDs[i] = trd.dockerDiffIDs[prev]
+					}
+					return nil
+				}
+				// ensure blob is compressed, the layer is streamed from the tar to the upload without buffering the full content
 				rdrUC, err := archive.Decompress(trd.tr)
 				if err != nil {
 					return err
 				}
-				gzipR, err := archive.Compress(rdrUC, archive.CompressGzip)
+				// the uncompressed digest is computed while streaming to compare with the config diff ids,
+				// using the algorithm declared by the diff id
+				algoUC := trd.digestAlgo
+				if indexes[0] < len(trd.dockerConfDiffIDs) && trd.dockerConfDiffIDs[indexes[0]].Algorithm().Available() {
+					algoUC = trd.dockerConfDiffIDs[indexes[0]].Algorithm()
+				}
+				digUC := algoUC.Digester()
+				rdrUC = io.TeeReader(rdrUC, digUC.Hash())
+				gzipR, err := archive.Compress(rdrUC, archive.CompressGzip, archive.CompressWithGzipLevel(rc.gzipLevel))
 				if err != nil {
 					return err
 				}
 				defer gzipR.Close()
 				// upload blob, digest and size is unknown
-				d, err := rc.BlobPut(ctx, r, descriptor.Descriptor{}, gzipR)
+				d := descriptor.Descriptor{}
+				err = d.DigestAlgoPrefer(trd.digestAlgo)
+				if err != nil {
+					return err
+				}
+				d, err = rc.imageImportBlobPut(ctx, r, d, gzipR, trd)
 				if err != nil {
 					return err
 				}
-				// save the resulting descriptor in the appropriate layer
+				// save the resulting descriptor in the appropriate layers
 				if od, ok := trd.dockerManifestList[index].LayerSources[d.Digest]; ok {
-					trd.dockerManifest.Layers[i] = od
+					d = od
 				} else {
 					d.MediaType = mediatype.Docker2LayerGzip
+				}
+				for _, i := range indexes {
+					trd.dockerDiffIDs[i] = digUC.Digest()
 					trd.dockerManifest.Layers[i] = d
 				}
 				return nil
 			}
-		}(i)
+		}(indexes)
 	}
-	trd.handleAdded = true
 }
 
 // imageImportOCIAddHandler adds handlers for oci-layout and index.json found in OCI layout tar files.
@@ -1613,7 +4036,10 @@ func (rc *RegClient) imageImportOCIHandleManifest(ctx context.Context, r ref.Ref
 			if !ok {
 				return fmt.Errorf("could not find manifest to tag, ref: %s, digest: %s", r.CommonName(), d.Digest)
 			}
-			return rc.ManifestPut(ctx, r, mRef)
+			if trd.validate != nil {
+				trd.validate.Desc = mRef.GetDescriptor()
+			}
+			return rc.imageImportManifestPut(ctx, r, mRef, trd)
 		})
 	} else if m.IsList() {
 		// for index/manifest lists, add handlers for each embedded manifest
@@ -1676,15 +4102,17 @@ func (rc *RegClient) imageImportOCIHandleManifest(ctx context.Context, r ref.Ref
 		trd.finish = append(trd.finish, func() error {
 			mRef := r
 			mRef.Digest = string(m.GetDescriptor().Digest)
-			_, err := rc.ManifestHead(ctx, mRef)
-			if err == nil {
-				return nil
+			if trd.validate == nil {
+				_, err := rc.ManifestHead(ctx, mRef)
+				if err == nil {
+					return nil
+				}
 			}
 			opts := []ManifestOpts{}
 			if child {
 				opts = append(opts, WithManifestChild())
 			}
-			return rc.ManifestPut(ctx, mRef, m, opts...)
+			return rc.imageImportManifestPut(ctx, mRef, m, trd, opts...)
 		})
 	}
 	trd.handleAdded = true
@@ -1703,6 +4131,21 @@ func (rc *RegClient) imageImportOCIPushManifests(_ context.Context, _ ref.Ref, t
 	return nil
 }
 
+// imagePlatformList returns the platforms and digests from a manifest list for error messages.
+func imagePlatformList(dl []descriptor.Descriptor) string {
+	entries := []string{}
+	for _, d := range dl {
+		if d.Platform == nil {
+			continue
+		}
+		entries = append(entries, d.Platform.String()+" ("+d.Digest.String()+")")
+	}
+	if len(entries) == 0 {
+		return "no platforms defined"
+	}
+	return strings.Join(entries, ", ")
+}
+
 func imagePlatformInList(target *platform.Platform, list []string) (bool, error) {
 	// special case for an unset platform
 	if target == nil || target.OS == "" {
@@ -1728,6 +4171,35 @@ func imagePlatformInList(target *platform.Platform, list []string) (bool, error)
 	return false, nil
 }
 
+// tarReadDataNew returns the state used to import a tar file.
+func tarReadDataNew(name string, algo digest.Algorithm) *tarReadData {
+	if algo == "" {
+		algo = digest.Canonical
+	}
+	return &tarReadData{
+		name:       name,
+		digestAlgo: algo,
+		handlers:   map[string]tarFileHandler{},
+		links:      map[string][]string{},
+		processed:  map[string]bool{},
+		finish:     []func() error{},
+		manifests:  map[digest.Digest]manifest.Manifest{},
+	}
+}
+
+// dockerLayerPrev returns the index of an imported layer with the same diff id as layer i from the config, or -1 if none is found.
+func (trd *tarReadData) dockerLayerPrev(i int) int {
+	if i >= len(trd.dockerConfDiffIDs) {
+		return -1
+	}
+	for j, dig := range trd.dockerDiffIDs {
+		if dig != "" && dig == trd.dockerConfDiffIDs[i] {
+			return j
+		}
+	}
+	return -1
+}
+
 // tarReadAll processes the tar file in a loop looking for matching filenames in the list of handlers.
 // Handlers for filenames are added at the top level, and by manifest imports.
 func (trd *tarReadData) tarReadAll(rs io.ReadSeeker) error {
@@ -1741,79 +4213,88 @@ func (trd *tarReadData) tarReadAll(rs io.ReadSeeker) error {
 		if err != nil {
 			return err
 		}
-		dr, err := archive.Decompress(rs)
-		if err != nil {
+		done, err := trd.tarReadPass(rs)
+		if err != nil || done {
 			return err
 		}
-		trd.tr = tar.NewReader(dr)
-		trd.handleAdded = false
-		// loop over each entry of the tar file
-		for {
-			header, err := trd.tr.Next()
-			if err == io.EOF {
-				break
-			} else if err != nil {
-				return err
+		// if entire file read without adding a new handler, fail
+		if !trd.handleAdded {
+			return fmt.Errorf("unable to read all files from tar: %w", errs.ErrNotFound)
+		}
+	}
+}
+
+// tarReadPass reads the tar once, running the handlers for matching filenames.
+// It returns true when all handlers have been processed.
+func (trd *tarReadData) tarReadPass(rdr io.Reader) (bool, error) {
+	dr, err := archive.Decompress(rdr)
+	if err != nil {
+		return false, err
+	}
+	trd.tr = tar.NewReader(dr)
+	trd.handleAdded = false
+	// loop over each entry of the tar file
+	for {
+		header, err := trd.tr.Next()
+		if err == io.EOF {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		name := filepath.ToSlash(filepath.Clean(header.Name))
+		// track symlinks
+		if header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
+			// normalize target relative to root of tar
+			target := header.Linkname
+			if !filepath.IsAbs(target) {
+				target, err = filepath.Rel(filepath.Dir(name), target)
+				if err != nil {
+					return false, err
+				}
 			}
-			name := filepath.ToSlash(filepath.Clean(header.Name))
-			// track symlinks
-			if header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
-				// normalize target relative to root of tar
-				target := header.Linkname
-				if !filepath.IsAbs(target) {
-					target, err = filepath.Rel(filepath.Dir(name), target)
-					if err != nil {
-						return err
+			target = filepath.ToSlash(filepath.Clean("/" + target)[1:])
+			// track and set handleAdded if an existing handler points to the target
+			if trd.linkAdd(name, target) && !trd.handleAdded {
+				list, err := trd.linkList(target)
+				if err != nil {
+					return false, err
+				}
+				for _, src := range append(list, name) {
+					if trd.handlers[src] != nil {
+						trd.handleAdded = true
 					}
 				}
-				target = filepath.ToSlash(filepath.Clean("/" + target)[1:])
-				// track and set handleAdded if an existing handler points to the target
-				if trd.linkAdd(name, target) && !trd.handleAdded {
-					list, err := trd.linkList(target)
-					if err != nil {
-						return err
+			}
+		} else {
+			// loop through filename and symlinks to file in search of handlers
+			list, err := trd.linkList(name)
+			if err != nil {
+				return false, err
+			}
+			list = append(list, name)
+			trdUsed := false
+			for _, entry := range list {
+				if trd.handlers[entry] != nil {
+					// trd cannot be reused, force the loop to run again
+					if trdUsed {
+						trd.handleAdded = true
+						break
 					}
-					for _, src := range append(list, name) {
-						if trd.handlers[src] != nil {
-							trd.handleAdded = true
-						}
+					trdUsed = true
+					// run handler
+					err = trd.handlers[entry](header, trd)
+					if err != nil {
+						return false, err
 					}
-				}
-			} else {
-				// loop through filename and symlinks to file in search of handlers
-				list, err := trd.linkList(name)
-				if err != nil {
-					return err
-				}
-				list = append(list, name)
-				trdUsed := false
-				for _, entry := range list {
-					if trd.handlers[entry] != nil {
-						// trd cannot be reused, force the loop to run again
-						if trdUsed {
-							trd.handleAdded = true
-							break
-						}
-						trdUsed = true
-						// run handler
-						err = trd.handlers[entry](header, trd)
-						if err != nil {
-							return err
-						}
-						delete(trd.handlers, entry)
-						trd.processed[entry] = true
-						// return if last handler processed
-						if len(trd.handlers) == 0 {
-							return nil
-						}
+					delete(trd.handlers, entry)
+					trd.processed[entry] = true
+					// return if last handler processed
+					if len(trd.handlers) == 0 {
+						return true, nil
 					}
 				}
 			}
 		}
-		// if entire file read without adding a new handler, fail
-		if !trd.handleAdded {
-			return fmt.Errorf("unable to read all files from tar: %w", errs.ErrNotFound)
-		}
 	}
 }
 
@@ -2,24 +2,39 @@ package regclient
 
 import (
 	"archive/tar"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/olareg/olareg"
 	oConfig "github.com/olareg/olareg/config"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/regclient/regclient/config"
 	"github.com/regclient/regclient/internal/copyfs"
+	"github.com/regclient/regclient/pkg/archive"
 	"github.com/regclient/regclient/scheme/reg"
+	"github.com/regclient/regclient/types/descriptor"
+	"github.com/regclient/regclient/types/docker/schema2"
 	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/manifest"
+	"github.com/regclient/regclient/types/mediatype"
+	v1 "github.com/regclient/regclient/types/oci/v1"
+	"github.com/regclient/regclient/types/platform"
 	"github.com/regclient/regclient/types/ref"
 )
 
@@ -189,12 +204,13 @@ func TestImageConfig(t *testing.T) {
 		WithRetryDelay(delayInit, delayMax),
 	)
 	tt := []struct {
-		name       string
-		r          string
-		opts       []ImageOpts
-		expectErr  error
-		expectArch string
-		expectOS   string
+		name        string
+		r           string
+		opts        []ImageOpts
+		expectErr   error
+		errContains string
+		expectArch  string
+		expectOS    string
 	}{
 		{
 			name:       "ocidir-v1-amd64",
@@ -203,11 +219,24 @@ func TestImageConfig(t *testing.T) {
 			expectArch: "amd64",
 			expectOS:   "linux",
 		},
+		{
+			name:     "ocidir-v1-local",
+			r:        "ocidir://testdata/testrepo:v1",
+			opts:     []ImageOpts{},
+			expectOS: "linux",
+		},
 		{
 			name:      "ocidir-not-found",
 			r:         "ocidir://testdata/testrepo:missing",
 			expectErr: errs.ErrNotFound,
 		},
+		{
+			name:        "ocidir-platform-missing",
+			r:           "ocidir://testdata/testrepo:v1",
+			opts:        []ImageOpts{ImageWithPlatform("linux/s390x")},
+			expectErr:   errs.ErrNotFound,
+			errContains: "linux/amd64 (sha256:",
+		},
 		{
 			name:      "ocidir-a1",
 			r:         "ocidir://testdata/testrepo:a1",
@@ -236,6 +265,9 @@ func TestImageConfig(t *testing.T) {
 				if !errors.Is(err, tc.expectErr) && err.Error() != tc.expectErr.Error() {
 					t.Errorf("unexpected error, expected %v, received %v", tc.expectErr, err)
 				}
+				if tc.errContains != "" && !strings.Contains(err.Error(), tc.errContains) {
+					t.Errorf("error does not contain %s: %v", tc.errContains, err)
+				}
 				return
 			}
 			if err != nil {
@@ -249,6 +281,108 @@ func TestImageConfig(t *testing.T) {
 	}
 }
 
+func TestImageAge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	created, err := rc.ImageAge(ctx, r, ImageWithPlatform("linux/amd64"))
+	if err != nil {
+		t.Fatalf("failed to get age: %v", err)
+	}
+	expect := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if !created.Equal(expect) {
+		t.Errorf("unexpected created time, expected %s, received %s", expect, created)
+	}
+	_, err = rc.ImageAge(ctx, r.SetTag("a1"))
+	if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+		t.Errorf("unexpected error for an artifact, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+	}
+}
+
+func TestImagePlatforms(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rIndex, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	t.Run("index", func(t *testing.T) {
+		pl, err := rc.ImagePlatforms(ctx, rIndex)
+		if err != nil {
+			t.Fatalf("failed to get platforms: %v", err)
+		}
+		found := false
+		for _, p := range pl {
+			if p.OS == "linux" && p.Architecture == "arm64" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("linux/arm64 not found in %v", pl)
+		}
+	})
+	t.Run("image", func(t *testing.T) {
+		m, err := rc.ManifestHead(ctx, rIndex, WithManifestPlatform(platform.Platform{OS: "linux", Architecture: "arm64"}), WithManifestRequireDigest())
+		if err != nil {
+			t.Fatalf("failed to head manifest: %v", err)
+		}
+		pl, err := rc.ImagePlatforms(ctx, rIndex.SetDigest(m.GetDescriptor().Digest.String()))
+		if err != nil {
+			t.Fatalf("failed to get platforms: %v", err)
+		}
+		if len(pl) != 1 || pl[0].OS != "linux" || pl[0].Architecture != "arm64" {
+			t.Errorf("unexpected platforms, expected linux/arm64, received %v", pl)
+		}
+	})
+	t.Run("artifact", func(t *testing.T) {
+		rArtifact, err := ref.New("ocidir://testdata/testrepo:a1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		_, err = rc.ImagePlatforms(ctx, rArtifact)
+		if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, err := rc.ImagePlatforms(ctx, rIndex.SetTag("missing"))
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrNotFound, err)
+		}
+	})
+}
+
+func TestImageConfigEnvPlatform(t *testing.T) {
+	// t.Setenv prevents this test from running in parallel
+	t.Setenv(envPlatform, "linux/arm64")
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	bConf, err := rc.ImageConfig(ctx, r)
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	if c := bConf.GetConfig(); c.OS != "linux" || c.Architecture != "arm64" {
+		t.Errorf("unexpected config from env platform, expected linux/arm64, received %s/%s", c.OS, c.Architecture)
+	}
+	// an explicit platform overrides the env
+	bConf, err = rc.ImageConfig(ctx, r, ImageWithPlatform("linux/amd64"))
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	if c := bConf.GetConfig(); c.OS != "linux" || c.Architecture != "amd64" {
+		t.Errorf("unexpected config with platform option, expected linux/amd64, received %s/%s", c.OS, c.Architecture)
+	}
+}
+
 func TestCopy(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -428,106 +562,3146 @@ func TestCopy(t *testing.T) {
 	}
 }
 
-func TestExportImport(t *testing.T) {
+func TestCopySubject(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
-	// copy testdata images into tempdir
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	ts := httptest.NewServer(regHandler)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rcHosts := []config.Host{
+		{
+			Name:     tsHost,
+			Hostname: tsHost,
+			TLS:      config.TLSDisabled,
+		},
+	}
+	tt := []struct {
+		name       string
+		src, tgt   string
+		opts       []ImageOpts
+		expectWarn bool
+	}{
+		{
+			name:       "artifact without subject on target",
+			src:        "ocidir://./testdata/testrepo:a1",
+			tgt:        tsHost + "/dest-subject-missing:a1",
+			expectWarn: true,
+		},
+		{
+			name: "image with referrers",
+			src:  "ocidir://./testdata/testrepo:v2",
+			tgt:  tsHost + "/dest-subject-bundle:v2",
+			opts: []ImageOpts{ImageWithReferrers()},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			logBuf := &bytes.Buffer{}
+			log := slog.New(slog.NewTextHandler(logBuf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+			rc := New(
+				WithConfigHost(rcHosts...),
+				WithSlog(log),
+			)
+			rSrc, err := ref.New(tc.src)
+			if err != nil {
+				t.Fatalf("failed to parse ref %s: %v", tc.src, err)
+			}
+			rTgt, err := ref.New(tc.tgt)
+			if err != nil {
+				t.Fatalf("failed to parse ref %s: %v", tc.tgt, err)
+			}
+			err = rc.ImageCopy(ctx, rSrc, rTgt, tc.opts...)
+			if err != nil {
+				t.Fatalf("copy failed: %v", err)
+			}
+			warned := strings.Contains(logBuf.String(), "Subject of copied manifest not found on target")
+			if tc.expectWarn && !warned {
+				t.Errorf("missing subject warning, log: %s", logBuf.String())
+			} else if !tc.expectWarn && warned {
+				t.Errorf("unexpected subject warning, log: %s", logBuf.String())
+			}
+		})
+	}
+}
+
+func TestCopyReferrersNested(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
 	tempDir := t.TempDir()
-	err := copyfs.Copy(tempDir+"/testrepo", "testdata/testrepo")
+	err := copyfs.Copy(tempDir+"/src", "./testdata/testrepo")
 	if err != nil {
-		t.Fatalf("failed to copyfs to tempdir: %v", err)
+		t.Fatalf("failed to copy testrepo to tempDir: %v", err)
 	}
-	// create regclient
-	rc := New()
-	rIn1, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
+	rSrc, err := ref.New("ocidir://" + tempDir + "/src:v2")
 	if err != nil {
-		t.Fatalf("failed to parse ref: %v", err)
+		t.Fatalf("failed to parse src ref: %v", err)
 	}
-	rOut1, err := ref.New("ocidir://" + tempDir + "/testout:v1")
+	rTgt, err := ref.New("ocidir://" + tempDir + "/tgt:v2")
 	if err != nil {
-		t.Fatalf("failed to parse ref: %v", err)
+		t.Fatalf("failed to parse tgt ref: %v", err)
 	}
-	rIn3, err := ref.New("ocidir://" + tempDir + "/testrepo:v3")
+	dEmpty := descriptor.Descriptor{MediaType: mediatype.OCI1Empty, Digest: descriptor.EmptyDigest, Size: int64(len(descriptor.EmptyData))}
+	_, err = rc.BlobPut(ctx, rSrc, dEmpty, bytes.NewReader(descriptor.EmptyData))
 	if err != nil {
-		t.Fatalf("failed to parse ref: %v", err)
+		t.Fatalf("failed to push empty blob: %v", err)
 	}
-	rOut3, err := ref.New("ocidir://" + tempDir + "/testout:v3")
+	// push a chain of referrers, each one referring to the previous: a1 <- sig1 <- sig2 <- sig3
+	mh, err := rc.ManifestHead(ctx, rSrc.SetTag("a1"), WithManifestRequireDigest())
 	if err != nil {
-		t.Fatalf("failed to parse ref: %v", err)
+		t.Fatalf("failed to head a1: %v", err)
 	}
+	dSubject := mh.GetDescriptor()
+	chain := []descriptor.Descriptor{dSubject}
+	for i := 1; i <= 3; i++ {
+		m, err := manifest.New(manifest.WithOrig(v1.Manifest{
+			Versioned:    v1.ManifestSchemaVersion,
+			MediaType:    mediatype.OCI1Manifest,
+			ArtifactType: "application/vnd.example.sig",
+			Config:       dEmpty,
+			Layers:       []descriptor.Descriptor{dEmpty},
+			Annotations:  map[string]string{"level": fmt.Sprintf("%d", i)},
+			Subject:      &descriptor.Descriptor{MediaType: dSubject.MediaType, Digest: dSubject.Digest, Size: dSubject.Size},
+		}))
+		if err != nil {
+			t.Fatalf("failed to create sig%d: %v", i, err)
+		}
+		err = rc.ManifestPut(ctx, rSrc.SetDigest(m.GetDescriptor().Digest.String()), m)
+		if err != nil {
+			t.Fatalf("failed to push sig%d: %v", i, err)
+		}
+		dSubject = m.GetDescriptor()
+		chain = append(chain, dSubject)
+	}
+	err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithReferrers())
+	if err != nil {
+		t.Fatalf("failed to copy: %v", err)
+	}
+	// every level of the chain is copied and listed as a referrer of its subject
+	for i := 1; i < len(chain); i++ {
+		d := chain[i]
+		rSubject := rTgt.SetDigest(chain[i-1].Digest.String())
+		rl, err := rc.ReferrerList(ctx, rSubject)
+		if err != nil {
+			t.Fatalf("failed to list referrers of %s: %v", rSubject.CommonName(), err)
+		}
+		found := false
+		for _, rd := range rl.Descriptors {
+			if rd.Digest == d.Digest {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("referrer %d, %s, not found on target subject %s", i, d.Digest, rSubject.CommonName())
+		}
+	}
+}
 
-	// export repo to tar
-	fileOut1, err := os.Create(filepath.Join(tempDir, "test1.tar"))
+func TestCopyTargetClient(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regSrc := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	regTgt := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	tsSrc := httptest.NewServer(regSrc)
+	tsTgt := httptest.NewServer(regTgt)
+	t.Cleanup(func() {
+		tsSrc.Close()
+		tsTgt.Close()
+		_ = regSrc.Close()
+		_ = regTgt.Close()
+	})
+	tsSrcURL, _ := url.Parse(tsSrc.URL)
+	tsTgtURL, _ := url.Parse(tsTgt.URL)
+	// each client is only configured for its own registry
+	rcSrc := New(WithConfigHost(config.Host{
+		Name:     tsSrcURL.Host,
+		Hostname: tsSrcURL.Host,
+		TLS:      config.TLSDisabled,
+	}))
+	rcTgt := New(WithConfigHost(config.Host{
+		Name:     tsTgtURL.Host,
+		Hostname: tsTgtURL.Host,
+		TLS:      config.TLSDisabled,
+	}))
+	rSrc, err := ref.New(tsSrcURL.Host + "/testrepo:v1")
 	if err != nil {
-		t.Fatalf("failed to create output tar: %v", err)
+		t.Fatalf("failed to parse src: %v", err)
 	}
-	err = rc.ImageExport(ctx, rIn1, fileOut1)
-	fileOut1.Close()
+	rTgt, err := ref.New(tsTgtURL.Host + "/testrepo:v1")
 	if err != nil {
-		t.Errorf("failed to export: %v", err)
+		t.Fatalf("failed to parse tgt: %v", err)
 	}
-	fileOut3, err := os.Create(filepath.Join(tempDir, "test3.tar.gz"))
+	err = rcSrc.ImageCopy(ctx, rSrc, rTgt, ImageWithTargetClient(rcTgt))
 	if err != nil {
-		t.Fatalf("failed to create output tar: %v", err)
+		t.Fatalf("failed to copy with target client: %v", err)
 	}
-	err = rc.ImageExport(ctx, rIn3, fileOut3, ImageWithExportCompress())
-	fileOut3.Close()
+	mSrc, err := rcSrc.ManifestHead(ctx, rSrc, WithManifestRequireDigest())
 	if err != nil {
-		t.Errorf("failed to export: %v", err)
+		t.Fatalf("failed to head src: %v", err)
+	}
+	mTgt, err := rcTgt.ManifestHead(ctx, rTgt, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head tgt: %v", err)
 	}
+	if mSrc.GetDescriptor().Digest != mTgt.GetDescriptor().Digest {
+		t.Errorf("digest mismatch, expected %s, received %s", mSrc.GetDescriptor().Digest, mTgt.GetDescriptor().Digest)
+	}
+	// verify the image was copied with content by checking a platform specific manifest
+	_, err = rcTgt.ImageConfig(ctx, rTgt, ImageWithPlatform("linux/amd64"))
+	if err != nil {
+		t.Errorf("failed to get config from tgt: %v", err)
+	}
+}
 
-	// modify tar for tests
-	fileR, err := os.Open(filepath.Join(tempDir, "test1.tar"))
+func TestCopyForce(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	rc := New()
+	rSrc, err := ref.New("ocidir://./testdata/testrepo:v1")
 	if err != nil {
-		t.Fatalf("failed to open tar: %v", err)
+		t.Fatalf("failed to parse src: %v", err)
 	}
-	fileW, err := os.Create(filepath.Join(tempDir, "test2.tar"))
+	rTgt, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
 	if err != nil {
-		t.Errorf("failed to create tar: %v", err)
+		t.Fatalf("failed to parse tgt: %v", err)
 	}
-	tr := tar.NewReader(fileR)
-	tw := tar.NewWriter(fileW)
-	for {
-		th, err := tr.Next()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			t.Errorf("failed to read tar header: %v", err)
-		}
-		th.Name = "./" + th.Name
-		err = tw.WriteHeader(th)
+	err = rc.ImageCopy(ctx, rSrc, rTgt)
+	if err != nil {
+		t.Fatalf("failed to copy: %v", err)
+	}
+	// corrupt a layer in the target
+	mi, err := rc.ManifestGet(ctx, rTgt, WithManifestPlatform(platform.Platform{OS: "linux", Architecture: "amd64"}))
+	if err != nil {
+		t.Fatalf("failed to get manifest: %v", err)
+	}
+	layers, err := mi.(manifest.Imager).GetLayers()
+	if err != nil || len(layers) == 0 {
+		t.Fatalf("failed to get layers: %v", err)
+	}
+	dLayer := layers[0]
+	layerFile := filepath.Join(tempDir, "testrepo", "blobs", dLayer.Digest.Algorithm().String(), dLayer.Digest.Encoded())
+	err = os.WriteFile(layerFile, []byte("corrupt"), 0644)
+	if err != nil {
+		t.Fatalf("failed to corrupt layer: %v", err)
+	}
+	layerOK := func() bool {
+		b, err := os.ReadFile(layerFile)
 		if err != nil {
-			t.Errorf("failed to write tar header: %v", err)
-		}
-		if th.Size > 0 {
-			_, err = io.Copy(tw, tr)
-			if err != nil {
-				t.Errorf("failed to copy tar file contents %s: %v", th.Name, err)
-			}
+			t.Fatalf("failed to read layer: %v", err)
 		}
+		return dLayer.Digest.Algorithm().FromBytes(b) == dLayer.Digest
 	}
-	fileR.Close()
-	fileW.Close()
-
-	// import tar to repo
-	fileIn2, err := os.Open(filepath.Join(tempDir, "test2.tar"))
+	// a normal copy skips the existing content
+	err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithForceRecursive())
 	if err != nil {
-		t.Fatalf("failed to open tar: %v", err)
+		t.Fatalf("failed to copy: %v", err)
 	}
-	defer fileIn2.Close()
-	err = rc.ImageImport(ctx, rOut1, fileIn2)
+	if layerOK() {
+		t.Fatalf("layer was repaired without force")
+	}
+	// a forced copy pushes every blob
+	err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithForce(), ImageWithFastCheck())
 	if err != nil {
-		t.Errorf("failed to import: %v", err)
+		t.Fatalf("failed to copy with force: %v", err)
+	}
+	if !layerOK() {
+		t.Errorf("layer was not repaired with force")
 	}
+}
 
-	fileIn3, err := os.Open(filepath.Join(tempDir, "test3.tar.gz"))
+func TestCopyRateLimit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rSrc, err := ref.New("ocidir://./testdata/testrepo:v1")
 	if err != nil {
-		t.Fatalf("failed to open tar: %v", err)
+		t.Fatalf("failed to parse src: %v", err)
 	}
-	defer fileIn3.Close()
-	err = rc.ImageImport(ctx, rOut3, fileIn3)
+	m, err := rc.ManifestGet(ctx, rSrc, WithManifestPlatform(platform.Platform{OS: "linux", Architecture: "amd64"}))
 	if err != nil {
-		t.Errorf("failed to import: %v", err)
+		t.Fatalf("failed to get manifest: %v", err)
+	}
+	rSrc = rSrc.SetDigest(m.GetDescriptor().Digest.String())
+	mi := m.(manifest.Imager)
+	layers, err := mi.GetLayers()
+	if err != nil {
+		t.Fatalf("failed to get layers: %v", err)
+	}
+	conf, err := mi.GetConfig()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	size := conf.Size
+	for _, l := range layers {
+		size += l.Size
 	}
+	// with a one second burst, transferring twice the rate takes at least one second
+	bps := size / 2
+	t.Run("copy", func(t *testing.T) {
+		t.Parallel()
+		rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testrepo:v1")
+		if err != nil {
+			t.Fatalf("failed to parse tgt: %v", err)
+		}
+		start := time.Now()
+		err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithRateLimit(bps))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+			t.Errorf("copy was not rate limited, %d bytes at %d bps in %s", size, bps, elapsed)
+		}
+	})
+	t.Run("export", func(t *testing.T) {
+		t.Parallel()
+		start := time.Now()
+		err := rc.ImageExport(ctx, rSrc, io.Discard, ImageWithRateLimit(bps))
+		if err != nil {
+			t.Fatalf("failed to export: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+			t.Errorf("export was not rate limited, %d bytes at %d bps in %s", size, bps, elapsed)
+		}
+	})
+}
+
+func TestCopyConcurrency(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	// track the requests in flight, each blob is delayed so the transfers overlap
+	var mu sync.Mutex
+	inFlight, inFlightMax := 0, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > inFlightMax {
+			inFlightMax = inFlight
+		}
+		mu.Unlock()
+		if strings.Contains(req.URL.Path, "/blobs/") {
+			time.Sleep(20 * time.Millisecond)
+		}
+		regHandler.ServeHTTP(w, req)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	limit := 2
+	rc := New(WithConfigHost(config.Host{
+		Name:          tsHost,
+		Hostname:      tsHost,
+		TLS:           config.TLSDisabled,
+		ReqConcurrent: int64(limit),
+	}))
+	rSrc, err := ref.New(tsHost + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse src: %v", err)
+	}
+	rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse tgt: %v", err)
+	}
+	// platforms and the layers within each platform are copied concurrently, bounded by the host limit
+	err = rc.ImageCopy(ctx, rSrc, rTgt)
+	if err != nil {
+		t.Fatalf("failed to copy: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if inFlightMax > limit {
+		t.Errorf("requests exceeded the host limit, expected %d, received %d", limit, inFlightMax)
+	}
+	if inFlightMax < limit {
+		t.Errorf("requests were not run concurrently, expected %d, received %d", limit, inFlightMax)
+	}
+	_, err = rc.ImageCheck(ctx, rTgt)
+	if err != nil {
+		t.Errorf("failed to check copy: %v", err)
+	}
+}
+
+func TestCopyBatch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regSrc := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	ts := httptest.NewServer(regSrc)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regSrc.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(
+		WithConfigHost(config.Host{
+			Name:     tsHost,
+			Hostname: tsHost,
+			TLS:      config.TLSDisabled,
+		}),
+		WithSlog(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))),
+	)
+	newPair := func(src, tgt string) CopyPair {
+		rSrc, err := ref.New(tsHost + "/" + src)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", src, err)
+		}
+		rTgt, err := ref.New(tsHost + "/" + tgt)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", tgt, err)
+		}
+		return CopyPair{Src: rSrc, Tgt: rTgt}
+	}
+	tt := []struct {
+		name       string
+		pairs      []CopyPair
+		opts       BatchOpts
+		expectErr  bool
+		expectStat []CopyStatus
+	}{
+		{
+			name: "success",
+			pairs: []CopyPair{
+				newPair("testrepo:v1", "testbatch1:v1"),
+				newPair("testrepo:v2", "testbatch1:v2"),
+				newPair("testrepo:v3", "testbatch1:v3"),
+			},
+			opts:       BatchOpts{Concurrency: 2},
+			expectStat: []CopyStatus{CopySuccess, CopySuccess, CopySuccess},
+		},
+		{
+			name: "partial failure",
+			pairs: []CopyPair{
+				newPair("testrepo:v1", "testbatch2:v1"),
+				newPair("testrepo:missing", "testbatch2:missing"),
+				newPair("testrepo:v2", "testbatch2:v2"),
+			},
+			opts:       BatchOpts{Retries: 2, RetryDelay: time.Millisecond},
+			expectErr:  true,
+			expectStat: []CopyStatus{CopySuccess, CopyFailed, CopySuccess},
+		},
+		{
+			name: "fail fast",
+			pairs: []CopyPair{
+				newPair("testrepo:missing", "testbatch3:missing"),
+				newPair("testrepo:v1", "testbatch3:v1"),
+				newPair("testrepo:v2", "testbatch3:v2"),
+			},
+			opts:       BatchOpts{Concurrency: 1, FailFast: true},
+			expectErr:  true,
+			expectStat: []CopyStatus{CopyFailed, CopySkipped, CopySkipped},
+		},
+		{
+			name: "filter annotation",
+			pairs: []CopyPair{
+				newPair("testrepo:v1", "testbatch4:v1"),
+				newPair("testrepo:v2", "testbatch4:v2"),
+				newPair("testrepo:v3", "testbatch4:v3"),
+			},
+			opts:       BatchOpts{Filter: CopyFilterAnnotation("org.example.version", "v2")},
+			expectStat: []CopyStatus{CopyFiltered, CopySuccess, CopyFiltered},
+		},
+		{
+			name: "filter annotation key",
+			pairs: []CopyPair{
+				newPair("testrepo:v1", "testbatch5:v1"),
+				newPair("testrepo:v2", "testbatch5:v2"),
+				newPair("testrepo:missing", "testbatch5:missing"),
+			},
+			opts:       BatchOpts{Filter: CopyFilterAnnotation("org.opencontainers.image.base.name", "")},
+			expectErr:  true,
+			expectStat: []CopyStatus{CopyFiltered, CopySuccess, CopyFailed},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := rc.ImageCopyBatch(ctx, tc.pairs, tc.opts)
+			if tc.expectErr && err == nil {
+				t.Errorf("batch did not fail")
+			} else if !tc.expectErr && err != nil {
+				t.Errorf("batch failed: %v", err)
+			}
+			if len(results) != len(tc.pairs) {
+				t.Fatalf("unexpected number of results, expected %d, received %d", len(tc.pairs), len(results))
+			}
+			for i, res := range results {
+				if res.Status != tc.expectStat[i] {
+					t.Errorf("unexpected status for %s, expected %s, received %s, err %v", res.Pair.Src.CommonName(), tc.expectStat[i], res.Status, res.Err)
+				}
+				if res.Status == CopyFailed && !errors.Is(res.Err, errs.ErrNotFound) {
+					t.Errorf("unexpected error for %s: %v", res.Pair.Src.CommonName(), res.Err)
+				}
+				if res.Status == CopyFiltered {
+					_, err := rc.ManifestHead(ctx, res.Pair.Tgt)
+					if err == nil {
+						t.Errorf("filtered image was copied: %s", res.Pair.Tgt.CommonName())
+					}
+				}
+				if res.Status == CopyFailed && tc.opts.Filter == nil && res.Attempts != 1 {
+					t.Errorf("not found errors should not be retried, attempts %d", res.Attempts)
+				}
+				if res.Status == CopySuccess {
+					mSrc, err := rc.ManifestHead(ctx, res.Pair.Src, WithManifestRequireDigest())
+					if err != nil {
+						t.Fatalf("failed to head src: %v", err)
+					}
+					mTgt, err := rc.ManifestHead(ctx, res.Pair.Tgt, WithManifestRequireDigest())
+					if err != nil {
+						t.Fatalf("failed to head tgt: %v", err)
+					}
+					if mSrc.GetDescriptor().Digest != mTgt.GetDescriptor().Digest {
+						t.Errorf("digest mismatch, expected %s, received %s", mSrc.GetDescriptor().Digest, mTgt.GetDescriptor().Digest)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCopyDelta(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	rc := New()
+	rSrcV2, err := ref.New("ocidir://./testdata/testrepo:v2")
+	if err != nil {
+		t.Fatalf("failed to parse src: %v", err)
+	}
+	rBase, err := ref.New("ocidir://" + tempDir + "/base:v1")
+	if err != nil {
+		t.Fatalf("failed to parse base: %v", err)
+	}
+	err = rc.ImageCopy(ctx, rSrcV2.SetTag("v1"), rBase)
+	if err != nil {
+		t.Fatalf("failed to copy base: %v", err)
+	}
+	pAMD64 := platform.Platform{OS: "linux", Architecture: "amd64"}
+	getLayers := func(t *testing.T, r ref.Ref) []descriptor.Descriptor {
+		t.Helper()
+		m, err := rc.ManifestGet(ctx, r, WithManifestPlatform(pAMD64))
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		mi, ok := m.(manifest.Imager)
+		if !ok {
+			t.Fatalf("manifest is not an image")
+		}
+		layers, err := mi.GetLayers()
+		if err != nil {
+			t.Fatalf("failed to get layers: %v", err)
+		}
+		return layers
+	}
+	baseLayers := getLayers(t, rBase)
+
+	t.Run("other repo", func(t *testing.T) {
+		regTgt := olareg.New(oConfig.Config{
+			Storage: oConfig.ConfigStorage{
+				StoreType: oConfig.StoreMem,
+			},
+		})
+		ts := httptest.NewServer(regTgt)
+		t.Cleanup(func() {
+			ts.Close()
+			_ = regTgt.Close()
+		})
+		tsURL, _ := url.Parse(ts.URL)
+		rcReg := New(WithConfigHost(config.Host{
+			Name:     tsURL.Host,
+			Hostname: tsURL.Host,
+			TLS:      config.TLSDisabled,
+		}))
+		rRegBase, err := ref.New(tsURL.Host + "/base:v1")
+		if err != nil {
+			t.Fatalf("failed to parse base: %v", err)
+		}
+		rTgt, err := ref.New(tsURL.Host + "/other:v2")
+		if err != nil {
+			t.Fatalf("failed to parse tgt: %v", err)
+		}
+		err = rcReg.ImageCopy(ctx, rSrcV2.SetTag("v1"), rRegBase)
+		if err != nil {
+			t.Fatalf("failed to copy base: %v", err)
+		}
+		err = rcReg.ImageCopyDelta(ctx, rSrcV2, rTgt, rRegBase)
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		m, err := rcReg.ManifestGet(ctx, rTgt, WithManifestPlatform(pAMD64))
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		layers, err := m.(manifest.Imager).GetLayers()
+		if err != nil {
+			t.Fatalf("failed to get layers: %v", err)
+		}
+		for _, l := range layers {
+			_, err := rcReg.BlobHead(ctx, rTgt, l)
+			if err != nil {
+				t.Errorf("layer missing from target %s: %v", l.Digest, err)
+			}
+		}
+	})
+	t.Run("same repo", func(t *testing.T) {
+		// remove a base layer to verify the delta copy trusts the base without copying it
+		removed := baseLayers[0]
+		err := os.Remove(filepath.Join(tempDir, "base", "blobs", removed.Digest.Algorithm().String(), removed.Digest.Encoded()))
+		if err != nil {
+			t.Fatalf("failed to remove layer: %v", err)
+		}
+		rTgt := rBase.SetTag("v2")
+		err = rc.ImageCopyDelta(ctx, rSrcV2, rTgt, rBase)
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		for _, l := range getLayers(t, rTgt) {
+			_, err := rc.BlobHead(ctx, rTgt, l)
+			if l.Digest == removed.Digest {
+				if err == nil {
+					t.Errorf("base layer was copied %s", l.Digest)
+				}
+			} else if err != nil {
+				t.Errorf("layer missing from target %s: %v", l.Digest, err)
+			}
+		}
+	})
+	t.Run("other registry", func(t *testing.T) {
+		rReg, err := ref.New("registry.example.org/repo:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		err = rc.ImageCopyDelta(ctx, rSrcV2, rBase.SetTag("v3"), rReg)
+		if !errors.Is(err, errs.ErrInvalidReference) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrInvalidReference, err)
+		}
+	})
+}
+
+func TestImageFanout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regSrc := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	// count blob downloads from the source
+	var srcMu sync.Mutex
+	srcBlobGets := map[string]int{}
+	tsSrc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && strings.Contains(req.URL.Path, "/blobs/") {
+			srcMu.Lock()
+			srcBlobGets[req.URL.Path]++
+			srcMu.Unlock()
+		}
+		regSrc.ServeHTTP(w, req)
+	}))
+	regTgt := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	// count manifest pushes to the target
+	tgtManifestPuts := 0
+	tsTgt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.Contains(req.URL.Path, "/manifests/") {
+			srcMu.Lock()
+			tgtManifestPuts++
+			srcMu.Unlock()
+		}
+		regTgt.ServeHTTP(w, req)
+	}))
+	t.Cleanup(func() {
+		tsSrc.Close()
+		tsTgt.Close()
+		_ = regSrc.Close()
+		_ = regTgt.Close()
+	})
+	tsSrcURL, _ := url.Parse(tsSrc.URL)
+	tsTgtURL, _ := url.Parse(tsTgt.URL)
+	rc := New(WithConfigHost(
+		config.Host{
+			Name:     tsSrcURL.Host,
+			Hostname: tsSrcURL.Host,
+			TLS:      config.TLSDisabled,
+		},
+		config.Host{
+			Name:     tsTgtURL.Host,
+			Hostname: tsTgtURL.Host,
+			TLS:      config.TLSDisabled,
+		},
+	))
+	rSrc, err := ref.New(tsSrcURL.Host + "/testrepo:v3")
+	if err != nil {
+		t.Fatalf("failed to parse src: %v", err)
+	}
+	tgtList := []string{
+		tsTgtURL.Host + "/fanout-a:v3",
+		tsTgtURL.Host + "/fanout-b:latest",
+		"ocidir://" + t.TempDir() + "/fanout:v3",
+	}
+	rTgts := []ref.Ref{}
+	for _, tgt := range tgtList {
+		r, err := ref.New(tgt)
+		if err != nil {
+			t.Fatalf("failed to parse tgt %s: %v", tgt, err)
+		}
+		rTgts = append(rTgts, r)
+	}
+	// seed one target with an existing image to verify partial copies
+	err = rc.ImageCopy(ctx, rSrc.SetTag("v1"), rTgts[0].SetTag("v1"))
+	if err != nil {
+		t.Fatalf("failed to seed target: %v", err)
+	}
+	srcMu.Lock()
+	srcBlobGets = map[string]int{}
+	srcMu.Unlock()
+	err = rc.ImageFanout(ctx, rSrc, rTgts)
+	if err != nil {
+		t.Fatalf("failed to fanout: %v", err)
+	}
+	srcMu.Lock()
+	if len(srcBlobGets) == 0 {
+		t.Errorf("no blobs downloaded from the source")
+	}
+	for path, count := range srcBlobGets {
+		if count > 1 {
+			t.Errorf("blob downloaded %d times: %s", count, path)
+		}
+	}
+	srcMu.Unlock()
+	mSrc, err := rc.ManifestHead(ctx, rSrc, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head source: %v", err)
+	}
+	for _, rTgt := range rTgts {
+		mTgt, err := rc.ManifestHead(ctx, rTgt, WithManifestRequireDigest())
+		if err != nil {
+			t.Errorf("failed to head target %s: %v", rTgt.CommonName(), err)
+			continue
+		}
+		if mTgt.GetDescriptor().Digest != mSrc.GetDescriptor().Digest {
+			t.Errorf("digest mismatch on %s, expected %s, received %s", rTgt.CommonName(), mSrc.GetDescriptor().Digest, mTgt.GetDescriptor().Digest)
+		}
+		_, err = rc.ImageCheck(ctx, rTgt)
+		if err != nil {
+			t.Errorf("check failed on %s: %v", rTgt.CommonName(), err)
+		}
+	}
+	t.Run("existing", func(t *testing.T) {
+		srcMu.Lock()
+		srcBlobGets = map[string]int{}
+		tgtManifestPuts = 0
+		srcMu.Unlock()
+		hookCount := 0
+		hook := func(ctx context.Context, r ref.Ref, d descriptor.Descriptor) error {
+			hookCount++
+			return nil
+		}
+		err := rc.ImageFanout(ctx, rSrc, rTgts, ImageWithPushHook(hook))
+		if err != nil {
+			t.Fatalf("failed to fanout: %v", err)
+		}
+		srcMu.Lock()
+		if len(srcBlobGets) > 0 || tgtManifestPuts > 0 {
+			t.Errorf("existing content was copied, blob gets %d, manifest puts %d", len(srcBlobGets), tgtManifestPuts)
+		}
+		srcMu.Unlock()
+		if hookCount != len(rTgts) {
+			t.Errorf("unexpected push hook calls, expected %d, received %d", len(rTgts), hookCount)
+		}
+	})
+	t.Run("force", func(t *testing.T) {
+		srcMu.Lock()
+		tgtManifestPuts = 0
+		srcMu.Unlock()
+		err := rc.ImageFanout(ctx, rSrc, rTgts[:1], ImageWithForce())
+		if err != nil {
+			t.Fatalf("failed to fanout: %v", err)
+		}
+		srcMu.Lock()
+		if tgtManifestPuts == 0 {
+			t.Errorf("manifests were not pushed with force")
+		}
+		srcMu.Unlock()
+	})
+	t.Run("missing source", func(t *testing.T) {
+		err := rc.ImageFanout(ctx, rSrc.SetTag("missing"), rTgts)
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrNotFound, err)
+		}
+	})
+}
+
+func TestImageFanoutHostConcurrency(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	regSrc := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	tsSrc := httptest.NewServer(regSrc)
+	t.Cleanup(func() {
+		tsSrc.Close()
+		_ = regSrc.Close()
+	})
+	tsSrcURL, _ := url.Parse(tsSrc.URL)
+	hosts := []config.Host{
+		{
+			Name:     tsSrcURL.Host,
+			Hostname: tsSrcURL.Host,
+			TLS:      config.TLSDisabled,
+		},
+	}
+	// each target has a different blob limit, tracking the uploads in flight from the POST to the final PUT
+	limits := []int{1, 2, 1}
+	var mu sync.Mutex
+	inFlight := make([]int, len(limits))
+	inFlightMax := make([]int, len(limits))
+	rTgts := []ref.Ref{}
+	for i, limit := range limits {
+		i := i
+		regTgt := olareg.New(oConfig.Config{
+			Storage: oConfig.ConfigStorage{
+				StoreType: oConfig.StoreMem,
+			},
+		})
+		tsTgt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !strings.Contains(req.URL.Path, "/blobs/uploads/") {
+				regTgt.ServeHTTP(w, req)
+				return
+			}
+			mu.Lock()
+			if req.Method == http.MethodPost {
+				inFlight[i]++
+				if inFlight[i] > inFlightMax[i] {
+					inFlightMax[i] = inFlight[i]
+				}
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			// record the response so the upload is finished before the client sees it
+			rec := httptest.NewRecorder()
+			regTgt.ServeHTTP(rec, req)
+			if req.Method == http.MethodPut && rec.Code == http.StatusCreated {
+				mu.Lock()
+				inFlight[i]--
+				mu.Unlock()
+			}
+			for k, v := range rec.Header() {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(rec.Code)
+			_, _ = w.Write(rec.Body.Bytes())
+		}))
+		t.Cleanup(func() {
+			tsTgt.Close()
+			_ = regTgt.Close()
+		})
+		tsTgtURL, _ := url.Parse(tsTgt.URL)
+		hosts = append(hosts, config.Host{
+			Name:           tsTgtURL.Host,
+			Hostname:       tsTgtURL.Host,
+			TLS:            config.TLSDisabled,
+			ReqConcurrent:  3,
+			BlobConcurrent: int64(limit),
+		})
+		rTgt, err := ref.New(tsTgtURL.Host + "/fanout:v1")
+		if err != nil {
+			t.Fatalf("failed to parse tgt: %v", err)
+		}
+		rTgts = append(rTgts, rTgt)
+	}
+	rc := New(WithConfigHost(hosts...))
+	rSrc, err := ref.New(tsSrcURL.Host + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse src: %v", err)
+	}
+	err = rc.ImageFanout(ctx, rSrc, rTgts)
+	if err != nil {
+		t.Fatalf("failed to fanout: %v", err)
+	}
+	mu.Lock()
+	for i, limit := range limits {
+		if inFlightMax[i] == 0 {
+			t.Errorf("no blob uploads seen to target %d", i)
+		}
+		if inFlightMax[i] > limit {
+			t.Errorf("blob uploads to target %d exceeded the host limit, expected %d, received %d", i, limit, inFlightMax[i])
+		}
+	}
+	mu.Unlock()
+	for _, rTgt := range rTgts {
+		_, err = rc.ImageCheck(ctx, rTgt)
+		if err != nil {
+			t.Errorf("failed to check %s: %v", rTgt.CommonName(), err)
+		}
+	}
+}
+
+func TestImageIndexCreate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rSrc, err := ref.New("ocidir://./testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testrepo:multi")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	pAMD64, err := platform.Parse("linux/amd64")
+	if err != nil {
+		t.Fatalf("failed to parse platform: %v", err)
+	}
+	pARM64, err := platform.Parse("linux/arm64")
+	if err != nil {
+		t.Fatalf("failed to parse platform: %v", err)
+	}
+	mARM64, err := rc.ManifestHead(ctx, rSrc, WithManifestPlatform(pARM64), WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head manifest: %v", err)
+	}
+	// the arm64 entry is a single platform image, with the platform read from the config
+	entries := []ImageIndexEntry{
+		{Ref: rSrc, Platform: pAMD64},
+		{Ref: rSrc.SetDigest(mARM64.GetDescriptor().Digest.String())},
+	}
+	m, err := rc.ImageIndexCreate(ctx, rTgt, entries)
+	if err != nil {
+		t.Fatalf("failed to create index: %v", err)
+	}
+	mTgt, err := rc.ManifestGet(ctx, rTgt)
+	if err != nil {
+		t.Fatalf("failed to get index: %v", err)
+	}
+	if mTgt.GetDescriptor().Digest != m.GetDescriptor().Digest {
+		t.Errorf("unexpected digest, expected %s, received %s", m.GetDescriptor().Digest, mTgt.GetDescriptor().Digest)
+	}
+	mi, ok := mTgt.(manifest.Indexer)
+	if !ok {
+		t.Fatalf("manifest is not an index")
+	}
+	dl, err := mi.GetManifestList()
+	if err != nil {
+		t.Fatalf("failed to get manifest list: %v", err)
+	}
+	if len(dl) != 2 {
+		t.Fatalf("unexpected number of entries, expected 2, received %d", len(dl))
+	}
+	for i, p := range []platform.Platform{pAMD64, pARM64} {
+		if dl[i].Platform == nil || !platform.Match(*dl[i].Platform, p) {
+			t.Errorf("unexpected platform for entry %d, expected %s, received %v", i, p.String(), dl[i].Platform)
+		}
+	}
+	if dl[1].Digest != mARM64.GetDescriptor().Digest {
+		t.Errorf("unexpected digest for arm64, expected %s, received %s", mARM64.GetDescriptor().Digest, dl[1].Digest)
+	}
+	_, err = rc.ImageCheck(ctx, rTgt)
+	if err != nil {
+		t.Errorf("failed to check index: %v", err)
+	}
+	// an index requires a platform to select the image
+	_, err = rc.ImageIndexCreate(ctx, rTgt.SetTag("missing-platform"), []ImageIndexEntry{{Ref: rSrc}})
+	if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+		t.Errorf("unexpected error for an index without a platform, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+	}
+	_, err = rc.ImageIndexCreate(ctx, rTgt.SetTag("empty"), nil)
+	if !errors.Is(err, errs.ErrInvalidReference) {
+		t.Errorf("unexpected error for no entries, expected %v, received %v", errs.ErrInvalidReference, err)
+	}
+}
+
+func TestCopyTags(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regSrc := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	ts := httptest.NewServer(regSrc)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regSrc.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	rSrc, err := ref.New(tsHost + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse src: %v", err)
+	}
+	rTgt, err := ref.New(tsHost + "/testtags:v1.2.3")
+	if err != nil {
+		t.Fatalf("failed to parse tgt: %v", err)
+	}
+	mSrc, err := rc.ManifestHead(ctx, rSrc, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head src: %v", err)
+	}
+	t.Run("tags", func(t *testing.T) {
+		result := ImageCopyResult{}
+		err := rc.ImageCopy(ctx, rSrc, rTgt, ImageWithTags("v1.2", "v1", "v1.2.3"), ImageWithCopyResult(&result))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		if result.Desc.Digest != mSrc.GetDescriptor().Digest {
+			t.Errorf("result digest mismatch, expected %s, received %s", mSrc.GetDescriptor().Digest, result.Desc.Digest)
+		}
+		expectTags := []string{"v1.2.3", "v1.2", "v1"}
+		if !slices.Equal(result.Tags, expectTags) {
+			t.Errorf("result tags mismatch, expected %v, received %v", expectTags, result.Tags)
+		}
+		for _, tag := range expectTags {
+			mTgt, err := rc.ManifestHead(ctx, rTgt.SetTag(tag), WithManifestRequireDigest())
+			if err != nil {
+				t.Fatalf("failed to head tag %s: %v", tag, err)
+			}
+			if mTgt.GetDescriptor().Digest != mSrc.GetDescriptor().Digest {
+				t.Errorf("digest mismatch on tag %s, expected %s, received %s", tag, mSrc.GetDescriptor().Digest, mTgt.GetDescriptor().Digest)
+			}
+		}
+	})
+	t.Run("digest", func(t *testing.T) {
+		result := ImageCopyResult{}
+		rTgtDig := rTgt.SetDigest(mSrc.GetDescriptor().Digest.String())
+		err := rc.ImageCopy(ctx, rSrc, rTgtDig, ImageWithTags("pinned"), ImageWithCopyResult(&result))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		if !slices.Equal(result.Tags, []string{"pinned"}) {
+			t.Errorf("result tags mismatch, expected [pinned], received %v", result.Tags)
+		}
+	})
+	t.Run("invalid tag", func(t *testing.T) {
+		err := rc.ImageCopy(ctx, rSrc, rTgt, ImageWithTags("invalid:tag"))
+		if err == nil {
+			t.Errorf("copy with an invalid tag did not fail")
+		}
+	})
+	t.Run("no overwrite", func(t *testing.T) {
+		rSrc2 := rSrc.SetTag("v2")
+		err := rc.ImageCopy(ctx, rSrc2, rTgt, ImageWithNoOverwrite())
+		if !errors.Is(err, errs.ErrTagExists) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrTagExists, err)
+		}
+		err = rc.ImageCopy(ctx, rSrc2, rTgt.SetTag("v2-new"), ImageWithNoOverwrite(), ImageWithTags("v1"))
+		if !errors.Is(err, errs.ErrTagExists) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrTagExists, err)
+		}
+		_, err = rc.ManifestHead(ctx, rTgt.SetTag("v2-new"))
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("tag was pushed after a failed check: %v", err)
+		}
+		// identical content may be pushed again
+		err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithNoOverwrite())
+		if err != nil {
+			t.Errorf("failed to copy identical content: %v", err)
+		}
+		m2, err := rc.ManifestGet(ctx, rSrc2)
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		err = rc.ManifestPut(ctx, rTgt, m2, WithManifestNoOverwrite())
+		if !errors.Is(err, errs.ErrTagExists) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrTagExists, err)
+		}
+		m1, err := rc.ManifestGet(ctx, rSrc)
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		err = rc.ManifestPut(ctx, rTgt.SetTag("v1-put"), m1, WithManifestNoOverwrite())
+		if err != nil {
+			t.Errorf("failed to put a new tag: %v", err)
+		}
+	})
+	t.Run("push hook", func(t *testing.T) {
+		calls := []ref.Ref{}
+		hook := func(_ context.Context, r ref.Ref, d descriptor.Descriptor) error {
+			if d.Digest != mSrc.GetDescriptor().Digest {
+				t.Errorf("unexpected hook args, ref %s, digest %s", r.CommonName(), d.Digest)
+			}
+			calls = append(calls, r)
+			return nil
+		}
+		rTgtHook := rTgt.SetTag("hook")
+		err := rc.ImageCopy(ctx, rSrc, rTgtHook, ImageWithPushHook(hook))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		if len(calls) != 1 || calls[0].Tag != "hook" {
+			t.Errorf("unexpected hook calls: %v", calls)
+		}
+		errHook := errors.New("hook failed")
+		err = rc.ImageCopy(ctx, rSrc, rTgtHook, ImageWithPushHook(func(_ context.Context, _ ref.Ref, _ descriptor.Descriptor) error {
+			return errHook
+		}))
+		if !errors.Is(err, errHook) {
+			t.Errorf("unexpected error, expected %v, received %v", errHook, err)
+		}
+	})
+}
+
+func TestImageCheck(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	err := copyfs.Copy(tempDir+"/testrepo", "./testdata/testrepo")
+	if err != nil {
+		t.Fatalf("failed to setup tempDir: %v", err)
+	}
+	rc := New()
+	r, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	m, err := rc.ManifestGet(ctx, r, WithManifestPlatform(platform.Platform{OS: "linux", Architecture: "amd64"}))
+	if err != nil {
+		t.Fatalf("failed to get manifest: %v", err)
+	}
+	mi, ok := m.(manifest.Imager)
+	if !ok {
+		t.Fatalf("manifest is not an image")
+	}
+	layers, err := mi.GetLayers()
+	if err != nil || len(layers) < 2 {
+		t.Fatalf("failed to get layers (%d): %v", len(layers), err)
+	}
+	t.Run("valid", func(t *testing.T) {
+		results, err := rc.ImageCheck(ctx, r, ImageWithCheckDeep())
+		if err != nil {
+			t.Fatalf("check failed: %v", err)
+		}
+		if len(results) == 0 {
+			t.Errorf("no results returned")
+		}
+		for _, res := range results {
+			if res.Digest != res.Desc.Digest {
+				t.Errorf("computed digest mismatch for %s, received %s", res.Desc.Digest, res.Digest)
+			}
+		}
+	})
+	// corrupt the first layer, keeping the same size
+	layerFile := filepath.Join(tempDir, "testrepo", "blobs", layers[0].Digest.Algorithm().String(), layers[0].Digest.Encoded())
+	err = os.WriteFile(layerFile, bytes.Repeat([]byte("x"), int(layers[0].Size)), 0644)
+	if err != nil {
+		t.Fatalf("failed to corrupt layer: %v", err)
+	}
+	t.Run("shallow", func(t *testing.T) {
+		_, err := rc.ImageCheck(ctx, r, ImageWithPlatform("linux/amd64"))
+		if err != nil {
+			t.Errorf("shallow check failed on a blob with the same size: %v", err)
+		}
+	})
+	t.Run("deep", func(t *testing.T) {
+		results, err := rc.ImageCheck(ctx, r, ImageWithPlatform("linux/amd64"), ImageWithCheckDeep())
+		if !errors.Is(err, errs.ErrMismatch) {
+			t.Errorf("deep check did not return a mismatch: %v", err)
+		}
+		failed := 0
+		for _, res := range results {
+			if res.Err == nil {
+				continue
+			}
+			failed++
+			if res.Desc.Digest != layers[0].Digest {
+				t.Errorf("unexpected failure for %s: %v", res.Desc.Digest, res.Err)
+			}
+			if !errors.Is(res.Err, errs.ErrDigestMismatch) {
+				t.Errorf("unexpected error for %s: %v", res.Desc.Digest, res.Err)
+			}
+			if res.Digest == res.Desc.Digest || res.Digest == "" {
+				t.Errorf("computed digest not reported, received %s", res.Digest)
+			}
+		}
+		if failed != 1 {
+			t.Errorf("unexpected number of failures, expected 1, received %d", failed)
+		}
+	})
+	// delete the second layer
+	err = os.Remove(filepath.Join(tempDir, "testrepo", "blobs", layers[1].Digest.Algorithm().String(), layers[1].Digest.Encoded()))
+	if err != nil {
+		t.Fatalf("failed to delete layer: %v", err)
+	}
+	t.Run("missing", func(t *testing.T) {
+		results, err := rc.ImageCheck(ctx, r, ImageWithPlatform("linux/amd64"))
+		if !errors.Is(err, errs.ErrMismatch) {
+			t.Errorf("check did not return a mismatch: %v", err)
+		}
+		found := false
+		for _, res := range results {
+			if res.Desc.Digest == layers[1].Digest {
+				found = true
+				if !errors.Is(res.Err, errs.ErrNotFound) {
+					t.Errorf("missing layer did not return not found: %v", res.Err)
+				}
+			} else if res.Err != nil {
+				t.Errorf("unexpected failure for %s: %v", res.Desc.Digest, res.Err)
+			}
+		}
+		if !found {
+			t.Errorf("missing layer was not checked")
+		}
+	})
+}
+
+func TestExportImport(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// copy testdata images into tempdir
+	tempDir := t.TempDir()
+	err := copyfs.Copy(tempDir+"/testrepo", "testdata/testrepo")
+	if err != nil {
+		t.Fatalf("failed to copyfs to tempdir: %v", err)
+	}
+	// create regclient
+	rc := New()
+	rIn1, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rOut1, err := ref.New("ocidir://" + tempDir + "/testout:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rIn3, err := ref.New("ocidir://" + tempDir + "/testrepo:v3")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rOut3, err := ref.New("ocidir://" + tempDir + "/testout:v3")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+
+	// export repo to tar
+	fileOut1, err := os.Create(filepath.Join(tempDir, "test1.tar"))
+	if err != nil {
+		t.Fatalf("failed to create output tar: %v", err)
+	}
+	err = rc.ImageExport(ctx, rIn1, fileOut1)
+	fileOut1.Close()
+	if err != nil {
+		t.Errorf("failed to export: %v", err)
+	}
+	fileOut3, err := os.Create(filepath.Join(tempDir, "test3.tar.gz"))
+	if err != nil {
+		t.Fatalf("failed to create output tar: %v", err)
+	}
+	err = rc.ImageExport(ctx, rIn3, fileOut3, ImageWithExportCompress())
+	fileOut3.Close()
+	if err != nil {
+		t.Errorf("failed to export: %v", err)
+	}
+
+	// modify tar for tests
+	fileR, err := os.Open(filepath.Join(tempDir, "test1.tar"))
+	if err != nil {
+		t.Fatalf("failed to open tar: %v", err)
+	}
+	fileW, err := os.Create(filepath.Join(tempDir, "test2.tar"))
+	if err != nil {
+		t.Errorf("failed to create tar: %v", err)
+	}
+	tr := tar.NewReader(fileR)
+	tw := tar.NewWriter(fileW)
+	for {
+		th, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			t.Errorf("failed to read tar header: %v", err)
+		}
+		th.Name = "./" + th.Name
+		err = tw.WriteHeader(th)
+		if err != nil {
+			t.Errorf("failed to write tar header: %v", err)
+		}
+		if th.Size > 0 {
+			_, err = io.Copy(tw, tr)
+			if err != nil {
+				t.Errorf("failed to copy tar file contents %s: %v", th.Name, err)
+			}
+		}
+	}
+	fileR.Close()
+	fileW.Close()
+
+	// import tar to repo
+	fileIn2, err := os.Open(filepath.Join(tempDir, "test2.tar"))
+	if err != nil {
+		t.Fatalf("failed to open tar: %v", err)
+	}
+	defer fileIn2.Close()
+	err = rc.ImageImport(ctx, rOut1, fileIn2)
+	if err != nil {
+		t.Errorf("failed to import: %v", err)
+	}
+
+	fileIn3, err := os.Open(filepath.Join(tempDir, "test3.tar.gz"))
+	if err != nil {
+		t.Fatalf("failed to open tar: %v", err)
+	}
+	defer fileIn3.Close()
+	err = rc.ImageImport(ctx, rOut3, fileIn3)
+	if err != nil {
+		t.Errorf("failed to import: %v", err)
+	}
+
+	// rewrite the tar without the OCI layout to import with the docker manifest.json
+	rewriteTar := func(t *testing.T, src, tgt string, fn func(name string, data []byte) ([]byte, bool)) {
+		t.Helper()
+		fileR, err := os.Open(src)
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileR.Close()
+		fileW, err := os.Create(tgt)
+		if err != nil {
+			t.Fatalf("failed to create tar: %v", err)
+		}
+		defer fileW.Close()
+		tr := tar.NewReader(fileR)
+		tw := tar.NewWriter(fileW)
+		defer tw.Close()
+		for {
+			th, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				t.Fatalf("failed to read tar header: %v", err)
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatalf("failed to read tar file %s: %v", th.Name, err)
+			}
+			data, keep := fn(th.Name, data)
+			if !keep {
+				continue
+			}
+			th.Size = int64(len(data))
+			err = tw.WriteHeader(th)
+			if err != nil {
+				t.Fatalf("failed to write tar header: %v", err)
+			}
+			_, err = tw.Write(data)
+			if err != nil {
+				t.Fatalf("failed to write tar file %s: %v", th.Name, err)
+			}
+		}
+	}
+	dockerOnly := func(name string, data []byte) ([]byte, bool) {
+		return data, name != "oci-layout" && name != "index.json"
+	}
+	// the docker manifest.json is only included when exporting a single platform
+	pAMD64, err := platform.Parse("linux/amd64")
+	if err != nil {
+		t.Fatalf("failed to parse platform: %v", err)
+	}
+	mAMD64, err := rc.ManifestHead(ctx, rIn1, WithManifestPlatform(pAMD64), WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head manifest: %v", err)
+	}
+	fileAMD64, err := os.Create(filepath.Join(tempDir, "amd64.tar"))
+	if err != nil {
+		t.Fatalf("failed to create output tar: %v", err)
+	}
+	err = rc.ImageExport(ctx, rIn1.SetDigest(mAMD64.GetDescriptor().Digest.String()), fileAMD64)
+	fileAMD64.Close()
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	t.Run("docker", func(t *testing.T) {
+		fileDocker := filepath.Join(tempDir, "docker.tar")
+		rewriteTar(t, filepath.Join(tempDir, "amd64.tar"), fileDocker, dockerOnly)
+		fileIn, err := os.Open(fileDocker)
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		rDocker := rOut1.SetTag("docker")
+		err = rc.ImageImport(ctx, rDocker, fileIn)
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		_, err = rc.ImageCheck(ctx, rDocker)
+		if err != nil {
+			t.Errorf("failed to check imported image: %v", err)
+		}
+	})
+	t.Run("docker sha512", func(t *testing.T) {
+		fileIn, err := os.Open(filepath.Join(tempDir, "docker.tar"))
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		rDocker := rOut1.SetTag("docker-sha512")
+		err = rc.ImageImport(ctx, rDocker, fileIn, ImageWithDigestAlgo(digest.SHA512))
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		m, err := rc.ManifestGet(ctx, rDocker)
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		mi, ok := m.(manifest.Imager)
+		if !ok {
+			t.Fatalf("manifest is not an image")
+		}
+		dConf, err := mi.GetConfig()
+		if err != nil {
+			t.Fatalf("failed to get config: %v", err)
+		}
+		layers, err := mi.GetLayers()
+		if err != nil {
+			t.Fatalf("failed to get layers: %v", err)
+		}
+		if dConf.Digest.Algorithm() != digest.SHA512 {
+			t.Errorf("unexpected config digest: %s", dConf.Digest)
+		}
+		for _, l := range layers {
+			if l.Digest.Algorithm() != digest.SHA512 {
+				t.Errorf("unexpected layer digest: %s", l.Digest)
+			}
+		}
+		_, err = rc.ImageCheck(ctx, rDocker)
+		if err != nil {
+			t.Errorf("failed to check imported image: %v", err)
+		}
+	})
+	t.Run("docker digest algo unavailable", func(t *testing.T) {
+		fileIn, err := os.Open(filepath.Join(tempDir, "docker.tar"))
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		err = rc.ImageImport(ctx, rOut1.SetTag("docker-bad-algo"), fileIn, ImageWithDigestAlgo(digest.Algorithm("unknown")))
+		if !errors.Is(err, errs.ErrUnsupported) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupported, err)
+		}
+	})
+	t.Run("docker diff id mismatch", func(t *testing.T) {
+		fileDocker := filepath.Join(tempDir, "docker-bad.tar")
+		rewriteTar(t, filepath.Join(tempDir, "amd64.tar"), fileDocker, func(name string, data []byte) ([]byte, bool) {
+			// replace the diff ids in any image config
+			conf := map[string]interface{}{}
+			if strings.HasPrefix(name, "blobs/") && json.Unmarshal(data, &conf) == nil && conf["rootfs"] != nil {
+				conf["rootfs"] = map[string]interface{}{
+					"type":     "layers",
+					"diff_ids": []string{"sha256:" + strings.Repeat("0", 64), "sha256:" + strings.Repeat("1", 64)},
+				}
+				data, _ = json.Marshal(conf)
+			}
+			return dockerOnly(name, data)
+		})
+		fileIn, err := os.Open(fileDocker)
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		err = rc.ImageImport(ctx, rOut1.SetTag("docker-bad"), fileIn)
+		if !errors.Is(err, errs.ErrDigestMismatch) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrDigestMismatch, err)
+		}
+	})
+	t.Run("oci tar stream", func(t *testing.T) {
+		// pipe the export directly into the import without seeking
+		pr, pw := io.Pipe()
+		go func() {
+			_ = pw.CloseWithError(rc.ImageExport(ctx, rIn1, pw))
+		}()
+		rStream := rOut1.SetTag("stream")
+		err := rc.ImageImportOCITar(ctx, rStream, pr)
+		_ = pr.Close()
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		_, err = rc.ImageCheck(ctx, rStream)
+		if err != nil {
+			t.Errorf("failed to check imported image: %v", err)
+		}
+	})
+	t.Run("oci tar stream compressed", func(t *testing.T) {
+		fileIn, err := os.Open(filepath.Join(tempDir, "test3.tar.gz"))
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		rStream := rOut3.SetTag("stream")
+		// hide the Seek method from the import
+		err = rc.ImageImportOCITar(ctx, rStream, struct{ io.Reader }{fileIn})
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		_, err = rc.ImageCheck(ctx, rStream)
+		if err != nil {
+			t.Errorf("failed to check imported image: %v", err)
+		}
+	})
+	t.Run("oci tar stream missing layout", func(t *testing.T) {
+		fileMissing := filepath.Join(tempDir, "missing.tar")
+		rewriteTar(t, filepath.Join(tempDir, "test1.tar"), fileMissing, func(name string, data []byte) ([]byte, bool) {
+			return data, name != "oci-layout"
+		})
+		fileIn, err := os.Open(fileMissing)
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		err = rc.ImageImportOCITar(ctx, rOut1.SetTag("missing"), fileIn)
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrNotFound, err)
+		}
+	})
+	t.Run("oci validate", func(t *testing.T) {
+		fileIn, err := os.Open(filepath.Join(tempDir, "test1.tar"))
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		mIn, err := rc.ManifestHead(ctx, rIn1, WithManifestRequireDigest())
+		if err != nil {
+			t.Fatalf("failed to head manifest: %v", err)
+		}
+		rValidate := rOut1.SetTag("validate")
+		res := ImageImportResult{}
+		err = rc.ImageImport(ctx, rValidate, fileIn, ImageWithImportValidate(&res))
+		if err != nil {
+			t.Fatalf("failed to validate: %v", err)
+		}
+		if res.Desc.Digest != mIn.GetDescriptor().Digest {
+			t.Errorf("unexpected digest, expected %s, received %s", mIn.GetDescriptor().Digest, res.Desc.Digest)
+		}
+		if len(res.Manifests) < 2 || res.Manifests[len(res.Manifests)-1].Digest != res.Desc.Digest {
+			t.Errorf("unexpected manifests, expected the index last: %v", res.Manifests)
+		}
+		if len(res.Blobs) == 0 {
+			t.Errorf("no blobs in the result")
+		}
+		_, err = rc.ManifestHead(ctx, rValidate)
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("validated image was pushed: %v", err)
+		}
+	})
+	t.Run("docker validate", func(t *testing.T) {
+		fileIn, err := os.Open(filepath.Join(tempDir, "docker.tar"))
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		// the validated digest matches the earlier import of the same tar
+		mDocker, err := rc.ManifestHead(ctx, rOut1.SetTag("docker"), WithManifestRequireDigest())
+		if err != nil {
+			t.Fatalf("failed to head manifest: %v", err)
+		}
+		res := ImageImportResult{}
+		err = rc.ImageImport(ctx, rOut1.SetTag("docker-validate"), fileIn, ImageWithImportValidate(&res))
+		if err != nil {
+			t.Fatalf("failed to validate: %v", err)
+		}
+		if res.Desc.Digest != mDocker.GetDescriptor().Digest {
+			t.Errorf("unexpected digest, expected %s, received %s", mDocker.GetDescriptor().Digest, res.Desc.Digest)
+		}
+		if len(res.Manifests) != 1 {
+			t.Errorf("unexpected manifests: %v", res.Manifests)
+		}
+		if len(res.Blobs) < 2 || res.Blobs[0].MediaType != mediatype.Docker2ImageConfig {
+			t.Errorf("unexpected blobs, expected the config and layers: %v", res.Blobs)
+		}
+		_, err = rc.ManifestHead(ctx, rOut1.SetTag("docker-validate"))
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("validated image was pushed: %v", err)
+		}
+	})
+	t.Run("oci validate digest mismatch", func(t *testing.T) {
+		fileBad := filepath.Join(tempDir, "validate-bad.tar")
+		rewriteTar(t, filepath.Join(tempDir, "test1.tar"), fileBad, func(name string, data []byte) ([]byte, bool) {
+			// corrupt the layers, keeping the size unchanged
+			if strings.HasPrefix(name, "blobs/") && len(data) > 0 && !json.Valid(data) {
+				data[0] ^= 0xff
+			}
+			return data, true
+		})
+		fileIn, err := os.Open(fileBad)
+		if err != nil {
+			t.Fatalf("failed to open tar: %v", err)
+		}
+		defer fileIn.Close()
+		err = rc.ImageImportOCITar(ctx, rOut1.SetTag("validate-bad"), fileIn, ImageWithImportValidate(nil))
+		if !errors.Is(err, errs.ErrDigestMismatch) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrDigestMismatch, err)
+		}
+	})
+}
+
+// limitWriter fails any write beyond the limit.
+type limitWriter struct {
+	w     io.Writer
+	limit int
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	if len(p) > lw.limit {
+		n, _ := lw.w.Write(p[:lw.limit])
+		lw.limit = 0
+		return n, io.ErrShortWrite
+	}
+	lw.limit -= len(p)
+	return lw.w.Write(p)
+}
+
+func TestImageExportWriteErr(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	for _, compress := range []bool{false, true} {
+		opts := []ImageOpts{}
+		name := "tar"
+		if compress {
+			opts = append(opts, ImageWithExportCompress())
+			name = "tar.gz"
+		}
+		t.Run(name, func(t *testing.T) {
+			full := &bytes.Buffer{}
+			err := rc.ImageExport(ctx, r, full, opts...)
+			if err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+			// fail on the trailing data written when the export is finished
+			out := &limitWriter{w: io.Discard, limit: full.Len() - 10}
+			err = rc.ImageExport(ctx, r, out, opts...)
+			if !errors.Is(err, io.ErrShortWrite) {
+				t.Errorf("unexpected error, expected %v, received %v", io.ErrShortWrite, err)
+			}
+		})
+	}
+}
+
+func TestImageExportTime(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rIndex, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mPlat, err := rc.ManifestGet(ctx, rIndex, WithManifestPlatform(platform.Platform{OS: "linux", Architecture: "amd64"}))
+	if err != nil {
+		t.Fatalf("failed to get platform manifest: %v", err)
+	}
+	rPlat := rIndex.SetDigest(mPlat.GetDescriptor().Digest.String())
+	conf, err := rc.ImageConfig(ctx, rPlat)
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	if conf.GetConfig().Created == nil {
+		t.Fatalf("config is missing the created time")
+	}
+	created := *conf.GetConfig().Created
+	fixed := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+	tt := []struct {
+		name   string
+		r      ref.Ref
+		opts   []ImageOpts
+		expect time.Time
+	}{
+		{
+			name:   "default epoch",
+			r:      rPlat,
+			expect: time.Unix(0, 0),
+		},
+		{
+			name:   "fixed",
+			r:      rPlat,
+			opts:   []ImageOpts{ImageWithExportTime(fixed)},
+			expect: fixed,
+		},
+		{
+			name:   "created",
+			r:      rPlat,
+			opts:   []ImageOpts{ImageWithExportTimeCreated()},
+			expect: created,
+		},
+		{
+			name:   "created index fallback",
+			r:      rIndex,
+			opts:   []ImageOpts{ImageWithExportTime(fixed), ImageWithExportTimeCreated()},
+			expect: fixed,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := rc.ImageExport(ctx, tc.r, buf, tc.opts...)
+			if err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+			tr := tar.NewReader(buf)
+			count := 0
+			for {
+				th, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("failed to read tar: %v", err)
+				}
+				count++
+				if !th.ModTime.Equal(tc.expect) {
+					t.Errorf("unexpected time for %s, expected %s, received %s", th.Name, tc.expect.String(), th.ModTime.String())
+				}
+			}
+			if count == 0 {
+				t.Errorf("no files in the export")
+			}
+		})
+	}
+}
+
+func TestImageExportEnvPlatform(t *testing.T) {
+	// t.Setenv prevents this test from running in parallel
+	t.Setenv(envPlatform, "linux/arm64")
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	tt := []struct {
+		name string
+		opts []ImageOpts
+		plat platform.Platform
+	}{
+		{
+			name: "env",
+			plat: platform.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			name: "option",
+			opts: []ImageOpts{ImageWithPlatform("linux/amd64")},
+			plat: platform.Platform{OS: "linux", Architecture: "amd64"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mPlat, err := rc.ManifestGet(ctx, r, WithManifestPlatform(tc.plat))
+			if err != nil {
+				t.Fatalf("failed to get platform manifest: %v", err)
+			}
+			buf := &bytes.Buffer{}
+			err = rc.ImageExport(ctx, r, buf, tc.opts...)
+			if err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+			tr := tar.NewReader(buf)
+			found := false
+			for {
+				th, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("failed to read tar: %v", err)
+				}
+				if th.Name != "index.json" {
+					continue
+				}
+				found = true
+				idx := v1.Index{}
+				err = json.NewDecoder(tr).Decode(&idx)
+				if err != nil {
+					t.Fatalf("failed to parse index.json: %v", err)
+				}
+				if len(idx.Manifests) != 1 || idx.Manifests[0].Digest != mPlat.GetDescriptor().Digest {
+					t.Errorf("unexpected index.json, expected %s, received %v", mPlat.GetDescriptor().Digest.String(), idx.Manifests)
+				}
+				if idx.Manifests[0].Annotations[annotationRefName] != "v1" {
+					t.Errorf("unexpected ref name annotation: %v", idx.Manifests[0].Annotations)
+				}
+			}
+			if !found {
+				t.Errorf("index.json not found in the export")
+			}
+		})
+	}
+}
+
+func TestImageExportBlobDigest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	err = rc.ImageExport(ctx, r, buf)
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	// blobs are exported unmodified, so the content of every file matches the digest in the filename
+	tr := tar.NewReader(buf)
+	count := 0
+	for {
+		th, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read tar: %v", err)
+		}
+		if !strings.HasPrefix(th.Name, "blobs/sha256/") || th.Typeflag != tar.TypeReg {
+			continue
+		}
+		count++
+		expect := digest.NewDigestFromEncoded(digest.SHA256, strings.TrimPrefix(th.Name, "blobs/sha256/"))
+		received, err := digest.SHA256.FromReader(tr)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", th.Name, err)
+		}
+		if received != expect {
+			t.Errorf("digest mismatch for %s, received %s", th.Name, received.String())
+		}
+	}
+	if count == 0 {
+		t.Errorf("no blobs in the export")
+	}
+}
+
+func TestImageExportCompression(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rSrc, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mSrc, err := rc.ManifestHead(ctx, rSrc, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head manifest: %v", err)
+	}
+	tempDir := t.TempDir()
+	for _, ct := range []archive.CompressType{archive.CompressNone, archive.CompressGzip, archive.CompressZstd} {
+		t.Run(ct.String(), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := rc.ImageExport(ctx, rSrc, buf, ImageWithExportCompression(ct))
+			if err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+			if detected := archive.DetectCompression(buf.Bytes()); detected != ct {
+				t.Errorf("unexpected compression, expected %s, received %s", ct.String(), detected.String())
+			}
+			// the import detects the compression of the tar
+			rTgt, err := ref.New("ocidir://" + tempDir + "/" + ct.String() + ":v1")
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			err = rc.ImageImport(ctx, rTgt, bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("failed to import: %v", err)
+			}
+			mTgt, err := rc.ManifestHead(ctx, rTgt, WithManifestRequireDigest())
+			if err != nil {
+				t.Fatalf("failed to head manifest: %v", err)
+			}
+			if mTgt.GetDescriptor().Digest != mSrc.GetDescriptor().Digest {
+				t.Errorf("unexpected digest, expected %s, received %s", mSrc.GetDescriptor().Digest, mTgt.GetDescriptor().Digest)
+			}
+		})
+	}
+	t.Run("unsupported", func(t *testing.T) {
+		err := rc.ImageExport(ctx, rSrc, io.Discard, ImageWithExportCompression(archive.CompressXz))
+		if !errors.Is(err, errs.ErrUnsupported) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupported, err)
+		}
+	})
+}
+
+func TestImageExportLayerSkip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rIndex, err := ref.New("ocidir://testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mOrig, err := rc.ManifestGet(ctx, rIndex, WithManifestPlatform(platform.Platform{OS: "linux", Architecture: "amd64"}))
+	if err != nil {
+		t.Fatalf("failed to get platform manifest: %v", err)
+	}
+	rPlat := rIndex.SetDigest(mOrig.GetDescriptor().Digest.String())
+	layersOrig, err := mOrig.(manifest.Imager).GetLayers()
+	if err != nil {
+		t.Fatalf("failed to get layers: %v", err)
+	}
+	if len(layersOrig) < 2 {
+		t.Fatalf("test requires an image with multiple layers")
+	}
+	skipFn := func(i int, d descriptor.Descriptor) bool {
+		return i == 0
+	}
+	t.Run("index", func(t *testing.T) {
+		err := rc.ImageExport(ctx, rIndex, io.Discard, ImageWithExportLayerSkip(skipFn))
+		if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+		}
+	})
+	t.Run("skip first", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := rc.ImageExport(ctx, rPlat, buf, ImageWithExportLayerSkip(skipFn))
+		if err != nil {
+			t.Fatalf("failed to export: %v", err)
+		}
+		files := map[string][]byte{}
+		tr := tar.NewReader(buf)
+		for {
+			th, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("failed to read tar: %v", err)
+			}
+			if th.Typeflag != tar.TypeReg {
+				continue
+			}
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", th.Name, err)
+			}
+			files[th.Name] = b
+		}
+		// every blob matches the digest in the filename
+		for name, b := range files {
+			if strings.HasPrefix(name, "blobs/sha256/") && digest.FromBytes(b).Encoded() != strings.TrimPrefix(name, "blobs/sha256/") {
+				t.Errorf("digest mismatch for %s", name)
+			}
+		}
+		index := v1.Index{}
+		err = json.Unmarshal(files["index.json"], &index)
+		if err != nil || len(index.Manifests) != 1 {
+			t.Fatalf("failed to parse index.json: %v", err)
+		}
+		mDesc := index.Manifests[0]
+		if mDesc.Digest == mOrig.GetDescriptor().Digest {
+			t.Errorf("manifest digest was not changed")
+		}
+		m := v1.Manifest{}
+		err = json.Unmarshal(files["blobs/sha256/"+mDesc.Digest.Encoded()], &m)
+		if err != nil {
+			t.Fatalf("failed to parse manifest: %v", err)
+		}
+		if len(m.Layers) != len(layersOrig)-1 || m.Layers[0].Digest != layersOrig[1].Digest {
+			t.Errorf("unexpected layers: %v", m.Layers)
+		}
+		if _, ok := files["blobs/sha256/"+layersOrig[0].Digest.Encoded()]; ok {
+			t.Errorf("skipped layer included in the export")
+		}
+		conf := v1.Image{}
+		err = json.Unmarshal(files["blobs/sha256/"+m.Config.Digest.Encoded()], &conf)
+		if err != nil {
+			t.Fatalf("failed to parse config: %v", err)
+		}
+		if len(conf.RootFS.DiffIDs) != len(m.Layers) {
+			t.Errorf("diff_ids do not match layers, %d diff_ids, %d layers", len(conf.RootFS.DiffIDs), len(m.Layers))
+		}
+		historyLayers := 0
+		for _, h := range conf.History {
+			if !h.EmptyLayer {
+				historyLayers++
+			}
+		}
+		if len(conf.History) > 0 && historyLayers != len(m.Layers) {
+			t.Errorf("history does not match layers, %d history layers, %d layers", historyLayers, len(m.Layers))
+		}
+		dockerManifest := []dockerTarManifest{}
+		err = json.Unmarshal(files["manifest.json"], &dockerManifest)
+		if err != nil || len(dockerManifest) != 1 {
+			t.Fatalf("failed to parse manifest.json: %v", err)
+		}
+		if len(dockerManifest[0].Layers) != len(m.Layers) {
+			t.Errorf("unexpected layers in manifest.json: %v", dockerManifest[0].Layers)
+		}
+	})
+	t.Run("digest algo", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := rc.ImageExport(ctx, rPlat, buf, ImageWithExportLayerSkip(skipFn), ImageWithDigestAlgo(digest.SHA512))
+		if err != nil {
+			t.Fatalf("failed to export: %v", err)
+		}
+		files := map[string][]byte{}
+		tr := tar.NewReader(buf)
+		for {
+			th, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("failed to read tar: %v", err)
+			}
+			if th.Typeflag != tar.TypeReg {
+				continue
+			}
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", th.Name, err)
+			}
+			files[th.Name] = b
+		}
+		index := v1.Index{}
+		err = json.Unmarshal(files["index.json"], &index)
+		if err != nil || len(index.Manifests) != 1 {
+			t.Fatalf("failed to parse index.json: %v", err)
+		}
+		mDesc := index.Manifests[0]
+		if mDesc.Digest.Algorithm() != digest.SHA512 {
+			t.Fatalf("unexpected manifest digest algorithm: %s", mDesc.Digest.String())
+		}
+		mBytes, ok := files["blobs/sha512/"+mDesc.Digest.Encoded()]
+		if !ok || digest.SHA512.FromBytes(mBytes) != mDesc.Digest {
+			t.Fatalf("manifest missing or does not match %s", mDesc.Digest.String())
+		}
+		m := v1.Manifest{}
+		err = json.Unmarshal(mBytes, &m)
+		if err != nil {
+			t.Fatalf("failed to parse manifest: %v", err)
+		}
+		if m.Config.Digest.Algorithm() != digest.SHA512 {
+			t.Errorf("unexpected config digest algorithm: %s", m.Config.Digest.String())
+		}
+		if confBytes, ok := files["blobs/sha512/"+m.Config.Digest.Encoded()]; !ok || digest.SHA512.FromBytes(confBytes) != m.Config.Digest {
+			t.Errorf("config missing or does not match %s", m.Config.Digest.String())
+		}
+		// existing layers keep their original digest
+		if _, ok := files["blobs/sha256/"+layersOrig[1].Digest.Encoded()]; !ok {
+			t.Errorf("layer %s missing from the export", layersOrig[1].Digest.String())
+		}
+	})
+}
+
+func TestImageExportMulti(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	tempDir := t.TempDir()
+	// copy single platform images from the test repo, tagging one image twice
+	copyPlatform := func(srcTag string, tgtTags ...string) ref.Ref {
+		rSrc, err := ref.New("ocidir://testdata/testrepo:" + srcTag)
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		m, err := rc.ManifestGet(ctx, rSrc)
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		p, err := platform.Parse("linux/amd64")
+		if err != nil {
+			t.Fatalf("failed to parse platform: %v", err)
+		}
+		d, err := manifest.GetPlatformDesc(m, &p)
+		if err != nil {
+			t.Fatalf("failed to get platform: %v", err)
+		}
+		var rTgt ref.Ref
+		for _, tag := range tgtTags {
+			rTgt, err = ref.New("ocidir://" + tempDir + "/src:" + tag)
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			err = rc.ImageCopy(ctx, rSrc.SetDigest(d.Digest.String()), rTgt)
+			if err != nil {
+				t.Fatalf("failed to copy: %v", err)
+			}
+		}
+		return rTgt
+	}
+	rA1 := copyPlatform("v1", "a1")
+	rAlias := copyPlatform("v1", "alias")
+	rA2 := copyPlatform("v2", "a2")
+	rIndex, err := ref.New("ocidir://testdata/testrepo:v3")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+
+	t.Run("export", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := rc.ImageExportMulti(ctx, []ref.Ref{rA1, rA2, rAlias, rIndex}, buf)
+		if err != nil {
+			t.Fatalf("failed to export: %v", err)
+		}
+		files := map[string]int{}
+		var index v1.Index
+		var dockerManifest []dockerTarManifest
+		tr := tar.NewReader(buf)
+		for {
+			th, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				t.Fatalf("failed to read tar: %v", err)
+			}
+			files[th.Name]++
+			switch th.Name {
+			case ociIndexFilename:
+				err = json.NewDecoder(tr).Decode(&index)
+			case dockerManifestFilename:
+				err = json.NewDecoder(tr).Decode(&dockerManifest)
+			}
+			if err != nil {
+				t.Fatalf("failed to parse %s: %v", th.Name, err)
+			}
+		}
+		for name, count := range files {
+			if count != 1 {
+				t.Errorf("file %s included %d times", name, count)
+			}
+		}
+		if len(index.Manifests) != 4 {
+			t.Errorf("unexpected index entries, expected 4, received %d", len(index.Manifests))
+		}
+		if len(dockerManifest) != 2 {
+			t.Fatalf("unexpected docker manifest entries, expected 2, received %d", len(dockerManifest))
+		}
+		if len(dockerManifest[0].RepoTags) != 2 || len(dockerManifest[1].RepoTags) != 1 {
+			t.Errorf("unexpected repo tags: %v, %v", dockerManifest[0].RepoTags, dockerManifest[1].RepoTags)
+		}
+		for _, dm := range dockerManifest {
+			if files[dm.Config] != 1 {
+				t.Errorf("config missing: %s", dm.Config)
+			}
+			for _, l := range dm.Layers {
+				if files[l] != 1 {
+					t.Errorf("layer missing: %s", l)
+				}
+			}
+		}
+	})
+	t.Run("import", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := rc.ImageExportMulti(ctx, []ref.Ref{rA1, rA2}, buf)
+		if err != nil {
+			t.Fatalf("failed to export: %v", err)
+		}
+		rImport, err := ref.New("ocidir://" + tempDir + "/import:a2")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		err = rc.ImageImport(ctx, rImport, bytes.NewReader(buf.Bytes()), ImageWithImportName(rA2.Tag))
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		mImport, err := rc.ManifestHead(ctx, rImport, WithManifestRequireDigest())
+		if err != nil {
+			t.Fatalf("failed to head import: %v", err)
+		}
+		mA2, err := rc.ManifestHead(ctx, rA2, WithManifestRequireDigest())
+		if err != nil {
+			t.Fatalf("failed to head source: %v", err)
+		}
+		if mImport.GetDescriptor().Digest != mA2.GetDescriptor().Digest {
+			t.Errorf("imported digest mismatch, expected %s, received %s", mA2.GetDescriptor().Digest, mImport.GetDescriptor().Digest)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		err := rc.ImageExportMulti(ctx, []ref.Ref{}, io.Discard)
+		if !errors.Is(err, errs.ErrInvalidReference) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrInvalidReference, err)
+		}
+		err = rc.ImageExportMulti(ctx, []ref.Ref{rA1, rA2}, io.Discard, ImageWithExportRef(rA1))
+		if !errors.Is(err, errs.ErrUnsupported) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupported, err)
+		}
+	})
+}
+
+func TestImportDockerDuplicateLayers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	// count the blob uploads started on the registry
+	uploads := 0
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/blobs/uploads/") {
+			mu.Lock()
+			uploads++
+			mu.Unlock()
+		}
+		regHandler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	r, err := ref.New(tsHost + "/testrepo:dup")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	// generate a layer tar
+	layerBuf := &bytes.Buffer{}
+	ltw := tar.NewWriter(layerBuf)
+	layerFile := []byte("duplicate layer content")
+	err = ltw.WriteHeader(&tar.Header{Name: "file.txt", Mode: 0644, Size: int64(len(layerFile)), ModTime: time.Unix(0, 0)})
+	if err != nil {
+		t.Fatalf("failed to write layer header: %v", err)
+	}
+	_, err = ltw.Write(layerFile)
+	if err != nil {
+		t.Fatalf("failed to write layer: %v", err)
+	}
+	err = ltw.Close()
+	if err != nil {
+		t.Fatalf("failed to close layer: %v", err)
+	}
+	layerBytes := layerBuf.Bytes()
+	dLayerUC := digest.Canonical.FromBytes(layerBytes)
+	// the same content is included in two files, and one file is listed twice
+	confBytes := []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":["` +
+		dLayerUC.String() + `","` + dLayerUC.String() + `","` + dLayerUC.String() + `"]}}`)
+	confName := digest.Canonical.FromBytes(confBytes).Encoded() + ".json"
+	dtm, err := json.Marshal([]dockerTarManifest{{
+		Config:   confName,
+		RepoTags: []string{"testrepo:dup"},
+		Layers:   []string{"a/layer.tar", "b/layer.tar", "a/layer.tar"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	tarBuf := &bytes.Buffer{}
+	tw := tar.NewWriter(tarBuf)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{name: "a/layer.tar", data: layerBytes},
+		{name: "b/layer.tar", data: layerBytes},
+		{name: confName, data: confBytes},
+		{name: dockerManifestFilename, data: dtm},
+	} {
+		err = tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.data)), ModTime: time.Unix(0, 0)})
+		if err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		_, err = tw.Write(entry.data)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", entry.name, err)
+		}
+	}
+	err = tw.Close()
+	if err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+
+	err = rc.ImageImport(ctx, r, bytes.NewReader(tarBuf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	// one upload for the config and one for the layer
+	if uploads != 2 {
+		t.Errorf("unexpected number of uploads, expected 2, received %d", uploads)
+	}
+	m, err := rc.ManifestGet(ctx, r)
+	if err != nil {
+		t.Fatalf("failed to get manifest: %v", err)
+	}
+	layers, err := m.(manifest.Imager).GetLayers()
+	if err != nil {
+		t.Fatalf("failed to get layers: %v", err)
+	}
+	if len(layers) != 3 {
+		t.Fatalf("unexpected number of layers, expected 3, received %d", len(layers))
+	}
+	for i, l := range layers {
+		if l.Digest == "" || l.Digest != layers[0].Digest {
+			t.Errorf("layer %d digest mismatch, expected %s, received %s", i, layers[0].Digest, l.Digest)
+		}
+	}
+}
+
+func TestImportDockerPlatform(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	// generate a layer tar
+	layerBuf := &bytes.Buffer{}
+	ltw := tar.NewWriter(layerBuf)
+	layerFile := []byte("platform layer content")
+	err := ltw.WriteHeader(&tar.Header{Name: "file.txt", Mode: 0644, Size: int64(len(layerFile)), ModTime: time.Unix(0, 0)})
+	if err != nil {
+		t.Fatalf("failed to write layer header: %v", err)
+	}
+	_, err = ltw.Write(layerFile)
+	if err != nil {
+		t.Fatalf("failed to write layer: %v", err)
+	}
+	err = ltw.Close()
+	if err != nil {
+		t.Fatalf("failed to close layer: %v", err)
+	}
+	layerBytes := layerBuf.Bytes()
+	dLayerUC := digest.Canonical.FromBytes(layerBytes)
+	genTar := func(confBytes []byte) []byte {
+		confName := digest.Canonical.FromBytes(confBytes).Encoded() + ".json"
+		dtm, err := json.Marshal([]dockerTarManifest{{
+			Config:   confName,
+			RepoTags: []string{"testrepo:platform"},
+			Layers:   []string{"layer.tar"},
+		}})
+		if err != nil {
+			t.Fatalf("failed to marshal manifest: %v", err)
+		}
+		tarBuf := &bytes.Buffer{}
+		tw := tar.NewWriter(tarBuf)
+		for _, entry := range []struct {
+			name string
+			data []byte
+		}{
+			{name: "layer.tar", data: layerBytes},
+			{name: confName, data: confBytes},
+			{name: dockerManifestFilename, data: dtm},
+		} {
+			err = tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.data)), ModTime: time.Unix(0, 0)})
+			if err != nil {
+				t.Fatalf("failed to write header: %v", err)
+			}
+			_, err = tw.Write(entry.data)
+			if err != nil {
+				t.Fatalf("failed to write %s: %v", entry.name, err)
+			}
+		}
+		err = tw.Close()
+		if err != nil {
+			t.Fatalf("failed to close tar: %v", err)
+		}
+		return tarBuf.Bytes()
+	}
+	rootFS := `"rootfs":{"type":"layers","diff_ids":["` + dLayerUC.String() + `"]}`
+	confNoPlat := []byte(`{"config":{"Labels":{"keep":"value"}},` + rootFS + `}`)
+	confPlat := []byte(`{"architecture":"ppc64le","os":"linux",` + rootFS + `}`)
+	local := platform.Local()
+	tt := []struct {
+		name       string
+		conf       []byte
+		opts       []ImageOpts
+		expectOS   string
+		expectArch string
+		expectVar  string
+	}{
+		{
+			name:       "override",
+			conf:       confNoPlat,
+			opts:       []ImageOpts{ImageWithImportPlatform("linux/arm/v7")},
+			expectOS:   "linux",
+			expectArch: "arm",
+			expectVar:  "v7",
+		},
+		{
+			name:       "local",
+			conf:       confNoPlat,
+			expectOS:   local.OS,
+			expectArch: local.Architecture,
+			expectVar:  local.Variant,
+		},
+		{
+			name:       "config",
+			conf:       confPlat,
+			opts:       []ImageOpts{ImageWithImportPlatform("linux/arm64")},
+			expectOS:   "linux",
+			expectArch: "ppc64le",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := ref.New("ocidir://" + t.TempDir() + "/testrepo:platform")
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			err = rc.ImageImport(ctx, r, bytes.NewReader(genTar(tc.conf)), tc.opts...)
+			if err != nil {
+				t.Fatalf("failed to import: %v", err)
+			}
+			conf, err := rc.ImageConfig(ctx, r)
+			if err != nil {
+				t.Fatalf("failed to get config: %v", err)
+			}
+			oc := conf.GetConfig()
+			if oc.OS != tc.expectOS || oc.Architecture != tc.expectArch || oc.Variant != tc.expectVar {
+				t.Errorf("unexpected platform, expected %s/%s/%s, received %s/%s/%s", tc.expectOS, tc.expectArch, tc.expectVar, oc.OS, oc.Architecture, oc.Variant)
+			}
+			if bytes.Equal(tc.conf, confNoPlat) && oc.Config.Labels["keep"] != "value" {
+				t.Errorf("config labels were not preserved: %v", oc.Config.Labels)
+			}
+		})
+	}
+}
+
+func TestCopyExternal(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// external layer is only available from a separate server
+	layer := []byte("external layer content")
+	dLayer := digest.FromBytes(layer)
+	tsExt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/layer" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(layer)
+	}))
+	t.Cleanup(tsExt.Close)
+	boolT := true
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+		API: oConfig.ConfigAPI{
+			DeleteEnabled: &boolT,
+			Blob: oConfig.ConfigAPIBlob{
+				DeleteEnabled: &boolT,
+			},
+		},
+	})
+	ts := httptest.NewServer(regHandler)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	rSrc, err := ref.New(tsHost + "/testsrc:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	// push a config and a manifest with a foreign layer
+	confBytes := []byte(`{"architecture":"amd64","os":"windows","rootfs":{"type":"layers","diff_ids":["` + dLayer.String() + `"]}}`)
+	dConf, err := rc.BlobPut(ctx, rSrc, descriptor.Descriptor{}, bytes.NewReader(confBytes))
+	if err != nil {
+		t.Fatalf("failed to push config: %v", err)
+	}
+	dConf.MediaType = mediatype.Docker2ImageConfig
+	mSrc, err := manifest.New(manifest.WithOrig(schema2.Manifest{
+		Versioned: schema2.ManifestSchemaVersion,
+		Config:    dConf,
+		Layers: []descriptor.Descriptor{
+			{
+				MediaType: mediatype.Docker2ForeignLayer,
+				Digest:    dLayer,
+				Size:      int64(len(layer)),
+				URLs:      []string{tsExt.URL + "/layer"},
+			},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create manifest: %v", err)
+	}
+	// the registry validates the layer exists, so push and then delete it after the manifest
+	dLayerPut, err := rc.BlobPut(ctx, rSrc, descriptor.Descriptor{Digest: dLayer, Size: int64(len(layer))}, bytes.NewReader(layer))
+	if err != nil {
+		t.Fatalf("failed to push layer: %v", err)
+	}
+	err = rc.ManifestPut(ctx, rSrc, mSrc)
+	if err != nil {
+		t.Fatalf("failed to push manifest: %v", err)
+	}
+	err = rc.BlobDelete(ctx, rSrc, dLayerPut)
+	if err != nil {
+		t.Fatalf("failed to delete layer: %v", err)
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		// ocidir does not verify the layer exists
+		rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testtgt:skip")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		err = rc.ImageCopy(ctx, rSrc, rTgt)
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		mh, err := rc.ManifestHead(ctx, rTgt, WithManifestRequireDigest())
+		if err != nil {
+			t.Fatalf("failed to head target: %v", err)
+		}
+		if mh.GetDescriptor().Digest != mSrc.GetDescriptor().Digest {
+			t.Errorf("digest mismatch, expected %s, received %s", mSrc.GetDescriptor().Digest, mh.GetDescriptor().Digest)
+		}
+	})
+	t.Run("urls-rm", func(t *testing.T) {
+		rTgt, err := ref.New(tsHost + "/testtgt:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithExternalURLsRm())
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		mTgt, err := rc.ManifestGet(ctx, rTgt)
+		if err != nil {
+			t.Fatalf("failed to get target: %v", err)
+		}
+		if mTgt.GetDescriptor().Digest == mSrc.GetDescriptor().Digest {
+			t.Errorf("digest was not changed")
+		}
+		layers, err := mTgt.(manifest.Imager).GetLayers()
+		if err != nil || len(layers) != 1 {
+			t.Fatalf("unexpected layers: %v, %v", layers, err)
+		}
+		if len(layers[0].URLs) > 0 || layers[0].MediaType != mediatype.Docker2LayerGzip {
+			t.Errorf("external layer not converted: %v", layers[0])
+		}
+		_, err = rc.BlobHead(ctx, rTgt, layers[0])
+		if err != nil {
+			t.Errorf("layer missing from target: %v", err)
+		}
+	})
+}
+
+func TestCopyExportImportNoLayers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	ts := httptest.NewServer(regHandler)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	tempDir := t.TempDir()
+	dEmpty := descriptor.Descriptor{MediaType: mediatype.OCI1Empty, Digest: descriptor.EmptyDigest, Size: int64(len(descriptor.EmptyData))}
+	tt := []struct {
+		name   string
+		layers []descriptor.Descriptor
+	}{
+		{
+			name:   "empty layers",
+			layers: []descriptor.Descriptor{},
+		},
+		{
+			name:   "nil layers",
+			layers: nil,
+		},
+		{
+			name:   "empty blob layer",
+			layers: []descriptor.Descriptor{dEmpty},
+		},
+	}
+	for i, tc := range tt {
+		tc := tc
+		tag := fmt.Sprintf("config-only-%d", i)
+		t.Run(tc.name, func(t *testing.T) {
+			rSrc, err := ref.New("ocidir://" + tempDir + "/src:" + tag)
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			_, err = rc.BlobPut(ctx, rSrc, dEmpty, bytes.NewReader(descriptor.EmptyData))
+			if err != nil {
+				t.Fatalf("failed to push config: %v", err)
+			}
+			m, err := manifest.New(manifest.WithOrig(v1.Manifest{
+				Versioned:    v1.ManifestSchemaVersion,
+				MediaType:    mediatype.OCI1Manifest,
+				ArtifactType: "application/vnd.example.config-only",
+				Config:       dEmpty,
+				Layers:       tc.layers,
+			}))
+			if err != nil {
+				t.Fatalf("failed to create manifest: %v", err)
+			}
+			err = rc.ManifestPut(ctx, rSrc, m)
+			if err != nil {
+				t.Fatalf("failed to push manifest: %v", err)
+			}
+			// copy to a registry
+			rReg, err := ref.New(tsHost + "/config-only:" + tag)
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			err = rc.ImageCopy(ctx, rSrc, rReg)
+			if err != nil {
+				t.Fatalf("failed to copy: %v", err)
+			}
+			mReg, err := rc.ManifestGet(ctx, rReg)
+			if err != nil {
+				t.Fatalf("failed to get copied manifest: %v", err)
+			}
+			if mReg.GetDescriptor().Digest != m.GetDescriptor().Digest {
+				t.Errorf("digest changed on copy, expected %s, received %s", m.GetDescriptor().Digest, mReg.GetDescriptor().Digest)
+			}
+			// export and import
+			buf := &bytes.Buffer{}
+			err = rc.ImageExport(ctx, rReg, buf)
+			if err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+			rImport := rReg.SetTag(tag + "-import")
+			err = rc.ImageImport(ctx, rImport, bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("failed to import: %v", err)
+			}
+			mImport, err := rc.ManifestGet(ctx, rImport)
+			if err != nil {
+				t.Fatalf("failed to get imported manifest: %v", err)
+			}
+			if mImport.GetDescriptor().Digest != m.GetDescriptor().Digest {
+				t.Errorf("digest changed on import, expected %s, received %s", m.GetDescriptor().Digest, mImport.GetDescriptor().Digest)
+			}
+			_, err = rc.ImageCheck(ctx, rImport)
+			if err != nil {
+				t.Errorf("failed to check imported image: %v", err)
+			}
+		})
+	}
+}
+
+func TestCopyBlobTransform(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rSrc, err := ref.New("ocidir://./testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mSrc, err := rc.ManifestGet(ctx, rSrc)
+	if err != nil {
+		t.Fatalf("failed to get source: %v", err)
+	}
+	prefix := []byte("transformed:")
+	mtTransformed := "application/vnd.example.layer.transformed"
+	errTransform := errors.New("transform failed")
+
+	t.Run("layers", func(t *testing.T) {
+		rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testtgt:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		var mu sync.Mutex
+		count := 0
+		err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithBlobTransform(func(ctx context.Context, d descriptor.Descriptor, rdr io.Reader) (descriptor.Descriptor, io.Reader, error) {
+			// leave the config unchanged
+			if d.MediaType == mediatype.OCI1ImageConfig || d.MediaType == mediatype.Docker2ImageConfig {
+				return descriptor.Descriptor{}, rdr, nil
+			}
+			mu.Lock()
+			count++
+			mu.Unlock()
+			return descriptor.Descriptor{MediaType: mtTransformed}, io.MultiReader(bytes.NewReader(prefix), rdr), nil
+		}))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		if count == 0 {
+			t.Errorf("transform was not called")
+		}
+		mTgt, err := rc.ManifestGet(ctx, rTgt)
+		if err != nil {
+			t.Fatalf("failed to get target: %v", err)
+		}
+		if mTgt.GetDescriptor().Digest == mSrc.GetDescriptor().Digest {
+			t.Errorf("index digest was not changed")
+		}
+		dl, err := mTgt.(manifest.Indexer).GetManifestList()
+		if err != nil {
+			t.Fatalf("failed to get manifest list: %v", err)
+		}
+		for _, d := range dl {
+			mEntry, err := rc.ManifestGet(ctx, rTgt.SetDigest(d.Digest.String()), WithManifestDesc(d))
+			if err != nil {
+				t.Fatalf("failed to get platform manifest %s: %v", d.Digest.String(), err)
+			}
+			mi, ok := mEntry.(manifest.Imager)
+			if !ok {
+				continue
+			}
+			cd, err := mi.GetConfig()
+			if err != nil {
+				t.Fatalf("failed to get config: %v", err)
+			}
+			_, err = rc.BlobHead(ctx, rTgt, cd)
+			if err != nil {
+				t.Errorf("config missing from target: %v", err)
+			}
+			layers, err := mi.GetLayers()
+			if err != nil {
+				t.Fatalf("failed to get layers: %v", err)
+			}
+			for _, l := range layers {
+				if l.MediaType != mtTransformed {
+					t.Errorf("layer media type not updated, expected %s, received %s", mtTransformed, l.MediaType)
+				}
+				br, err := rc.BlobGet(ctx, rTgt, l)
+				if err != nil {
+					t.Fatalf("failed to get layer %s: %v", l.Digest.String(), err)
+				}
+				b, err := io.ReadAll(br)
+				_ = br.Close()
+				if err != nil {
+					t.Fatalf("failed to read layer %s: %v", l.Digest.String(), err)
+				}
+				if !bytes.HasPrefix(b, prefix) {
+					t.Errorf("layer %s was not transformed", l.Digest.String())
+				}
+			}
+		}
+	})
+	t.Run("diff ids", func(t *testing.T) {
+		rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testtgt:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		// decompress each layer and append padding, changing the uncompressed content
+		err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithBlobTransform(func(ctx context.Context, d descriptor.Descriptor, rdr io.Reader) (descriptor.Descriptor, io.Reader, error) {
+			if d.MediaType == mediatype.OCI1ImageConfig || d.MediaType == mediatype.Docker2ImageConfig {
+				return descriptor.Descriptor{}, rdr, nil
+			}
+			dr, err := archive.Decompress(rdr)
+			if err != nil {
+				return d, nil, err
+			}
+			return descriptor.Descriptor{MediaType: mediatype.OCI1Layer}, io.MultiReader(dr, bytes.NewReader(make([]byte, 1024))), nil
+		}))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		mTgt, err := rc.ManifestGet(ctx, rTgt)
+		if err != nil {
+			t.Fatalf("failed to get target: %v", err)
+		}
+		dl, err := mTgt.(manifest.Indexer).GetManifestList()
+		if err != nil {
+			t.Fatalf("failed to get manifest list: %v", err)
+		}
+		checked := 0
+		for _, d := range dl {
+			mEntry, err := rc.ManifestGet(ctx, rTgt.SetDigest(d.Digest.String()), WithManifestDesc(d))
+			if err != nil {
+				t.Fatalf("failed to get platform manifest %s: %v", d.Digest.String(), err)
+			}
+			mi, ok := mEntry.(manifest.Imager)
+			if !ok {
+				continue
+			}
+			cd, err := mi.GetConfig()
+			if err != nil {
+				t.Fatalf("failed to get config: %v", err)
+			}
+			layers, err := mi.GetLayers()
+			if err != nil {
+				t.Fatalf("failed to get layers: %v", err)
+			}
+			conf, err := rc.BlobGetOCIConfig(ctx, rTgt, cd)
+			if err != nil {
+				t.Fatalf("failed to get config: %v", err)
+			}
+			diffIDs := conf.GetConfig().RootFS.DiffIDs
+			if len(diffIDs) != len(layers) {
+				// configs without matching diff_ids are copied unmodified
+				continue
+			}
+			checked++
+			// uncompressed layers have a diff_id matching the layer digest
+			for i, l := range layers {
+				if diffIDs[i] != l.Digest {
+					t.Errorf("diff_id %d not updated, expected %s, received %s", i, l.Digest.String(), diffIDs[i].String())
+				}
+			}
+		}
+		if checked == 0 {
+			t.Errorf("no configs with diff_ids were checked")
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testtgt:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithBlobTransform(func(ctx context.Context, d descriptor.Descriptor, rdr io.Reader) (descriptor.Descriptor, io.Reader, error) {
+			return d, nil, errTransform
+		}))
+		if !errors.Is(err, errTransform) {
+			t.Errorf("unexpected error, expected %v, received %v", errTransform, err)
+		}
+	})
+}
+
+func TestCopyLogID(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rSrc, err := ref.New("ocidir://./testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	tempDir := t.TempDir()
+	tt := []struct {
+		name   string
+		tgt    string
+		opts   []ImageOpts
+		expect string
+	}{
+		{
+			name:   "default",
+			tgt:    "ocidir://" + tempDir + "/default:v1",
+			expect: rSrc.CommonName() + " -> " + "ocidir://" + tempDir + "/default:v1",
+		},
+		{
+			name:   "provided",
+			tgt:    "ocidir://" + tempDir + "/provided:v1",
+			opts:   []ImageOpts{ImageWithLogID("copy-42")},
+			expect: "copy-42",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rTgt, err := ref.New(tc.tgt)
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			buf := &bytes.Buffer{}
+			rc := New(WithSlog(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+			err = rc.ImageCopy(ctx, rSrc, rTgt, tc.opts...)
+			if err != nil {
+				t.Fatalf("failed to copy: %v", err)
+			}
+			count := 0
+			dec := json.NewDecoder(buf)
+			for dec.More() {
+				entry := map[string]any{}
+				err = dec.Decode(&entry)
+				if err != nil {
+					t.Fatalf("failed to parse log: %v", err)
+				}
+				msg, _ := entry["msg"].(string)
+				if !strings.HasPrefix(msg, "Copy ") {
+					continue
+				}
+				count++
+				if entry["op"] != tc.expect {
+					t.Errorf("unexpected op for %q, expected %q, received %v", msg, tc.expect, entry["op"])
+				}
+			}
+			if count == 0 {
+				t.Errorf("no copy log messages found")
+			}
+		})
+	}
+	t.Run("batch", func(t *testing.T) {
+		rTgtDefault, err := ref.New("ocidir://" + tempDir + "/batch-default:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		rTgtProvided, err := ref.New("ocidir://" + tempDir + "/batch-provided:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		buf := &bytes.Buffer{}
+		rc := New(WithSlog(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+		// a digest mismatch is retried before the copy fails
+		pairs := []CopyPair{
+			{
+				Src:  rSrc,
+				Tgt:  rTgtDefault,
+				Opts: []ImageOpts{ImageWithExpectDigest(digest.FromString("other"))},
+			},
+			{
+				Src:  rSrc,
+				Tgt:  rTgtProvided,
+				Opts: []ImageOpts{ImageWithExpectDigest(digest.FromString("other")), ImageWithLogID("copy-42")},
+			},
+		}
+		_, err = rc.ImageCopyBatch(ctx, pairs, BatchOpts{Retries: 1, RetryDelay: time.Millisecond})
+		if !errors.Is(err, errs.ErrDigestMismatch) {
+			t.Fatalf("unexpected error, expected %v, received %v", errs.ErrDigestMismatch, err)
+		}
+		expect := map[string]string{
+			rTgtDefault.CommonName():  rSrc.CommonName() + " -> " + rTgtDefault.CommonName(),
+			rTgtProvided.CommonName(): "copy-42",
+		}
+		counts := map[string]int{}
+		dec := json.NewDecoder(buf)
+		for dec.More() {
+			entry := map[string]any{}
+			err = dec.Decode(&entry)
+			if err != nil {
+				t.Fatalf("failed to parse log: %v", err)
+			}
+			msg, _ := entry["msg"].(string)
+			if msg != "Retrying image copy" && msg != "Failed to copy image" {
+				continue
+			}
+			counts[msg]++
+			tgt, _ := entry["tgt"].(string)
+			if entry["op"] != expect[tgt] {
+				t.Errorf("unexpected op for %q to %s, expected %q, received %v", msg, tgt, expect[tgt], entry["op"])
+			}
+		}
+		if counts["Retrying image copy"] != len(pairs) || counts["Failed to copy image"] != len(pairs) {
+			t.Errorf("unexpected batch log messages: %v", counts)
+		}
+	})
+}
+
+func TestCopyConfigOnly(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rSrc, err := ref.New("ocidir://./testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testtgt:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	err = rc.ImageCopyConfigOnly(ctx, rSrc, rTgt)
+	if err != nil {
+		t.Fatalf("failed to copy: %v", err)
+	}
+	mSrc, err := rc.ManifestGet(ctx, rSrc)
+	if err != nil {
+		t.Fatalf("failed to get source: %v", err)
+	}
+	mTgt, err := rc.ManifestGet(ctx, rTgt)
+	if err != nil {
+		t.Fatalf("failed to get target: %v", err)
+	}
+	if mSrc.GetDescriptor().Digest != mTgt.GetDescriptor().Digest {
+		t.Errorf("digest mismatch, expected %s, received %s", mSrc.GetDescriptor().Digest, mTgt.GetDescriptor().Digest)
+	}
+	mi, ok := mTgt.(manifest.Indexer)
+	if !ok {
+		t.Fatalf("target is not an index")
+	}
+	dl, err := mi.GetManifestList()
+	if err != nil {
+		t.Fatalf("failed to get manifest list: %v", err)
+	}
+	layerCount := 0
+	for _, d := range dl {
+		mPlat, err := rc.ManifestGet(ctx, rTgt, WithManifestDesc(d))
+		if err != nil {
+			t.Fatalf("failed to get platform manifest %s: %v", d.Digest, err)
+		}
+		mpi, ok := mPlat.(manifest.Imager)
+		if !ok {
+			continue
+		}
+		cd, err := mpi.GetConfig()
+		if err != nil {
+			t.Fatalf("failed to get config: %v", err)
+		}
+		_, err = rc.BlobHead(ctx, rTgt, cd)
+		if err != nil {
+			t.Errorf("config %s missing from target: %v", cd.Digest, err)
+		}
+		layers, err := mpi.GetLayers()
+		if err != nil {
+			t.Fatalf("failed to get layers: %v", err)
+		}
+		for _, l := range layers {
+			layerCount++
+			_, err = rc.BlobHead(ctx, rTgt, l)
+			if err == nil {
+				t.Errorf("layer %s was copied to the target", l.Digest)
+			}
+		}
+	}
+	if layerCount == 0 {
+		t.Errorf("no layers found in the target manifests")
+	}
+}
+
+func TestImageExpectDigest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	rSrc, err := ref.New("ocidir://./testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rOther, err := ref.New("ocidir://./testdata/testrepo:v2")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mh, err := rc.ManifestHead(ctx, rSrc, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head source: %v", err)
+	}
+	digSrc := mh.GetDescriptor().Digest
+	mh, err = rc.ManifestHead(ctx, rOther, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head other: %v", err)
+	}
+	digOther := mh.GetDescriptor().Digest
+	t.Run("copy", func(t *testing.T) {
+		rTgt, err := ref.New("ocidir://" + t.TempDir() + "/testtgt:v1")
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithExpectDigest(digOther))
+		if !errors.Is(err, errs.ErrDigestMismatch) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrDigestMismatch, err)
+		}
+		_, err = rc.ManifestHead(ctx, rTgt)
+		if err == nil {
+			t.Errorf("target was copied after a digest mismatch")
+		}
+		err = rc.ImageCopy(ctx, rSrc, rTgt, ImageWithExpectDigest(digSrc))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		mh, err := rc.ManifestHead(ctx, rTgt, WithManifestRequireDigest())
+		if err != nil {
+			t.Fatalf("failed to head target: %v", err)
+		}
+		if mh.GetDescriptor().Digest != digSrc {
+			t.Errorf("unexpected digest, expected %s, received %s", digSrc, mh.GetDescriptor().Digest)
+		}
+	})
+	t.Run("export", func(t *testing.T) {
+		err := rc.ImageExport(ctx, rSrc, io.Discard, ImageWithExpectDigest(digOther))
+		if !errors.Is(err, errs.ErrDigestMismatch) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrDigestMismatch, err)
+		}
+		buf := &bytes.Buffer{}
+		err = rc.ImageExport(ctx, rSrc, buf, ImageWithExpectDigest(digSrc))
+		if err != nil {
+			t.Fatalf("failed to export: %v", err)
+		}
+		// the export is named with the tag
+		if !bytes.Contains(buf.Bytes(), []byte(`"org.opencontainers.image.ref.name":"v1"`)) {
+			t.Errorf("export does not include the tag")
+		}
+	})
+	t.Run("export multi", func(t *testing.T) {
+		err := rc.ImageExportMulti(ctx, []ref.Ref{rSrc}, io.Discard, ImageWithExpectDigest(digSrc))
+		if !errors.Is(err, errs.ErrUnsupported) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupported, err)
+		}
+	})
+}
+
+func TestImageArtifactConfig(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://./testdata/testrepo:a1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	t.Run("config", func(t *testing.T) {
+		_, err := rc.ImageConfig(ctx, r)
+		if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+		} else if !strings.Contains(err.Error(), "artifact") {
+			t.Errorf("error does not describe the artifact: %v", err)
+		}
+	})
+	t.Run("platforms", func(t *testing.T) {
+		_, err := rc.ImagePlatforms(ctx, r)
+		if !errors.Is(err, errs.ErrUnsupportedMediaType) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+		}
+	})
+	t.Run("export", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := rc.ImageExport(ctx, r, buf, ImageWithExportTimeCreated())
+		if err != nil {
+			t.Fatalf("failed to export: %v", err)
+		}
+		files := map[string]bool{}
+		tr := tar.NewReader(buf)
+		for {
+			th, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("failed to read tar: %v", err)
+			}
+			files[th.Name] = true
+		}
+		if !files[ociIndexFilename] {
+			t.Errorf("export is missing %s", ociIndexFilename)
+		}
+		if files[dockerManifestFilename] {
+			t.Errorf("export of an artifact includes %s", dockerManifestFilename)
+		}
+	})
 }
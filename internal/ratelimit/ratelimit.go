@@ -0,0 +1,115 @@
+// Package ratelimit provides a token bucket limiter for throttling requests and the bandwidth of readers
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket that allows a number of tokens, e.g. bytes or requests, per second.
+// A single Limiter may be shared by concurrent callers to cap their combined rate.
+type Limiter struct {
+	mu    sync.Mutex
+	rate  float64
+	burst float64
+	avail float64
+	last  time.Time
+}
+
+// New returns a Limiter allowing bps bytes per second.
+// The bucket holds up to one second of tokens.
+func New(bps int64) *Limiter {
+	return &Limiter{
+		rate:  float64(bps),
+		burst: float64(bps),
+		avail: float64(bps),
+		last:  time.Now(),
+	}
+}
+
+// NewBurst returns a Limiter allowing rate tokens per second, with bursts of up to burst tokens.
+// The burst is at least 1.
+func NewBurst(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:  rate,
+		burst: float64(burst),
+		avail: float64(burst),
+		last:  time.Now(),
+	}
+}
+
+// Wait blocks until n tokens are allowed, or the context is done.
+// Tokens reserved by a canceled Wait are returned to the bucket.
+func (l *Limiter) Wait(ctx context.Context, n int) error {
+	if l == nil || l.rate <= 0 || n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.avail += now.Sub(l.last).Seconds() * l.rate
+	if l.avail > l.burst {
+		l.avail = l.burst
+	}
+	l.last = now
+	// reserve the tokens, sleeping for any deficit outside of the lock
+	l.avail -= float64(n)
+	delay := time.Duration(0)
+	if l.avail < 0 {
+		delay = time.Duration(-l.avail / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.avail += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Reader throttles reads from an underlying reader with a Limiter.
+type Reader struct {
+	Ctx     context.Context
+	Reader  io.Reader
+	Limiter *Limiter
+}
+
+// Read reads up to one second of data from the underlying reader and waits for the limiter.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.Limiter != nil && r.Limiter.burst >= 1 && float64(len(p)) > r.Limiter.burst {
+		p = p[:int(r.Limiter.burst)]
+	}
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		ctx := r.Ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if errW := r.Limiter.Wait(ctx, n); errW != nil {
+			return n, errW
+		}
+	}
+	return n, err
+}
+
+// Seek passes through to the underlying reader when it implements [io.Seeker].
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	rs, ok := r.Reader.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("reader does not support seek")
+	}
+	return rs.Seek(offset, whence)
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("0123456789"), 300)
+	t.Run("throttled", func(t *testing.T) {
+		t.Parallel()
+		// 3000 bytes at 1000 bytes per second with a 1 second burst takes at least 2 seconds
+		r := &Reader{Ctx: ctx, Reader: bytes.NewReader(data), Limiter: New(1000)}
+		start := time.Now()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		if !bytes.Equal(out, data) {
+			t.Errorf("data mismatch")
+		}
+		if elapsed := time.Since(start); elapsed < 1900*time.Millisecond {
+			t.Errorf("read was not throttled, elapsed %s", elapsed)
+		}
+	})
+	t.Run("unlimited", func(t *testing.T) {
+		t.Parallel()
+		r := &Reader{Ctx: ctx, Reader: bytes.NewReader(data), Limiter: New(0)}
+		start := time.Now()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		if !bytes.Equal(out, data) {
+			t.Errorf("data mismatch")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("unlimited read was throttled, elapsed %s", elapsed)
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+		ctxCancel, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		r := &Reader{Ctx: ctxCancel, Reader: bytes.NewReader(data), Limiter: New(10)}
+		_, err := io.ReadAll(r)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error, expected %v, received %v", context.DeadlineExceeded, err)
+		}
+	})
+	t.Run("seek", func(t *testing.T) {
+		t.Parallel()
+		r := &Reader{Ctx: ctx, Reader: bytes.NewReader(data), Limiter: New(100000)}
+		_, err := io.CopyN(io.Discard, r, 100)
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil || offset != 100 {
+			t.Errorf("unexpected seek result, offset %d, err %v", offset, err)
+		}
+	})
+}
+
+func TestLimiterBurst(t *testing.T) {
+	t.Parallel()
+	t.Run("burst", func(t *testing.T) {
+		t.Parallel()
+		l := NewBurst(1, 3)
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		for i := 0; i < 3; i++ {
+			if err := l.Wait(ctx, 1); err != nil {
+				t.Fatalf("failed to get token %d: %v", i, err)
+			}
+		}
+		// bucket is empty, next token is 1s away
+		err := l.Wait(ctx, 1)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error, expected %v, received %v", context.DeadlineExceeded, err)
+		}
+	})
+	t.Run("rate", func(t *testing.T) {
+		t.Parallel()
+		l := NewBurst(50, 1)
+		ctx := context.Background()
+		start := time.Now()
+		for i := 0; i < 6; i++ {
+			if err := l.Wait(ctx, 1); err != nil {
+				t.Fatalf("failed to get token %d: %v", i, err)
+			}
+		}
+		// first token is immediate, 5 more at 20ms each
+		if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+			t.Errorf("tokens released too quickly: %s", elapsed)
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		l := NewBurst(0.001, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := l.Wait(ctx, 1); err != nil {
+			t.Fatalf("failed to get first token: %v", err)
+		}
+		cancel()
+		err := l.Wait(ctx, 1)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error, expected %v, received %v", context.Canceled, err)
+		}
+	})
+	t.Run("refund", func(t *testing.T) {
+		t.Parallel()
+		l := NewBurst(10, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := l.Wait(ctx, 1); err != nil {
+			t.Fatalf("failed to get first token: %v", err)
+		}
+		cancel()
+		_ = l.Wait(ctx, 1)
+		// the canceled wait does not consume a token, so the next one is at most 100ms away
+		start := time.Now()
+		if err := l.Wait(context.Background(), 1); err != nil {
+			t.Fatalf("failed to get token: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+			t.Errorf("canceled wait consumed a token, elapsed %s", elapsed)
+		}
+	})
+}
@@ -3,9 +3,11 @@ package reghttp
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -29,6 +31,7 @@ import (
 	"github.com/regclient/regclient/config"
 	"github.com/regclient/regclient/internal/auth"
 	"github.com/regclient/regclient/internal/pqueue"
+	"github.com/regclient/regclient/internal/ratelimit"
 	"github.com/regclient/regclient/internal/reqmeta"
 	"github.com/regclient/regclient/types"
 	"github.com/regclient/regclient/types/errs"
@@ -37,7 +40,9 @@ import (
 
 var defaultDelayInit, _ = time.ParseDuration("0.1s")
 var defaultDelayMax, _ = time.ParseDuration("30s")
-var warnRegexp = regexp.MustCompile(`^299\s+-\s+"([^"]+)"`)
+
+// warnRegexp matches the persistent warning code with any warn-agent, e.g. `299 - "message"` or `299 registry.example.com "message"`.
+var warnRegexp = regexp.MustCompile(`^299\s+\S+\s+"([^"]+)"`)
 
 const (
 	DefaultRetryLimit = 5 // number of times a request will be retried
@@ -47,17 +52,19 @@ const (
 // Client is an HTTP client wrapper.
 // It handles features like authentication, retries, backoff delays, TLS settings.
 type Client struct {
-	httpClient    *http.Client              // upstream [http.Client], this is wrapped per repository for an auth handler on redirects
-	getConfigHost func(string) *config.Host // call-back to get the [config.Host] for a specific registry
-	host          map[string]*clientHost    // host specific settings, wrap access with a mutex lock
-	rootCAPool    [][]byte                  // list of root CAs for configuring the http.Client transport
-	rootCADirs    []string                  // list of directories for additional root CAs
-	retryLimit    int                       // number of retries before failing a request, this applies to each host, and each request
-	delayInit     time.Duration             // how long to initially delay requests on a failure
-	delayMax      time.Duration             // maximum time to delay a request
-	slog          *slog.Logger              // logging for tracing and failures
-	userAgent     string                    // user agent to specify in http request headers
-	mu            sync.Mutex                // mutex to prevent data races
+	httpClient    *http.Client                                // upstream [http.Client], this is wrapped per repository for an auth handler on redirects
+	getConfigHost func(string) *config.Host                   // call-back to get the [config.Host] for a specific registry
+	host          map[string]*clientHost                      // host specific settings, wrap access with a mutex lock
+	rootCAPool    [][]byte                                    // list of root CAs for configuring the http.Client transport
+	rootCADirs    []string                                    // list of directories for additional root CAs
+	retryLimit    int                                         // number of retries before failing a request, this applies to each host, and each request
+	delayInit     time.Duration                               // how long to initially delay requests on a failure
+	delayMax      time.Duration                               // maximum time to delay a request
+	rateLimit     *ratelimit.Limiter                          // optional client wide limit on the request rate
+	transportWrap []func(http.RoundTripper) http.RoundTripper // middleware wrapping the transport of each host, the first entry is the outermost
+	slog          *slog.Logger                                // logging for tracing and failures
+	userAgent     string                                      // user agent to specify in http request headers
+	mu            sync.Mutex                                  // mutex to prevent data races
 }
 
 type clientHost struct {
@@ -193,6 +200,19 @@ func WithHTTPClient(hc *http.Client) Opts {
 	}
 }
 
+// WithRateLimit limits the rate of all requests sent by the client, across every host.
+// The limit is a token bucket refilled at reqPerSec, allowing bursts of up to burst requests.
+// A reqPerSec of 0 disables the limit (the default).
+func WithRateLimit(reqPerSec float64, burst int) Opts {
+	return func(c *Client) {
+		if reqPerSec > 0 {
+			c.rateLimit = ratelimit.NewBurst(reqPerSec, burst)
+		} else {
+			c.rateLimit = nil
+		}
+	}
+}
+
 // WithRetryLimit restricts the number of retries (defaults to 5).
 func WithRetryLimit(rl int) Opts {
 	return func(c *Client) {
@@ -216,6 +236,22 @@ func WithTransport(t *http.Transport) Opts {
 	}
 }
 
+// WithTransportWrap wraps the transport with custom middleware, e.g. for tracing, metrics, or request signing.
+// Each wrap function receives the next [http.RoundTripper] and returns a new one.
+// The first wrap is the outermost and sees each request first.
+// Wraps are applied per host after the TLS and protocol settings, so those settings continue to work.
+// Requests for auth tokens also pass through the wrapped transport.
+// Multiple calls append to the list of wraps.
+func WithTransportWrap(wraps ...func(http.RoundTripper) http.RoundTripper) Opts {
+	return func(c *Client) {
+		for _, w := range wraps {
+			if w != nil {
+				c.transportWrap = append(c.transportWrap, w)
+			}
+		}
+	}
+}
+
 // WithUserAgent sets a user agent header.
 func WithUserAgent(ua string) Opts {
 	return func(c *Client) {
@@ -280,6 +316,13 @@ func (resp *Resp) next() error {
 		if ctxErr != nil {
 			return ctxErr
 		}
+		// wait for the client wide rate limit
+		if c.rateLimit != nil {
+			err := c.rateLimit.Wait(resp.ctx, 1)
+			if err != nil {
+				return err
+			}
+		}
 		// wait for other concurrent requests to this host
 		throttleDone, throttleErr := h.throttle.Acquire(resp.ctx, reqmeta.Data{
 			Kind: req.MetaKind,
@@ -390,6 +433,10 @@ func (resp *Resp) next() error {
 						scope = scope + ",push"
 					}
 					_ = hAuth.AddScope(h.config.Hostname, scope)
+					// a cross repository blob mount also needs to pull from the source repository
+					if from := req.Query.Get("from"); from != "" && req.Method == "POST" {
+						_ = hAuth.AddScope(h.config.Hostname, "repository:"+from+":pull")
+					}
 				}
 				// add auth headers
 				err = hAuth.UpdateRequest(httpReq)
@@ -471,14 +518,26 @@ func (resp *Resp) next() error {
 					backoff = true
 					dropHost = true
 				}
-				errHTTP := HTTPError(resp.resp.StatusCode)
 				errBody, _ := io.ReadAll(resp.resp.Body)
 				_ = resp.resp.Body.Close()
-				return fmt.Errorf("request failed: %w: %s", errHTTP, errBody)
+				return fmt.Errorf("request failed: %w", httpErrorResp(resp.resp, errBody))
 			}
 
 			resp.reader = resp.resp.Body
 			resp.done = false
+			// the transport only decompresses when it added the Accept-Encoding header, handle requests that set it directly
+			if !resp.resp.Uncompressed && req.Method != "HEAD" && strings.EqualFold(resp.resp.Header.Get("Content-Encoding"), "gzip") {
+				gzr, err := gzip.NewReader(resp.resp.Body)
+				if err != nil {
+					_ = resp.resp.Body.Close()
+					return fmt.Errorf("failed to decompress response: %w", err)
+				}
+				resp.reader = gzr
+				resp.resp.Header.Del("Content-Encoding")
+				resp.resp.Header.Del("Content-Length")
+				resp.resp.ContentLength = -1
+				resp.resp.Uncompressed = true
+			}
 			// set variables from headers if found
 			clHeader := resp.resp.Header.Get("Content-Length")
 			if resp.readCur == 0 && clHeader != "" {
@@ -751,6 +810,8 @@ func (c *Client) getHost(host string) *clientHost {
 	if h.config.TLS == config.TLSInsecure || len(c.rootCAPool) > 0 || len(c.rootCADirs) > 0 || h.config.RegCert != "" || (h.config.ClientCert != "" && h.config.ClientKey != "") {
 		t, ok := h.httpClient.Transport.(*http.Transport)
 		if ok {
+			// clone to avoid sharing the host specific CA and client certs with other hosts
+			t = t.Clone()
 			var tlsc *tls.Config
 			if t.TLSClientConfig != nil {
 				tlsc = t.TLSClientConfig.Clone()
@@ -782,6 +843,38 @@ func (c *Client) getHost(host string) *clientHost {
 			h.httpClient.Transport = t
 		}
 	}
+	// configure the HTTP protocol version
+	if h.config.Protocol == config.ProtocolHTTP1 || h.config.Protocol == config.ProtocolHTTP2 {
+		t, ok := h.httpClient.Transport.(*http.Transport)
+		if ok {
+			// clone to avoid changing a transport shared with other hosts
+			t = t.Clone()
+			if h.config.Protocol == config.ProtocolHTTP1 {
+				// a non-nil empty map disables HTTP/2 on the transport
+				t.ForceAttemptHTTP2 = false
+				t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+				if t.TLSClientConfig != nil && len(t.TLSClientConfig.NextProtos) > 0 {
+					nextProtos := []string{}
+					for _, proto := range t.TLSClientConfig.NextProtos {
+						if proto != "h2" {
+							nextProtos = append(nextProtos, proto)
+						}
+					}
+					t.TLSClientConfig.NextProtos = nextProtos
+				}
+			} else {
+				t.ForceAttemptHTTP2 = true
+			}
+			h.httpClient.Transport = t
+		} else {
+			c.slog.Warn("failed to configure protocol, transport is not an http.Transport",
+				slog.String("host", h.config.Name))
+		}
+	}
+	// apply any custom middleware, the last wrap is closest to the original transport
+	for i := len(c.transportWrap) - 1; i >= 0; i-- {
+		h.httpClient.Transport = c.transportWrap[i](h.httpClient.Transport)
+	}
 	// wrap the transport for logging and to handle warning headers
 	h.httpClient.Transport = &wrapTransport{c: c, orig: h.httpClient.Transport}
 
@@ -869,11 +962,10 @@ func (wt *wrapTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 			slog.Any("req-headers", reqHead),
 			slog.String("err", err.Error()))
 	} else {
-		// extract any warnings
+		// extract any warnings, the logger includes the registry host for the default hook
 		for _, wh := range resp.Header.Values("Warning") {
 			if match := warnRegexp.FindStringSubmatch(wh); len(match) == 2 {
-				// TODO(bmitch): pass other fields (registry hostname) with structured logging
-				warning.Handle(req.Context(), wt.c.slog, match[1])
+				warning.Handle(req.Context(), wt.c.slog.With(slog.String("host", req.URL.Host)), match[1])
 			}
 		}
 		wt.c.slog.Log(req.Context(), types.LevelTrace, "reg http request",
@@ -887,7 +979,15 @@ func (wt *wrapTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 // HTTPError returns an error based on the status code.
+// The returned error is an [errs.HTTPError] wrapping the error for the status.
 func HTTPError(statusCode int) error {
+	return &errs.HTTPError{
+		StatusCode: statusCode,
+		Err:        httpStatusErr(statusCode),
+	}
+}
+
+func httpStatusErr(statusCode int) error {
 	switch statusCode {
 	case 401:
 		return fmt.Errorf("%w [http %d]", errs.ErrHTTPUnauthorized, statusCode)
@@ -902,6 +1002,27 @@ func HTTPError(statusCode int) error {
 	}
 }
 
+// httpErrorResp returns an [errs.HTTPError] for the response, including the headers and any registry errors from the body.
+func httpErrorResp(resp *http.Response, body []byte) error {
+	errHTTP := &errs.HTTPError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Err:        httpStatusErr(resp.StatusCode),
+	}
+	if len(body) == 0 {
+		return errHTTP
+	}
+	errResp := struct {
+		Errors []errs.RegistryError `json:"errors"`
+	}{}
+	if err := json.Unmarshal(body, &errResp); err != nil || len(errResp.Errors) == 0 {
+		errHTTP.Err = fmt.Errorf("%w: %s", errHTTP.Err, body)
+		return errHTTP
+	}
+	errHTTP.Errors = errResp.Errors
+	return errHTTP
+}
+
 func makeRootPool(rootCAPool [][]byte, rootCADirs []string, hostname string, hostcert string) (*x509.CertPool, error) {
 	pool, err := x509.SystemCertPool()
 	if err != nil {
@@ -3,14 +3,27 @@ package reghttp
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -117,6 +130,7 @@ func TestRegHttp(t *testing.T) {
 	})
 	warnMsg1 := "test warning 1"
 	warnMsg2 := "test warning 2"
+	warnMsg3 := "test warning 3"
 	rrsToken := []reqresp.ReqResp{
 		{
 			ReqEntry: reqresp.ReqEntry{
@@ -466,6 +480,10 @@ func TestRegHttp(t *testing.T) {
 			},
 			RespEntry: reqresp.RespEntry{
 				Status: http.StatusNotFound,
+				Body:   []byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown","detail":{"Tag":"tag-get"}}]}`),
+				Headers: http.Header{
+					"Content-Type": {"application/json"},
+				},
 			},
 		},
 		{
@@ -476,6 +494,7 @@ func TestRegHttp(t *testing.T) {
 			},
 			RespEntry: reqresp.RespEntry{
 				Status: http.StatusForbidden,
+				Body:   []byte(`{"errors":[{"code":"DENIED","message":"requested access to the resource is denied"}]}`),
 			},
 		},
 		{
@@ -724,6 +743,7 @@ func TestRegHttp(t *testing.T) {
 						`299 - "` + warnMsg1 + `"`,
 						`299 - "` + warnMsg2 + `"`,
 						`299 - "` + warnMsg1 + `"`,
+						`299 registry.example.com "` + warnMsg3 + `" "Wed, 21 Oct 2015 07:28:00 GMT"`,
 					},
 				},
 			},
@@ -1456,6 +1476,26 @@ func TestRegHttp(t *testing.T) {
 		} else if !errors.Is(err, errs.ErrNotFound) {
 			t.Errorf("unexpected error, expected %v, received %v", errs.ErrNotFound, err)
 		}
+		var regErr *errs.RegistryError
+		if !errors.As(err, &regErr) {
+			t.Errorf("registry error not found in %v", err)
+		} else if regErr.Code != "MANIFEST_UNKNOWN" || regErr.Message != "manifest unknown" {
+			t.Errorf("unexpected registry error: %v", regErr)
+		}
+		var httpErr *errs.HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Errorf("http error not found in %v", err)
+		} else {
+			if httpErr.StatusCode != http.StatusNotFound {
+				t.Errorf("unexpected status code, expected %d, received %d", http.StatusNotFound, httpErr.StatusCode)
+			}
+			if httpErr.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("unexpected headers: %v", httpErr.Header)
+			}
+			if len(httpErr.Errors) != 1 || httpErr.Errors[0].Code != "MANIFEST_UNKNOWN" {
+				t.Errorf("unexpected registry errors: %v", httpErr.Errors)
+			}
+		}
 	})
 	t.Run("Forbidden", func(t *testing.T) {
 		getReq := &Req{
@@ -1472,6 +1512,12 @@ func TestRegHttp(t *testing.T) {
 		} else if !errors.Is(err, errs.ErrHTTPUnauthorized) {
 			t.Errorf("unexpected error, expected %v, received %v", errs.ErrHTTPUnauthorized, err)
 		}
+		var regErr *errs.RegistryError
+		if !errors.As(err, &regErr) {
+			t.Errorf("registry error not found in %v", err)
+		} else if regErr.Code != "DENIED" {
+			t.Errorf("unexpected registry error: %v", regErr)
+		}
 	})
 	t.Run("Bad GW", func(t *testing.T) {
 		getReq := &Req{
@@ -1607,8 +1653,8 @@ func TestRegHttp(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to run get: %v", err)
 		}
-		if len(w.List) != 2 {
-			t.Errorf("warning count, expected 2, received %d", len(w.List))
+		if len(w.List) != 3 {
+			t.Errorf("warning count, expected 3, received %d", len(w.List))
 		} else {
 			if w.List[0] != warnMsg1 {
 				t.Errorf("warning 1, expected %s, received %s", warnMsg1, w.List[0])
@@ -1616,6 +1662,9 @@ func TestRegHttp(t *testing.T) {
 			if w.List[1] != warnMsg2 {
 				t.Errorf("warning 2, expected %s, received %s", warnMsg2, w.List[1])
 			}
+			if w.List[2] != warnMsg3 {
+				t.Errorf("warning 3, expected %s, received %s", warnMsg3, w.List[2])
+			}
 		}
 		err = resp.Close()
 		if err != nil {
@@ -1711,5 +1760,331 @@ func TestRegHttp(t *testing.T) {
 			t.Errorf("requests finished faster than expected time, expected %s, received %s", expectMin.String(), dur.String())
 		}
 	})
+	t.Run("rate-limit", func(t *testing.T) {
+		hcLimit := NewClient(
+			WithConfigHostFn(func(name string) *config.Host {
+				if configHosts[name] == nil {
+					configHosts[name] = config.HostNewName(name)
+				}
+				return configHosts[name]
+			}),
+			WithRateLimit(20, 2),
+			WithUserAgent(useragent),
+		)
+		getReq := &Req{
+			Host:       tsHost,
+			Method:     "GET",
+			Repository: "project",
+			Path:       "manifests/tag-get",
+			Headers:    headers,
+		}
+		start := time.Now()
+		count := 6
+		for i := 0; i < count; i++ {
+			resp, err := hcLimit.Do(ctx, getReq)
+			if err != nil {
+				t.Fatalf("failed to run get: %v", err)
+			}
+			_, _ = io.Copy(io.Discard, resp)
+			_ = resp.Close()
+		}
+		// first 2 requests use the burst, remaining requests are released at 50ms each
+		dur := time.Since(start)
+		expectMin := (time.Second / 20) * time.Duration(count-2)
+		if dur < expectMin {
+			t.Errorf("requests finished faster than expected time, expected %s, received %s", expectMin.String(), dur.String())
+		}
+		// a canceled context stops the wait for a token
+		ctxCancel, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := hcLimit.Do(ctxCancel, getReq)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error, expected %v, received %v", context.Canceled, err)
+		}
+	})
 	// TODO: test various TLS configs (custom root for all hosts, custom root for one host, insecure)
 }
+
+func TestProtocol(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Proto", req.Proto)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	tests := []struct {
+		name        string
+		protocol    config.ProtocolConf
+		expectMajor int
+	}{
+		{
+			name:        "default",
+			expectMajor: 2,
+		},
+		{
+			name:        "auto",
+			protocol:    config.ProtocolAuto,
+			expectMajor: 2,
+		},
+		{
+			name:        "http1",
+			protocol:    config.ProtocolHTTP1,
+			expectMajor: 1,
+		},
+		{
+			name:        "http2",
+			protocol:    config.ProtocolHTTP2,
+			expectMajor: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hc := NewClient(
+				WithConfigHostFn(func(name string) *config.Host {
+					return &config.Host{
+						Name:     name,
+						Hostname: tsHost,
+						TLS:      config.TLSInsecure,
+						Protocol: tc.protocol,
+					}
+				}),
+			)
+			resp, err := hc.Do(ctx, &Req{
+				Host:       tsHost,
+				Method:     "GET",
+				Repository: "project",
+				Path:       "tags/list",
+			})
+			if err != nil {
+				t.Fatalf("failed to run request: %v", err)
+			}
+			_ = resp.Close()
+			if resp.HTTPResponse().ProtoMajor != tc.expectMajor {
+				t.Errorf("unexpected protocol, expected major version %d, received %s, server received %s",
+					tc.expectMajor, resp.HTTPResponse().Proto, resp.HTTPResponse().Header.Get("X-Proto"))
+			}
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransportWrap(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Signed", req.Header.Get("X-Signed"))
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	var mu sync.Mutex
+	order := []string{}
+	wrapNamed := func(name string) func(http.RoundTripper) http.RoundTripper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				if req.Header.Get("X-Signed") == "" {
+					req = req.Clone(req.Context())
+					req.Header.Set("X-Signed", name)
+				}
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	hc := NewClient(
+		WithConfigHostFn(func(name string) *config.Host {
+			return &config.Host{
+				Name:     name,
+				Hostname: tsHost,
+				TLS:      config.TLSInsecure,
+			}
+		}),
+		WithTransportWrap(wrapNamed("outer"), nil),
+		WithTransportWrap(wrapNamed("inner")),
+	)
+	resp, err := hc.Do(ctx, &Req{
+		Host:       tsHost,
+		Method:     "GET",
+		Repository: "project",
+		Path:       "tags/list",
+	})
+	if err != nil {
+		t.Fatalf("failed to run request: %v", err)
+	}
+	_ = resp.Close()
+	if resp.HTTPResponse().Header.Get("X-Signed") != "outer" {
+		t.Errorf("request was not modified by the outer wrap, received %s", resp.HTTPResponse().Header.Get("X-Signed"))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(order, []string{"outer", "inner"}) {
+		t.Errorf("unexpected wrap order: %v", order)
+	}
+}
+
+func TestMountScope(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// the token server records the requested scopes
+	var mu sync.Mutex
+	scopes := []string{}
+	tsToken := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		mu.Lock()
+		for _, s := range req.Form["scope"] {
+			scopes = append(scopes, strings.Fields(s)...)
+		}
+		mu.Unlock()
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`{"token":"mount-token","expires_in":900}`))
+	}))
+	defer tsToken.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer mount-token" {
+			rw.Header().Set("WWW-Authenticate", `Bearer realm="`+tsToken.URL+`/token",service=test,scope="repository:project2:pull,push"`)
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	hc := NewClient(
+		WithConfigHostFn(func(name string) *config.Host {
+			return &config.Host{
+				Name:     name,
+				Hostname: tsHost,
+				TLS:      config.TLSDisabled,
+			}
+		}),
+	)
+	resp, err := hc.Do(ctx, &Req{
+		Host:       tsHost,
+		Method:     "POST",
+		Repository: "project2",
+		Path:       "blobs/uploads/",
+		Query: url.Values{
+			"mount": {"sha256:" + strings.Repeat("0", 64)},
+			"from":  {"project"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to run request: %v", err)
+	}
+	_ = resp.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	for _, expect := range []string{"repository:project2:pull,push", "repository:project:pull"} {
+		if !slices.Contains(scopes, expect) {
+			t.Errorf("scope %s not requested, received %v", expect, scopes)
+		}
+	}
+}
+
+// testTLSServer starts a TLS server with a certificate signed by a new CA, returning the server and the CA in PEM format.
+func testTLSServer(t *testing.T, handler http.Handler) (*httptest.Server, string) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDer)
+	if err != nil {
+		t.Fatalf("failed to parse CA: %v", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create cert: %v", err)
+	}
+	ts := httptest.NewUnstartedServer(handler)
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer}))
+}
+
+func TestRegCertPerHost(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	tsA, caA := testTLSServer(t, handler)
+	tsB, caB := testTLSServer(t, handler)
+	hostA := strings.TrimPrefix(tsA.URL, "https://")
+	hostB := strings.TrimPrefix(tsB.URL, "https://")
+	// hostBad is served by tsB but configured with the CA of tsA
+	hostBad := "bad.example.com"
+	hc := NewClient(
+		// a shared transport must not leak the CA of one host to another
+		WithTransport(http.DefaultTransport.(*http.Transport).Clone()),
+		WithRetryLimit(1),
+		WithDelay(time.Millisecond, time.Millisecond),
+		WithConfigHostFn(func(name string) *config.Host {
+			switch name {
+			case hostA:
+				return &config.Host{Name: name, Hostname: hostA, RegCert: caA}
+			case hostB:
+				return &config.Host{Name: name, Hostname: hostB, RegCert: caB}
+			default:
+				return &config.Host{Name: name, Hostname: hostB, RegCert: caA}
+			}
+		}),
+	)
+	for _, host := range []string{hostA, hostB} {
+		resp, err := hc.Do(ctx, &Req{Host: host, Method: "GET", NoMirrors: true})
+		if err != nil {
+			t.Errorf("request to %s failed: %v", host, err)
+			continue
+		}
+		_ = resp.Close()
+	}
+	resp, err := hc.Do(ctx, &Req{Host: hostBad, Method: "GET", NoMirrors: true})
+	if err == nil {
+		_ = resp.Close()
+		t.Errorf("request with the wrong CA did not fail")
+	}
+}
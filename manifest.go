@@ -2,9 +2,12 @@ package regclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
+	"github.com/opencontainers/go-digest"
+
 	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/types/descriptor"
 	"github.com/regclient/regclient/types/errs"
@@ -16,7 +19,9 @@ import (
 
 type manifestOpt struct {
 	d             descriptor.Descriptor
+	noOverwrite   bool
 	platform      *platform.Platform
+	pushHook      PushHook
 	schemeOpts    []scheme.ManifestOpts
 	requireDigest bool
 }
@@ -24,6 +29,11 @@ type manifestOpt struct {
 // ManifestOpts define options for the Manifest* commands.
 type ManifestOpts func(*manifestOpt)
 
+// PushHook is called after a manifest is pushed with the reference and descriptor of the pushed manifest.
+// The descriptor includes the digest, which may not be set in the ref when pushing a tag.
+// This can be used to trigger a registry side scan or notify a webhook.
+type PushHook func(ctx context.Context, r ref.Ref, d descriptor.Descriptor) error
+
 // WithManifest passes a manifest to ManifestDelete.
 func WithManifest(m manifest.Manifest) ManifestOpts {
 	return func(opts *manifestOpt) {
@@ -55,6 +65,15 @@ func WithManifestDesc(d descriptor.Descriptor) ManifestOpts {
 	}
 }
 
+// WithManifestNoOverwrite prevents ManifestPut from changing an existing tag.
+// If the tag exists with a different digest, ErrTagExists is returned.
+// Pushing the same content to an existing tag is allowed.
+func WithManifestNoOverwrite() ManifestOpts {
+	return func(opts *manifestOpt) {
+		opts.noOverwrite = true
+	}
+}
+
 // WithManifestPlatform resolves the platform specific manifest on Get and Head requests.
 // This causes an additional GET query to a registry when an Index or Manifest List is encountered.
 // This option is ignored if the retrieved manifest is not an Index or Manifest List.
@@ -64,6 +83,14 @@ func WithManifestPlatform(p platform.Platform) ManifestOpts {
 	}
 }
 
+// WithManifestPushHook calls hook after a successful ManifestPut.
+// An error from the hook is returned by ManifestPut, after the manifest has been pushed.
+func WithManifestPushHook(hook PushHook) ManifestOpts {
+	return func(opts *manifestOpt) {
+		opts.pushHook = hook
+	}
+}
+
 // WithManifestRequireDigest falls back from a HEAD to a GET request when digest headers aren't received.
 func WithManifestRequireDigest() ManifestOpts {
 	return func(opts *manifestOpt) {
@@ -190,6 +217,8 @@ func (rc *RegClient) ManifestHead(ctx context.Context, r ref.Ref, opts ...Manife
 
 // ManifestPut pushes a manifest.
 // Any descriptors referenced by the manifest typically need to be pushed first.
+// For an OCI index or Docker manifest list, that includes each child manifest.
+// ImageCopy pushes the child manifests before the index.
 func (rc *RegClient) ManifestPut(ctx context.Context, r ref.Ref, m manifest.Manifest, opts ...ManifestOpts) error {
 	if !r.IsSetRepo() {
 		return fmt.Errorf("ref is not set: %s%.0w", r.CommonName(), errs.ErrInvalidReference)
@@ -202,5 +231,104 @@ func (rc *RegClient) ManifestPut(ctx context.Context, r ref.Ref, m manifest.Mani
 	if err != nil {
 		return err
 	}
-	return schemeAPI.ManifestPut(ctx, r, m, opt.schemeOpts...)
+	if opt.noOverwrite && r.Tag != "" {
+		err = rc.manifestTagCheck(ctx, r.SetTag(r.Tag), m.GetDescriptor().Digest)
+		if err != nil {
+			return err
+		}
+	}
+	err = schemeAPI.ManifestPut(ctx, r, m, opt.schemeOpts...)
+	if err != nil || opt.pushHook == nil {
+		return err
+	}
+	d := m.GetDescriptor()
+	err = opt.pushHook(ctx, r, d)
+	if err != nil {
+		return fmt.Errorf("push hook failed for %s: %w", r.CommonName(), err)
+	}
+	return nil
+}
+
+// ResolveRef returns the reference pinned to the digest of the manifest, keeping any tag.
+// The CommonName of the result includes both, e.g. "docker.io/library/alpine:3.14@sha256:...".
+// When the reference already includes a digest, the manifest is verified to exist.
+func (rc *RegClient) ResolveRef(ctx context.Context, r ref.Ref) (ref.Ref, error) {
+	if !r.IsSet() {
+		return r, fmt.Errorf("ref is not set: %s%.0w", r.CommonName(), errs.ErrInvalidReference)
+	}
+	mh, err := rc.ManifestHead(ctx, r, WithManifestRequireDigest())
+	if err != nil {
+		return r, fmt.Errorf("failed to resolve %s: %w", r.CommonName(), err)
+	}
+	rOut := r
+	rOut.Digest = mh.GetDescriptor().Digest.String()
+	if r.Digest != "" && r.Digest != rOut.Digest {
+		return r, fmt.Errorf("digest mismatch for %s, received %s%.0w", r.CommonName(), rOut.Digest, errs.ErrDigestMismatch)
+	}
+	return rOut, nil
+}
+
+// ResolvedPlatform contains the digests of a reference resolved to a single platform.
+type ResolvedPlatform struct {
+	// Ref is the reference pinned to the digest the tag points to.
+	ref.Ref
+	// Index is the descriptor of the index or manifest list, the digest is empty for a single platform image.
+	Index descriptor.Descriptor
+	// Manifest is the descriptor of the platform specific manifest.
+	Manifest descriptor.Descriptor
+}
+
+// PlatformRef returns the reference pinned to the platform specific manifest digest.
+func (rp ResolvedPlatform) PlatformRef() ref.Ref {
+	return rp.Ref.SetDigest(rp.Manifest.Digest.String())
+}
+
+// ResolvePlatform resolves a reference to both the digest the tag points to and the digest of the platform specific manifest.
+// A nested index is followed until an image manifest is found, the Index descriptor is the top level index.
+func (rc *RegClient) ResolvePlatform(ctx context.Context, r ref.Ref, p platform.Platform) (ResolvedPlatform, error) {
+	result := ResolvedPlatform{Ref: r}
+	if !r.IsSet() {
+		return result, fmt.Errorf("ref is not set: %s%.0w", r.CommonName(), errs.ErrInvalidReference)
+	}
+	m, err := rc.ManifestGet(ctx, r)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve %s: %w", r.CommonName(), err)
+	}
+	d := m.GetDescriptor()
+	if r.Digest != "" && r.Digest != d.Digest.String() {
+		return result, fmt.Errorf("digest mismatch for %s, received %s%.0w", r.CommonName(), d.Digest.String(), errs.ErrDigestMismatch)
+	}
+	result.Ref.Digest = d.Digest.String()
+	if m.IsList() {
+		result.Index = d
+	}
+	rPlat := result.Ref
+	for m.IsList() {
+		dp, err := manifest.GetPlatformDesc(m, &p)
+		if err != nil {
+			return result, fmt.Errorf("failed to resolve platform %s for %s: %w", p.String(), r.CommonName(), err)
+		}
+		rPlat = rPlat.SetDigest(dp.Digest.String())
+		m, err = rc.ManifestGet(ctx, rPlat, WithManifestDesc(*dp))
+		if err != nil {
+			return result, fmt.Errorf("failed to resolve %s: %w", rPlat.CommonName(), err)
+		}
+		d = *dp
+	}
+	result.Manifest = d
+	return result, nil
+}
+
+// manifestTagCheck returns ErrTagExists if the tag exists and does not match the digest.
+func (rc *RegClient) manifestTagCheck(ctx context.Context, r ref.Ref, dig digest.Digest) error {
+	mh, err := rc.ManifestHead(ctx, r, WithManifestRequireDigest())
+	if err != nil && errors.Is(err, errs.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to check existing tag %s: %w", r.CommonName(), err)
+	}
+	if mh.GetDescriptor().Digest != dig {
+		return fmt.Errorf("tag %s has digest %s, refusing to overwrite with %s%.0w", r.CommonName(), mh.GetDescriptor().Digest.String(), dig.String(), errs.ErrTagExists)
+	}
+	return nil
 }
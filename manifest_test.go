@@ -11,6 +11,8 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -477,3 +479,264 @@ func TestManifest(t *testing.T) {
 
 	})
 }
+
+func TestResolveRef(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://./testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mh, err := rc.ManifestHead(ctx, r, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head manifest: %v", err)
+	}
+	dig := mh.GetDescriptor().Digest.String()
+	rOut, err := rc.ResolveRef(ctx, r)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if rOut.Tag != "v1" || rOut.Digest != dig {
+		t.Errorf("unexpected ref, expected tag v1 and digest %s, received %s", dig, rOut.CommonName())
+	}
+	if rOut.CommonName() != "ocidir://./testdata/testrepo:v1@"+dig {
+		t.Errorf("unexpected common name: %s", rOut.CommonName())
+	}
+	_, err = rc.ResolveRef(ctx, r.SetTag("missing"))
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("unexpected error, expected %v, received %v", errs.ErrNotFound, err)
+	}
+}
+
+func TestResolvePlatform(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rc := New()
+	r, err := ref.New("ocidir://./testdata/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	p, err := platform.Parse("linux/arm64")
+	if err != nil {
+		t.Fatalf("failed to parse platform: %v", err)
+	}
+	mi, err := rc.ManifestHead(ctx, r, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head index: %v", err)
+	}
+	mp, err := rc.ManifestHead(ctx, r, WithManifestPlatform(p), WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head platform: %v", err)
+	}
+	rp, err := rc.ResolvePlatform(ctx, r, p)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if rp.Index.Digest != mi.GetDescriptor().Digest {
+		t.Errorf("unexpected index digest, expected %s, received %s", mi.GetDescriptor().Digest, rp.Index.Digest)
+	}
+	if rp.Manifest.Digest != mp.GetDescriptor().Digest {
+		t.Errorf("unexpected platform digest, expected %s, received %s", mp.GetDescriptor().Digest, rp.Manifest.Digest)
+	}
+	if rp.Tag != "v1" || rp.Digest != mi.GetDescriptor().Digest.String() {
+		t.Errorf("unexpected ref: %s", rp.CommonName())
+	}
+	if rp.PlatformRef().Digest != mp.GetDescriptor().Digest.String() {
+		t.Errorf("unexpected platform ref: %s", rp.PlatformRef().CommonName())
+	}
+	// resolving a platform specific manifest returns an empty index
+	rp2, err := rc.ResolvePlatform(ctx, rp.PlatformRef(), p)
+	if err != nil {
+		t.Fatalf("failed to resolve platform ref: %v", err)
+	}
+	if rp2.Index.Digest != "" || rp2.Manifest.Digest != rp.Manifest.Digest {
+		t.Errorf("unexpected result resolving platform ref, index %s, manifest %s", rp2.Index.Digest, rp2.Manifest.Digest)
+	}
+	_, err = rc.ResolvePlatform(ctx, r, platform.Platform{OS: "linux", Architecture: "s390x"})
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("unexpected error, expected %v, received %v", errs.ErrNotFound, err)
+	}
+}
+
+func TestManifestPutIndex(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	olaregHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	ts := httptest.NewServer(olaregHandler)
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	t.Cleanup(func() {
+		ts.Close()
+		_ = olaregHandler.Close()
+	})
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	rc := New(
+		WithConfigHost(config.Host{
+			Name:     tsHost,
+			Hostname: tsHost,
+			TLS:      config.TLSDisabled,
+		}),
+		WithSlog(log),
+	)
+	r, err := ref.New(tsHost + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mSrc, err := rc.ManifestGet(ctx, r)
+	if err != nil {
+		t.Fatalf("failed to get manifest: %v", err)
+	}
+	mi, ok := mSrc.(manifest.Indexer)
+	if !ok {
+		t.Fatalf("source is not an index: %s", mSrc.GetDescriptor().MediaType)
+	}
+	children, err := mi.GetManifestList()
+	if err != nil {
+		t.Fatalf("failed to get manifest list: %v", err)
+	}
+	rMissing, err := ref.New(tsHost + "/missing:put-oci")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mDocker, err := manifest.New(manifest.WithOrig(schema2.ManifestList{
+		Versioned: schema2.ManifestListSchemaVersion,
+		Manifests: children,
+	}))
+	if err != nil {
+		t.Fatalf("failed to create manifest list: %v", err)
+	}
+	tests := []struct {
+		name      string
+		r         ref.Ref
+		m         manifest.Manifest
+		expectErr bool
+	}{
+		{
+			name: "oci index",
+			r:    r.SetTag("put-oci"),
+			m:    mSrc,
+		},
+		{
+			name: "docker manifest list",
+			r:    r.SetTag("put-docker"),
+			m:    mDocker,
+		},
+		{
+			name:      "missing children",
+			r:         rMissing,
+			m:         mSrc,
+			expectErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := rc.ManifestPut(ctx, tc.r, tc.m)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("put did not fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to put manifest: %v", err)
+			}
+			mGet, err := rc.ManifestGet(ctx, tc.r)
+			if err != nil {
+				t.Fatalf("failed to get manifest: %v", err)
+			}
+			if mGet.GetDescriptor().MediaType != tc.m.GetDescriptor().MediaType {
+				t.Errorf("unexpected media type, expected %s, received %s", tc.m.GetDescriptor().MediaType, mGet.GetDescriptor().MediaType)
+			}
+			if mGet.GetDescriptor().Digest != tc.m.GetDescriptor().Digest {
+				t.Errorf("unexpected digest, expected %s, received %s", tc.m.GetDescriptor().Digest, mGet.GetDescriptor().Digest)
+			}
+		})
+	}
+}
+
+func TestManifestTagDigest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	// track the manifest requests sent to the registry
+	var mu sync.Mutex
+	manifestPaths := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "/manifests/") {
+			mu.Lock()
+			manifestPaths = append(manifestPaths, req.URL.Path)
+			mu.Unlock()
+		}
+		regHandler.ServeHTTP(w, req)
+	}))
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	for _, repoName := range []string{tsHost + "/testrepo", "ocidir://./testdata/testrepo"} {
+		t.Run(repoName, func(t *testing.T) {
+			rV1, err := ref.New(repoName + ":v1")
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			mV1, err := rc.ManifestHead(ctx, rV1, WithManifestRequireDigest())
+			if err != nil {
+				t.Fatalf("failed to head v1: %v", err)
+			}
+			dV1 := mV1.GetDescriptor().Digest
+			// the tag is informational, content is pulled by the digest
+			r, err := ref.New(repoName + ":v2@" + dV1.String())
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			if r.Tag != "v2" || r.Digest != dV1.String() {
+				t.Fatalf("tag and digest not parsed, tag %s, digest %s", r.Tag, r.Digest)
+			}
+			if r.CommonName() != repoName+":v2@"+dV1.String() {
+				t.Errorf("unexpected common name %s", r.CommonName())
+			}
+			mu.Lock()
+			manifestPaths = []string{}
+			mu.Unlock()
+			mh, err := rc.ManifestHead(ctx, r)
+			if err != nil {
+				t.Fatalf("failed to head: %v", err)
+			}
+			if mh.GetDescriptor().Digest != dV1 {
+				t.Errorf("unexpected head digest, expected %s, received %s", dV1, mh.GetDescriptor().Digest)
+			}
+			m, err := rc.ManifestGet(ctx, r)
+			if err != nil {
+				t.Fatalf("failed to get: %v", err)
+			}
+			if m.GetDescriptor().Digest != dV1 {
+				t.Errorf("unexpected get digest, expected %s, received %s", dV1, m.GetDescriptor().Digest)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, p := range manifestPaths {
+				if !strings.HasSuffix(p, "/manifests/"+dV1.String()) {
+					t.Errorf("manifest request did not use the digest: %s", p)
+				}
+			}
+		})
+	}
+}
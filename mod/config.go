@@ -73,6 +73,30 @@ func WithConfigCmd(cmd []string) Opts {
 	}
 }
 
+// WithConfigCreated sets the created timestamp in the config.
+// A zero time uses the current time, or SOURCE_DATE_EPOC when defined.
+// Without this option, the created timestamp from the source image is preserved.
+// History entries are not modified, see [WithConfigTimestamp] to adjust those.
+func WithConfigCreated(t time.Time) Opts {
+	if t.IsZero() {
+		t = timeStart
+	}
+	return func(dc *dagConfig, dm *dagManifest) error {
+		dc.stepsOCIConfig = append(dc.stepsOCIConfig, func(c context.Context, rc *regclient.RegClient, rSrc, rTgt ref.Ref, doc *dagOCIConfig) error {
+			oc := doc.oc.GetConfig()
+			if oc.Created != nil && oc.Created.Equal(t) {
+				return nil
+			}
+			oc.Created = &t
+			doc.oc.SetConfig(oc)
+			doc.newDesc = doc.oc.GetDescriptor()
+			doc.modified = true
+			return nil
+		})
+		return nil
+	}
+}
+
 // WithConfigDigestAlgo changes the digest algorithm.
 func WithConfigDigestAlgo(algo digest.Algorithm) Opts {
 	return func(dc *dagConfig, dm *dagManifest) error {
@@ -2,6 +2,7 @@ package mod
 
 import (
 	"archive/tar"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -78,7 +79,7 @@ func WithLayerAddTar(rdr io.Reader, mt string, platforms []platform.Platform) Op
 				}
 				digUC := desc.DigestAlgo().Digester() // uncompressed digest
 				ucDigRdr := io.TeeReader(rdr, digUC.Hash())
-				cRdr, err := archive.Compress(ucDigRdr, comp)
+				cRdr, err := archive.Compress(ucDigRdr, comp, archive.CompressWithGzipLevel(dc.gzipLevel))
 				if err != nil {
 					return fmt.Errorf("failed to compress layer with %s: %w", comp.String(), err)
 				}
@@ -119,6 +120,11 @@ func WithLayerCompression(algo archive.CompressType) Opts {
 			if dl.newDesc.MediaType != "" {
 				desc = dl.newDesc
 			}
+			// decompress using the compression declared by the current media type
+			dOpts := []archive.DecompressOpts{}
+			if ct, ok := archive.MediaTypeCompression(desc.MediaType); ok {
+				dOpts = append(dOpts, archive.DecompressWithType(ct))
+			}
 			desc.Size = 0
 			err := desc.DigestAlgoPrefer(desc.DigestAlgo())
 			if err != nil {
@@ -141,13 +147,13 @@ func WithLayerCompression(algo archive.CompressType) Opts {
 				dl.newDesc = desc
 				digRaw := desc.DigestAlgo().Digester() // raw/compressed digest
 				digUC := desc.DigestAlgo().Digester()  // uncompressed digest
-				ucRdr, err := archive.Decompress(rdr)
+				ucRdr, err := archive.Decompress(rdr, dOpts...)
 				if err != nil {
 					_ = rdr.Close()
 					return nil, err
 				}
 				ucDigRdr := io.TeeReader(ucRdr, digUC.Hash())
-				cRdr, err := archive.Compress(ucDigRdr, algo)
+				cRdr, err := archive.Compress(ucDigRdr, algo, archive.CompressWithGzipLevel(dc.gzipLevel))
 				if err != nil {
 					_ = rdr.Close()
 					return nil, err
@@ -181,13 +187,13 @@ func WithLayerCompression(algo archive.CompressType) Opts {
 				dl.newDesc = desc
 				digRaw := desc.DigestAlgo().Digester() // raw/compressed digest
 				digUC := desc.DigestAlgo().Digester()  // uncompressed digest
-				ucRdr, err := archive.Decompress(rdr)
+				ucRdr, err := archive.Decompress(rdr, dOpts...)
 				if err != nil {
 					_ = rdr.Close()
 					return nil, err
 				}
 				ucDigRdr := io.TeeReader(ucRdr, digUC.Hash())
-				cRdr, err := archive.Compress(ucDigRdr, algo)
+				cRdr, err := archive.Compress(ucDigRdr, algo, archive.CompressWithGzipLevel(dc.gzipLevel))
 				if err != nil {
 					_ = rdr.Close()
 					return nil, err
@@ -220,7 +226,7 @@ func WithLayerCompression(algo archive.CompressType) Opts {
 				}
 				dl.newDesc = desc
 				dig := desc.DigestAlgo().Digester()
-				ucRdr, err := archive.Decompress(rdr)
+				ucRdr, err := archive.Decompress(rdr, dOpts...)
 				if err != nil {
 					_ = rdr.Close()
 					return nil, err
@@ -246,6 +252,19 @@ func WithLayerCompression(algo archive.CompressType) Opts {
 	}
 }
 
+// WithLayerGzipLevel sets the gzip compression level for any layers that are recompressed.
+// The default is the level of the RegClient, see [regclient.WithGzipLevel].
+// Valid values range from [gzip.HuffmanOnly] to [gzip.BestCompression].
+func WithLayerGzipLevel(level int) Opts {
+	return func(dc *dagConfig, dm *dagManifest) error {
+		if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			return fmt.Errorf("invalid gzip compression level: %d", level)
+		}
+		dc.gzipLevel = level
+		return nil
+	}
+}
+
 // WithLayerDigestAlgo changes the digester algorithm.
 func WithLayerDigestAlgo(algo digest.Algorithm) Opts {
 	return func(dc *dagConfig, dm *dagManifest) error {
@@ -3,6 +3,7 @@ package mod
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/opencontainers/go-digest"
@@ -289,6 +290,90 @@ func WithLabelToAnnotation() Opts {
 	}
 }
 
+// WithNameReplace rewrites the values of the listed label and annotation keys, e.g. org.opencontainers.image.source.
+// Each key in names found in a value is replaced with the map value, e.g. {"github.com/old/app": "github.com/new/app"}.
+// Longer names are replaced first, and replacement is applied to the config labels and manifest annotations of every platform.
+// Layers are not modified.
+func WithNameReplace(keys []string, names map[string]string) Opts {
+	// sort the names from longest to shortest so the most specific match is replaced
+	oldNames := make([]string, 0, len(names))
+	for name := range names {
+		oldNames = append(oldNames, name)
+	}
+	slices.SortFunc(oldNames, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+	pairs := make([]string, 0, len(oldNames)*2)
+	for _, name := range oldNames {
+		pairs = append(pairs, name, names[name])
+	}
+	replacer := strings.NewReplacer(pairs...)
+	return func(dc *dagConfig, dm *dagManifest) error {
+		if len(keys) == 0 || len(names) == 0 {
+			return fmt.Errorf("WithNameReplace requires keys and names")
+		}
+		dc.stepsOCIConfig = append(dc.stepsOCIConfig, func(c context.Context, rc *regclient.RegClient, rSrc, rTgt ref.Ref, doc *dagOCIConfig) error {
+			changed := false
+			oc := doc.oc.GetConfig()
+			for _, key := range keys {
+				cur, ok := oc.Config.Labels[key]
+				if !ok {
+					continue
+				}
+				if value := replacer.Replace(cur); value != cur {
+					oc.Config.Labels[key] = value
+					changed = true
+				}
+			}
+			if changed {
+				doc.oc.SetConfig(oc)
+				doc.modified = true
+				doc.newDesc = doc.oc.GetDescriptor()
+			}
+			return nil
+		})
+		dc.stepsManifest = append(dc.stepsManifest, func(c context.Context, rc *regclient.RegClient, rSrc, rTgt ref.Ref, dm *dagManifest) error {
+			if dm.mod == deleted {
+				return nil
+			}
+			ma, ok := dm.m.(manifest.Annotator)
+			if !ok {
+				return nil
+			}
+			annotations, err := ma.GetAnnotations()
+			if err != nil {
+				return err
+			}
+			changed := false
+			for _, key := range keys {
+				cur, ok := annotations[key]
+				if !ok {
+					continue
+				}
+				if value := replacer.Replace(cur); value != cur {
+					err = ma.SetAnnotation(key, value)
+					if err != nil {
+						return err
+					}
+					changed = true
+				}
+			}
+			if !changed {
+				return nil
+			}
+			if dm.mod == unchanged {
+				dm.mod = replaced
+			}
+			dm.newDesc = dm.m.GetDescriptor()
+			return nil
+		})
+		return nil
+	}
+}
+
 // WithManifestDigestAlgo changes the digester algorithm.
 func WithManifestDigestAlgo(algo digest.Algorithm) Opts {
 	return func(dc *dagConfig, dm *dagManifest) error {
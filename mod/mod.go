@@ -74,6 +74,7 @@ func Apply(ctx context.Context, rc *regclient.RegClient, rSrc ref.Ref, opts ...O
 		stepsLayer:     []func(context.Context, *regclient.RegClient, ref.Ref, ref.Ref, *dagLayer, io.ReadCloser) (io.ReadCloser, error){},
 		stepsLayerFile: []func(context.Context, *regclient.RegClient, ref.Ref, ref.Ref, *dagLayer, *tar.Header, io.Reader) (*tar.Header, io.Reader, changes, error){},
 		maxDataSize:    -1, // unchanged, if a data field exists, preserve it
+		gzipLevel:      rc.GzipLevel(),
 		rTgt:           rTgt,
 	}
 	for _, opt := range opts {
@@ -192,7 +193,11 @@ func Apply(ctx context.Context, rc *regclient.RegClient, rSrc ref.Ref, opts ...O
 				digUC := desc.DigestAlgo().Digester()  // uncompressed digest
 				if dl.desc.MediaType == mediatype.Docker2LayerGzip || dl.desc.MediaType == mediatype.OCI1LayerGzip {
 					cw := io.MultiWriter(fh, digRaw.Hash())
-					gw = gzip.NewWriter(cw)
+					gw, err = gzip.NewWriterLevel(cw, dc.gzipLevel)
+					if err != nil {
+						_ = rdr.Close()
+						return nil, err
+					}
 					defer gw.Close()
 					ucw := io.MultiWriter(gw, digUC.Hash())
 					tw = tar.NewWriter(ucw)
@@ -2,6 +2,7 @@ package mod
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -403,6 +404,20 @@ func TestMod(t *testing.T) {
 			ref:     tTgtHost + "/testrepo:v1",
 			wantErr: fmt.Errorf("label not found: org.opencontainers.image.created"),
 		},
+		{
+			name: "Config Created Set",
+			opts: []Opts{
+				WithConfigCreated(baseTime),
+			},
+			ref: tTgtHost + "/testrepo:v1",
+		},
+		{
+			name: "Config Created Now",
+			opts: []Opts{
+				WithConfigCreated(time.Time{}),
+			},
+			ref: tTgtHost + "/testrepo:v1",
+		},
 		{
 			name: "Config Digest sha256",
 			opts: []Opts{
@@ -591,6 +606,23 @@ func TestMod(t *testing.T) {
 			},
 			ref: tTgtHost + "/testrepo:v1",
 		},
+		{
+			name: "Layer Compressed gzip best speed",
+			opts: []Opts{
+				WithLayerCompression(archive.CompressNone),
+				WithLayerCompression(archive.CompressGzip),
+				WithLayerGzipLevel(gzip.BestSpeed),
+			},
+			ref: tTgtHost + "/testrepo:v1",
+		},
+		{
+			name: "Layer Compressed gzip invalid level",
+			opts: []Opts{
+				WithLayerGzipLevel(12),
+			},
+			ref:     tTgtHost + "/testrepo:v1",
+			wantErr: fmt.Errorf("invalid gzip compression level: 12"),
+		},
 		{
 			name: "Layer Digest sha256",
 			opts: []Opts{
@@ -1089,6 +1121,146 @@ func TestMod(t *testing.T) {
 			}
 		})
 	}
+	t.Run("Name Replace", func(t *testing.T) {
+		key := "org.opencontainers.image.source"
+		rSrc, err := ref.New(tTgtHost + "/testrepo:v1")
+		if err != nil {
+			t.Fatalf("failed creating ref: %v", err)
+		}
+		rOld, err := ref.New(tTgtHost + "/rename:old")
+		if err != nil {
+			t.Fatalf("failed creating ref: %v", err)
+		}
+		rNew, err := ref.New(tTgtHost + "/rename:new")
+		if err != nil {
+			t.Fatalf("failed creating ref: %v", err)
+		}
+		rOld, err = Apply(ctx, rc, rSrc, WithRefTgt(rOld),
+			WithLabel(key, "https://github.com/example/old-app"),
+			WithAnnotation("[*]"+key, "https://github.com/example/old-app"))
+		if err != nil {
+			t.Fatalf("failed to setup image: %v", err)
+		}
+		_, err = Apply(ctx, rc, rOld, WithRefTgt(rNew), WithNameReplace([]string{key}, map[string]string{
+			"github.com/example/old":     "github.com/example/wrong",
+			"github.com/example/old-app": "github.com/example/new-app",
+		}))
+		if err != nil {
+			t.Fatalf("failed to replace names: %v", err)
+		}
+		expect := "https://github.com/example/new-app"
+		m, err := rc.ManifestGet(ctx, rNew)
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		annotations, err := m.(manifest.Annotator).GetAnnotations()
+		if err != nil {
+			t.Fatalf("failed to get annotations: %v", err)
+		}
+		if annotations[key] != expect {
+			t.Errorf("unexpected annotation, expected %s, received %s", expect, annotations[key])
+		}
+		conf, err := rc.ImageConfig(ctx, rNew, regclient.ImageWithPlatform("linux/amd64"))
+		if err != nil {
+			t.Fatalf("failed to get config: %v", err)
+		}
+		if label := conf.GetConfig().Config.Labels[key]; label != expect {
+			t.Errorf("unexpected label, expected %s, received %s", expect, label)
+		}
+		// layers are unchanged
+		pAMD64 := platform.Platform{OS: "linux", Architecture: "amd64"}
+		mOld, err := rc.ManifestGet(ctx, rOld, regclient.WithManifestPlatform(pAMD64))
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		mNew, err := rc.ManifestGet(ctx, rNew, regclient.WithManifestPlatform(pAMD64))
+		if err != nil {
+			t.Fatalf("failed to get manifest: %v", err)
+		}
+		if mOld.GetDescriptor().Digest == mNew.GetDescriptor().Digest {
+			t.Errorf("platform manifest was not changed")
+		}
+		layersOld, _ := mOld.(manifest.Imager).GetLayers()
+		layersNew, _ := mNew.(manifest.Imager).GetLayers()
+		if len(layersOld) != len(layersNew) {
+			t.Fatalf("layer count changed from %d to %d", len(layersOld), len(layersNew))
+		}
+		for i := range layersOld {
+			if layersOld[i].Digest != layersNew[i].Digest {
+				t.Errorf("layer %d changed from %s to %s", i, layersOld[i].Digest, layersNew[i].Digest)
+			}
+		}
+	})
+	t.Run("Config Created Value", func(t *testing.T) {
+		rSrc, err := ref.New(tTgtHost + "/testrepo:v1")
+		if err != nil {
+			t.Fatalf("failed creating ref: %v", err)
+		}
+		platOpt := regclient.ImageWithPlatform("linux/amd64")
+		confSrc, err := rc.ImageConfig(ctx, rSrc, platOpt)
+		if err != nil {
+			t.Fatalf("failed to get source config: %v", err)
+		}
+		rMod, err := Apply(ctx, rc, rSrc, WithConfigCreated(baseTime))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		confMod, err := rc.ImageConfig(ctx, rMod, platOpt)
+		if err != nil {
+			t.Fatalf("failed to get mod config: %v", err)
+		}
+		ocSrc, ocMod := confSrc.GetConfig(), confMod.GetConfig()
+		if ocMod.Created == nil || !ocMod.Created.Equal(baseTime) {
+			t.Errorf("unexpected created time, expected %v, received %v", baseTime, ocMod.Created)
+		}
+		if len(ocSrc.History) != len(ocMod.History) {
+			t.Fatalf("history length changed, expected %d, received %d", len(ocSrc.History), len(ocMod.History))
+		}
+		for i := range ocSrc.History {
+			if ocSrc.History[i].Created != nil && !ocSrc.History[i].Created.Equal(*ocMod.History[i].Created) {
+				t.Errorf("history %d created time changed from %v to %v", i, ocSrc.History[i].Created, ocMod.History[i].Created)
+			}
+		}
+	})
+}
+
+func TestModGzipLevel(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	err := copyfs.Copy(filepath.Join(tempDir, "testrepo"), "../testdata/testrepo")
+	if err != nil {
+		t.Fatalf("failed to setup tempDir: %v", err)
+	}
+	r, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	recompress := []Opts{
+		WithLayerCompression(archive.CompressNone),
+		WithLayerCompression(archive.CompressGzip),
+	}
+	// the level of the RegClient is the default, and the mod option overrides it
+	rcDefault := regclient.New()
+	rcFast := regclient.New(regclient.WithGzipLevel(gzip.BestSpeed))
+	rDefault, err := Apply(ctx, rcDefault, r, recompress...)
+	if err != nil {
+		t.Fatalf("failed to apply default: %v", err)
+	}
+	rClient, err := Apply(ctx, rcFast, r, recompress...)
+	if err != nil {
+		t.Fatalf("failed to apply client level: %v", err)
+	}
+	rOpt, err := Apply(ctx, rcDefault, r, append(recompress, WithLayerGzipLevel(gzip.BestSpeed))...)
+	if err != nil {
+		t.Fatalf("failed to apply mod level: %v", err)
+	}
+	if rClient.Digest != rOpt.Digest {
+		t.Errorf("client level was not used, expected %s, received %s", rOpt.Digest, rClient.Digest)
+	}
+	if rClient.Digest == rDefault.Digest {
+		t.Errorf("client level did not change the compressed layers")
+	}
 }
 
 func TestInList(t *testing.T) {
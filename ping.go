@@ -2,7 +2,10 @@ package regclient
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/regclient/regclient/scheme"
+	"github.com/regclient/regclient/types/errs"
 	"github.com/regclient/regclient/types/ping"
 	"github.com/regclient/regclient/types/ref"
 )
@@ -16,3 +19,19 @@ func (rc *RegClient) Ping(ctx context.Context, r ref.Ref) (ping.Result, error) {
 
 	return schemeAPI.Ping(ctx, r)
 }
+
+// PingCapabilities probes a registry for the features it supports, see [ping.Capabilities].
+// There is no standard discovery API, so this is a best effort check and each capability may be unknown.
+// Including a tag or digest of an OCI manifest in the ref allows OCI manifest support to be detected.
+// The delete API is only probed with [scheme.WithCapabilityProbeDelete].
+func (rc *RegClient) PingCapabilities(ctx context.Context, r ref.Ref, opts ...scheme.CapabilityOpts) (ping.Capabilities, error) {
+	schemeAPI, err := rc.schemeGet(r.Scheme)
+	if err != nil {
+		return ping.Capabilities{}, err
+	}
+	sc, ok := schemeAPI.(scheme.Capabler)
+	if !ok {
+		return ping.Capabilities{}, fmt.Errorf("scheme does not support capabilities: %s%.0w", r.Scheme, errs.ErrUnsupported)
+	}
+	return sc.Capabilities(ctx, r, opts...)
+}
@@ -0,0 +1,144 @@
+package regclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/olareg/olareg"
+	oConfig "github.com/olareg/olareg/config"
+
+	"github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/scheme"
+	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/ping"
+	"github.com/regclient/regclient/types/ref"
+)
+
+func TestPingCapabilities(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	boolT := true
+	boolF := false
+	var deletes atomic.Int64
+	newReg := func(deleteEnabled *bool) string {
+		regHandler := olareg.New(oConfig.Config{
+			Storage: oConfig.ConfigStorage{
+				StoreType: oConfig.StoreMem,
+				RootDir:   "./testdata",
+			},
+			API: oConfig.ConfigAPI{
+				DeleteEnabled: deleteEnabled,
+			},
+		})
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodDelete {
+				deletes.Add(1)
+			}
+			regHandler.ServeHTTP(w, req)
+		}))
+		t.Cleanup(func() {
+			ts.Close()
+			_ = regHandler.Close()
+		})
+		tsURL, _ := url.Parse(ts.URL)
+		return tsURL.Host
+	}
+	hostDel := newReg(&boolT)
+	hostNoDel := newReg(&boolF)
+	rc := New(WithConfigHost(
+		config.Host{
+			Name:     hostDel,
+			Hostname: hostDel,
+			TLS:      config.TLSDisabled,
+		},
+		config.Host{
+			Name:     hostNoDel,
+			Hostname: hostNoDel,
+			TLS:      config.TLSDisabled,
+		},
+	))
+	tt := []struct {
+		name          string
+		ref           string
+		opts          []scheme.CapabilityOpts
+		expect        ping.Capabilities
+		expectErr     error
+		expectDeletes int64
+	}{
+		{
+			name: "default",
+			ref:  hostNoDel + "/testrepo:v1",
+			expect: ping.Capabilities{
+				OCIManifest: ping.SupportYes,
+				Referrers:   ping.SupportYes,
+				Delete:      ping.SupportUnknown,
+			},
+		},
+		{
+			name: "delete enabled",
+			ref:  hostDel + "/testrepo:v1",
+			opts: []scheme.CapabilityOpts{scheme.WithCapabilityProbeDelete()},
+			expect: ping.Capabilities{
+				OCIManifest: ping.SupportYes,
+				Referrers:   ping.SupportYes,
+				Delete:      ping.SupportUnknown,
+			},
+			expectDeletes: 1,
+		},
+		{
+			name: "delete disabled",
+			ref:  hostNoDel + "/testrepo:v1",
+			opts: []scheme.CapabilityOpts{scheme.WithCapabilityProbeDelete()},
+			expect: ping.Capabilities{
+				OCIManifest: ping.SupportYes,
+				Referrers:   ping.SupportYes,
+				Delete:      ping.SupportNo,
+			},
+			expectDeletes: 1,
+		},
+		{
+			name: "missing manifest",
+			ref:  hostDel + "/testrepo:missing",
+			expect: ping.Capabilities{
+				OCIManifest: ping.SupportUnknown,
+				Referrers:   ping.SupportYes,
+				Delete:      ping.SupportUnknown,
+			},
+		},
+		{
+			name:      "ocidir",
+			ref:       "ocidir://testdata/testrepo:v1",
+			expectErr: errs.ErrUnsupported,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := ref.New(tc.ref)
+			if err != nil {
+				t.Fatalf("failed to parse ref: %v", err)
+			}
+			deletesStart := deletes.Load()
+			c, err := rc.PingCapabilities(ctx, r, tc.opts...)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Errorf("unexpected error, expected %v, received %v", tc.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to probe capabilities: %v", err)
+			}
+			if c != tc.expect {
+				t.Errorf("unexpected capabilities, expected %+v, received %+v", tc.expect, c)
+			}
+			if d := deletes.Load() - deletesStart; d != tc.expectDeletes {
+				t.Errorf("unexpected delete requests, expected %d, received %d", tc.expectDeletes, d)
+			}
+		})
+	}
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/klauspost/compress/zstd"
 	"github.com/ulikunitz/xz"
+
+	"github.com/regclient/regclient/types/mediatype"
 )
 
 // CompressType identifies the detected compression type
@@ -32,11 +34,38 @@ var compressHeaders = map[CompressType][]byte{
 	CompressZstd:  []byte("\x28\xB5\x2F\xFD"),
 }
 
-func Compress(r io.Reader, oComp CompressType) (io.ReadCloser, error) {
+type compressConfig struct {
+	gzipLevel int
+}
+
+// CompressOpts configures the compression performed by [Compress].
+type CompressOpts func(*compressConfig)
+
+// CompressWithGzipLevel sets the gzip compression level.
+// Valid values range from [gzip.HuffmanOnly] to [gzip.BestCompression], and default to [gzip.DefaultCompression].
+func CompressWithGzipLevel(level int) CompressOpts {
+	return func(cc *compressConfig) {
+		cc.gzipLevel = level
+	}
+}
+
+// Compress returns a reader of r compressed with the requested algorithm.
+func Compress(r io.Reader, oComp CompressType, opts ...CompressOpts) (io.ReadCloser, error) {
+	cc := compressConfig{
+		gzipLevel: gzip.DefaultCompression,
+	}
+	for _, opt := range opts {
+		opt(&cc)
+	}
 	switch oComp {
 	// note, bzip2 compression is not supported
 	case CompressGzip:
-		return writeToRead(r, newGzipWriter)
+		if cc.gzipLevel < gzip.HuffmanOnly || cc.gzipLevel > gzip.BestCompression {
+			return nil, fmt.Errorf("invalid gzip compression level: %d", cc.gzipLevel)
+		}
+		return writeToRead(r, func(w io.Writer) (*gzip.Writer, error) {
+			return gzip.NewWriterLevel(w, cc.gzipLevel)
+		})
 	case CompressXz:
 		return writeToRead(r, xz.NewWriter)
 	case CompressZstd:
@@ -48,11 +77,6 @@ func Compress(r io.Reader, oComp CompressType) (io.ReadCloser, error) {
 	}
 }
 
-// newGzipWriter generates a writer and an always nil error.
-func newGzipWriter(w io.Writer) (io.WriteCloser, error) {
-	return gzip.NewWriter(w), nil
-}
-
 // newZstdWriter generates a writer with the default options.
 func newZstdWriter(w io.Writer) (io.WriteCloser, error) {
 	return zstd.NewWriter(w)
@@ -83,8 +107,44 @@ func writeToRead[wc io.WriteCloser](src io.Reader, newWriterFn func(io.Writer) (
 	return pr, nil
 }
 
+type decompressConfig struct {
+	declared    CompressType
+	declaredSet bool
+}
+
+// DecompressOpts configures the decompression performed by [Decompress].
+type DecompressOpts func(*decompressConfig)
+
+// DecompressWithType sets the declared compression of the stream, e.g. from the layer media type.
+// The declared type is used instead of detecting the compression, and an [ErrCompressMismatch] is returned when the stream does not match.
+func DecompressWithType(ct CompressType) DecompressOpts {
+	return func(dc *decompressConfig) {
+		dc.declared = ct
+		dc.declaredSet = true
+	}
+}
+
+// MediaTypeCompression returns the compression declared by a layer media type, including foreign layers.
+// Any media type parameters are ignored, and the bool is false for media types without a known compression.
+func MediaTypeCompression(mt string) (CompressType, bool) {
+	switch mediatype.Base(mt) {
+	case mediatype.Docker2Layer, mediatype.OCI1Layer, mediatype.OCI1ForeignLayer:
+		return CompressNone, true
+	case mediatype.Docker2LayerGzip, mediatype.Docker2ForeignLayer, mediatype.OCI1LayerGzip, mediatype.OCI1ForeignLayerGzip:
+		return CompressGzip, true
+	case mediatype.Docker2LayerZstd, mediatype.OCI1LayerZstd, mediatype.OCI1ForeignLayerZstd:
+		return CompressZstd, true
+	default:
+		return CompressNone, false
+	}
+}
+
 // Decompress extracts gzip and bzip streams
-func Decompress(r io.Reader) (io.Reader, error) {
+func Decompress(r io.Reader, opts ...DecompressOpts) (io.Reader, error) {
+	dc := decompressConfig{}
+	for _, opt := range opts {
+		opt(&dc)
+	}
 	// create bufio to peak on first few bytes
 	br := bufio.NewReader(r)
 	head, err := br.Peek(10)
@@ -93,7 +153,11 @@ func Decompress(r io.Reader) (io.Reader, error) {
 	}
 
 	// compare peaked data against known compression types
-	switch DetectCompression(head) {
+	ct := DetectCompression(head)
+	if dc.declaredSet && ct != dc.declared {
+		return br, fmt.Errorf("%w: declared %s, detected %s", ErrCompressMismatch, dc.declared.String(), ct.String())
+	}
+	switch ct {
 	case CompressBzip2:
 		return bzip2.NewReader(br), nil
 	case CompressGzip:
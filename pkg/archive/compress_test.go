@@ -2,9 +2,14 @@ package archive
 
 import (
 	"bytes"
+	"compress/gzip"
+	"errors"
 	"io"
+	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/regclient/regclient/types/mediatype"
 )
 
 func TestMarshal(t *testing.T) {
@@ -70,6 +75,38 @@ func TestRoundtrip(t *testing.T) {
 	}
 }
 
+func TestGzipLevel(t *testing.T) {
+	t.Parallel()
+	content := bytes.Repeat([]byte("hello world "), 1024)
+	for _, level := range []int{gzip.HuffmanOnly, gzip.DefaultCompression, gzip.NoCompression, gzip.BestSpeed, gzip.BestCompression} {
+		level := level
+		t.Run(strconv.Itoa(level), func(t *testing.T) {
+			t.Parallel()
+			cr, err := Compress(bytes.NewReader(content), CompressGzip, CompressWithGzipLevel(level))
+			if err != nil {
+				t.Fatalf("failed to compress: %v", err)
+			}
+			dr, err := Decompress(cr)
+			if err != nil {
+				t.Fatalf("failed to decompress: %v", err)
+			}
+			out, err := io.ReadAll(dr)
+			if err != nil {
+				t.Fatalf("failed to ReadAll: %v", err)
+			}
+			if !bytes.Equal(content, out) {
+				t.Errorf("output mismatch")
+			}
+		})
+	}
+	for _, level := range []int{-3, 10} {
+		_, err := Compress(bytes.NewReader(content), CompressGzip, CompressWithGzipLevel(level))
+		if err == nil {
+			t.Errorf("invalid level %d did not fail", level)
+		}
+	}
+}
+
 func FuzzRoundTrip(f *testing.F) {
 	f.Add(int(CompressNone), "hello world")
 	f.Fuzz(func(t *testing.T, comp int, s string) {
@@ -92,3 +129,69 @@ func FuzzRoundTrip(f *testing.F) {
 		}
 	})
 }
+
+func TestDecompressWithType(t *testing.T) {
+	t.Parallel()
+	content := []byte(`hello world`)
+	for _, algo := range []CompressType{CompressNone, CompressGzip, CompressZstd} {
+		algo := algo
+		t.Run(algo.String(), func(t *testing.T) {
+			t.Parallel()
+			for _, declared := range []CompressType{CompressNone, CompressGzip, CompressZstd} {
+				cr, err := Compress(bytes.NewReader(content), algo)
+				if err != nil {
+					t.Fatalf("failed to compress: %v", err)
+				}
+				dr, err := Decompress(cr, DecompressWithType(declared))
+				if declared != algo {
+					if !errors.Is(err, ErrCompressMismatch) {
+						t.Errorf("declared %s did not return a mismatch: %v", declared.String(), err)
+					}
+					_ = cr.Close()
+					continue
+				}
+				if err != nil {
+					t.Fatalf("failed to decompress: %v", err)
+				}
+				out, err := io.ReadAll(dr)
+				if err != nil {
+					t.Fatalf("failed to ReadAll: %v", err)
+				}
+				if !bytes.Equal(content, out) {
+					t.Errorf("output mismatch: expected %s, received %s", content, out)
+				}
+			}
+		})
+	}
+}
+
+func TestMediaTypeCompression(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		mt       string
+		expect   CompressType
+		expectOK bool
+	}{
+		{mt: mediatype.OCI1Layer, expect: CompressNone, expectOK: true},
+		{mt: mediatype.OCI1LayerGzip, expect: CompressGzip, expectOK: true},
+		{mt: mediatype.OCI1LayerZstd, expect: CompressZstd, expectOK: true},
+		{mt: mediatype.Docker2Layer, expect: CompressNone, expectOK: true},
+		{mt: mediatype.Docker2LayerGzip, expect: CompressGzip, expectOK: true},
+		{mt: mediatype.Docker2LayerZstd, expect: CompressZstd, expectOK: true},
+		{mt: mediatype.Docker2ForeignLayer, expect: CompressGzip, expectOK: true},
+		{mt: mediatype.OCI1ForeignLayer, expect: CompressNone, expectOK: true},
+		{mt: mediatype.OCI1ForeignLayerGzip, expect: CompressGzip, expectOK: true},
+		{mt: mediatype.OCI1ForeignLayerZstd, expect: CompressZstd, expectOK: true},
+		{mt: mediatype.OCI1LayerGzip + "; charset=utf-8", expect: CompressGzip, expectOK: true},
+		{mt: mediatype.OCI1ImageConfig, expect: CompressNone, expectOK: false},
+		{mt: "", expect: CompressNone, expectOK: false},
+	}
+	for _, tc := range tt {
+		t.Run(tc.mt, func(t *testing.T) {
+			ct, ok := MediaTypeCompression(tc.mt)
+			if ct != tc.expect || ok != tc.expectOK {
+				t.Errorf("unexpected result, expected %s/%t, received %s/%t", tc.expect, tc.expectOK, ct, ok)
+			}
+		})
+	}
+}
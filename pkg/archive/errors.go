@@ -3,6 +3,8 @@ package archive
 import "errors"
 
 var (
+	// ErrCompressMismatch used when the declared compression does not match the stream
+	ErrCompressMismatch = errors.New("declared compression does not match content")
 	// ErrNotImplemented used for routines that need to be developed still
 	ErrNotImplemented = errors.New("this archive routine is not implemented yet")
 	// ErrUnknownType used for unknown compression types
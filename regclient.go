@@ -2,17 +2,24 @@
 package regclient
 
 import (
+	"compress/gzip"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"fmt"
 
 	"github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/internal/pqueue"
+	"github.com/regclient/regclient/internal/reqmeta"
 	"github.com/regclient/regclient/internal/version"
 	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/scheme/ocidir"
 	"github.com/regclient/regclient/scheme/reg"
+	"github.com/regclient/regclient/types/ref"
 )
 
 const (
@@ -29,13 +36,27 @@ const (
 )
 
 // RegClient is used to access OCI distribution-spec registries.
+// A RegClient should be reused for multiple requests.
+// Auth tokens are cached by registry and reused for every request to a repository that was already authorized,
+// including the manifest and blob requests from separate calls like [RegClient.ImageConfig].
+// A new token is only requested when it expires or a request needs another repository or action.
 type RegClient struct {
+	blobCache   *url.URL
+	blobCacheHC *http.Client
+	gzipLevel   int
 	hosts       map[string]*config.Host
 	hostDefault *config.Host
 	regOpts     []reg.Opts
 	schemes     map[string]scheme.API
 	slog        *slog.Logger
 	userAgent   string
+	transfers   *transferQueues
+}
+
+// transferQueues tracks the blob transfer queue for each registry host.
+type transferQueues struct {
+	mu     sync.Mutex
+	queues map[string]*pqueue.Queue[reqmeta.Data]
 }
 
 // Opt functions are used by [New] to create a [*RegClient].
@@ -44,11 +65,13 @@ type Opt func(*RegClient)
 // New returns a registry client.
 func New(opts ...Opt) *RegClient {
 	var rc = RegClient{
+		gzipLevel: gzip.DefaultCompression,
 		hosts:     map[string]*config.Host{},
 		userAgent: DefaultUserAgent,
 		regOpts:   []reg.Opts{},
 		schemes:   map[string]scheme.API{},
 		slog:      slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+		transfers: &transferQueues{queues: map[string]*pqueue.Queue[reqmeta.Data]{}},
 	}
 
 	info := version.GetInfo()
@@ -64,6 +87,12 @@ func New(opts ...Opt) *RegClient {
 	for _, opt := range opts {
 		opt(&rc)
 	}
+	// validate after all options so the warning uses the configured logger
+	if rc.gzipLevel < gzip.HuffmanOnly || rc.gzipLevel > gzip.BestCompression {
+		rc.slog.Warn("Ignoring invalid gzip level",
+			slog.Int("level", rc.gzipLevel))
+		rc.gzipLevel = gzip.DefaultCompression
+	}
 
 	// configure regOpts
 	hostList := []*config.Host{}
@@ -90,6 +119,23 @@ func New(opts ...Opt) *RegClient {
 	return &rc
 }
 
+// WithBlobCacheProxy configures a shared read-through cache for blobs pulled from registries.
+// Blobs are requested from the cache with a GET to "<proxy>/blobs/<algorithm>/<encoded>" before falling back to the registry.
+// On a cache miss, the blob pulled from the registry is sent to the same URL with a PUT to populate the cache.
+// The content from the cache is verified against the requested digest.
+func WithBlobCacheProxy(proxy string) Opt {
+	return func(rc *RegClient) {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			rc.slog.Warn("Ignoring invalid blob cache proxy",
+				slog.String("proxy", proxy))
+			return
+		}
+		rc.blobCache = u
+		rc.blobCacheHC = &http.Client{}
+	}
+}
+
 // WithBlobLimit sets the max size for chunked blob uploads which get stored in memory.
 //
 // Deprecated: replace with WithRegOpts(reg.WithBlobLimit(limit)), see [WithRegOpts] and [reg.WithBlobLimit].
@@ -171,6 +217,45 @@ func WithDockerCredsFile(fname string) Opt {
 	}
 }
 
+// WithEnvCreds adds registry logins from environment variables, see [config.EnvLoad].
+// This is useful in CI pipelines that inject credentials without a docker config file.
+// A login is skipped when credentials were already configured for the host, so this should be added after other credential options.
+func WithEnvCreds() Opt {
+	return func(rc *RegClient) {
+		configHosts, err := config.EnvLoad()
+		if err != nil {
+			rc.slog.Warn("Failed to load environment creds",
+				slog.String("err", err.Error()))
+			return
+		}
+		envHosts := []config.Host{}
+		for _, configHost := range configHosts {
+			name := configHost.Name
+			if name == DockerRegistryDNS || name == DockerRegistryAuth {
+				name = DockerRegistry
+			}
+			if h, ok := rc.hosts[name]; ok && (h.User != "" || h.Pass != "" || h.Token != "" || h.CredHelper != "") {
+				rc.slog.Debug("Ignoring environment creds for a host with existing creds",
+					slog.String("host", name))
+				continue
+			}
+			envHosts = append(envHosts, configHost)
+		}
+		rc.hostLoad("env", envHosts)
+	}
+}
+
+// WithGzipLevel sets the gzip compression level used when layers and exports are compressed.
+// Valid values range from [gzip.HuffmanOnly] to [gzip.BestCompression].
+// Use [gzip.BestSpeed] for the fastest compression or [gzip.BestCompression] for the smallest output.
+// An invalid level is logged and the default level is used.
+// This is also the default level for layers compressed by the mod package.
+func WithGzipLevel(level int) Opt {
+	return func(rc *RegClient) {
+		rc.gzipLevel = level
+	}
+}
+
 // WithRegOpts passes through opts to the reg scheme.
 func WithRegOpts(opts ...reg.Opts) Opt {
 	return func(rc *RegClient) {
@@ -213,6 +298,11 @@ func WithUserAgent(ua string) Opt {
 	}
 }
 
+// GzipLevel returns the gzip compression level configured with [WithGzipLevel].
+func (rc *RegClient) GzipLevel() int {
+	return rc.gzipLevel
+}
+
 func (rc *RegClient) hostLoad(src string, hosts []config.Host) {
 	for _, configHost := range hosts {
 		if configHost.Name == "" {
@@ -233,6 +323,7 @@ func (rc *RegClient) hostLoad(src string, hosts []config.Host) {
 			}
 		}
 		tls, _ := configHost.TLS.MarshalText()
+		protocol, _ := configHost.Protocol.MarshalText()
 		rc.slog.Debug("Loading config",
 			slog.Int64("blobChunk", configHost.BlobChunk),
 			slog.Int64("blobMax", configHost.BlobMax),
@@ -241,6 +332,7 @@ func (rc *RegClient) hostLoad(src string, hosts []config.Host) {
 			slog.Any("mirrors", configHost.Mirrors),
 			slog.String("name", configHost.Name),
 			slog.String("pathPrefix", configHost.PathPrefix),
+			slog.String("protocol", string(protocol)),
 			slog.Bool("repoAuth", configHost.RepoAuth),
 			slog.String("source", src),
 			slog.String("tls", string(tls)),
@@ -271,3 +363,26 @@ func (rc *RegClient) hostSet(newHost config.Host) error {
 	}
 	return nil
 }
+
+// transferThrottle returns the queue limiting the concurrent blob transfers to the registry of r.
+// The limit is the BlobConcurrent setting of the host, nil is returned when it is not set.
+func (rc *RegClient) transferThrottle(r ref.Ref) *pqueue.Queue[reqmeta.Data] {
+	if r.Scheme != "reg" {
+		return nil
+	}
+	h := rc.hosts[r.Registry]
+	if h == nil {
+		h = rc.hostDefault
+	}
+	if h == nil || h.BlobConcurrent <= 0 {
+		return nil
+	}
+	rc.transfers.mu.Lock()
+	defer rc.transfers.mu.Unlock()
+	if q, ok := rc.transfers.queues[r.Registry]; ok {
+		return q
+	}
+	q := pqueue.New(pqueue.Opts[reqmeta.Data]{Max: int(h.BlobConcurrent), Next: reqmeta.DataNext})
+	rc.transfers.queues[r.Registry] = q
+	return q
+}
@@ -1,11 +1,27 @@
 package regclient
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/olareg/olareg"
+	oConfig "github.com/olareg/olareg/config"
+
+	"github.com/regclient/regclient/config"
 	"github.com/regclient/regclient/scheme/reg"
+	"github.com/regclient/regclient/types/ref"
 )
 
 func TestNew(t *testing.T) {
@@ -49,6 +65,24 @@ func TestNew(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "gzip level",
+			opts: []Opt{
+				WithGzipLevel(gzip.BestSpeed),
+			},
+			expect: RegClient{
+				gzipLevel: gzip.BestSpeed,
+			},
+		},
+		{
+			name: "gzip level invalid",
+			opts: []Opt{
+				WithGzipLevel(12),
+			},
+			expect: RegClient{
+				gzipLevel: gzip.DefaultCompression,
+			},
+		},
 		{
 			name: "log",
 			opts: []Opt{
@@ -96,9 +130,119 @@ func TestNew(t *testing.T) {
 				}
 				// TODO: can content of each regOpt be compared?
 			}
+			if tc.expect.gzipLevel != 0 && tc.expect.gzipLevel != result.GzipLevel() {
+				t.Errorf("gzipLevel, expected %d, received %d", tc.expect.gzipLevel, result.GzipLevel())
+			}
 			if tc.expect.userAgent != "" && tc.expect.userAgent != result.userAgent {
 				t.Errorf("userAgent, expected %s, received %s", tc.expect.userAgent, result.userAgent)
 			}
 		})
 	}
 }
+
+func TestGzipLevelWarning(t *testing.T) {
+	t.Parallel()
+	// the warning is logged with the configured logger, even when it is set after the level
+	buf := &bytes.Buffer{}
+	log := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	rc := New(WithGzipLevel(12), WithSlog(log))
+	if rc.GzipLevel() != gzip.DefaultCompression {
+		t.Errorf("unexpected gzip level, expected %d, received %d", gzip.DefaultCompression, rc.GzipLevel())
+	}
+	if !strings.Contains(buf.String(), "Ignoring invalid gzip level") {
+		t.Errorf("warning not logged: %s", buf.String())
+	}
+}
+
+func TestEnvCreds(t *testing.T) {
+	// t.Setenv prevents this test from running in parallel
+	t.Setenv("REGCLIENT_AUTH_REGISTRY_EXAMPLE_COM", "envuser:envpass")
+	t.Setenv("REGCLIENT_AUTH_EXAMPLE_ORG", "envuser:envpass")
+	t.Setenv("REGISTRY_HOST", "")
+	t.Setenv("REGISTRY_USERNAME", "ciuser")
+	t.Setenv("REGISTRY_PASSWORD", "cipass")
+	rc := New(
+		WithConfigHost(config.Host{Name: "registry.example.com", User: "confuser", Pass: "confpass"}),
+		WithEnvCreds(),
+	)
+	if h := rc.hosts["registry.example.com"]; h == nil || h.User != "confuser" || h.Pass != "confpass" {
+		t.Errorf("configured creds were replaced: %v", h)
+	}
+	if h := rc.hosts["example.org"]; h == nil || h.User != "envuser" || h.Pass != "envpass" {
+		t.Errorf("env creds not loaded: %v", h)
+	}
+	if h := rc.hosts[config.DockerRegistry]; h == nil || h.User != "" || h.Pass != "" {
+		t.Errorf("creds without REGISTRY_HOST were applied to Docker Hub: %v", h)
+	}
+}
+
+func TestAuthTokenReuse(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	var mu sync.Mutex
+	tokenReqs := 0
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			mu.Lock()
+			tokenReqs++
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"token":      "testtoken",
+				"expires_in": 300,
+				"issued_at":  time.Now().UTC(),
+			})
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer testtoken" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, ts.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		regHandler.ServeHTTP(w, req)
+	}))
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	for _, tag := range []string{"v1", "v2", "v3", "v1"} {
+		r, err := ref.New(tsHost + "/testrepo:" + tag)
+		if err != nil {
+			t.Fatalf("failed to parse ref: %v", err)
+		}
+		_, err = rc.ImageConfig(ctx, r, ImageWithPlatform("linux/amd64"))
+		if err != nil {
+			t.Fatalf("failed to get config for %s: %v", tag, err)
+		}
+	}
+	mu.Lock()
+	if tokenReqs != 1 {
+		t.Errorf("token requests for one repository, expected 1, received %d", tokenReqs)
+	}
+	mu.Unlock()
+	// a new repository adds a scope and requests a new token
+	r, err := ref.New(tsHost + "/testrepo2:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	_, _ = rc.ManifestHead(ctx, r)
+	mu.Lock()
+	if tokenReqs != 2 {
+		t.Errorf("token requests after a second repository, expected 2, received %d", tokenReqs)
+	}
+	mu.Unlock()
+}
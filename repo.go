@@ -7,15 +7,22 @@ import (
 
 	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/ref"
 	"github.com/regclient/regclient/types/repo"
+	"github.com/regclient/regclient/types/tag"
 )
 
+// listPageDefault is the default page size for [RegClient.RepoListWalk] and [RegClient.TagListWalk].
+const listPageDefault = 1000
+
 type repoLister interface {
 	RepoList(ctx context.Context, hostname string, opts ...scheme.RepoOpts) (*repo.RepoList, error)
 }
 
 // RepoList returns a list of repositories on a registry.
 // Note the underlying "_catalog" API is not supported on many cloud registries.
+// When following the pagination of the registry fails, the repositories received so far are returned with the error.
+// The listing can be resumed by passing [repo.RepoRegistryList.GetLast] to [scheme.WithRepoLast].
 func (rc *RegClient) RepoList(ctx context.Context, hostname string, opts ...scheme.RepoOpts) (*repo.RepoList, error) {
 	i := strings.Index(hostname, "/")
 	if i > 0 {
@@ -31,3 +38,74 @@ func (rc *RegClient) RepoList(ctx context.Context, hostname string, opts ...sche
 	}
 	return rl.RepoList(ctx, hostname, opts...)
 }
+
+// RepoListWalk calls fn with each page of repositories from a registry.
+// Pages are requested with the limit from [scheme.WithRepoLimit], defaulting to 1000 entries.
+// Listing stops when a page is not full, or when fn returns an error.
+// This avoids buffering the entire catalog of a large registry in memory.
+// Listing starts after the repository from [scheme.WithRepoLast], allowing an interrupted walk to be resumed.
+// Persisting [repo.RepoRegistryList.GetLast] after fn processes each page provides the value to resume from.
+// Errors after the first page include the last repository passed to fn.
+func (rc *RegClient) RepoListWalk(ctx context.Context, hostname string, fn func(*repo.RepoList) error, opts ...scheme.RepoOpts) error {
+	config := scheme.RepoConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.Limit <= 0 {
+		config.Limit = listPageDefault
+	}
+	last := config.Last
+	for {
+		rl, err := rc.RepoList(ctx, hostname, scheme.WithRepoLimit(config.Limit), scheme.WithRepoLast(last))
+		if err != nil {
+			if last != config.Last {
+				return fmt.Errorf("repository list interrupted after %s: %w", last, err)
+			}
+			return err
+		}
+		repos, err := rl.GetRepos()
+		if err != nil {
+			return err
+		}
+		// stop when the registry returns nothing new, e.g. when it ignores the last parameter
+		if len(repos) == 0 || repos[len(repos)-1] == last {
+			return nil
+		}
+		err = fn(rl)
+		if err != nil {
+			return err
+		}
+		if len(repos) < config.Limit {
+			return nil
+		}
+		last = repos[len(repos)-1]
+	}
+}
+
+// RepoCopy copies every tag from the source repository to the same tag in the target repository.
+// Tags are listed with [RegClient.TagListWalk] and copied with [RegClient.ImageCopyBatch] using opts.
+// The results include an entry for each tag, in the order listed by the source.
+// Tags copied concurrently share their blobs, each blob is transferred once and blobs already copied for another tag are skipped.
+func (rc *RegClient) RepoCopy(ctx context.Context, srcRepo, tgtRepo ref.Ref, opts BatchOpts) ([]CopyResult, error) {
+	if !srcRepo.IsSetRepo() {
+		return nil, fmt.Errorf("source repository is not set: %s%.0w", srcRepo.CommonName(), errs.ErrInvalidReference)
+	}
+	if !tgtRepo.IsSetRepo() {
+		return nil, fmt.Errorf("target repository is not set: %s%.0w", tgtRepo.CommonName(), errs.ErrInvalidReference)
+	}
+	pairs := []CopyPair{}
+	err := rc.TagListWalk(ctx, srcRepo, func(tl *tag.List) error {
+		tags, err := tl.GetTags()
+		if err != nil {
+			return err
+		}
+		for _, t := range tags {
+			pairs = append(pairs, CopyPair{Src: srcRepo.SetTag(t), Tgt: tgtRepo.SetTag(t)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags for %s: %w", srcRepo.CommonName(), err)
+	}
+	return rc.ImageCopyBatch(ctx, pairs, opts)
+}
@@ -2,13 +2,29 @@ package regclient
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/olareg/olareg"
+	oConfig "github.com/olareg/olareg/config"
+
+	"github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/ref"
+	"github.com/regclient/regclient/types/repo"
 )
 
 func TestRepoList(t *testing.T) {
@@ -25,3 +41,195 @@ func TestRepoList(t *testing.T) {
 		t.Errorf("RepoList unexpected error on hostname with a path: expected %v, received %v", errs.ErrParsingFailed, err)
 	}
 }
+
+func TestRepoListWalkResume(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repoNames := []string{}
+	for i := 0; i < 25; i++ {
+		repoNames = append(repoNames, fmt.Sprintf("repo%02d", i))
+	}
+	// the registry fails the request after repo09 until failing is disabled
+	var mu sync.Mutex
+	failing := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet || req.URL.Path != "/v2/_catalog" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		last := req.URL.Query().Get("last")
+		n, err := strconv.Atoi(req.URL.Query().Get("n"))
+		if err != nil || n <= 0 {
+			n = len(repoNames)
+		}
+		mu.Lock()
+		fail := failing && last == "repo09"
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page := []string{}
+		for _, name := range repoNames {
+			if name > last && len(page) < n {
+				page = append(page, name)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string][]string{"repositories": page})
+	}))
+	t.Cleanup(ts.Close)
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	received := []string{}
+	saved := ""
+	walkFn := func(rl *repo.RepoList) error {
+		repos, err := rl.GetRepos()
+		if err != nil {
+			return err
+		}
+		received = append(received, repos...)
+		saved = rl.GetLast()
+		return nil
+	}
+	err := rc.RepoListWalk(ctx, tsHost, walkFn, scheme.WithRepoLimit(5))
+	if err == nil {
+		t.Fatalf("walk did not fail")
+	}
+	if !strings.Contains(err.Error(), "repo09") {
+		t.Errorf("error does not include the last repository: %v", err)
+	}
+	if saved != "repo09" {
+		t.Fatalf("unexpected last repository, expected repo09, received %s", saved)
+	}
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	err = rc.RepoListWalk(ctx, tsHost, walkFn, scheme.WithRepoLimit(5), scheme.WithRepoLast(saved))
+	if err != nil {
+		t.Fatalf("failed to resume walk: %v", err)
+	}
+	if !slices.Equal(received, repoNames) {
+		t.Errorf("unexpected repositories, expected %v, received %v", repoNames, received)
+	}
+	if saved != "repo24" {
+		t.Errorf("unexpected last repository, expected repo24, received %s", saved)
+	}
+}
+
+func TestRepoCopy(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// count the uploads of each blob to the registry
+	var mu sync.Mutex
+	uploads := map[string]int{}
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+		},
+	})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if dig := req.URL.Query().Get("digest"); dig != "" && strings.Contains(req.URL.Path, "/blobs/uploads/") {
+			mu.Lock()
+			uploads[dig]++
+			mu.Unlock()
+		}
+		regHandler.ServeHTTP(w, req)
+	}))
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	tsURL, _ := url.Parse(ts.URL)
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsURL.Host,
+		Hostname: tsURL.Host,
+		TLS:      config.TLSDisabled,
+	}))
+	srcRepo, err := ref.New("ocidir://./testdata/testrepo")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	tgtRepoOCI, err := ref.New("ocidir://" + t.TempDir() + "/testrepo")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	tgtRepoReg, err := ref.New(tsURL.Host + "/testrepo")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	tl, err := rc.TagList(ctx, srcRepo)
+	if err != nil {
+		t.Fatalf("failed to list source tags: %v", err)
+	}
+	srcTags, err := tl.GetTags()
+	if err != nil {
+		t.Fatalf("failed to get source tags: %v", err)
+	}
+	tt := []struct {
+		name    string
+		tgtRepo ref.Ref
+	}{
+		{
+			name:    "ocidir",
+			tgtRepo: tgtRepoOCI,
+		},
+		{
+			name:    "registry",
+			tgtRepo: tgtRepoReg,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := rc.RepoCopy(ctx, srcRepo, tc.tgtRepo, BatchOpts{})
+			if err != nil {
+				t.Fatalf("failed to copy repository: %v", err)
+			}
+			if len(results) != len(srcTags) {
+				t.Fatalf("unexpected number of results, expected %d, received %d", len(srcTags), len(results))
+			}
+			for i, res := range results {
+				if res.Status != CopySuccess {
+					t.Errorf("copy of %s failed: %s, %v", res.Pair.Src.CommonName(), res.Status, res.Err)
+				}
+				if res.Pair.Src.Tag != srcTags[i] || res.Pair.Tgt.Tag != srcTags[i] {
+					t.Errorf("unexpected pair for tag %s: %s -> %s", srcTags[i], res.Pair.Src.CommonName(), res.Pair.Tgt.CommonName())
+				}
+			}
+			for _, tagName := range srcTags {
+				mSrc, err := rc.ManifestHead(ctx, srcRepo.SetTag(tagName), WithManifestRequireDigest())
+				if err != nil {
+					t.Fatalf("failed to head source %s: %v", tagName, err)
+				}
+				mTgt, err := rc.ManifestHead(ctx, tc.tgtRepo.SetTag(tagName), WithManifestRequireDigest())
+				if err != nil {
+					t.Errorf("failed to head target %s: %v", tagName, err)
+					continue
+				}
+				if mSrc.GetDescriptor().Digest != mTgt.GetDescriptor().Digest {
+					t.Errorf("digest mismatch for %s", tagName)
+				}
+			}
+		})
+	}
+	// blobs shared by the tags copied concurrently are only uploaded once
+	mu.Lock()
+	if len(uploads) == 0 {
+		t.Errorf("no blobs were uploaded to the registry")
+	}
+	for dig, count := range uploads {
+		if count > 1 {
+			t.Errorf("blob %s was uploaded %d times", dig, count)
+		}
+	}
+	mu.Unlock()
+	_, err = rc.RepoCopy(ctx, ref.Ref{}, tt[0].tgtRepo, BatchOpts{})
+	if !errors.Is(err, errs.ErrInvalidReference) {
+		t.Errorf("unexpected error for an unset source, expected %v, received %v", errs.ErrInvalidReference, err)
+	}
+}
@@ -39,7 +39,7 @@ func (o *OCIDir) BlobGet(ctx context.Context, r ref.Ref, d descriptor.Descriptor
 	//#nosec G304 users should validate references they attempt to open
 	fd, err := os.Open(file)
 	if err != nil {
-		return nil, err
+		return nil, errNotExist(err)
 	}
 	if d.Size <= 0 {
 		fi, err := fd.Stat()
@@ -70,7 +70,7 @@ func (o *OCIDir) BlobHead(ctx context.Context, r ref.Ref, d descriptor.Descripto
 	//#nosec G304 users should validate references they attempt to open
 	fd, err := os.Open(file)
 	if err != nil {
-		return nil, err
+		return nil, errNotExist(err)
 	}
 	defer fd.Close()
 	if d.Size <= 0 {
@@ -3,6 +3,7 @@ package ocidir
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,8 +11,11 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/opencontainers/go-digest"
+
 	"github.com/regclient/regclient/internal/copyfs"
 	"github.com/regclient/regclient/types/descriptor"
+	"github.com/regclient/regclient/types/errs"
 	"github.com/regclient/regclient/types/manifest"
 	"github.com/regclient/regclient/types/ref"
 )
@@ -96,6 +100,17 @@ func TestBlob(t *testing.T) {
 		t.Errorf("blob read mismatch, expected %s, received %s", string(bBytes), string(bFS))
 	}
 
+	// blob not found
+	dMissing := descriptor.Descriptor{Digest: digest.FromString("missing blob")}
+	_, err = o.BlobHead(ctx, rImg, dMissing)
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("blob head on missing blob did not return not found: %v", err)
+	}
+	_, err = o.BlobGet(ctx, rImg, dMissing)
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("blob get on missing blob did not return not found: %v", err)
+	}
+
 	// toOCIConfig
 	bg, err = o.BlobGet(ctx, rImg, cd)
 	if err != nil {
@@ -17,6 +17,7 @@ import (
 
 	"github.com/opencontainers/go-digest"
 
+	"github.com/regclient/regclient/internal/limitread"
 	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/types/errs"
 	"github.com/regclient/regclient/types/manifest"
@@ -120,14 +121,24 @@ func (o *OCIDir) manifestGet(_ context.Context, r ref.Ref) (manifest.Manifest, e
 	if err = desc.Digest.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid digest in index: %s: %w", string(desc.Digest), err)
 	}
+	if desc.Size > 0 && o.manifestMax > 0 && desc.Size > o.manifestMax {
+		return nil, fmt.Errorf("manifest too large, received %d, limit %d: %s%.0w", desc.Size, o.manifestMax, r.CommonName(), errs.ErrSizeLimitExceeded)
+	}
 	file := path.Join(r.Path, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded())
 	//#nosec G304 users should validate references they attempt to open
 	fd, err := os.Open(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open manifest: %w", err)
+		return nil, fmt.Errorf("failed to open manifest: %w", errNotExist(err))
 	}
 	defer fd.Close()
-	mb, err := io.ReadAll(fd)
+	var rdr io.Reader = fd
+	if o.manifestMax > 0 {
+		rdr = &limitread.LimitRead{
+			Reader: fd,
+			Limit:  o.manifestMax,
+		}
+	}
+	mb, err := io.ReadAll(rdr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read manifest: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path"
@@ -11,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/regclient/regclient/internal/copyfs"
+	"github.com/regclient/regclient/types/errs"
 	"github.com/regclient/regclient/types/manifest"
 	"github.com/regclient/regclient/types/mediatype"
 	v1 "github.com/regclient/regclient/types/oci/v1"
@@ -63,8 +65,20 @@ func TestManifest(t *testing.T) {
 	}
 	rMissing := r.SetDigest("sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
 	_, err = o.ManifestHead(ctx, rMissing)
-	if err == nil {
-		t.Errorf("manifest head succeeded on missing digest: %s", rMissing.CommonName())
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("manifest head on missing digest did not return not found: %s: %v", rMissing.CommonName(), err)
+	}
+	_, err = o.ManifestGet(ctx, rMissing)
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("manifest get on missing digest did not return not found: %s: %v", rMissing.CommonName(), err)
+	}
+	rMissingDir, err := ref.New("ocidir://" + tempDir + "/missing:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	_, err = o.ManifestGet(ctx, rMissingDir)
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("manifest get on missing directory did not return not found: %s: %v", rMissingDir.CommonName(), err)
 	}
 	// image manifest
 	m, err := o.ManifestGet(ctx, r)
@@ -212,3 +226,41 @@ func TestManifest(t *testing.T) {
 		t.Errorf("could not query manifest after pushing dup tag")
 	}
 }
+
+func TestManifestMax(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rStr := "ocidir://../../testdata/testrepo:v1"
+	r, err := ref.New(rStr)
+	if err != nil {
+		t.Fatalf("failed to parse ref %s: %v", rStr, err)
+	}
+	ml, err := New().ManifestGet(ctx, r)
+	if err != nil {
+		t.Fatalf("manifest get: %v", err)
+	}
+	dl, err := ml.(manifest.Indexer).GetManifestList()
+	if err != nil || len(dl) < 1 {
+		t.Fatalf("descriptor list (%d): %v", len(dl), err)
+	}
+	o := New(WithManifestMax(100))
+	t.Run("size from index", func(t *testing.T) {
+		_, err := o.ManifestGet(ctx, r)
+		if !errors.Is(err, errs.ErrSizeLimitExceeded) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrSizeLimitExceeded, err)
+		}
+	})
+	t.Run("size unknown", func(t *testing.T) {
+		// child manifests are not listed in the index.json, only the digest is known
+		_, err := o.ManifestGet(ctx, r.SetDigest(dl[0].Digest.String()))
+		if !errors.Is(err, errs.ErrSizeLimitExceeded) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrSizeLimitExceeded, err)
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		_, err := New(WithManifestMax(0)).ManifestGet(ctx, r.SetDigest(dl[0].Digest.String()))
+		if err != nil {
+			t.Errorf("manifest get: %v", err)
+		}
+	})
+}
@@ -27,6 +27,8 @@ const (
 	aOCIRefName     = "org.opencontainers.image.ref.name"
 	aCtrdImageName  = "io.containerd.image.name"
 	defThrottle     = 3
+	// defManifestMax limits the largest manifest that will be read
+	defManifestMax = 1024 * 1024 * 8
 )
 
 // OCIDir is used for accessing OCI Image Layouts defined as a directory
@@ -36,6 +38,7 @@ type OCIDir struct {
 	modRefs     map[string]*ociGC
 	throttle    map[string]*pqueue.Queue[reqmeta.Data]
 	throttleDef int
+	manifestMax int64
 	mu          sync.Mutex
 }
 
@@ -45,9 +48,10 @@ type ociGC struct {
 }
 
 type ociConf struct {
-	gc       bool
-	slog     *slog.Logger
-	throttle int
+	gc          bool
+	manifestMax int64
+	slog        *slog.Logger
+	throttle    int
 }
 
 // Opts are used for passing options to ocidir
@@ -56,9 +60,10 @@ type Opts func(*ociConf)
 // New creates a new OCIDir with options
 func New(opts ...Opts) *OCIDir {
 	conf := ociConf{
-		slog:     slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
-		gc:       true,
-		throttle: defThrottle,
+		slog:        slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+		gc:          true,
+		manifestMax: defManifestMax,
+		throttle:    defThrottle,
 	}
 	for _, opt := range opts {
 		opt(&conf)
@@ -69,6 +74,7 @@ func New(opts ...Opts) *OCIDir {
 		modRefs:     map[string]*ociGC{},
 		throttle:    map[string]*pqueue.Queue[reqmeta.Data]{},
 		throttleDef: conf.throttle,
+		manifestMax: conf.manifestMax,
 	}
 }
 
@@ -80,6 +86,14 @@ func WithGC(gc bool) Opts {
 	}
 }
 
+// WithManifestMax sets the size limit for manifests read from the directory.
+// This defaults to 8MB, and a limit <= 0 disables the check.
+func WithManifestMax(max int64) Opts {
+	return func(c *ociConf) {
+		c.manifestMax = max
+	}
+}
+
 // WithSlog provides a slog logger.
 // By default logging is disabled.
 func WithSlog(slog *slog.Logger) Opts {
@@ -189,7 +203,7 @@ func (o *OCIDir) readIndex(r ref.Ref, locked bool) (v1.Index, error) {
 	//#nosec G304 users should validate references they attempt to open
 	fh, err := os.Open(indexFile)
 	if err != nil {
-		return index, fmt.Errorf("%s cannot be open: %w", indexFile, err)
+		return index, fmt.Errorf("%s cannot be open: %w", indexFile, errNotExist(err))
 	}
 	defer fh.Close()
 	ib, err := io.ReadAll(fh)
@@ -298,7 +312,7 @@ func (o *OCIDir) valid(dir string, locked bool) error {
 	//#nosec G304 users should validate references they attempt to open
 	fh, err := os.Open(path.Join(dir, imageLayoutFile))
 	if err != nil {
-		return fmt.Errorf("%s cannot be open: %w", imageLayoutFile, err)
+		return fmt.Errorf("%s cannot be open: %w", imageLayoutFile, errNotExist(err))
 	}
 	defer fh.Close()
 	lb, err := io.ReadAll(fh)
@@ -323,6 +337,14 @@ func (o *OCIDir) refMod(r ref.Ref) {
 	}
 }
 
+// errNotExist includes errs.ErrNotFound in errors for missing files.
+func errNotExist(err error) error {
+	if errors.Is(err, fs.ErrNotExist) && !errors.Is(err, errs.ErrNotFound) {
+		return fmt.Errorf("%w%.0w", err, errs.ErrNotFound)
+	}
+	return err
+}
+
 func indexCreate() v1.Index {
 	i := v1.Index{
 		Versioned:   v1.IndexSchemaVersion,
@@ -41,9 +41,13 @@ func (reg *Reg) BlobDelete(ctx context.Context, r ref.Ref, d descriptor.Descript
 		Path:       "blobs/" + d.Digest.String(),
 	}
 	resp, err := reg.reghttp.Do(ctx, req)
+	if isDeleteDisabled(resp) {
+		return fmt.Errorf("failed to delete blob, digest %s, ref %s: %w", d.Digest.String(), r.CommonName(), errs.ErrDeleteDisabled)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete blob, digest %s, ref %s: %w", d.Digest.String(), r.CommonName(), err)
 	}
+	defer resp.Close()
 	if resp.HTTPResponse().StatusCode != 202 {
 		return fmt.Errorf("failed to delete blob, digest %s, ref %s: %w", d.Digest.String(), r.CommonName(), reghttp.HTTPError(resp.HTTPResponse().StatusCode))
 	}
@@ -186,6 +190,45 @@ func (reg *Reg) BlobPut(ctx context.Context, r ref.Ref, d descriptor.Descriptor,
 			putURL = nil
 		}
 	}
+	return reg.blobPutSession(ctx, r, d, putURL, rdr)
+}
+
+// BlobMountPut attempts a server side mount of the blob from rSrc to rTgt, uploading the blob when the mount fails.
+// When the mount is rejected with an upload location, that upload session is used rather than canceling it and starting a new one.
+// The rdrFn is only called when the blob needs to be uploaded, and the descriptor it returns is used for the upload.
+// The returned bool is true when the blob was mounted.
+func (reg *Reg) BlobMountPut(ctx context.Context, rSrc ref.Ref, rTgt ref.Ref, d descriptor.Descriptor, rdrFn func() (io.Reader, descriptor.Descriptor, error)) (bool, error) {
+	// dedup warnings
+	if w := warning.FromContext(ctx); w == nil {
+		ctx = warning.NewContext(ctx, &warning.Warning{Hook: warning.DefaultHook()})
+	}
+	putURL, _, err := reg.blobMount(ctx, rTgt, d, rSrc)
+	if err == nil {
+		return true, nil
+	}
+	if err != errs.ErrMountReturnedLocation {
+		reg.slog.Debug("Blob mount failed, falling back to an upload",
+			slog.String("src", rSrc.CommonName()),
+			slog.String("tgt", rTgt.CommonName()),
+			slog.String("digest", d.Digest.String()),
+			slog.String("err", err.Error()))
+		putURL = nil
+	}
+	rdr, dPut, err := rdrFn()
+	if err != nil {
+		if putURL != nil {
+			_ = reg.blobUploadCancel(ctx, rTgt, putURL)
+		}
+		return false, err
+	}
+	_, err = reg.blobPutSession(ctx, rTgt, dPut, putURL, rdr)
+	return false, err
+}
+
+// blobPutSession uploads a blob using the upload session at putURL, requesting a new session when putURL is nil.
+func (reg *Reg) blobPutSession(ctx context.Context, r ref.Ref, d descriptor.Descriptor, putURL *url.URL, rdr io.Reader) (descriptor.Descriptor, error) {
+	var err error
+	validDesc := (d.Size > 0 && d.Digest.Validate() == nil) || (d.Size == 0 && d.Digest == zeroDig)
 	// fallback to requesting upload URL
 	if putURL == nil {
 		putURL, err = reg.blobGetUploadURL(ctx, r, d)
@@ -193,8 +236,11 @@ func (reg *Reg) BlobPut(ctx context.Context, r ref.Ref, d descriptor.Descriptor,
 			return d, err
 		}
 	}
-	// send upload as one-chunk
+	// send upload as one-chunk, unless the registry has rejected a one-chunk upload
 	tryPut := validDesc
+	if fullPut, ok := reg.featureGet("blobPutFull", r.Registry, ""); ok && !fullPut {
+		tryPut = false
+	}
 	if tryPut {
 		host := reg.hostGet(r.Registry)
 		maxPut := host.BlobMax
@@ -210,6 +256,13 @@ func (reg *Reg) BlobPut(ctx context.Context, r ref.Ref, d descriptor.Descriptor,
 		if err == nil {
 			return d, nil
 		}
+		if blobPutFullRejected(err) {
+			// later blobs, including those from a reader that cannot seek, are sent with a chunked upload
+			reg.slog.Debug("Registry rejected a one-chunk blob upload, switching to chunked uploads",
+				slog.String("host", r.Registry),
+				slog.String("err", err.Error()))
+			reg.featureSet("blobPutFull", r.Registry, "", false)
+		}
 		// on failure, attempt to seek back to start to perform a chunked upload
 		rdrSeek, ok := rdr.(io.ReadSeeker)
 		if !ok {
@@ -278,17 +331,14 @@ func (reg *Reg) blobGetUploadURL(ctx context.Context, r ref.Ref, d descriptor.De
 			}
 		}
 	}
-	// Extract the location into a new putURL based on whether it's relative, fqdn with a scheme, or without a scheme.
+	// extract the location into a new putURL, which may be absolute or relative to the post URL
 	location := resp.HTTPResponse().Header.Get("Location")
 	if location == "" {
 		return nil, fmt.Errorf("failed to send blob post, ref %s: %w", r.CommonName(), errs.ErrMissingLocation)
 	}
 	reg.slog.Debug("Upload location received",
 		slog.String("location", location))
-
-	// put url may be relative to the above post URL, so parse in that context
-	postURL := resp.HTTPResponse().Request.URL
-	putURL, err := postURL.Parse(location)
+	putURL, err := blobUploadLocation(resp.HTTPResponse(), location)
 	if err != nil {
 		reg.slog.Warn("Location url failed to parse",
 			slog.String("location", location),
@@ -356,8 +406,7 @@ func (reg *Reg) blobMount(ctx context.Context, rTgt ref.Ref, d descriptor.Descri
 	location := resp.HTTPResponse().Header.Get("Location")
 	uuid := resp.HTTPResponse().Header.Get("Docker-Upload-UUID")
 	if resp.HTTPResponse().StatusCode == 202 && location != "" {
-		postURL := resp.HTTPResponse().Request.URL
-		putURL, err := postURL.Parse(location)
+		putURL, err := blobUploadLocation(resp.HTTPResponse(), location)
 		if err != nil {
 			reg.slog.Warn("Mount location header failed to parse",
 				slog.String("digest", d.Digest.String()),
@@ -372,7 +421,22 @@ func (reg *Reg) blobMount(ctx context.Context, rTgt ref.Ref, d descriptor.Descri
 	return nil, "", fmt.Errorf("failed to mount blob, digest %s, ref %s: %w", d.Digest.String(), rTgt.CommonName(), reghttp.HTTPError(resp.HTTPResponse().StatusCode))
 }
 
+// blobContentType returns the Content-Type header for a blob upload.
+// Registries expect "application/octet-stream", unless the host enables the "blobMediaType" API option to send the descriptor media type.
+func (reg *Reg) blobContentType(r ref.Ref, d descriptor.Descriptor) string {
+	host := reg.hostGet(r.Registry)
+	if d.MediaType != "" && host.APIOpts != nil {
+		if b, err := strconv.ParseBool(host.APIOpts["blobMediaType"]); err == nil && b {
+			return d.MediaType
+		}
+	}
+	return "application/octet-stream"
+}
+
 func (reg *Reg) blobPutUploadFull(ctx context.Context, r ref.Ref, d descriptor.Descriptor, putURL *url.URL, rdr io.Reader) error {
+	// copy the url to keep the upload session unchanged for a chunked upload fallback
+	putURLCopy := *putURL
+	putURL = &putURLCopy
 	// append digest to request to use the monolithic upload option
 	if putURL.RawQuery != "" {
 		putURL.RawQuery = putURL.RawQuery + "&digest=" + url.QueryEscape(d.Digest.String())
@@ -404,7 +468,7 @@ func (reg *Reg) blobPutUploadFull(ctx context.Context, r ref.Ref, d descriptor.D
 
 	// build/send request
 	header := http.Header{
-		"Content-Type": {"application/octet-stream"},
+		"Content-Type": {reg.blobContentType(r, d)},
 	}
 	req := &reghttp.Req{
 		MetaKind:   reqmeta.Blob,
@@ -429,6 +493,19 @@ func (reg *Reg) blobPutUploadFull(ctx context.Context, r ref.Ref, d descriptor.D
 	return nil
 }
 
+// blobPutFullRejected returns true when the error from a one-chunk upload indicates the registry requires a chunked upload.
+func blobPutFullRejected(err error) bool {
+	var errHTTP *errs.HTTPError
+	if !errors.As(err, &errHTTP) {
+		return false
+	}
+	switch errHTTP.StatusCode {
+	case http.StatusMethodNotAllowed, http.StatusLengthRequired, http.StatusRequestEntityTooLarge, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
 func (reg *Reg) blobPutUploadChunked(ctx context.Context, r ref.Ref, d descriptor.Descriptor, putURL *url.URL, rdr io.Reader) (descriptor.Descriptor, error) {
 	host := reg.hostGet(r.Registry)
 	bufSize := host.BlobChunk
@@ -500,7 +577,7 @@ func (reg *Reg) blobPutUploadChunked(ctx context.Context, r ref.Ref, d descripto
 		if chunkSize > 0 {
 			// write chunk
 			header := http.Header{
-				"Content-Type":  {"application/octet-stream"},
+				"Content-Type":  {reg.blobContentType(r, d)},
 				"Content-Range": {fmt.Sprintf("%d-%d", chunkStart, chunkStart+int64(chunkSize)-1)},
 			}
 			req := &reghttp.Req{
@@ -517,7 +594,33 @@ func (reg *Reg) blobPutUploadChunked(ctx context.Context, r ref.Ref, d descripto
 			}
 			resp, err := reg.reghttp.Do(ctx, req)
 			if err != nil && !errors.Is(err, errs.ErrHTTPStatus) && !errors.Is(err, errs.ErrNotFound) {
-				return d, fmt.Errorf("failed to send blob (chunk), ref %s: http do: %w", r.CommonName(), err)
+				// resume the existing upload session rather than restarting the upload
+				retryCur++
+				if ctx.Err() != nil || retryCur > retryLimit {
+					return d, fmt.Errorf("failed to send blob (chunk), ref %s: http do: %w", r.CommonName(), err)
+				}
+				statusResp, statusErr := reg.blobUploadStatus(ctx, r, &chunkURL)
+				if statusErr != nil {
+					return d, fmt.Errorf("failed to send blob (chunk), ref %s: http do: %w", r.CommonName(), err)
+				}
+				rangeEnd, rangeErr := blobUploadCurBytes(statusResp)
+				if rangeErr != nil {
+					return d, fmt.Errorf("failed to send blob (chunk), ref %s: http do: %w", r.CommonName(), err)
+				}
+				reg.slog.Debug("Resuming chunk upload from the upload status",
+					slog.String("ref", r.CommonName()),
+					slog.Int64("chunkStart", chunkStart),
+					slog.Int64("rangeEnd", rangeEnd),
+					slog.String("err", err.Error()))
+				chunkStart = rangeEnd + 1
+				if location := statusResp.Header.Get("Location"); location != "" {
+					parseURL, err := blobUploadLocation(statusResp, location)
+					if err != nil {
+						return d, fmt.Errorf("failed to send blob (parse next chunk location), ref %s: %w", r.CommonName(), err)
+					}
+					chunkURL = *parseURL
+				}
+				continue
 			}
 			err = resp.Close()
 			if err != nil {
@@ -562,8 +665,7 @@ func (reg *Reg) blobPutUploadChunked(ctx context.Context, r ref.Ref, d descripto
 			if location != "" {
 				reg.slog.Debug("Next chunk upload location received",
 					slog.String("location", location))
-				prevURL := httpResp.Request.URL
-				parseURL, err := prevURL.Parse(location)
+				parseURL, err := blobUploadLocation(httpResp, location)
 				if err != nil {
 					return d, fmt.Errorf("failed to send blob (parse next chunk location), ref %s: %w", r.CommonName(), err)
 				}
@@ -592,8 +694,9 @@ func (reg *Reg) blobPutUploadChunked(ctx context.Context, r ref.Ref, d descripto
 	}
 
 	header := http.Header{
-		"Content-Type": {"application/octet-stream"},
+		"Content-Type": {reg.blobContentType(r, d)},
 	}
+	// the empty body is sent with a "Content-Length: 0" header, some registries reject the commit without it
 	req := &reghttp.Req{
 		MetaKind:   reqmeta.Query,
 		Host:       r.Registry,
@@ -662,6 +765,26 @@ func (reg *Reg) blobUploadStatus(ctx context.Context, r ref.Ref, putURL *url.URL
 	return resp.HTTPResponse(), nil
 }
 
+// blobUploadLocation resolves a Location header from an upload response.
+// Registries may return an absolute URL, an absolute path, or a path relative to the request,
+// so the location is resolved against the URL of the request that returned it, after any redirects.
+func blobUploadLocation(resp *http.Response, location string) (*url.URL, error) {
+	if location == "" {
+		return nil, errs.ErrMissingLocation
+	}
+	if resp == nil || resp.Request == nil || resp.Request.URL == nil {
+		return nil, fmt.Errorf("request url is unknown for location %s", location)
+	}
+	u, err := resp.Request.URL.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme in location %s", location)
+	}
+	return u, nil
+}
+
 func blobUploadCurBytes(resp *http.Response) (int64, error) {
 	if resp == nil {
 		return 0, fmt.Errorf("missing response")
@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -402,6 +403,7 @@ func TestBlobPut(t *testing.T) {
 	blobRepo := "/proj/repo"
 	blobRepo5 := "/proj/repo5"
 	blobRepo6 := "/proj/repo6"
+	blobRepo7 := "/proj/repo7"
 	blobRepo1sha512 := "/proj/repo1-sha512"
 	blobRepo5sha512 := "/proj/repo5-sha512"
 	// privateRepo := "/proj/private"
@@ -422,6 +424,9 @@ func TestBlobPut(t *testing.T) {
 	d5, blob5 := reqresp.NewRandomBlob(blobLen5, seed+4)
 	blob6 := []byte{}
 	d6 := digest.SHA256.FromBytes(blob6)
+	blob7 := []byte(`{"sbom":"example"}`)
+	d7 := digest.SHA256.FromBytes(blob7)
+	mt7 := "application/vnd.example.sbom+json"
 	d1sha512 := digest.SHA512.FromBytes(blob1)
 	d5sha512 := digest.SHA512.FromBytes(blob5)
 	uuid1 := reqresp.NewRandomID(seed + 10)
@@ -431,6 +436,7 @@ func TestBlobPut(t *testing.T) {
 	uuid4 := reqresp.NewRandomID(seed + 14)
 	uuid5 := reqresp.NewRandomID(seed + 15)
 	uuid6 := reqresp.NewRandomID(seed + 16)
+	uuid7 := reqresp.NewRandomID(seed + 17)
 	// dMissing := digest.FromBytes([]byte("missing"))
 	user := "testing"
 	pass := "password"
@@ -1337,6 +1343,46 @@ func TestBlobPut(t *testing.T) {
 				},
 			},
 		},
+		// get upload7 location
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:   "POST for d7",
+				Method: "POST",
+				Path:   "/v2" + blobRepo7 + "/blobs/uploads/",
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusAccepted,
+				Headers: http.Header{
+					"Content-Length": {"0"},
+					"Location":       {uuid7},
+				},
+			},
+		},
+		// upload put for d7 with the media type in the Content-Type
+		{
+			ReqEntry: reqresp.ReqEntry{
+				DelOnUse: false,
+				Name:     "PUT for d7",
+				Method:   "PUT",
+				Path:     "/v2" + blobRepo7 + "/blobs/uploads/" + uuid7,
+				Query: map[string][]string{
+					"digest": {d7.String()},
+				},
+				Headers: http.Header{
+					"Content-Length": {fmt.Sprintf("%d", len(blob7))},
+					"Content-Type":   {mt7},
+				},
+				Body: blob7,
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusCreated,
+				Headers: http.Header{
+					"Content-Length":        {"0"},
+					"Location":              {"/v2" + blobRepo7 + "/blobs/" + d7.String()},
+					"Docker-Content-Digest": {d7.String()},
+				},
+			},
+		},
 	}
 	rrs = append(rrs, reqresp.BaseEntries...)
 	// create a server
@@ -1371,6 +1417,16 @@ func TestBlobPut(t *testing.T) {
 			BlobChunk: int64(blobChunk),
 			BlobMax:   int64(-1),
 		},
+		{
+			Name:      "mediatype." + tsHost,
+			Hostname:  tsHost,
+			TLS:       config.TLSDisabled,
+			BlobChunk: int64(blobChunk),
+			BlobMax:   int64(-1),
+			APIOpts: map[string]string{
+				"blobMediaType": "true",
+			},
+		},
 	}
 	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
 	// use short delays for fast tests
@@ -1560,5 +1616,351 @@ func TestBlobPut(t *testing.T) {
 		}
 	})
 
+	t.Run("Media type", func(t *testing.T) {
+		r, err := ref.New("mediatype." + tsURL.Host + blobRepo7)
+		if err != nil {
+			t.Fatalf("Failed creating ref: %v", err)
+		}
+		br := bytes.NewReader(blob7)
+		dp, err := reg.BlobPut(ctx, r, descriptor.Descriptor{MediaType: mt7, Digest: d7, Size: int64(len(blob7))}, br)
+		if err != nil {
+			t.Fatalf("Failed running BlobPut: %v", err)
+		}
+		if dp.Digest.String() != d7.String() {
+			t.Errorf("Digest mismatch, expected %s, received %s", d7.String(), dp.Digest.String())
+		}
+		if dp.MediaType != mt7 {
+			t.Errorf("Media type mismatch, expected %s, received %s", mt7, dp.MediaType)
+		}
+	})
+
 	// TODO: test failed mount (blobGetUploadURL)
 }
+
+func TestBlobUploadLocation(t *testing.T) {
+	t.Parallel()
+	reqURL, err := url.Parse("https://registry.example.com/v2/proj/repo/blobs/uploads/?mount=sha256:1234")
+	if err != nil {
+		t.Fatalf("failed to parse url: %v", err)
+	}
+	resp := &http.Response{Request: &http.Request{URL: reqURL}}
+	tests := []struct {
+		name      string
+		resp      *http.Response
+		location  string
+		expect    string
+		expectErr error
+	}{
+		{
+			name:     "absolute url",
+			resp:     resp,
+			location: "https://upload.example.com/v2/proj/repo/blobs/uploads/uuid?state=1",
+			expect:   "https://upload.example.com/v2/proj/repo/blobs/uploads/uuid?state=1",
+		},
+		{
+			name:     "absolute path",
+			resp:     resp,
+			location: "/v2/proj/repo/blobs/uploads/uuid?state=1",
+			expect:   "https://registry.example.com/v2/proj/repo/blobs/uploads/uuid?state=1",
+		},
+		{
+			name:     "relative path",
+			resp:     resp,
+			location: "uuid?state=1",
+			expect:   "https://registry.example.com/v2/proj/repo/blobs/uploads/uuid?state=1",
+		},
+		{
+			name:     "scheme relative",
+			resp:     resp,
+			location: "//upload.example.com/uuid",
+			expect:   "https://upload.example.com/uuid",
+		},
+		{
+			name:      "empty",
+			resp:      resp,
+			location:  "",
+			expectErr: errs.ErrMissingLocation,
+		},
+		{
+			name:     "missing request",
+			resp:     &http.Response{},
+			location: "uuid",
+		},
+		{
+			name:     "unsupported scheme",
+			resp:     resp,
+			location: "ftp://registry.example.com/uuid",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := blobUploadLocation(tc.resp, tc.location)
+			if tc.expect == "" {
+				if err == nil {
+					t.Errorf("location did not fail: %s", u.String())
+				} else if tc.expectErr != nil && !errors.Is(err, tc.expectErr) {
+					t.Errorf("unexpected error, expected %v, received %v", tc.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to resolve location: %v", err)
+			}
+			if u.String() != tc.expect {
+				t.Errorf("unexpected url, expected %s, received %s", tc.expect, u.String())
+			}
+		})
+	}
+}
+
+func TestBlobPutResume(t *testing.T) {
+	t.Parallel()
+	blobRepo := "/proj/repo"
+	ctx := context.Background()
+	seed := time.Now().UTC().Unix()
+	t.Logf("Using seed %d", seed)
+	blobChunk := 512
+	blobLen := 1024
+	blobResume := blobChunk + 100 // bytes received by the server before the connection failed
+	d1, blob1 := reqresp.NewRandomBlob(blobLen, seed)
+	uuid1 := reqresp.NewRandomID(seed + 10)
+	rrs := []reqresp.ReqResp{
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:   "POST for d1",
+				Method: "POST",
+				Path:   "/v2" + blobRepo + "/blobs/uploads/",
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusAccepted,
+				Headers: http.Header{
+					"Content-Length": {"0"},
+					"Location":       {uuid1},
+				},
+			},
+		},
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:     "PATCH 1 for d1",
+				Method:   "PATCH",
+				Path:     "/v2" + blobRepo + "/blobs/uploads/" + uuid1,
+				Query:    map[string][]string{},
+				IfState:  []string{""},
+				SetState: "chunk2",
+				Headers: http.Header{
+					"Content-Length": {fmt.Sprintf("%d", blobChunk)},
+					"Content-Range":  {fmt.Sprintf("0-%d", blobChunk-1)},
+				},
+				Body: blob1[0:blobChunk],
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusAccepted,
+				Headers: http.Header{
+					"Content-Length": {"0"},
+					"Range":          {fmt.Sprintf("bytes=0-%d", blobChunk-1)},
+					"Location":       {uuid1 + "?chunk=2"},
+				},
+			},
+		},
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:    "PATCH 2 for d1 fails",
+				Method:  "PATCH",
+				Path:    "/v2" + blobRepo + "/blobs/uploads/" + uuid1,
+				Query:   map[string][]string{"chunk": {"2"}},
+				IfState: []string{"chunk2"},
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusAccepted,
+				Fail:   true,
+			},
+		},
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:     "GET status for d1",
+				Method:   "GET",
+				Path:     "/v2" + blobRepo + "/blobs/uploads/" + uuid1,
+				Query:    map[string][]string{"chunk": {"2"}},
+				IfState:  []string{"chunk2"},
+				SetState: "resumed",
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusNoContent,
+				Headers: http.Header{
+					"Range":    {fmt.Sprintf("bytes=0-%d", blobResume-1)},
+					"Location": {uuid1 + "?chunk=3"},
+				},
+			},
+		},
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:    "PATCH 3 for d1",
+				Method:  "PATCH",
+				Path:    "/v2" + blobRepo + "/blobs/uploads/" + uuid1,
+				Query:   map[string][]string{"chunk": {"3"}},
+				IfState: []string{"resumed"},
+				Headers: http.Header{
+					"Content-Length": {fmt.Sprintf("%d", blobLen-blobResume)},
+					"Content-Range":  {fmt.Sprintf("%d-%d", blobResume, blobLen-1)},
+				},
+				Body: blob1[blobResume:],
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusAccepted,
+				Headers: http.Header{
+					"Content-Length": {"0"},
+					"Range":          {fmt.Sprintf("bytes=0-%d", blobLen-1)},
+					"Location":       {uuid1 + "?chunk=4"},
+				},
+			},
+		},
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:    "PUT for d1",
+				Method:  "PUT",
+				Path:    "/v2" + blobRepo + "/blobs/uploads/" + uuid1,
+				Query:   map[string][]string{"chunk": {"4"}, "digest": {d1.String()}},
+				IfState: []string{"resumed"},
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusCreated,
+				Headers: http.Header{
+					"Content-Length":        {"0"},
+					"Location":              {"/v2" + blobRepo + "/blobs/" + d1.String()},
+					"Docker-Content-Digest": {d1.String()},
+				},
+			},
+		},
+	}
+	rrs = append(rrs, reqresp.BaseEntries...)
+	ts := httptest.NewServer(reqresp.NewHandler(t, rrs))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	rcHosts := []*config.Host{
+		{
+			Name:      tsHost,
+			Hostname:  tsHost,
+			TLS:       config.TLSDisabled,
+			BlobChunk: int64(blobChunk),
+			BlobMax:   int64(blobChunk),
+		},
+	}
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	// use short delays for fast tests
+	delayInit, _ := time.ParseDuration("0.05s")
+	delayMax, _ := time.ParseDuration("0.10s")
+	reg := New(
+		WithConfigHosts(rcHosts),
+		WithSlog(log),
+		WithDelay(delayInit, delayMax),
+	)
+	r, err := ref.New(tsHost + blobRepo)
+	if err != nil {
+		t.Fatalf("Failed creating ref: %v", err)
+	}
+	dp, err := reg.BlobPut(ctx, r, descriptor.Descriptor{Digest: d1, Size: int64(len(blob1))}, bytes.NewReader(blob1))
+	if err != nil {
+		t.Fatalf("Failed running BlobPut: %v", err)
+	}
+	if dp.Digest != d1 || dp.Size != int64(len(blob1)) {
+		t.Errorf("Descriptor mismatch, expected %s/%d, received %s/%d", d1.String(), len(blob1), dp.Digest.String(), dp.Size)
+	}
+}
+
+func TestBlobPutChunkedFinalLength(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	blobRepo := "/proj/repo"
+	blobChunk := 512
+	d1, blob1 := reqresp.NewRandomBlob(blobChunk*2+100, time.Now().UTC().Unix())
+	tt := []struct {
+		name  string
+		http2 bool
+	}{
+		{
+			name: "http1",
+		},
+		{
+			name:  "http2",
+			http2: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// minimal chunked upload handler, recording the headers of the final put
+			var mu sync.Mutex
+			received := []byte{}
+			finalLen := ""
+			finalProto := ""
+			uploadPath := "/v2" + blobRepo + "/blobs/uploads/session"
+			ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case req.Method == http.MethodPost && req.URL.Path == "/v2"+blobRepo+"/blobs/uploads/":
+					w.Header().Set("Location", uploadPath)
+					w.WriteHeader(http.StatusAccepted)
+				case req.Method == http.MethodPatch && req.URL.Path == uploadPath:
+					b, _ := io.ReadAll(req.Body)
+					received = append(received, b...)
+					w.Header().Set("Location", uploadPath)
+					w.Header().Set("Range", fmt.Sprintf("0-%d", len(received)-1))
+					w.WriteHeader(http.StatusAccepted)
+				case req.Method == http.MethodPut && req.URL.Path == uploadPath:
+					finalLen = req.Header.Get("Content-Length")
+					finalProto = req.Proto
+					if req.URL.Query().Get("digest") != d1.String() || !bytes.Equal(received, blob1) {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.Header().Set("Location", "/v2"+blobRepo+"/blobs/"+d1.String())
+					w.Header().Set("Docker-Content-Digest", d1.String())
+					w.WriteHeader(http.StatusCreated)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			tls := config.TLSDisabled
+			if tc.http2 {
+				ts.EnableHTTP2 = true
+				ts.StartTLS()
+				tls = config.TLSInsecure
+			} else {
+				ts.Start()
+			}
+			defer ts.Close()
+			tsURL, _ := url.Parse(ts.URL)
+			tsHost := tsURL.Host
+			reg := New(
+				WithConfigHosts([]*config.Host{
+					{
+						Name:      tsHost,
+						Hostname:  tsHost,
+						TLS:       tls,
+						BlobChunk: int64(blobChunk),
+						BlobMax:   int64(blobChunk),
+					},
+				}),
+				WithSlog(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))),
+				WithDelay(time.Millisecond*10, time.Millisecond*20),
+			)
+			r, err := ref.New(tsHost + blobRepo)
+			if err != nil {
+				t.Fatalf("failed creating ref: %v", err)
+			}
+			_, err = reg.BlobPut(ctx, r, descriptor.Descriptor{Digest: d1, Size: int64(len(blob1))}, bytes.NewReader(blob1))
+			if err != nil {
+				t.Fatalf("failed running BlobPut: %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if finalLen != "0" {
+				t.Errorf("final put did not include a zero Content-Length, received %q over %s", finalLen, finalProto)
+			}
+			if tc.http2 && finalProto != "HTTP/2.0" {
+				t.Errorf("unexpected protocol %s", finalProto)
+			}
+		})
+	}
+}
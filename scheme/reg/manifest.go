@@ -71,6 +71,9 @@ func (reg *Reg) ManifestDelete(ctx context.Context, r ref.Ref, opts ...scheme.Ma
 		Path:       "manifests/" + r.Digest,
 	}
 	resp, err := reg.reghttp.Do(ctx, req)
+	if isDeleteDisabled(resp) {
+		return fmt.Errorf("failed to delete manifest %s: %w", r.CommonName(), errs.ErrDeleteDisabled)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete manifest %s: %w", r.CommonName(), err)
 	}
@@ -108,6 +111,7 @@ func (reg *Reg) ManifestGet(ctx context.Context, r ref.Ref) (manifest.Manifest,
 			mediatype.Docker1Manifest,
 			mediatype.OCI1Artifact,
 		},
+		"Accept-Encoding": []string{"gzip"},
 	}
 	req := &reghttp.Req{
 		MetaKind:   reqmeta.Manifest,
@@ -131,9 +135,12 @@ func (reg *Reg) ManifestGet(ctx context.Context, r ref.Ref) (manifest.Manifest,
 	if size > 0 && reg.manifestMaxPull > 0 && int64(size) > reg.manifestMaxPull {
 		return nil, fmt.Errorf("manifest too large, received %d, limit %d: %s%.0w", size, reg.manifestMaxPull, r.CommonName(), errs.ErrSizeLimitExceeded)
 	}
-	rdr := &limitread.LimitRead{
-		Reader: resp,
-		Limit:  reg.manifestMaxPull,
+	var rdr io.Reader = resp
+	if reg.manifestMaxPull > 0 {
+		rdr = &limitread.LimitRead{
+			Reader: resp,
+			Limit:  reg.manifestMaxPull,
+		}
 	}
 
 	// read manifest
@@ -309,6 +309,16 @@ func TestManifest(t *testing.T) {
 				},
 			},
 		},
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:   "Delete disabled",
+				Method: "DELETE",
+				Path:   "/v2" + repoPath + "/manifests/" + mDigest256.String(),
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusMethodNotAllowed,
+			},
+		},
 	}
 	rrs = append(rrs, reqresp.BaseEntries...)
 	// create a server
@@ -525,6 +535,21 @@ func TestManifest(t *testing.T) {
 			t.Fatalf("unexpected error, expected %v, received %v", errs.ErrSizeLimitExceeded, err)
 		}
 	})
+	t.Run("Size Limit Configured", func(t *testing.T) {
+		regSmall := New(
+			WithConfigHosts(rcHosts),
+			WithSlog(log),
+			WithManifestMax(0, int64(mLen-1)),
+		)
+		getRef, err := ref.New(tsURL.Host + repoPath + ":" + getTag256)
+		if err != nil {
+			t.Fatalf("Failed creating ref: %v", err)
+		}
+		_, err = regSmall.ManifestGet(ctx, getRef)
+		if !errors.Is(err, errs.ErrSizeLimitExceeded) {
+			t.Fatalf("unexpected error, expected %v, received %v", errs.ErrSizeLimitExceeded, err)
+		}
+	})
 	t.Run("Read beyond size", func(t *testing.T) {
 		shortRef, err := ref.New(tsURL.Host + repoPath + ":" + shortReadTag)
 		if err != nil {
@@ -626,4 +651,21 @@ func TestManifest(t *testing.T) {
 			t.Errorf("unexpected error, expected %v, received %v", errs.ErrSizeLimitExceeded, err)
 		}
 	})
+
+	t.Run("Delete disabled", func(t *testing.T) {
+		delRef, err := ref.New(tsURL.Host + repoPath + "@" + mDigest256.String())
+		if err != nil {
+			t.Fatalf("failed creating ref: %v", err)
+		}
+		err = reg.ManifestDelete(ctx, delRef)
+		if err == nil {
+			t.Fatalf("delete manifest did not fail")
+		}
+		if !errors.Is(err, errs.ErrDeleteDisabled) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrDeleteDisabled, err)
+		}
+		if !errors.Is(err, errs.ErrHTTPStatus) {
+			t.Errorf("error does not wrap %v, received %v", errs.ErrHTTPStatus, err)
+		}
+	})
 }
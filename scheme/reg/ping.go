@@ -3,9 +3,14 @@ package reg
 import (
 	"context"
 	"fmt"
+	"net/http"
+
+	"github.com/opencontainers/go-digest"
 
 	"github.com/regclient/regclient/internal/reghttp"
 	"github.com/regclient/regclient/internal/reqmeta"
+	"github.com/regclient/regclient/scheme"
+	"github.com/regclient/regclient/types/mediatype"
 	"github.com/regclient/regclient/types/ping"
 	"github.com/regclient/regclient/types/ref"
 )
@@ -37,3 +42,82 @@ func (reg *Reg) Ping(ctx context.Context, r ref.Ref) (ping.Result, error) {
 
 	return ret, nil
 }
+
+// capabilityProbeDigest does not match any content, allowing APIs to be probed without a side effect.
+var capabilityProbeDigest = digest.FromString("regclient capability probe")
+
+// Capabilities probes the registry for support of OCI manifests, the referrers API, and the delete API.
+// A tag or digest in the ref is needed to detect OCI manifests, and that manifest must be an OCI media type.
+// The delete API is only probed with [scheme.WithCapabilityProbeDelete], deleting a digest that does not exist.
+// The referrers API result is saved for later referrer requests to the same repository.
+func (reg *Reg) Capabilities(ctx context.Context, r ref.Ref, opts ...scheme.CapabilityOpts) (ping.Capabilities, error) {
+	config := scheme.CapabilityConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	c := ping.Capabilities{}
+	if _, err := reg.Ping(ctx, r); err != nil {
+		return c, err
+	}
+	// OCI manifests, only OCI media types are accepted
+	if r.Tag != "" || r.Digest != "" {
+		tagOrDigest := r.Tag
+		if r.Digest != "" {
+			tagOrDigest = r.Digest
+		}
+		req := &reghttp.Req{
+			MetaKind:   reqmeta.Head,
+			Host:       r.Registry,
+			Method:     "HEAD",
+			Repository: r.Repository,
+			Path:       "manifests/" + tagOrDigest,
+			Headers: http.Header{
+				"Accept": []string{mediatype.OCI1ManifestList, mediatype.OCI1Manifest},
+			},
+			IgnoreErr: true,
+		}
+		resp, err := reg.reghttp.Do(ctx, req)
+		if err == nil {
+			mt := mediatype.Base(resp.HTTPResponse().Header.Get("Content-Type"))
+			if resp.HTTPResponse().StatusCode == http.StatusOK && (mt == mediatype.OCI1Manifest || mt == mediatype.OCI1ManifestList) {
+				c.OCIManifest = ping.SupportYes
+			}
+			_ = resp.Close()
+		}
+	}
+	// referrers API
+	rProbe := r.SetDigest(capabilityProbeDigest.String())
+	if r.Digest != "" {
+		rProbe = r.SetDigest(r.Digest)
+	}
+	if reg.referrerPing(ctx, rProbe) {
+		c.Referrers = ping.SupportYes
+	} else {
+		c.Referrers = ping.SupportNo
+	}
+	// delete API, a missing digest is a 404 whether or not delete is enabled, so that result is unknown
+	if config.ProbeDelete {
+		req := &reghttp.Req{
+			MetaKind:   reqmeta.Query,
+			Host:       r.Registry,
+			NoMirrors:  true,
+			Method:     "DELETE",
+			Repository: r.Repository,
+			Path:       "manifests/" + capabilityProbeDigest.String(),
+			IgnoreErr:  true,
+		}
+		resp, err := reg.reghttp.Do(ctx, req)
+		if isDeleteDisabled(resp) {
+			c.Delete = ping.SupportNo
+		} else if resp != nil && resp.HTTPResponse() != nil && resp.HTTPResponse().StatusCode == http.StatusAccepted {
+			c.Delete = ping.SupportYes
+		}
+		if err == nil {
+			_ = resp.Close()
+		}
+	}
+	if ctx.Err() != nil {
+		return c, ctx.Err()
+	}
+	return c, nil
+}
@@ -146,6 +146,12 @@ func (reg *Reg) featureSet(kind, registry, repo string, enabled bool) {
 	reg.muHost.Unlock()
 }
 
+// isDeleteDisabled returns true when a registry responds to a delete request with a 405 Method Not Allowed.
+// This is returned by registries configured without delete support, e.g. distribution without REGISTRY_STORAGE_DELETE_ENABLED.
+func isDeleteDisabled(resp *reghttp.Resp) bool {
+	return resp != nil && resp.HTTPResponse() != nil && resp.HTTPResponse().StatusCode == http.StatusMethodNotAllowed
+}
+
 // WithBlobSize overrides default blob sizes
 func WithBlobSize(size, max int64) Opts {
 	return func(r *Reg) {
@@ -234,7 +240,9 @@ func WithHTTPClient(hc *http.Client) Opts {
 	}
 }
 
-// WithManifestMax sets the push and pull limits for manifests
+// WithManifestMax sets the push and pull limits for manifests.
+// The pull limit defaults to 8MB and guards against a registry returning an unbounded body.
+// A limit <= 0 disables the check.
 func WithManifestMax(push, pull int64) Opts {
 	return func(r *Reg) {
 		r.manifestMaxPush = push
@@ -242,6 +250,14 @@ func WithManifestMax(push, pull int64) Opts {
 	}
 }
 
+// WithRateLimit limits the rate of all requests to registries, waiting for a token before each request.
+// Requests are released at reqPerSec with bursts of up to burst requests, and a reqPerSec of 0 disables the limit.
+func WithRateLimit(reqPerSec float64, burst int) Opts {
+	return func(r *Reg) {
+		r.reghttpOpts = append(r.reghttpOpts, reghttp.WithRateLimit(reqPerSec, burst))
+	}
+}
+
 // WithRetryLimit restricts the number of retries (defaults to 5)
 func WithRetryLimit(l int) Opts {
 	return func(r *Reg) {
@@ -264,6 +280,15 @@ func WithTransport(t *http.Transport) Opts {
 	}
 }
 
+// WithTransportWrap wraps the http transport with custom middleware, e.g. for tracing, metrics, or request signing.
+// Each wrap function receives the next [http.RoundTripper] and returns a new one, the first wrap sees each request first.
+// Wraps are applied per registry after the TLS settings, and also apply to auth token requests.
+func WithTransportWrap(wraps ...func(http.RoundTripper) http.RoundTripper) Opts {
+	return func(r *Reg) {
+		r.reghttpOpts = append(r.reghttpOpts, reghttp.WithTransportWrap(wraps...))
+	}
+}
+
 // WithUserAgent sets a user agent header
 func WithUserAgent(ua string) Opts {
 	return func(r *Reg) {
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strconv"
 
+	"github.com/regclient/regclient/internal/httplink"
 	"github.com/regclient/regclient/internal/reghttp"
 	"github.com/regclient/regclient/internal/reqmeta"
 	"github.com/regclient/regclient/scheme"
@@ -31,9 +32,51 @@ func (reg *Reg) RepoList(ctx context.Context, hostname string, opts ...scheme.Re
 	if config.Limit > 0 {
 		query.Set("n", strconv.Itoa(config.Limit))
 	}
+	rl, link, err := reg.repoListReq(ctx, hostname, query, nil)
+	if err != nil {
+		return nil, err
+	}
 
+	for {
+		// if limit reached, stop searching
+		if config.Limit > 0 && len(rl.Repositories) >= config.Limit {
+			break
+		}
+		rlHead, err := rl.RawHeaders()
+		if err != nil {
+			return rl, err
+		}
+		links, err := httplink.Parse(rlHead.Values("Link"))
+		if err != nil {
+			return rl, err
+		}
+		next, err := links.Get("rel", "next")
+		if err != nil {
+			// no Link header with rel="next", all entries received
+			break
+		}
+		link, err = link.Parse(next.URI)
+		if err != nil {
+			return rl, fmt.Errorf("repo list failed to parse Link: %w", err)
+		}
+		rlAdd, linkAdd, err := reg.repoListReq(ctx, hostname, nil, link)
+		if err != nil {
+			return rl, fmt.Errorf("repo list failed to get Link: %w", err)
+		}
+		err = rl.Append(rlAdd)
+		if err != nil {
+			return rl, fmt.Errorf("repo list failed to append entries: %w", err)
+		}
+		link = linkAdd
+	}
+	return rl, nil
+}
+
+// repoListReq requests a single page of the repository list, returning the list and the URL of the request.
+func (reg *Reg) repoListReq(ctx context.Context, hostname string, query url.Values, link *url.URL) (*repo.RepoList, *url.URL, error) {
 	headers := http.Header{
-		"Accept": []string{"application/json"},
+		"Accept":          []string{"application/json"},
+		"Accept-Encoding": []string{"gzip"},
 	}
 	req := &reghttp.Req{
 		MetaKind:  reqmeta.Query,
@@ -43,15 +86,16 @@ func (reg *Reg) RepoList(ctx context.Context, hostname string, opts ...scheme.Re
 		Path:      "_catalog",
 		NoPrefix:  true,
 		Query:     query,
+		DirectURL: link,
 		Headers:   headers,
 	}
 	resp, err := reg.reghttp.Do(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list repositories for %s: %w", hostname, err)
+		return nil, nil, fmt.Errorf("failed to list repositories for %s: %w", hostname, err)
 	}
 	defer resp.Close()
 	if resp.HTTPResponse().StatusCode != 200 {
-		return nil, fmt.Errorf("failed to list repositories for %s: %w", hostname, reghttp.HTTPError(resp.HTTPResponse().StatusCode))
+		return nil, nil, fmt.Errorf("failed to list repositories for %s: %w", hostname, reghttp.HTTPError(resp.HTTPResponse().StatusCode))
 	}
 
 	respBody, err := io.ReadAll(resp)
@@ -59,7 +103,7 @@ func (reg *Reg) RepoList(ctx context.Context, hostname string, opts ...scheme.Re
 		reg.slog.Warn("Failed to read repo list",
 			slog.String("err", err.Error()),
 			slog.String("host", hostname))
-		return nil, fmt.Errorf("failed to read repo list for %s: %w", hostname, err)
+		return nil, nil, fmt.Errorf("failed to read repo list for %s: %w", hostname, err)
 	}
 	mt := mediatype.Base(resp.HTTPResponse().Header.Get("Content-Type"))
 	rl, err := repo.New(
@@ -73,7 +117,7 @@ func (reg *Reg) RepoList(ctx context.Context, hostname string, opts ...scheme.Re
 			slog.String("err", err.Error()),
 			slog.String("body", string(respBody)),
 			slog.String("host", hostname))
-		return nil, fmt.Errorf("failed to parse repo list for %s: %w", hostname, err)
+		return nil, nil, fmt.Errorf("failed to parse repo list for %s: %w", hostname, err)
 	}
-	return rl, nil
+	return rl, resp.HTTPResponse().Request.URL, nil
 }
@@ -82,6 +82,40 @@ func TestRepo(t *testing.T) {
 				},
 			},
 		}},
+		"link": {
+			{
+				ReqEntry: reqresp.ReqEntry{
+					Name:   "Link next",
+					Method: "GET",
+					Path:   "/v2/_catalog",
+					Query: map[string][]string{
+						"last": {listRegistry[partialLen-1]},
+					},
+				},
+				RespEntry: reqresp.RespEntry{
+					Status: http.StatusOK,
+					Body:   []byte(fmt.Sprintf(`{"repositories":["%s"]}`, strings.Join(listRegistry[partialLen:], `","`))),
+					Headers: http.Header{
+						"Content-Type": {"text/plain; charset=utf-8"},
+					},
+				},
+			},
+			{
+				ReqEntry: reqresp.ReqEntry{
+					Name:   "Link first",
+					Method: "GET",
+					Path:   "/v2/_catalog",
+				},
+				RespEntry: reqresp.RespEntry{
+					Status: http.StatusOK,
+					Body:   []byte(fmt.Sprintf(`{"repositories":["%s"]}`, strings.Join(listRegistry[:partialLen], `","`))),
+					Headers: http.Header{
+						"Content-Type": {"text/plain; charset=utf-8"},
+						"Link":         {fmt.Sprintf(`</v2/_catalog?last=%s&n=%d>; rel="next"`, listRegistry[partialLen-1], partialLen)},
+					},
+				},
+			},
+		},
 		"registry": {
 			{
 				ReqEntry: reqresp.ReqEntry{
@@ -214,6 +248,26 @@ func TestRepo(t *testing.T) {
 		}
 
 	})
+	t.Run("Link", func(t *testing.T) {
+		u, _ := url.Parse(tss["link"].URL)
+		host := u.Host
+		rl, err := reg.RepoList(ctx, host)
+		if err != nil {
+			t.Fatalf("error listing repos: %v", err)
+		}
+		rlRepos, err := rl.GetRepos()
+		if err != nil {
+			t.Errorf("error retrieving repos: %v", err)
+		} else if stringSliceCmp(listRegistry, rlRepos) == false {
+			t.Errorf("repositories do not match: expected %v, received %v", listRegistry, rlRepos)
+		}
+		body, err := rl.RawBody()
+		if err != nil {
+			t.Errorf("error retrieving body: %v", err)
+		} else if !strings.Contains(string(body), listRegistry[0]) || !strings.Contains(string(body), listRegistry[len(listRegistry)-1]) {
+			t.Errorf("body missing entries: %s", body)
+		}
+	})
 	// test with http errors
 	t.Run("Disabled", func(t *testing.T) {
 		u, _ := url.Parse(tss["disabled"].URL)
@@ -258,7 +258,8 @@ func (reg *Reg) tagListOCI(ctx context.Context, r ref.Ref, config scheme.TagConf
 		query.Set("n", strconv.Itoa(config.Limit))
 	}
 	headers := http.Header{
-		"Accept": []string{"application/json"},
+		"Accept":          []string{"application/json"},
+		"Accept-Encoding": []string{"gzip"},
 	}
 	req := &reghttp.Req{
 		MetaKind:   reqmeta.Query,
@@ -302,7 +303,8 @@ func (reg *Reg) tagListOCI(ctx context.Context, r ref.Ref, config scheme.TagConf
 
 func (reg *Reg) tagListLink(ctx context.Context, r ref.Ref, _ scheme.TagConfig, link *url.URL) (*tag.List, error) {
 	headers := http.Header{
-		"Accept": []string{"application/json"},
+		"Accept":          []string{"application/json"},
+		"Accept-Encoding": []string{"gzip"},
 	}
 	req := &reghttp.Req{
 		MetaKind:   reqmeta.Query,
@@ -1,6 +1,8 @@
 package reg
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -41,6 +43,18 @@ func TestTag(t *testing.T) {
 	listTagBody2 := []byte(fmt.Sprintf("{\"name\":\"%s\",\"tags\":[\"%s\"]}",
 		strings.TrimLeft(repoPath, "/"),
 		strings.Join(listTagList[pageLen:], "\",\"")))
+	repoPathGzip := "/proj-gzip"
+	gzipTagList := make([]string, 5000)
+	for i := range gzipTagList {
+		gzipTagList[i] = fmt.Sprintf("v%05d", i)
+	}
+	gzipTagBody := []byte(fmt.Sprintf("{\"name\":\"%s\",\"tags\":[\"%s\"]}",
+		strings.TrimLeft(repoPathGzip, "/"),
+		strings.Join(gzipTagList, "\",\"")))
+	gzipTagBuf := &bytes.Buffer{}
+	gzw := gzip.NewWriter(gzipTagBuf)
+	_, _ = gzw.Write(gzipTagBody)
+	_ = gzw.Close()
 	missingRepo := "/missing"
 	delOCITag := "del-oci"
 	delFallbackTag := "del-fallback"
@@ -101,6 +115,25 @@ func TestTag(t *testing.T) {
 				Body: listTagBody,
 			},
 		},
+		{
+			ReqEntry: reqresp.ReqEntry{
+				Name:   "tag get gzip",
+				Method: "GET",
+				Path:   "/v2" + repoPathGzip + "/tags/list",
+				Headers: http.Header{
+					"Accept-Encoding": {"gzip"},
+				},
+			},
+			RespEntry: reqresp.RespEntry{
+				Status: http.StatusOK,
+				Headers: http.Header{
+					"Content-Encoding": {"gzip"},
+					"Content-Length":   {fmt.Sprintf("%d", gzipTagBuf.Len())},
+					"Content-Type":     {"application/json"},
+				},
+				Body: gzipTagBuf.Bytes(),
+			},
+		},
 		{
 			ReqEntry: reqresp.ReqEntry{
 				Name:   "repo2 tag get page 2",
@@ -280,6 +313,24 @@ func TestTag(t *testing.T) {
 			t.Errorf("returned list mismatch, expected %v, received %v", listTagList, tags)
 		}
 	})
+	// list tags with a gzip compressed response
+	t.Run("List gzip", func(t *testing.T) {
+		listRef, err := ref.New(tsURL.Host + repoPathGzip)
+		if err != nil {
+			t.Fatalf("failed creating getRef: %v", err)
+		}
+		tl, err := reg.TagList(ctx, listRef)
+		if err != nil {
+			t.Fatalf("failed to list tags: %v", err)
+		}
+		tags, err := tl.GetTags()
+		if err != nil {
+			t.Fatalf("failed to extract tag list: %v", err)
+		}
+		if !stringSliceCmp(tags, gzipTagList) {
+			t.Errorf("returned list mismatch, expected %d tags, received %d", len(gzipTagList), len(tags))
+		}
+	})
 	// list tags with pagination
 	t.Run("Pagination", func(t *testing.T) {
 		listRef, err := ref.New(tsURL.Host + repoPath)
@@ -50,6 +50,18 @@ type API interface {
 	TagList(ctx context.Context, r ref.Ref, opts ...TagOpts) (*tag.List, error)
 }
 
+// BlobMountPutter is used to check if a scheme can fall back to an upload when a blob mount fails.
+// The upload reuses any upload session returned by the failed mount, and rdrFn is only called when an upload is needed.
+// The descriptor returned by rdrFn is used for the upload since the size may only be known after the blob is opened.
+type BlobMountPutter interface {
+	BlobMountPut(ctx context.Context, refSrc ref.Ref, refTgt ref.Ref, d descriptor.Descriptor, rdrFn func() (io.Reader, descriptor.Descriptor, error)) (bool, error)
+}
+
+// Capabler is used to check if a scheme implements the Capabilities API.
+type Capabler interface {
+	Capabilities(ctx context.Context, r ref.Ref, opts ...CapabilityOpts) (ping.Capabilities, error)
+}
+
 // Closer is used to check if a scheme implements the Close API.
 type Closer interface {
 	Close(ctx context.Context, r ref.Ref) error
@@ -69,6 +81,22 @@ type Throttler interface {
 	Throttle(r ref.Ref, put bool) []*pqueue.Queue[reqmeta.Data]
 }
 
+// CapabilityConfig is used by schemes to import [CapabilityOpts].
+type CapabilityConfig struct {
+	ProbeDelete bool // send a delete request to detect support for the delete API
+}
+
+// CapabilityOpts is used to set options on the capabilities API.
+type CapabilityOpts func(*CapabilityConfig)
+
+// WithCapabilityProbeDelete detects support for the delete API by deleting a digest that does not exist.
+// This sends a DELETE request to the registry, and the result is only known when the registry rejects the request.
+func WithCapabilityProbeDelete() CapabilityOpts {
+	return func(config *CapabilityConfig) {
+		config.ProbeDelete = true
+	}
+}
+
 // ManifestConfig is used by schemes to import [ManifestOpts].
 type ManifestConfig struct {
 	CheckReferrers bool
@@ -0,0 +1,364 @@
+package regclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/regclient/regclient/scheme"
+	"github.com/regclient/regclient/types/descriptor"
+	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/manifest"
+	"github.com/regclient/regclient/types/mediatype"
+	v1 "github.com/regclient/regclient/types/oci/v1"
+	"github.com/regclient/regclient/types/platform"
+	"github.com/regclient/regclient/types/ref"
+)
+
+const (
+	// SignatureAnnotation is the layer annotation containing the base64 encoded cosign signature.
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// signatureType is the type used in the simple signing payload for container images.
+	signatureType = "cosign container image signature"
+	// signPayloadMax is the largest signature payload read from a registry, simple signing payloads are typically under 1KB.
+	signPayloadMax = 256 * 1024
+)
+
+// Signer signs a payload, returning the signature.
+// The key and algorithm are managed by the caller, regclient only packages the result.
+type Signer func(ctx context.Context, payload []byte) ([]byte, error)
+
+// Verifier verifies the signatures of an image, see [ImageWithVerify].
+// It is called with the digest of the image and every signature found, returning an error when none of the signatures are trusted.
+// The verifier should also check the payload references the image digest.
+type Verifier func(ctx context.Context, dig digest.Digest, sigs []ImageSignature) error
+
+// ImageSignature is a cosign signature of an image passed to a [Verifier].
+type ImageSignature struct {
+	Ref       ref.Ref               // signature artifact
+	Layer     descriptor.Descriptor // layer with the payload, annotations include any signing certificate
+	Payload   []byte                // simple signing payload that was signed
+	Signature []byte                // signature decoded from the layer annotation
+}
+
+type signOpt struct {
+	annotations map[string]string
+	optional    map[string]interface{}
+	platform    string
+	referrers   bool
+}
+
+// SignOpts define options for ImageSign.
+type SignOpts func(*signOpt)
+
+// SignWithAnnotation adds an annotation to the signature layer.
+// Cosign uses this for values like the signing certificate and chain.
+func SignWithAnnotation(key, value string) SignOpts {
+	return func(opts *signOpt) {
+		if opts.annotations == nil {
+			opts.annotations = map[string]string{}
+		}
+		opts.annotations[key] = value
+	}
+}
+
+// SignWithOptional includes the optional section in the signed payload.
+func SignWithOptional(optional map[string]interface{}) SignOpts {
+	return func(opts *signOpt) {
+		opts.optional = optional
+	}
+}
+
+// SignWithPlatform signs a single platform from a manifest list.
+func SignWithPlatform(p string) SignOpts {
+	return func(opts *signOpt) {
+		opts.platform = p
+	}
+}
+
+// SignWithReferrers pushes the signature as a referrer to the image instead of the "sha256-<digest>.sig" tag.
+func SignWithReferrers() SignOpts {
+	return func(opts *signOpt) {
+		opts.referrers = true
+	}
+}
+
+// signPayload is the simple signing payload used by cosign.
+type signPayload struct {
+	Critical signCritical           `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+type signCritical struct {
+	Identity struct {
+		DockerReference string `json:"docker-reference"`
+	} `json:"identity"`
+	Image struct {
+		DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+	} `json:"image"`
+	Type string `json:"type"`
+}
+
+// ImageSign creates a cosign compatible signature for an image.
+// The signer is called with the simple signing payload, and the result is pushed as an OCI artifact.
+// By default, signatures are added to the "sha256-<digest>.sig" tag, appending to any existing signatures.
+// The returned ref is the pushed signature manifest.
+func (rc *RegClient) ImageSign(ctx context.Context, r ref.Ref, signer Signer, opts ...SignOpts) (ref.Ref, error) {
+	if !r.IsSet() {
+		return r, fmt.Errorf("ref is not set: %s%.0w", r.CommonName(), errs.ErrInvalidReference)
+	}
+	if signer == nil {
+		return r, fmt.Errorf("signer is not defined")
+	}
+	opt := signOpt{}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+	// resolve the image digest
+	mOpts := []ManifestOpts{WithManifestRequireDigest()}
+	if opt.platform != "" {
+		p, err := platform.Parse(opt.platform)
+		if err != nil {
+			return r, fmt.Errorf("failed to parse platform %s: %w", opt.platform, err)
+		}
+		mOpts = append(mOpts, WithManifestPlatform(p))
+	}
+	mh, err := rc.ManifestHead(ctx, r, mOpts...)
+	if err != nil {
+		return r, fmt.Errorf("failed to get image digest %s: %w", r.CommonName(), err)
+	}
+	dImage := mh.GetDescriptor()
+	r = r.SetDigest(dImage.Digest.String())
+
+	// generate and sign the payload
+	payload := signPayload{Optional: opt.optional}
+	payload.Critical.Identity.DockerReference = signIdentity(r)
+	payload.Critical.Image.DockerManifestDigest = dImage.Digest
+	payload.Critical.Type = signatureType
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return r, fmt.Errorf("failed to marshal signature payload: %w", err)
+	}
+	sig, err := signer(ctx, payloadBytes)
+	if err != nil {
+		return r, fmt.Errorf("failed to sign %s: %w", r.CommonName(), err)
+	}
+	dPayload, err := rc.BlobPut(ctx, r, descriptor.Descriptor{Digest: digest.Canonical.FromBytes(payloadBytes), Size: int64(len(payloadBytes))}, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return r, fmt.Errorf("failed to push signature payload: %w", err)
+	}
+	dLayer := descriptor.Descriptor{
+		MediaType: mediatype.CosignSimpleSigning,
+		Digest:    dPayload.Digest,
+		Size:      dPayload.Size,
+		Annotations: map[string]string{
+			SignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+		},
+	}
+	for k, v := range opt.annotations {
+		dLayer.Annotations[k] = v
+	}
+
+	if opt.referrers {
+		return rc.imageSignReferrer(ctx, r, dImage, dLayer)
+	}
+	return rc.imageSignTag(ctx, r, dImage, dLayer)
+}
+
+// imageSignReferrer pushes a signature artifact with a subject pointing to the image.
+func (rc *RegClient) imageSignReferrer(ctx context.Context, r ref.Ref, dImage, dLayer descriptor.Descriptor) (ref.Ref, error) {
+	_, err := rc.BlobPut(ctx, r, descriptor.Descriptor{Digest: descriptor.EmptyDigest, Size: int64(len(descriptor.EmptyData))}, bytes.NewReader(descriptor.EmptyData))
+	if err != nil {
+		return r, fmt.Errorf("failed to push signature config: %w", err)
+	}
+	m, err := manifest.New(manifest.WithOrig(v1.Manifest{
+		Versioned:    v1.ManifestSchemaVersion,
+		MediaType:    mediatype.OCI1Manifest,
+		ArtifactType: mediatype.CosignSignature,
+		Config: descriptor.Descriptor{
+			MediaType: mediatype.OCI1Empty,
+			Digest:    descriptor.EmptyDigest,
+			Size:      int64(len(descriptor.EmptyData)),
+		},
+		Layers: []descriptor.Descriptor{dLayer},
+		Subject: &descriptor.Descriptor{
+			MediaType: dImage.MediaType,
+			Digest:    dImage.Digest,
+			Size:      dImage.Size,
+		},
+	}))
+	if err != nil {
+		return r, err
+	}
+	rSig := r.SetDigest(m.GetDescriptor().Digest.String())
+	err = rc.ManifestPut(ctx, rSig, m, WithManifestChild())
+	if err != nil {
+		return r, fmt.Errorf("failed to push signature %s: %w", rSig.CommonName(), err)
+	}
+	return rSig, nil
+}
+
+// imageSignTag adds the signature to the "sha256-<digest>.sig" tag, appending to existing signatures.
+func (rc *RegClient) imageSignTag(ctx context.Context, r ref.Ref, dImage, dLayer descriptor.Descriptor) (ref.Ref, error) {
+	rSig := r.SetTag(fmt.Sprintf("%s-%s.sig", dImage.Digest.Algorithm().String(), dImage.Digest.Encoded()))
+	layers := []descriptor.Descriptor{}
+	mExisting, err := rc.ManifestGet(ctx, rSig)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return r, fmt.Errorf("failed to get existing signatures %s: %w", rSig.CommonName(), err)
+	} else if err == nil {
+		mi, ok := mExisting.(manifest.Imager)
+		if !ok {
+			return r, fmt.Errorf("existing signature is not an image manifest, %s: %w", rSig.CommonName(), errs.ErrUnsupportedMediaType)
+		}
+		layers, err = mi.GetLayers()
+		if err != nil {
+			return r, err
+		}
+		for _, l := range layers {
+			if l.Digest == dLayer.Digest && l.Annotations[SignatureAnnotation] == dLayer.Annotations[SignatureAnnotation] {
+				// signature already exists
+				return rSig, nil
+			}
+		}
+	}
+	layers = append(layers, dLayer)
+
+	// the config lists each payload as a layer
+	conf := v1.Image{
+		RootFS: v1.RootFS{
+			Type:    "layers",
+			DiffIDs: make([]digest.Digest, len(layers)),
+		},
+	}
+	for i, l := range layers {
+		conf.RootFS.DiffIDs[i] = l.Digest
+	}
+	confBytes, err := json.Marshal(conf)
+	if err != nil {
+		return r, err
+	}
+	dConf, err := rc.BlobPut(ctx, r, descriptor.Descriptor{Digest: digest.Canonical.FromBytes(confBytes), Size: int64(len(confBytes))}, bytes.NewReader(confBytes))
+	if err != nil {
+		return r, fmt.Errorf("failed to push signature config: %w", err)
+	}
+	m, err := manifest.New(manifest.WithOrig(v1.Manifest{
+		Versioned: v1.ManifestSchemaVersion,
+		MediaType: mediatype.OCI1Manifest,
+		Config: descriptor.Descriptor{
+			MediaType: mediatype.OCI1ImageConfig,
+			Digest:    dConf.Digest,
+			Size:      dConf.Size,
+		},
+		Layers: layers,
+	}))
+	if err != nil {
+		return r, err
+	}
+	err = rc.ManifestPut(ctx, rSig, m)
+	if err != nil {
+		return r, fmt.Errorf("failed to push signature %s: %w", rSig.CommonName(), err)
+	}
+	return rSig, nil
+}
+
+// signIdentity returns the repository name used in the signature payload.
+func signIdentity(r ref.Ref) string {
+	if r.Scheme == "ocidir" {
+		return r.Path
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// imageVerify calls the verifier with the signatures of an image, returning the ref with the verified digest.
+func (rc *RegClient) imageVerify(ctx context.Context, r ref.Ref, verifier Verifier) (ref.Ref, error) {
+	mh, err := rc.ManifestHead(ctx, r, WithManifestRequireDigest())
+	if err != nil {
+		return r, fmt.Errorf("failed to get the digest of %s: %w", r.CommonName(), err)
+	}
+	dig := mh.GetDescriptor().Digest
+	r.Digest = dig.String()
+	sigs, err := rc.imageSignatureList(ctx, r, dig)
+	if err != nil {
+		return r, fmt.Errorf("failed to get signatures of %s: %w", r.CommonName(), err)
+	}
+	if len(sigs) == 0 {
+		return r, fmt.Errorf("no signatures found for %s%.0w", r.CommonName(), errs.ErrSignatureVerification)
+	}
+	err = verifier(ctx, dig, sigs)
+	if err != nil {
+		return r, fmt.Errorf("failed to verify %s: %w%.0w", r.CommonName(), err, errs.ErrSignatureVerification)
+	}
+	return r, nil
+}
+
+// imageSignatureList returns the signatures from the "sha256-<digest>.sig" tag and the referrers of an image.
+func (rc *RegClient) imageSignatureList(ctx context.Context, r ref.Ref, dig digest.Digest) ([]ImageSignature, error) {
+	sigRefs := []ref.Ref{}
+	rSig := r.SetTag(fmt.Sprintf("%s-%s.sig", dig.Algorithm().String(), dig.Encoded()))
+	_, err := rc.ManifestHead(ctx, rSig)
+	if err == nil {
+		sigRefs = append(sigRefs, rSig)
+	} else if !errors.Is(err, errs.ErrNotFound) {
+		return nil, err
+	}
+	rl, err := rc.ReferrerList(ctx, r, scheme.WithReferrerMatchOpt(descriptor.MatchOpt{ArtifactType: mediatype.CosignSignature}))
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range rl.Descriptors {
+		sigRefs = append(sigRefs, r.SetDigest(d.Digest.String()))
+	}
+	sigs := []ImageSignature{}
+	for _, rSig := range sigRefs {
+		m, err := rc.ManifestGet(ctx, rSig)
+		if err != nil {
+			return nil, err
+		}
+		mi, ok := m.(manifest.Imager)
+		if !ok {
+			return nil, fmt.Errorf("signature is not an image manifest, %s%.0w", rSig.CommonName(), errs.ErrUnsupportedMediaType)
+		}
+		layers, err := mi.GetLayers()
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range layers {
+			sigEnc, ok := l.Annotations[SignatureAnnotation]
+			if !ok || l.MediaType != mediatype.CosignSimpleSigning {
+				continue
+			}
+			sig, err := base64.StdEncoding.DecodeString(sigEnc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode signature in %s: %w", rSig.CommonName(), err)
+			}
+			if l.Size > signPayloadMax {
+				return nil, fmt.Errorf("signature payload in %s is %d bytes, limit is %d%.0w", rSig.CommonName(), l.Size, signPayloadMax, errs.ErrSizeLimitExceeded)
+			}
+			br, err := rc.BlobGet(ctx, rSig, l)
+			if err != nil {
+				return nil, err
+			}
+			payload, err := io.ReadAll(io.LimitReader(br, signPayloadMax+1))
+			_ = br.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read signature payload in %s: %w", rSig.CommonName(), err)
+			}
+			if len(payload) > signPayloadMax {
+				return nil, fmt.Errorf("signature payload in %s exceeds %d bytes%.0w", rSig.CommonName(), signPayloadMax, errs.ErrSizeLimitExceeded)
+			}
+			sigs = append(sigs, ImageSignature{
+				Ref:       rSig,
+				Layer:     l,
+				Payload:   payload,
+				Signature: sig,
+			})
+		}
+	}
+	return sigs, nil
+}
@@ -0,0 +1,251 @@
+package regclient
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/regclient/regclient/internal/copyfs"
+	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/manifest"
+	"github.com/regclient/regclient/types/mediatype"
+	"github.com/regclient/regclient/types/ref"
+)
+
+func TestImageSign(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	err := copyfs.Copy(tempDir+"/testrepo", "./testdata/testrepo")
+	if err != nil {
+		t.Fatalf("failed to setup tempDir: %v", err)
+	}
+	rc := New()
+	r, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	mh, err := rc.ManifestHead(ctx, r, WithManifestRequireDigest())
+	if err != nil {
+		t.Fatalf("failed to head manifest: %v", err)
+	}
+	dig := mh.GetDescriptor().Digest
+	keyA := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	keyB := ed25519.NewKeyFromSeed([]byte("0123456789abcdef0123456789abcdef"))
+	signerFn := func(key ed25519.PrivateKey) Signer {
+		return func(_ context.Context, payload []byte) ([]byte, error) {
+			return ed25519.Sign(key, payload), nil
+		}
+	}
+	// verify each layer of a signature manifest, returning the number of layers
+	verify := func(t *testing.T, rSig ref.Ref, keys ...ed25519.PrivateKey) int {
+		t.Helper()
+		m, err := rc.ManifestGet(ctx, rSig)
+		if err != nil {
+			t.Fatalf("failed to get signature: %v", err)
+		}
+		mi, ok := m.(manifest.Imager)
+		if !ok {
+			t.Fatalf("signature is not an image")
+		}
+		layers, err := mi.GetLayers()
+		if err != nil {
+			t.Fatalf("failed to get layers: %v", err)
+		}
+		for i, l := range layers {
+			if l.MediaType != mediatype.CosignSimpleSigning {
+				t.Errorf("unexpected media type: %s", l.MediaType)
+			}
+			br, err := rc.BlobGet(ctx, rSig, l)
+			if err != nil {
+				t.Fatalf("failed to get payload: %v", err)
+			}
+			payloadBytes, err := io.ReadAll(br)
+			_ = br.Close()
+			if err != nil {
+				t.Fatalf("failed to read payload: %v", err)
+			}
+			payload := signPayload{}
+			err = json.Unmarshal(payloadBytes, &payload)
+			if err != nil {
+				t.Fatalf("failed to parse payload: %v", err)
+			}
+			if payload.Critical.Image.DockerManifestDigest != dig || payload.Critical.Type != signatureType {
+				t.Errorf("unexpected payload: %s", payloadBytes)
+			}
+			sig, err := base64.StdEncoding.DecodeString(l.Annotations[SignatureAnnotation])
+			if err != nil {
+				t.Fatalf("failed to decode signature: %v", err)
+			}
+			if i < len(keys) && !ed25519.Verify(keys[i].Public().(ed25519.PublicKey), payloadBytes, sig) {
+				t.Errorf("signature %d failed to verify", i)
+			}
+		}
+		return len(layers)
+	}
+
+	t.Run("tag", func(t *testing.T) {
+		rSig, err := rc.ImageSign(ctx, r, signerFn(keyA), SignWithAnnotation("test", "a"))
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		if rSig.Tag != "sha256-"+dig.Encoded()+".sig" {
+			t.Errorf("unexpected signature tag: %s", rSig.Tag)
+		}
+		if count := verify(t, rSig, keyA); count != 1 {
+			t.Errorf("unexpected number of signatures, expected 1, received %d", count)
+		}
+		// a second key appends to the existing signature
+		_, err = rc.ImageSign(ctx, r, signerFn(keyB))
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		if count := verify(t, rSig, keyA, keyB); count != 2 {
+			t.Errorf("unexpected number of signatures, expected 2, received %d", count)
+		}
+		// the same signature is not added twice
+		_, err = rc.ImageSign(ctx, r, signerFn(keyB))
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		if count := verify(t, rSig, keyA, keyB); count != 2 {
+			t.Errorf("unexpected number of signatures, expected 2, received %d", count)
+		}
+	})
+	t.Run("referrers", func(t *testing.T) {
+		rSig, err := rc.ImageSign(ctx, r, signerFn(keyA), SignWithReferrers())
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		if count := verify(t, rSig, keyA); count != 1 {
+			t.Errorf("unexpected number of signatures, expected 1, received %d", count)
+		}
+		rl, err := rc.ReferrerList(ctx, r)
+		if err != nil {
+			t.Fatalf("failed to list referrers: %v", err)
+		}
+		found := false
+		for _, d := range rl.Descriptors {
+			if d.Digest.String() == rSig.Digest && d.ArtifactType == mediatype.CosignSignature {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("signature not found in referrers: %v", rl.Descriptors)
+		}
+	})
+	t.Run("signer error", func(t *testing.T) {
+		errSigner := errors.New("signer failed")
+		_, err := rc.ImageSign(ctx, r, func(_ context.Context, _ []byte) ([]byte, error) {
+			return nil, errSigner
+		})
+		if !errors.Is(err, errSigner) {
+			t.Errorf("unexpected error, expected %v, received %v", errSigner, err)
+		}
+	})
+}
+
+func TestImageCopyVerify(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	err := copyfs.Copy(tempDir+"/testrepo", "./testdata/testrepo")
+	if err != nil {
+		t.Fatalf("failed to setup tempDir: %v", err)
+	}
+	rc := New()
+	rSrc, err := ref.New("ocidir://" + tempDir + "/testrepo:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	rTgt, err := ref.New("ocidir://" + tempDir + "/testout:v1")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	keyA := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	keyB := ed25519.NewKeyFromSeed([]byte("0123456789abcdef0123456789abcdef"))
+	signerFn := func(key ed25519.PrivateKey) Signer {
+		return func(_ context.Context, payload []byte) ([]byte, error) {
+			return ed25519.Sign(key, payload), nil
+		}
+	}
+	errUntrusted := errors.New("no trusted signature")
+	verifierFn := func(key ed25519.PrivateKey) Verifier {
+		return func(_ context.Context, dig digest.Digest, sigs []ImageSignature) error {
+			for _, sig := range sigs {
+				payload := signPayload{}
+				if json.Unmarshal(sig.Payload, &payload) != nil || payload.Critical.Image.DockerManifestDigest != dig {
+					continue
+				}
+				if ed25519.Verify(key.Public().(ed25519.PublicKey), sig.Payload, sig.Signature) {
+					return nil
+				}
+			}
+			return errUntrusted
+		}
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		err := rc.ImageCopy(ctx, rSrc, rTgt.SetTag("unsigned"), ImageWithVerify(verifierFn(keyA)))
+		if !errors.Is(err, errs.ErrSignatureVerification) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrSignatureVerification, err)
+		}
+		_, err = rc.ManifestHead(ctx, rTgt.SetTag("unsigned"))
+		if err == nil {
+			t.Errorf("unsigned image was copied")
+		}
+	})
+	_, err = rc.ImageSign(ctx, rSrc, signerFn(keyA))
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	t.Run("tag", func(t *testing.T) {
+		err := rc.ImageCopy(ctx, rSrc, rTgt, ImageWithVerify(verifierFn(keyA)))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+		_, err = rc.ManifestHead(ctx, rTgt)
+		if err != nil {
+			t.Errorf("failed to head copied image: %v", err)
+		}
+	})
+	t.Run("untrusted", func(t *testing.T) {
+		err := rc.ImageCopy(ctx, rSrc, rTgt.SetTag("untrusted"), ImageWithVerify(verifierFn(keyB)))
+		if !errors.Is(err, errs.ErrSignatureVerification) || !errors.Is(err, errUntrusted) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrSignatureVerification, err)
+		}
+		_, err = rc.ManifestHead(ctx, rTgt.SetTag("untrusted"))
+		if err == nil {
+			t.Errorf("untrusted image was copied")
+		}
+	})
+	t.Run("referrers", func(t *testing.T) {
+		rSrc := rSrc.SetTag("v2")
+		_, err := rc.ImageSign(ctx, rSrc, signerFn(keyB), SignWithReferrers())
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		err = rc.ImageCopy(ctx, rSrc, rTgt.SetTag("v2"), ImageWithVerify(verifierFn(keyB)))
+		if err != nil {
+			t.Fatalf("failed to copy: %v", err)
+		}
+	})
+	t.Run("payload limit", func(t *testing.T) {
+		rSrc := rSrc.SetTag("v3")
+		_, err := rc.ImageSign(ctx, rSrc, signerFn(keyA), SignWithOptional(map[string]interface{}{"filler": strings.Repeat("x", signPayloadMax)}))
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		err = rc.ImageCopy(ctx, rSrc, rTgt.SetTag("v3"), ImageWithVerify(verifierFn(keyA)))
+		if !errors.Is(err, errs.ErrSizeLimitExceeded) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrSizeLimitExceeded, err)
+		}
+	})
+}
@@ -2,8 +2,11 @@ package regclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/opencontainers/go-digest"
+
 	"github.com/regclient/regclient/scheme"
 	"github.com/regclient/regclient/types/errs"
 	"github.com/regclient/regclient/types/ref"
@@ -27,6 +30,37 @@ func (rc *RegClient) TagDelete(ctx context.Context, r ref.Ref) error {
 	return schemeAPI.TagDelete(ctx, r)
 }
 
+// TagDigestMap returns the digest of every tag in a repository.
+// Tags that are aliases of the same image have the same digest, allowing callers to group them before deleting.
+// Each tag is resolved with a ManifestHead, tags deleted after the listing are skipped.
+func (rc *RegClient) TagDigestMap(ctx context.Context, r ref.Ref) (map[string]digest.Digest, error) {
+	if !r.IsSetRepo() {
+		return nil, fmt.Errorf("ref is not set: %s%.0w", r.CommonName(), errs.ErrInvalidReference)
+	}
+	result := map[string]digest.Digest{}
+	err := rc.TagListWalk(ctx, r, func(tl *tag.List) error {
+		tags, err := tl.GetTags()
+		if err != nil {
+			return err
+		}
+		for _, t := range tags {
+			rTag := r.SetTag(t)
+			m, err := rc.ManifestHead(ctx, rTag, WithManifestRequireDigest())
+			if errors.Is(err, errs.ErrNotFound) {
+				continue
+			} else if err != nil {
+				return fmt.Errorf("failed to get digest for %s: %w", rTag.CommonName(), err)
+			}
+			result[t] = m.GetDescriptor().Digest
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // TagList returns a tag list from a repository
 func (rc *RegClient) TagList(ctx context.Context, r ref.Ref, opts ...scheme.TagOpts) (*tag.List, error) {
 	if !r.IsSetRepo() {
@@ -38,3 +72,39 @@ func (rc *RegClient) TagList(ctx context.Context, r ref.Ref, opts ...scheme.TagO
 	}
 	return schemeAPI.TagList(ctx, r, opts...)
 }
+
+// TagListWalk calls fn with each page of tags from a repository.
+// Pages are requested with the limit from [scheme.WithTagLimit], defaulting to 1000 entries.
+// Listing stops when a page is not full, or when fn returns an error.
+func (rc *RegClient) TagListWalk(ctx context.Context, r ref.Ref, fn func(*tag.List) error, opts ...scheme.TagOpts) error {
+	config := scheme.TagConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.Limit <= 0 {
+		config.Limit = listPageDefault
+	}
+	last := config.Last
+	for {
+		tl, err := rc.TagList(ctx, r, scheme.WithTagLimit(config.Limit), scheme.WithTagLast(last))
+		if err != nil {
+			return err
+		}
+		tags, err := tl.GetTags()
+		if err != nil {
+			return err
+		}
+		// stop when the registry returns nothing new, e.g. when it ignores the last parameter
+		if len(tags) == 0 || tags[len(tags)-1] == last {
+			return nil
+		}
+		err = fn(tl)
+		if err != nil {
+			return err
+		}
+		if len(tags) < config.Limit {
+			return nil
+		}
+		last = tags[len(tags)-1]
+	}
+}
@@ -2,10 +2,12 @@ package regclient
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,7 +16,10 @@ import (
 
 	"github.com/regclient/regclient/config"
 	"github.com/regclient/regclient/internal/copyfs"
+	"github.com/regclient/regclient/scheme"
+	"github.com/regclient/regclient/types/errs"
 	"github.com/regclient/regclient/types/ref"
+	"github.com/regclient/regclient/types/tag"
 )
 
 func TestTag(t *testing.T) {
@@ -128,3 +133,129 @@ func TestTag(t *testing.T) {
 		})
 	}
 }
+
+func TestTagListWalk(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	regHandler := olareg.New(oConfig.Config{
+		Storage: oConfig.ConfigStorage{
+			StoreType: oConfig.StoreMem,
+			RootDir:   "./testdata",
+		},
+	})
+	ts := httptest.NewServer(regHandler)
+	tsURL, _ := url.Parse(ts.URL)
+	tsHost := tsURL.Host
+	t.Cleanup(func() {
+		ts.Close()
+		_ = regHandler.Close()
+	})
+	rc := New(WithConfigHost(config.Host{
+		Name:     tsHost,
+		Hostname: tsHost,
+		TLS:      config.TLSDisabled,
+	}))
+	pageSize := 3
+	tt := []struct {
+		repo     string
+		minPages int
+	}{
+		{
+			repo:     tsHost + "/testrepo",
+			minPages: 2,
+		},
+		{
+			// ocidir ignores the limit and returns all tags in a single page
+			repo:     "ocidir://./testdata/testrepo",
+			minPages: 1,
+		},
+	}
+	for _, tc := range tt {
+		repo := tc.repo
+		t.Run(repo, func(t *testing.T) {
+			r, err := ref.New(repo)
+			if err != nil {
+				t.Fatalf("failed to parse ref %s: %v", repo, err)
+			}
+			tl, err := rc.TagList(ctx, r)
+			if err != nil {
+				t.Fatalf("failed to list tags: %v", err)
+			}
+			pages := 0
+			walkTags := []string{}
+			err = rc.TagListWalk(ctx, r, func(tl *tag.List) error {
+				pages++
+				walkTags = append(walkTags, tl.Tags...)
+				return nil
+			}, scheme.WithTagLimit(pageSize))
+			if err != nil {
+				t.Fatalf("failed to walk tags: %v", err)
+			}
+			if pages < tc.minPages {
+				t.Errorf("expected at least %d pages, received %d", tc.minPages, pages)
+			}
+			if strings.Join(walkTags, ",") != strings.Join(tl.Tags, ",") {
+				t.Errorf("tag mismatch, expected %v, received %v", tl.Tags, walkTags)
+			}
+			errStop := errors.New("stop")
+			pages = 0
+			err = rc.TagListWalk(ctx, r, func(tl *tag.List) error {
+				pages++
+				return errStop
+			}, scheme.WithTagLimit(pageSize))
+			if !errors.Is(err, errStop) || pages != 1 {
+				t.Errorf("walk did not stop on error, pages %d, err %v", pages, err)
+			}
+		})
+	}
+}
+
+func TestTagDigestMap(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	err := copyfs.Copy(tempDir+"/testrepo", "./testdata/testrepo")
+	if err != nil {
+		t.Fatalf("failed to setup tempDir: %v", err)
+	}
+	rc := New()
+	r, err := ref.New("ocidir://" + tempDir + "/testrepo")
+	if err != nil {
+		t.Fatalf("failed to parse ref: %v", err)
+	}
+	// add an alias of v1
+	err = rc.ImageCopy(ctx, r.SetTag("v1"), r.SetTag("alias"))
+	if err != nil {
+		t.Fatalf("failed to tag alias: %v", err)
+	}
+	tl, err := rc.TagList(ctx, r)
+	if err != nil {
+		t.Fatalf("failed to list tags: %v", err)
+	}
+	tdm, err := rc.TagDigestMap(ctx, r)
+	if err != nil {
+		t.Fatalf("failed to get tag digest map: %v", err)
+	}
+	if len(tdm) != len(tl.Tags) {
+		t.Errorf("unexpected number of tags, expected %d, received %d", len(tl.Tags), len(tdm))
+	}
+	for _, tagName := range []string{"v1", "v2"} {
+		m, err := rc.ManifestHead(ctx, r.SetTag(tagName), WithManifestRequireDigest())
+		if err != nil {
+			t.Fatalf("failed to head %s: %v", tagName, err)
+		}
+		if tdm[tagName] != m.GetDescriptor().Digest {
+			t.Errorf("digest mismatch for %s, expected %s, received %s", tagName, m.GetDescriptor().Digest, tdm[tagName])
+		}
+	}
+	if tdm["alias"] == "" || tdm["alias"] != tdm["v1"] {
+		t.Errorf("alias digest %s does not match v1 %s", tdm["alias"], tdm["v1"])
+	}
+	if tdm["v1"] == tdm["v2"] {
+		t.Errorf("v1 and v2 unexpectedly share a digest %s", tdm["v1"])
+	}
+	_, err = rc.TagDigestMap(ctx, ref.Ref{})
+	if !errors.Is(err, errs.ErrInvalidReference) {
+		t.Errorf("unexpected error, expected %v, received %v", errs.ErrInvalidReference, err)
+	}
+}
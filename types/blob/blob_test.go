@@ -13,6 +13,7 @@ import (
 
 	"github.com/opencontainers/go-digest"
 
+	"github.com/regclient/regclient/pkg/archive"
 	"github.com/regclient/regclient/types/descriptor"
 	"github.com/regclient/regclient/types/errs"
 	"github.com/regclient/regclient/types/mediatype"
@@ -561,6 +562,7 @@ func TestTarReader(t *testing.T) {
 	tt := []struct {
 		name     string
 		opts     []Opts
+		errGet   error
 		errClose bool
 	}{
 		{
@@ -587,6 +589,17 @@ func TestTarReader(t *testing.T) {
 				}),
 			},
 		},
+		{
+			name: "mislabeled compression",
+			opts: []Opts{
+				WithDesc(descriptor.Descriptor{
+					MediaType: mediatype.OCI1LayerZstd,
+					Size:      fhSize,
+					Digest:    dig,
+				}),
+			},
+			errGet: archive.ErrCompressMismatch,
+		},
 	}
 
 	for _, tc := range tt {
@@ -598,6 +611,13 @@ func TestTarReader(t *testing.T) {
 			opts := append(tc.opts, WithReader(fh))
 			btr := NewTarReader(opts...)
 			tr, err := btr.GetTarReader()
+			if tc.errGet != nil {
+				if !errors.Is(err, tc.errGet) {
+					t.Errorf("unexpected error, expected %v, received %v", tc.errGet, err)
+				}
+				_ = btr.Close()
+				return
+			}
 			if err != nil {
 				t.Fatalf("failed to get tar reader: %v", err)
 			}
@@ -736,6 +756,49 @@ func TestReadFile(t *testing.T) {
 	})
 }
 
+func TestListFiles(t *testing.T) {
+	fileBytes, err := os.ReadFile(fileLayerWH)
+	if err != nil {
+		t.Fatalf("failed to open test data: %v", err)
+	}
+	blobDigest := digest.FromBytes(fileBytes)
+	t.Run("list", func(t *testing.T) {
+		fh, err := os.Open(fileLayerWH)
+		if err != nil {
+			t.Fatalf("failed to open test data: %v", err)
+		}
+		btr := NewTarReader(WithReader(fh), WithDesc(descriptor.Descriptor{Size: int64(len(fileBytes)), Digest: blobDigest, MediaType: mediatype.OCI1Layer}))
+		defer btr.Close()
+		headers, err := btr.ListFiles()
+		if err != nil {
+			t.Fatalf("ListFiles failed: %v", err)
+		}
+		names := []string{}
+		for _, th := range headers {
+			names = append(names, th.Name)
+		}
+		expect := []string{"layer1.txt", ".wh.layer2.txt", "layer3.txt", "exdir/", "exdir/.wh..wh..opq"}
+		if !cmpSliceString(names, expect) {
+			t.Errorf("unexpected files, expected %v, received %v", expect, names)
+		}
+		if headers[0].Size != 2 {
+			t.Errorf("unexpected size for %s: %d", headers[0].Name, headers[0].Size)
+		}
+	})
+	t.Run("bad digest", func(t *testing.T) {
+		fh, err := os.Open(fileLayerWH)
+		if err != nil {
+			t.Fatalf("failed to open test data: %v", err)
+		}
+		btr := NewTarReader(WithReader(fh), WithDesc(descriptor.Descriptor{Size: int64(len(fileBytes)), Digest: digest.FromString("bad digest"), MediaType: mediatype.OCI1Layer}))
+		defer btr.Close()
+		_, err = btr.ListFiles()
+		if !errors.Is(err, errs.ErrDigestMismatch) {
+			t.Errorf("unexpected error, expected %v, received %v", errs.ErrDigestMismatch, err)
+		}
+	})
+}
+
 func cmpSliceString(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -73,7 +73,11 @@ func (tr *BTarReader) GetTarReader() (*tar.Reader, error) {
 		return nil, fmt.Errorf("blob has no reader defined")
 	}
 	if tr.tr == nil {
-		dr, err := archive.Decompress(tr.reader)
+		dOpts := []archive.DecompressOpts{}
+		if ct, ok := archive.MediaTypeCompression(tr.desc.MediaType); ok {
+			dOpts = append(dOpts, archive.DecompressWithType(ct))
+		}
+		dr, err := archive.Decompress(tr.reader, dOpts...)
 		if err != nil {
 			return nil, err
 		}
@@ -164,6 +168,38 @@ func (tr *BTarReader) ReadFile(filename string) (*tar.Header, io.Reader, error)
 	return nil, nil, errs.ErrFileNotFound
 }
 
+// ListFiles returns the header of every entry in the tar without reading the file contents.
+// Whiteout entries (".wh." prefixed names) are included, identifying files deleted from lower layers.
+// The digest of the blob is verified after the last header is read.
+func (tr *BTarReader) ListFiles() ([]*tar.Header, error) {
+	rdr, err := tr.GetTarReader()
+	if err != nil {
+		return nil, err
+	}
+	headers := []*tar.Header{}
+	for {
+		th, err := rdr.Next()
+		if err != nil {
+			// break on eof, everything else is an error
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		headers = append(headers, th)
+	}
+	if tr.digester != nil {
+		_, _ = io.Copy(io.Discard, tr.reader) // process/digest any trailing bytes from reader
+		dig := tr.digester.Digest()
+		tr.digester = nil
+		if tr.desc.Digest.String() != "" && dig != tr.desc.Digest {
+			return nil, fmt.Errorf("%w, expected %s, received %s", errs.ErrDigestMismatch, tr.desc.Digest.String(), dig.String())
+		}
+		tr.desc.Digest = dig
+	}
+	return headers, nil
+}
+
 func tarCmpWhiteout(whFile, tgtFile string) bool {
 	whSplit := strings.Split(whFile, "/")
 	tgtSplit := strings.Split(tgtFile, "/")
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net/http"
+	"strconv"
+	"time"
 )
 
 var (
@@ -28,6 +31,8 @@ var (
 	ErrHTTPStatus = errors.New("unexpected http status code")
 	// ErrInvalidChallenge indicates an issue with the received challenge in the WWW-Authenticate header
 	ErrInvalidChallenge = errors.New("invalid challenge header")
+	// ErrInvalidManifest indicates the manifest does not conform to the schema of its media type
+	ErrInvalidManifest = errors.New("invalid manifest")
 	// ErrInvalidReference indicates the reference to an image is has an invalid syntax
 	ErrInvalidReference = errors.New("invalid reference")
 	// ErrLoopDetected indicates a child node points back to the parent
@@ -64,10 +69,14 @@ var (
 	ErrRetryNeeded = errors.New("retry needed")
 	// ErrRetryLimitExceeded indicates too many retries have occurred
 	ErrRetryLimitExceeded = errors.New("retry limit exceeded")
+	// ErrSignatureVerification when the signature of an image is missing or cannot be verified
+	ErrSignatureVerification = errors.New("signature verification failed")
 	// ErrShortRead if contents are less than expected the size
 	ErrShortRead = errors.New("short read")
 	// ErrSizeLimitExceeded if contents exceed the size limit
 	ErrSizeLimitExceeded = errors.New("size limit exceeded")
+	// ErrTagExists when a tag already exists with a different digest and overwriting is not allowed
+	ErrTagExists = errors.New("tag exists")
 	// ErrUnavailable when a requested value is not available
 	ErrUnavailable = errors.New("unavailable")
 	// ErrUnsupported indicates the request was unsupported
@@ -82,8 +91,80 @@ var (
 
 // custom HTTP errors extend the ErrHTTPStatus error
 var (
+	// ErrDeleteDisabled when the registry rejects a delete request with a 405 Method Not Allowed
+	ErrDeleteDisabled = fmt.Errorf("delete disabled on registry%.0w", ErrHTTPStatus)
 	// ErrHTTPRateLimit when requests exceed server rate limit
 	ErrHTTPRateLimit = fmt.Errorf("rate limit exceeded%.0w", ErrHTTPStatus)
 	// ErrHTTPUnauthorized when authentication fails
 	ErrHTTPUnauthorized = fmt.Errorf("unauthorized%.0w", ErrHTTPStatus)
 )
+
+// RegistryError is an entry from the error response body returned by a registry.
+// Callers can use [errors.As] to branch on the Code, e.g. MANIFEST_UNKNOWN, DENIED, or NAME_UNKNOWN.
+type RegistryError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message,omitempty"`
+	Detail  interface{} `json:"detail,omitempty"`
+}
+
+func (e *RegistryError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Message
+}
+
+// HTTPError is returned when a request receives an unexpected HTTP status.
+// Callers can use [errors.As] to access the status code, response headers, and any registry errors,
+// while [errors.Is] continues to match the wrapped error, e.g. [ErrNotFound] or [ErrHTTPRateLimit].
+type HTTPError struct {
+	StatusCode int             // status code of the response
+	Header     http.Header     // headers from the response, may be nil
+	Errors     []RegistryError // errors parsed from the response body
+	Err        error           // wrapped error based on the status code
+}
+
+func (e *HTTPError) Error() string {
+	msg := ""
+	if e.Err != nil {
+		msg = e.Err.Error()
+	} else {
+		msg = fmt.Sprintf("http status %d", e.StatusCode)
+	}
+	for i := range e.Errors {
+		msg += ": " + e.Errors[i].Error()
+	}
+	return msg
+}
+
+// Unwrap returns the wrapped error and each registry error.
+func (e *HTTPError) Unwrap() []error {
+	errList := make([]error, 0, len(e.Errors)+1)
+	if e.Err != nil {
+		errList = append(errList, e.Err)
+	}
+	for i := range e.Errors {
+		errList = append(errList, &e.Errors[i])
+	}
+	return errList
+}
+
+// RetryAfter returns the delay requested by the Retry-After header, or 0 if the header is missing or invalid.
+func (e *HTTPError) RetryAfter() time.Duration {
+	if e.Header == nil {
+		return 0
+	}
+	ra := e.Header.Get("Retry-After")
+	if ra == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(ra); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(ra); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package errs
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPError(t *testing.T) {
+	t.Parallel()
+	err := error(&HTTPError{
+		StatusCode: http.StatusTooManyRequests,
+		Header: http.Header{
+			"Retry-After": {"30"},
+		},
+		Errors: []RegistryError{{Code: "TOOMANYREQUESTS", Message: "slow down"}},
+		Err:    ErrHTTPRateLimit,
+	})
+	if err.Error() != "rate limit exceeded: TOOMANYREQUESTS: slow down" {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+	if !errors.Is(err, ErrHTTPRateLimit) || !errors.Is(err, ErrHTTPStatus) {
+		t.Errorf("wrapped error not found: %v", err)
+	}
+	var regErr *RegistryError
+	if !errors.As(err, &regErr) || regErr.Code != "TOOMANYREQUESTS" {
+		t.Errorf("registry error not found: %v", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("http error not found: %v", err)
+	}
+	if ra := httpErr.RetryAfter(); ra != 30*time.Second {
+		t.Errorf("unexpected retry after, expected 30s, received %s", ra)
+	}
+	httpErr.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if ra := httpErr.RetryAfter(); ra <= 0 || ra > time.Minute {
+		t.Errorf("unexpected retry after for a date: %s", ra)
+	}
+	httpErr.Header.Set("Retry-After", "invalid")
+	if ra := httpErr.RetryAfter(); ra != 0 {
+		t.Errorf("unexpected retry after for an invalid value: %s", ra)
+	}
+}
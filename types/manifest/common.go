@@ -74,6 +74,9 @@ func (m *common) IsSet() bool {
 }
 
 // RawBody returns the raw body from the manifest if available.
+// When the manifest was pulled or created from raw bytes, these are the exact bytes that were provided,
+// and match the descriptor digest, unless the manifest has since been modified with a Set method.
+// The returned slice should not be modified by the caller.
 func (m *common) RawBody() ([]byte, error) {
 	if len(m.rawBody) == 0 {
 		return m.rawBody, errs.ErrManifestNotSet
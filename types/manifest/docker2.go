@@ -282,6 +282,9 @@ func (m *docker2Manifest) SetLayers(dl []descriptor.Descriptor) error {
 	if !m.manifSet {
 		return errs.ErrManifestNotSet
 	}
+	if dl == nil {
+		dl = []descriptor.Descriptor{}
+	}
 	m.Layers = dl
 	return m.updateDesc()
 }
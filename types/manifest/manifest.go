@@ -5,6 +5,7 @@ package manifest
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"reflect"
 	"strconv"
@@ -67,10 +68,19 @@ type Manifest interface {
 // Annotator is used by manifests that support annotations.
 // Note this will work for Docker manifests despite the spec not officially supporting it.
 type Annotator interface {
+	// GetAnnotations returns the top level annotations of the manifest.
 	GetAnnotations() (map[string]string, error)
+	// SetAnnotation sets an annotation, deleting it when val is empty, and updates the descriptor.
 	SetAnnotation(key, val string) error
 }
 
+// ArtifactTyper is used by manifests that support the artifactType field.
+// An empty artifactType on an image manifest indicates the config media type describes the artifact.
+type ArtifactTyper interface {
+	GetArtifactType() (string, error)
+	SetArtifactType(at string) error
+}
+
 // Indexer is used by manifests that contain a manifest list.
 type Indexer interface {
 	GetManifestList() ([]descriptor.Descriptor, error)
@@ -176,6 +186,20 @@ func WithRef(r ref.Ref) Opts {
 	}
 }
 
+// GetAnnotations returns a copy of the top level annotations from a manifest.
+// Changes to the returned map are not applied to the manifest, see [SetAnnotation].
+func GetAnnotations(m Manifest) (map[string]string, error) {
+	ma, ok := m.(Annotator)
+	if !ok {
+		return nil, fmt.Errorf("annotations not available for media type %s%.0w", m.GetDescriptor().MediaType, errs.ErrUnsupportedMediaType)
+	}
+	annotations, err := ma.GetAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	return maps.Clone(annotations), nil
+}
+
 // GetDigest returns the digest from the manifest descriptor.
 func GetDigest(m Manifest) digest.Digest {
 	d := m.GetDescriptor()
@@ -274,6 +298,42 @@ func HasRateLimit(m Manifest) bool {
 	return rl.Set
 }
 
+// ManifestSize returns the size in bytes of the content referenced by a manifest.
+// For an image, this is the size of the config and every layer.
+// For an index, this is the size of the child manifests, the content of each child must be resolved and added separately.
+// The size of the manifest itself is not included, and content that cannot be determined, e.g. from a docker schema1 manifest, is counted as 0.
+func ManifestSize(m Manifest) int64 {
+	var total int64
+	switch mt := m.(type) {
+	case Imager:
+		if cd, err := mt.GetConfig(); err == nil {
+			total += cd.Size
+		}
+		if dl, err := mt.GetLayers(); err == nil {
+			for _, d := range dl {
+				total += d.Size
+			}
+		}
+	case Indexer:
+		if dl, err := mt.GetManifestList(); err == nil {
+			for _, d := range dl {
+				total += d.Size
+			}
+		}
+	}
+	return total
+}
+
+// SetAnnotation sets a top level annotation on a manifest, updating the descriptor and digest.
+// An empty value deletes the annotation.
+func SetAnnotation(m Manifest, key, val string) error {
+	ma, ok := m.(Annotator)
+	if !ok {
+		return fmt.Errorf("annotations not available for media type %s%.0w", m.GetDescriptor().MediaType, errs.ErrUnsupportedMediaType)
+	}
+	return ma.SetAnnotation(key, val)
+}
+
 // OCIIndexFromAny converts manifest lists to an OCI index.
 func OCIIndexFromAny(orig interface{}) (v1.Index, error) {
 	ociI := v1.Index{
@@ -377,18 +437,35 @@ func fromOrig(c common, orig interface{}) (Manifest, error) {
 	var m Manifest
 	origDigest := c.desc.Digest
 
+	// a nil layer list is encoded as null, the schemas require an array, even for a config only artifact
+	if len(c.rawBody) == 0 {
+		switch mOrig := orig.(type) {
+		case schema2.Manifest:
+			if mOrig.Layers == nil {
+				mOrig.Layers = []descriptor.Descriptor{}
+				orig = mOrig
+			}
+		case v1.Manifest:
+			if mOrig.Layers == nil {
+				mOrig.Layers = []descriptor.Descriptor{}
+				orig = mOrig
+			}
+		}
+	}
 	mj, err := json.Marshal(orig)
 	if err != nil {
 		return nil, err
 	}
 	c.manifSet = true
+	// raw bytes are retained when provided, the digest must match what the registry stored
 	if len(c.rawBody) == 0 {
 		c.rawBody = mj
 	}
 	if _, ok := orig.(schema1.SignedManifest); !ok {
-		c.desc.Digest = c.desc.DigestAlgo().FromBytes(mj)
-	}
-	if c.desc.Size == 0 {
+		c.desc.Digest = c.desc.DigestAlgo().FromBytes(c.rawBody)
+		c.desc.Size = int64(len(c.rawBody))
+	} else if c.desc.Size == 0 {
+		// the digest of a signed schema1 manifest excludes the signatures, only a missing size is set
 		c.desc.Size = int64(len(mj))
 	}
 	// create manifest based on type
@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -34,6 +35,10 @@ var (
 	_ Annotator = (*oci1Manifest)(nil)
 	_ Annotator = (*oci1Artifact)(nil)
 
+	_ ArtifactTyper = (*oci1Index)(nil)
+	_ ArtifactTyper = (*oci1Manifest)(nil)
+	_ ArtifactTyper = (*oci1Artifact)(nil)
+
 	_ Subjecter = (*oci1Index)(nil)
 	_ Subjecter = (*oci1Manifest)(nil)
 	_ Subjecter = (*oci1Artifact)(nil)
@@ -1112,6 +1117,367 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestRawBody(t *testing.T) {
+	t.Parallel()
+	var manifestDockerSchema2 schema2.Manifest
+	err := json.Unmarshal(rawDockerSchema2, &manifestDockerSchema2)
+	if err != nil {
+		t.Fatalf("failed to unmarshal docker schema2 json: %v", err)
+	}
+	tt := []struct {
+		name string
+		opts []Opts
+	}{
+		{
+			name: "raw",
+			opts: []Opts{WithRaw(rawDockerSchema2)},
+		},
+		{
+			name: "raw and orig",
+			opts: []Opts{WithRaw(rawDockerSchema2), WithOrig(manifestDockerSchema2)},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("failed to create manifest: %v", err)
+			}
+			raw, err := m.RawBody()
+			if err != nil {
+				t.Fatalf("failed to get raw body: %v", err)
+			}
+			if !bytes.Equal(raw, rawDockerSchema2) {
+				t.Errorf("raw body does not match original bytes")
+			}
+			mj, err := m.MarshalJSON()
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			if !bytes.Equal(mj, rawDockerSchema2) {
+				t.Errorf("marshaled json does not match original bytes")
+			}
+			if m.GetDescriptor().Digest != digest.FromBytes(rawDockerSchema2) || m.GetDescriptor().Size != int64(len(rawDockerSchema2)) {
+				t.Errorf("descriptor does not match raw body: %v", m.GetDescriptor())
+			}
+			mi, ok := m.(Imager)
+			if !ok {
+				t.Fatalf("manifest is not an Imager")
+			}
+			layers, err := mi.GetLayers()
+			if err != nil {
+				t.Fatalf("failed to get layers: %v", err)
+			}
+			if len(layers) != len(manifestDockerSchema2.Layers) {
+				t.Errorf("layer count mismatch, expected %d, received %d", len(manifestDockerSchema2.Layers), len(layers))
+			}
+		})
+	}
+}
+
+func TestNoLayers(t *testing.T) {
+	t.Parallel()
+	confDesc := descriptor.Descriptor{
+		MediaType: mediatype.OCI1Empty,
+		Digest:    descriptor.EmptyDigest,
+		Size:      int64(len(descriptor.EmptyData)),
+	}
+	tt := []struct {
+		name string
+		orig interface{}
+	}{
+		{
+			name: "Docker Schema 2",
+			orig: schema2.Manifest{
+				Versioned: schema2.ManifestSchemaVersion,
+				Config:    confDesc,
+			},
+		},
+		{
+			name: "OCI Manifest",
+			orig: v1.Manifest{
+				Versioned: v1.ManifestSchemaVersion,
+				MediaType: mediatype.OCI1Manifest,
+				Config:    confDesc,
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := New(WithOrig(tc.orig))
+			if err != nil {
+				t.Fatalf("failed to create manifest: %v", err)
+			}
+			raw, err := m.RawBody()
+			if err != nil {
+				t.Fatalf("failed to get raw body: %v", err)
+			}
+			if !bytes.Contains(raw, []byte(`"layers":[]`)) {
+				t.Errorf("layers are not an empty array: %s", string(raw))
+			}
+			mi, ok := m.(Imager)
+			if !ok {
+				t.Fatalf("manifest is not an Imager")
+			}
+			err = mi.SetLayers(nil)
+			if err != nil {
+				t.Fatalf("failed to set layers: %v", err)
+			}
+			raw, err = m.RawBody()
+			if err != nil {
+				t.Fatalf("failed to get raw body: %v", err)
+			}
+			if !bytes.Contains(raw, []byte(`"layers":[]`)) {
+				t.Errorf("layers are not an empty array after SetLayers: %s", string(raw))
+			}
+			layers, err := mi.GetLayers()
+			if err != nil {
+				t.Fatalf("failed to get layers: %v", err)
+			}
+			if len(layers) != 0 {
+				t.Errorf("unexpected layers: %v", layers)
+			}
+		})
+	}
+}
+
+func TestManifestSize(t *testing.T) {
+	t.Parallel()
+	confDesc := descriptor.Descriptor{
+		MediaType: mediatype.OCI1ImageConfig,
+		Digest:    digest.FromString("config"),
+		Size:      100,
+	}
+	layers := []descriptor.Descriptor{
+		{
+			MediaType: mediatype.OCI1LayerGzip,
+			Digest:    digest.FromString("layer1"),
+			Size:      1000,
+		},
+		{
+			MediaType: mediatype.OCI1LayerGzip,
+			Digest:    digest.FromString("layer2"),
+			Size:      2000,
+		},
+	}
+	children := []descriptor.Descriptor{
+		{
+			MediaType: mediatype.OCI1Manifest,
+			Digest:    digest.FromString("amd64"),
+			Size:      500,
+			Platform:  &platform.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			MediaType: mediatype.OCI1Manifest,
+			Digest:    digest.FromString("arm64"),
+			Size:      600,
+			Platform:  &platform.Platform{OS: "linux", Architecture: "arm64"},
+		},
+	}
+	tt := []struct {
+		name   string
+		orig   interface{}
+		expect int64
+	}{
+		{
+			name: "OCI Manifest",
+			orig: v1.Manifest{
+				Versioned: v1.ManifestSchemaVersion,
+				MediaType: mediatype.OCI1Manifest,
+				Config:    confDesc,
+				Layers:    layers,
+			},
+			expect: 3100,
+		},
+		{
+			name: "Docker Schema 2",
+			orig: schema2.Manifest{
+				Versioned: schema2.ManifestSchemaVersion,
+				Config:    confDesc,
+				Layers:    layers,
+			},
+			expect: 3100,
+		},
+		{
+			name: "OCI Manifest without layers",
+			orig: v1.Manifest{
+				Versioned: v1.ManifestSchemaVersion,
+				MediaType: mediatype.OCI1Manifest,
+				Config:    confDesc,
+			},
+			expect: 100,
+		},
+		{
+			name: "OCI Index",
+			orig: v1.Index{
+				Versioned: v1.IndexSchemaVersion,
+				MediaType: mediatype.OCI1ManifestList,
+				Manifests: children,
+			},
+			expect: 1100,
+		},
+		{
+			name: "Docker Manifest List",
+			orig: schema2.ManifestList{
+				Versioned: schema2.ManifestListSchemaVersion,
+				Manifests: children,
+			},
+			expect: 1100,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := New(WithOrig(tc.orig))
+			if err != nil {
+				t.Fatalf("failed to create manifest: %v", err)
+			}
+			size := ManifestSize(m)
+			if size != tc.expect {
+				t.Errorf("unexpected size, expected %d, received %d", tc.expect, size)
+			}
+		})
+	}
+}
+
+func TestArtifactType(t *testing.T) {
+	t.Parallel()
+	at := "application/vnd.example.sbom+json"
+	tt := []struct {
+		name string
+		orig interface{}
+	}{
+		{
+			name: "manifest",
+			orig: v1.Manifest{
+				Versioned: v1.ManifestSchemaVersion,
+				MediaType: mediatype.OCI1Manifest,
+				Config: descriptor.Descriptor{
+					MediaType: mediatype.OCI1Empty,
+					Digest:    descriptor.EmptyDigest,
+					Size:      int64(len(descriptor.EmptyData)),
+				},
+				Layers: []descriptor.Descriptor{},
+			},
+		},
+		{
+			name: "index",
+			orig: v1.Index{
+				Versioned: v1.IndexSchemaVersion,
+				MediaType: mediatype.OCI1ManifestList,
+				Manifests: []descriptor.Descriptor{},
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := New(WithOrig(tc.orig))
+			if err != nil {
+				t.Fatalf("failed to create manifest: %v", err)
+			}
+			mat, ok := m.(ArtifactTyper)
+			if !ok {
+				t.Fatalf("manifest does not support artifactType")
+			}
+			dOrig := m.GetDescriptor()
+			err = mat.SetArtifactType(at)
+			if err != nil {
+				t.Fatalf("failed to set artifactType: %v", err)
+			}
+			if m.GetDescriptor().Digest == dOrig.Digest {
+				t.Errorf("digest did not change after setting artifactType")
+			}
+			raw, err := m.RawBody()
+			if err != nil {
+				t.Fatalf("failed to get raw body: %v", err)
+			}
+			mParsed, err := New(WithRaw(raw))
+			if err != nil {
+				t.Fatalf("failed to parse manifest: %v", err)
+			}
+			got, err := mParsed.(ArtifactTyper).GetArtifactType()
+			if err != nil {
+				t.Fatalf("failed to get artifactType: %v", err)
+			}
+			if got != at {
+				t.Errorf("unexpected artifactType, expected %s, received %s", at, got)
+			}
+		})
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	t.Parallel()
+	m, err := New(WithOrig(v1.Manifest{
+		Versioned: v1.ManifestSchemaVersion,
+		MediaType: mediatype.OCI1Manifest,
+		Config: descriptor.Descriptor{
+			MediaType: mediatype.OCI1Empty,
+			Digest:    descriptor.EmptyDigest,
+			Size:      int64(len(descriptor.EmptyData)),
+		},
+		Layers:      []descriptor.Descriptor{},
+		Annotations: map[string]string{"org.opencontainers.image.source": "https://example.com/repo"},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create manifest: %v", err)
+	}
+	annots, err := GetAnnotations(m)
+	if err != nil {
+		t.Fatalf("failed to get annotations: %v", err)
+	}
+	if annots["org.opencontainers.image.source"] != "https://example.com/repo" {
+		t.Errorf("unexpected annotations: %v", annots)
+	}
+	// changes to the returned map do not modify the manifest
+	dOrig := m.GetDescriptor()
+	annots["unset"] = "value"
+	if check, _ := GetAnnotations(m); check["unset"] != "" {
+		t.Errorf("annotation map was not a copy")
+	}
+	err = SetAnnotation(m, "org.opencontainers.image.created", "2000-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("failed to set annotation: %v", err)
+	}
+	if m.GetDescriptor().Digest == dOrig.Digest {
+		t.Errorf("digest did not change after setting annotation")
+	}
+	raw, err := m.RawBody()
+	if err != nil {
+		t.Fatalf("failed to get raw body: %v", err)
+	}
+	mParsed, err := New(WithRaw(raw))
+	if err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+	annots, err = GetAnnotations(mParsed)
+	if err != nil {
+		t.Fatalf("failed to get annotations: %v", err)
+	}
+	if annots["org.opencontainers.image.created"] != "2000-01-01T00:00:00Z" || annots["org.opencontainers.image.source"] != "https://example.com/repo" {
+		t.Errorf("unexpected annotations: %v", annots)
+	}
+	err = SetAnnotation(m, "org.opencontainers.image.created", "")
+	if err != nil {
+		t.Fatalf("failed to delete annotation: %v", err)
+	}
+	if m.GetDescriptor().Digest != dOrig.Digest {
+		t.Errorf("digest did not return to the original after deleting annotation")
+	}
+	// docker schema1 manifests do not support annotations
+	mS1, err := New(WithOrig(schema1.Manifest{
+		Versioned: schema1.ManifestSchemaVersion,
+	}))
+	if err != nil {
+		t.Fatalf("failed to create schema1 manifest: %v", err)
+	}
+	if _, err := GetAnnotations(mS1); !errors.Is(err, errs.ErrUnsupportedMediaType) {
+		t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+	}
+	if err := SetAnnotation(mS1, "key", "value"); !errors.Is(err, errs.ErrUnsupportedMediaType) {
+		t.Errorf("unexpected error, expected %v, received %v", errs.ErrUnsupportedMediaType, err)
+	}
+}
+
 func TestModify(t *testing.T) {
 	t.Parallel()
 	addDigest := digest.FromString("new layer digest")
@@ -1526,3 +1892,130 @@ func TestSet(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name      string
+		opts      []Opts
+		errLen    int
+		errNotSet bool
+	}{
+		{
+			name: "Docker Schema 1 Signed",
+			opts: []Opts{WithRaw(rawDockerSchema1Signed), WithDesc(descriptor.Descriptor{MediaType: mediatype.Docker1ManifestSigned})},
+		},
+		{
+			name: "Docker Schema 2",
+			opts: []Opts{WithRaw(rawDockerSchema2), WithDesc(descriptor.Descriptor{MediaType: mediatype.Docker2Manifest})},
+		},
+		{
+			name: "Docker Schema 2 List",
+			opts: []Opts{WithRaw(rawDockerSchema2List), WithDesc(descriptor.Descriptor{MediaType: mediatype.Docker2ManifestList})},
+		},
+		{
+			name: "OCI Image",
+			opts: []Opts{WithRaw(rawOCIImage), WithDesc(descriptor.Descriptor{MediaType: mediatype.OCI1Manifest})},
+		},
+		{
+			name: "OCI Index",
+			opts: []Opts{WithRaw(rawOCIIndex), WithDesc(descriptor.Descriptor{MediaType: mediatype.OCI1ManifestList})},
+		},
+		{
+			name: "OCI Image invalid",
+			opts: []Opts{WithOrig(v1.Manifest{
+				Versioned: v1.ManifestSchemaVersion,
+				MediaType: mediatype.OCI1Manifest,
+				Config: descriptor.Descriptor{
+					MediaType: mediatype.OCI1Empty,
+					Digest:    descriptor.EmptyDigest,
+					Size:      int64(len(descriptor.EmptyData)),
+				},
+				Layers: []descriptor.Descriptor{
+					{
+						MediaType: mediatype.OCI1LayerGzip,
+						Digest:    "sha256:invalid",
+						Size:      -1,
+					},
+					{
+						Digest: digest.FromString("missing media type"),
+						Size:   42,
+					},
+				},
+			})},
+			errLen: 4, // missing artifactType, invalid digest, negative size, missing mediaType
+		},
+		{
+			name: "OCI Index invalid",
+			opts: []Opts{WithOrig(v1.Index{
+				Versioned: v1.IndexSchemaVersion,
+				MediaType: mediatype.OCI1ManifestList,
+				Manifests: []descriptor.Descriptor{
+					{
+						MediaType: "not a media type",
+						Digest:    digest.FromString("child"),
+						Size:      5,
+					},
+				},
+				Subject: &descriptor.Descriptor{
+					MediaType: mediatype.OCI1Manifest,
+				},
+			})},
+			errLen: 2, // invalid media type, subject missing digest
+		},
+		{
+			name: "Docker Schema 2 OCI config",
+			opts: []Opts{WithOrig(schema2.Manifest{
+				Versioned: schema2.ManifestSchemaVersion,
+				Config: descriptor.Descriptor{
+					MediaType: mediatype.OCI1ImageConfig,
+					Digest:    digest.FromString("config"),
+					Size:      6,
+				},
+				Layers: []descriptor.Descriptor{},
+			})},
+			errLen: 1,
+		},
+		{
+			name: "Docker Schema 2 List missing platform",
+			opts: []Opts{WithOrig(schema2.ManifestList{
+				Versioned: schema2.ManifestListSchemaVersion,
+				Manifests: []descriptor.Descriptor{
+					{
+						MediaType: mediatype.Docker2Manifest,
+						Digest:    digest.FromString("child"),
+						Size:      5,
+					},
+				},
+			})},
+			errLen: 1,
+		},
+		{
+			name:      "Not set",
+			opts:      []Opts{WithDesc(descriptor.Descriptor{MediaType: mediatype.OCI1Manifest, Digest: digest.FromString("unset"), Size: 5})},
+			errLen:    1,
+			errNotSet: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("failed to create manifest: %v", err)
+			}
+			errList := Validate(m)
+			if len(errList) != tc.errLen {
+				t.Fatalf("unexpected number of errors, expected %d, received %d: %v", tc.errLen, len(errList), errList)
+			}
+			for _, err := range errList {
+				if tc.errNotSet {
+					if !errors.Is(err, errs.ErrManifestNotSet) {
+						t.Errorf("unexpected error, expected %v, received %v", errs.ErrManifestNotSet, err)
+					}
+				} else if !errors.Is(err, errs.ErrInvalidManifest) {
+					t.Errorf("error does not wrap %v: %v", errs.ErrInvalidManifest, err)
+				}
+			}
+		})
+	}
+}
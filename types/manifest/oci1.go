@@ -86,6 +86,25 @@ func (m *oci1Artifact) GetConfigDigest() (digest.Digest, error) {
 	return "", fmt.Errorf("config digest not available for media type %s%.0w", m.desc.MediaType, errs.ErrUnsupportedMediaType)
 }
 
+func (m *oci1Manifest) GetArtifactType() (string, error) {
+	if !m.manifSet {
+		return "", errs.ErrManifestNotSet
+	}
+	return m.Manifest.ArtifactType, nil
+}
+func (m *oci1Index) GetArtifactType() (string, error) {
+	if !m.manifSet {
+		return "", errs.ErrManifestNotSet
+	}
+	return m.Index.ArtifactType, nil
+}
+func (m *oci1Artifact) GetArtifactType() (string, error) {
+	if !m.manifSet {
+		return "", errs.ErrManifestNotSet
+	}
+	return m.ArtifactManifest.ArtifactType, nil
+}
+
 func (m *oci1Manifest) GetManifestList() ([]descriptor.Descriptor, error) {
 	return []descriptor.Descriptor{}, fmt.Errorf("platform descriptor list not available for media type %s%.0w", m.desc.MediaType, errs.ErrUnsupportedMediaType)
 }
@@ -461,6 +480,9 @@ func (m *oci1Manifest) SetLayers(dl []descriptor.Descriptor) error {
 	if !m.manifSet {
 		return errs.ErrManifestNotSet
 	}
+	if dl == nil {
+		dl = []descriptor.Descriptor{}
+	}
 	m.Layers = dl
 	return m.updateDesc()
 }
@@ -503,6 +525,28 @@ func (m *oci1Index) SetOrig(origIn interface{}) error {
 	return m.updateDesc()
 }
 
+func (m *oci1Manifest) SetArtifactType(at string) error {
+	if !m.manifSet {
+		return errs.ErrManifestNotSet
+	}
+	m.Manifest.ArtifactType = at
+	return m.updateDesc()
+}
+func (m *oci1Index) SetArtifactType(at string) error {
+	if !m.manifSet {
+		return errs.ErrManifestNotSet
+	}
+	m.Index.ArtifactType = at
+	return m.updateDesc()
+}
+func (m *oci1Artifact) SetArtifactType(at string) error {
+	if !m.manifSet {
+		return errs.ErrManifestNotSet
+	}
+	m.ArtifactManifest.ArtifactType = at
+	return m.updateDesc()
+}
+
 func (m *oci1Artifact) SetSubject(d *descriptor.Descriptor) error {
 	if !m.manifSet {
 		return errs.ErrManifestNotSet
@@ -0,0 +1,164 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/regclient/regclient/types/descriptor"
+	"github.com/regclient/regclient/types/docker/schema1"
+	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/mediatype"
+)
+
+// Validate checks the manifest against the schema of its media type.
+// All problems found are returned, an empty list indicates the manifest is valid.
+// Each returned error wraps [errs.ErrInvalidManifest].
+func Validate(m Manifest) []error {
+	if m == nil || !m.IsSet() {
+		return []error{errs.ErrManifestNotSet}
+	}
+	v := validator{}
+	switch mt := m.(type) {
+	case *oci1Manifest:
+		v.schemaVersion(mt.SchemaVersion, 2)
+		if mt.MediaType != "" && mt.MediaType != mediatype.OCI1Manifest {
+			v.add("mediaType %s does not match %s", mt.MediaType, mediatype.OCI1Manifest)
+		}
+		if mt.ArtifactType != "" && !mediatype.Valid(mt.ArtifactType) {
+			v.add("artifactType %s is not a valid media type", mt.ArtifactType)
+		}
+		v.desc("config", mt.Config)
+		switch mt.Config.MediaType {
+		case mediatype.Docker2ImageConfig:
+			v.add("config mediaType %s is not valid in an OCI manifest", mt.Config.MediaType)
+		case mediatype.OCI1Empty:
+			if mt.ArtifactType == "" {
+				v.add("artifactType is required when the config mediaType is %s", mediatype.OCI1Empty)
+			}
+		}
+		if mt.Layers == nil {
+			v.add("layers field is missing")
+		}
+		for i, d := range mt.Layers {
+			v.desc(fmt.Sprintf("layers[%d]", i), d)
+		}
+		if mt.Subject != nil {
+			v.desc("subject", *mt.Subject)
+		}
+	case *oci1Index:
+		v.schemaVersion(mt.SchemaVersion, 2)
+		if mt.MediaType != "" && mt.MediaType != mediatype.OCI1ManifestList {
+			v.add("mediaType %s does not match %s", mt.MediaType, mediatype.OCI1ManifestList)
+		}
+		if mt.ArtifactType != "" && !mediatype.Valid(mt.ArtifactType) {
+			v.add("artifactType %s is not a valid media type", mt.ArtifactType)
+		}
+		if mt.Manifests == nil {
+			v.add("manifests field is missing")
+		}
+		for i, d := range mt.Manifests {
+			v.desc(fmt.Sprintf("manifests[%d]", i), d)
+		}
+		if mt.Subject != nil {
+			v.desc("subject", *mt.Subject)
+		}
+	case *docker2Manifest:
+		v.schemaVersion(mt.SchemaVersion, 2)
+		if mt.MediaType != mediatype.Docker2Manifest {
+			v.add("mediaType %q does not match %s", mt.MediaType, mediatype.Docker2Manifest)
+		}
+		v.desc("config", mt.Config)
+		if mt.Config.MediaType != "" && mt.Config.MediaType != mediatype.Docker2ImageConfig {
+			v.add("config mediaType %s does not match %s", mt.Config.MediaType, mediatype.Docker2ImageConfig)
+		}
+		if mt.Layers == nil {
+			v.add("layers field is missing")
+		}
+		for i, d := range mt.Layers {
+			v.desc(fmt.Sprintf("layers[%d]", i), d)
+		}
+	case *docker2ManifestList:
+		v.schemaVersion(mt.SchemaVersion, 2)
+		if mt.MediaType != mediatype.Docker2ManifestList {
+			v.add("mediaType %q does not match %s", mt.MediaType, mediatype.Docker2ManifestList)
+		}
+		if mt.Manifests == nil {
+			v.add("manifests field is missing")
+		}
+		for i, d := range mt.Manifests {
+			v.desc(fmt.Sprintf("manifests[%d]", i), d)
+			if d.Platform == nil {
+				v.add("manifests[%d] platform is missing", i)
+			}
+		}
+	case *docker1Manifest:
+		v.schemaVersion(mt.SchemaVersion, 1)
+		v.docker1(mt.Name, mt.Tag, len(mt.History), mt.FSLayers)
+	case *docker1SignedManifest:
+		v.schemaVersion(mt.SchemaVersion, 1)
+		v.docker1(mt.Name, mt.Tag, len(mt.History), mt.FSLayers)
+	default:
+		v.add("media type %s is not supported%.0w", m.GetDescriptor().MediaType, errs.ErrUnsupportedMediaType)
+	}
+	return v.errs
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf(format+"%.0w", append(args, errs.ErrInvalidManifest)...))
+}
+
+func (v *validator) schemaVersion(received, expected int) {
+	if received != expected {
+		v.add("schemaVersion %d does not match %d", received, expected)
+	}
+}
+
+func (v *validator) desc(field string, d descriptor.Descriptor) {
+	if d.MediaType == "" {
+		v.add("%s mediaType is missing", field)
+	} else if !mediatype.Valid(d.MediaType) {
+		v.add("%s mediaType %s is not valid", field, d.MediaType)
+	}
+	if d.ArtifactType != "" && !mediatype.Valid(d.ArtifactType) {
+		v.add("%s artifactType %s is not valid", field, d.ArtifactType)
+	}
+	digOK := false
+	if d.Digest == "" {
+		v.add("%s digest is missing", field)
+	} else if err := d.Digest.Validate(); err != nil {
+		v.add("%s digest %s is not valid: %v", field, d.Digest, err)
+	} else {
+		digOK = true
+	}
+	if d.Size < 0 {
+		v.add("%s size %d is negative", field, d.Size)
+	}
+	if len(d.Data) > 0 && digOK {
+		if _, err := d.GetData(); err != nil {
+			v.add("%s data does not match the digest and size", field)
+		}
+	}
+}
+
+func (v *validator) docker1(name, tag string, historyLen int, fsLayers []schema1.FSLayer) {
+	if name == "" {
+		v.add("name is missing")
+	}
+	if tag == "" {
+		v.add("tag is missing")
+	}
+	if len(fsLayers) == 0 {
+		v.add("fsLayers is empty")
+	}
+	if len(fsLayers) != historyLen {
+		v.add("fsLayers length %d does not match history length %d", len(fsLayers), historyLen)
+	}
+	for i, l := range fsLayers {
+		if err := l.BlobSum.Validate(); err != nil {
+			v.add("fsLayers[%d] blobSum %s is not valid: %v", i, l.BlobSum, err)
+		}
+	}
+}
@@ -49,6 +49,10 @@ const (
 	OCI1Empty = "application/vnd.oci.empty.v1+json"
 	// BuildkitCacheConfig is used by buildkit cache images.
 	BuildkitCacheConfig = "application/vnd.buildkit.cacheconfig.v0"
+	// CosignSimpleSigning is the payload of a cosign signature, using the simple signing format.
+	CosignSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// CosignSignature is the artifact type of a cosign signature pushed with the referrers API.
+	CosignSignature = "application/vnd.dev.cosign.artifact.sig.v1+json"
 )
 
 // Base cleans the Content-Type header to return only the lower case base media type.
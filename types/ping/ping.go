@@ -11,3 +11,40 @@ type Result struct {
 	Header http.Header // Header is defined for responses from a registry.
 	Stat   fs.FileInfo // Stat is defined for responses from an ocidir.
 }
+
+// Support indicates if a capability was detected on a registry.
+type Support int
+
+const (
+	// SupportUnknown is returned when the probe could not determine support.
+	SupportUnknown Support = iota
+	// SupportNo is returned when the registry rejected the probe.
+	SupportNo
+	// SupportYes is returned when the registry accepted the probe.
+	SupportYes
+)
+
+// String returns the name of the support value.
+func (s Support) String() string {
+	switch s {
+	case SupportNo:
+		return "no"
+	case SupportYes:
+		return "yes"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText outputs the name of the support value.
+func (s Support) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Capabilities are the features of a registry found with a best effort probe.
+// There is no standard discovery API, so each value may be SupportUnknown.
+type Capabilities struct {
+	OCIManifest Support `json:"ociManifest"` // OCIManifest is SupportYes when an OCI manifest was returned with only OCI media types accepted.
+	Referrers   Support `json:"referrers"`   // Referrers is the support for the OCI referrers API.
+	Delete      Support `json:"delete"`      // Delete is only probed on request, and is SupportNo when a manifest delete is rejected with a 405 Method Not Allowed.
+}
@@ -41,10 +41,20 @@ var (
 	ocidirRE = regexp.MustCompile(`^(` + pathS + `)` +
 		`(?:` + regexp.QuoteMeta(`:`) + `(` + tagS + `))?` +
 		`(?:` + regexp.QuoteMeta(`@`) + `(` + digestS + `))?$`)
+	repoPartRE = regexp.MustCompile(`^` + repoPartS + `$`)
+	tagRE      = regexp.MustCompile(`^` + tagS + `$`)
+	digestRE   = regexp.MustCompile(`^` + digestS + `$`)
+	// digestLen is the length of the hex encoded hash for known algorithms.
+	digestLen = map[string]int{
+		"sha256": 64,
+		"sha512": 128,
+	}
 )
 
 // Ref is a reference to a registry/repository.
 // Direct access to the contents of this struct should not be assumed.
+// A reference may include both a tag and digest, e.g. "alpine:3.14@sha256:...".
+// Content is then pulled by the digest, and the tag is informational, included in the CommonName.
 type Ref struct {
 	Scheme     string // Scheme is the type of reference, "reg" or "ocidir".
 	Reference  string // Reference is the unparsed string or common name.
@@ -73,10 +83,7 @@ func New(parse string) (Ref, error) {
 		ret.Scheme = "reg"
 		matchRef := refRE.FindStringSubmatch(tail)
 		if matchRef == nil || len(matchRef) < 5 {
-			if refRE.FindStringSubmatch(strings.ToLower(tail)) != nil {
-				return Ref{}, fmt.Errorf("%w \"%s\", repo must be lowercase", errs.ErrInvalidReference, tail)
-			}
-			return Ref{}, fmt.Errorf("%w \"%s\"", errs.ErrInvalidReference, tail)
+			return Ref{}, regParseErr(tail)
 		}
 		ret.Registry = matchRef[1]
 		ret.Repository = matchRef[2]
@@ -102,6 +109,9 @@ func New(parse string) (Ref, error) {
 		if ret.Repository == "" {
 			return Ref{}, fmt.Errorf("%w \"%s\"", errs.ErrInvalidReference, tail)
 		}
+		if err := digestLenCheck(ret.Digest); err != nil {
+			return Ref{}, fmt.Errorf("%w \"%s\", %s", errs.ErrInvalidReference, tail, err.Error())
+		}
 
 	case "ocidir", "ocifile":
 		matchPath := ocidirRE.FindStringSubmatch(tail)
@@ -115,6 +125,9 @@ func New(parse string) (Ref, error) {
 		if len(matchPath) > 3 && matchPath[3] != "" {
 			ret.Digest = matchPath[3]
 		}
+		if err := digestLenCheck(ret.Digest); err != nil {
+			return Ref{}, fmt.Errorf("%w, invalid digest for scheme \"%s\": %s, %s", errs.ErrInvalidReference, scheme, tail, err.Error())
+		}
 
 	default:
 		return Ref{}, fmt.Errorf("%w, unknown scheme \"%s\" in \"%s\"", errs.ErrInvalidReference, scheme, parse)
@@ -122,6 +135,69 @@ func New(parse string) (Ref, error) {
 	return ret, nil
 }
 
+// regParseErr returns an error describing why a "reg" scheme reference failed to parse.
+func regParseErr(tail string) error {
+	if tail == "" {
+		return fmt.Errorf("%w \"%s\", reference is empty", errs.ErrInvalidReference, tail)
+	}
+	repo := tail
+	if i := strings.Index(repo, "@"); i >= 0 {
+		dig := repo[i+1:]
+		repo = repo[:i]
+		if !digestRE.MatchString(dig) {
+			return fmt.Errorf("%w \"%s\", invalid digest \"%s\", must be formatted as algorithm:hex", errs.ErrInvalidReference, tail, dig)
+		}
+		if err := digestLenCheck(dig); err != nil {
+			return fmt.Errorf("%w \"%s\", %s", errs.ErrInvalidReference, tail, err.Error())
+		}
+	}
+	if i := strings.LastIndex(repo, ":"); i >= 0 && !strings.Contains(repo[i+1:], "/") {
+		tag := repo[i+1:]
+		repo = repo[:i]
+		if len(tag) > 128 {
+			return fmt.Errorf("%w \"%s\", tag exceeds 128 characters", errs.ErrInvalidReference, tail)
+		}
+		if !tagRE.MatchString(tag) {
+			return fmt.Errorf("%w \"%s\", invalid tag \"%s\", tags may only contain [a-zA-Z0-9_.-] and cannot start with a period or dash", errs.ErrInvalidReference, tail, tag)
+		}
+	}
+	if i := strings.Index(repo, "/"); i >= 0 {
+		host := repo[:i]
+		if registryRE.MatchString(host) {
+			repo = repo[i+1:]
+		} else if strings.ContainsAny(host, ".:") {
+			return fmt.Errorf("%w \"%s\", invalid registry \"%s\"", errs.ErrInvalidReference, tail, host)
+		}
+	}
+	if repo == "" {
+		return fmt.Errorf("%w \"%s\", repository is missing", errs.ErrInvalidReference, tail)
+	}
+	for _, part := range strings.Split(repo, "/") {
+		if part == "" {
+			return fmt.Errorf("%w \"%s\", repository contains an empty path component", errs.ErrInvalidReference, tail)
+		}
+		if !repoPartRE.MatchString(part) {
+			if repoPartRE.MatchString(strings.ToLower(part)) {
+				return fmt.Errorf("%w \"%s\", repo must be lowercase", errs.ErrInvalidReference, tail)
+			}
+			return fmt.Errorf("%w \"%s\", invalid repository path component \"%s\", must be lowercase alphanumerics separated by a period, underscore, or dash", errs.ErrInvalidReference, tail, part)
+		}
+	}
+	return fmt.Errorf("%w \"%s\"", errs.ErrInvalidReference, tail)
+}
+
+// digestLenCheck verifies the length of the hex encoded hash for known digest algorithms.
+func digestLenCheck(dig string) error {
+	algo, hex, ok := strings.Cut(dig, ":")
+	if !ok {
+		return nil
+	}
+	if l, ok := digestLen[algo]; ok && len(hex) != l {
+		return fmt.Errorf("invalid digest \"%s\", %s requires %d hex characters, received %d", dig, algo, l, len(hex))
+	}
+	return nil
+}
+
 // NewHost returns a Reg for a registry hostname or equivalent.
 // The ocidir schema equivalent is the path.
 func NewHost(parse string) (Ref, error) {
@@ -191,6 +267,25 @@ func (r Ref) CommonName() string {
 	return cn
 }
 
+// CanonicalName outputs the fully qualified name of the normalized reference.
+// References that are [Ref.Equal] have the same canonical name.
+func (r Ref) CanonicalName() string {
+	return r.Normalize().CommonName()
+}
+
+// Equal returns true when two references are the same after normalizing.
+// This compares the registry, repository, path, tag, and digest.
+// E.g. "alpine" is equal to "docker.io/library/alpine:latest".
+func (r Ref) Equal(other Ref) bool {
+	a, b := r.Normalize(), other.Normalize()
+	return a.Scheme == b.Scheme &&
+		a.Registry == b.Registry &&
+		a.Repository == b.Repository &&
+		a.Path == b.Path &&
+		a.Tag == b.Tag &&
+		a.Digest == b.Digest
+}
+
 // IsSet returns true if needed values are defined for a specific reference.
 func (r Ref) IsSet() bool {
 	if !r.IsSetRepo() {
@@ -244,6 +339,34 @@ func (r Ref) SetTag(tag string) Ref {
 	return r
 }
 
+// Normalize returns a reference with the default values applied.
+// For the "reg" scheme, Docker Hub registry and repository names are expanded and the "latest" tag is added when there is no tag or digest.
+// For the "ocidir" scheme, the path is cleaned.
+// The Reference value is set to the resulting common name.
+func (r Ref) Normalize() Ref {
+	switch r.Scheme {
+	case "reg":
+		switch r.Registry {
+		case "", dockerRegistryDNS, dockerRegistryLegacy:
+			r.Registry = dockerRegistry
+		}
+		if r.Registry == dockerRegistry && r.Repository != "" && !strings.Contains(r.Repository, "/") {
+			r.Repository = dockerLibrary + "/" + r.Repository
+		}
+		if r.Repository != "" && r.Tag == "" && r.Digest == "" {
+			r.Tag = "latest"
+		}
+	case "ocidir":
+		if r.Path != "" {
+			r.Path = path.Clean(r.Path)
+		}
+	default:
+		return r
+	}
+	r.Reference = r.CommonName()
+	return r
+}
+
 // ToReg converts a reference to a registry like syntax.
 func (r Ref) ToReg() Ref {
 	switch r.Scheme {
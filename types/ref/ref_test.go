@@ -210,6 +210,72 @@ func TestNew(t *testing.T) {
 			path:       "",
 			wantE:      nil,
 		},
+		{
+			name:       "Localhost port without tag",
+			ref:        "localhost:5000/foo",
+			scheme:     "reg",
+			registry:   "localhost:5000",
+			repository: "foo",
+			tag:        "latest",
+		},
+		{
+			name:       "Localhost port with tag",
+			ref:        "localhost:5000/foo:bar",
+			scheme:     "reg",
+			registry:   "localhost:5000",
+			repository: "foo",
+			tag:        "bar",
+		},
+		{
+			name:       "Localhost port with numeric tag",
+			ref:        "localhost:5000/foo:5000",
+			scheme:     "reg",
+			registry:   "localhost:5000",
+			repository: "foo",
+			tag:        "5000",
+		},
+		{
+			name:       "Domain port with tag",
+			ref:        "myregistry.example.com:8443/team/app:v1",
+			scheme:     "reg",
+			registry:   "myregistry.example.com:8443",
+			repository: "team/app",
+			tag:        "v1",
+		},
+		{
+			name:       "Hostname port without tag",
+			ref:        "myregistry:8443/app",
+			scheme:     "reg",
+			registry:   "myregistry:8443",
+			repository: "app",
+			tag:        "latest",
+		},
+		{
+			name:       "Numeric tag without registry",
+			ref:        "foo:5000",
+			scheme:     "reg",
+			registry:   "docker.io",
+			repository: "library/foo",
+			tag:        "5000",
+		},
+		{
+			name:       "Docker library with tag and digest",
+			ref:        "alpine:3.14@" + testDigest,
+			scheme:     "reg",
+			registry:   "docker.io",
+			repository: "library/alpine",
+			tag:        "3.14",
+			digest:     testDigest,
+		},
+		{
+			name:       "Localhost port with tag and digest",
+			ref:        "localhost:5000/foo:bar@" + testDigest,
+			scheme:     "reg",
+			registry:   "localhost:5000",
+			repository: "foo",
+			tag:        "bar",
+			digest:     testDigest,
+		},
 		{
 			name:       "ip address registry",
 			ref:        "127.0.0.1:5000/image:v42",
@@ -394,6 +460,16 @@ func TestNew(t *testing.T) {
 			ref:   "localhost:5000",
 			wantE: errs.ErrInvalidReference,
 		},
+		{
+			name:  "localhost empty port",
+			ref:   "localhost:/foo",
+			wantE: errs.ErrInvalidReference,
+		},
+		{
+			name:  "non-numeric port",
+			ref:   "example.com:abc/foo",
+			wantE: errs.ErrInvalidReference,
+		},
 	}
 
 	for _, tc := range tt {
@@ -434,6 +510,107 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewErrMessage(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name    string
+		ref     string
+		wantMsg string
+	}{
+		{
+			name:    "empty",
+			ref:     "",
+			wantMsg: "reference is empty",
+		},
+		{
+			name:    "uppercase repo",
+			ref:     "UPPERCASE/Repo",
+			wantMsg: "repo must be lowercase",
+		},
+		{
+			name:    "uppercase repo with registry",
+			ref:     "registry.example.com/Project/repo:tag",
+			wantMsg: "repo must be lowercase",
+		},
+		{
+			name:    "invalid repo chars",
+			ref:     "project/star*:tag",
+			wantMsg: `invalid repository path component "star*"`,
+		},
+		{
+			name:    "empty repo component",
+			ref:     "registry.example.com/project//repo",
+			wantMsg: "repository contains an empty path component",
+		},
+		{
+			name:    "missing repo",
+			ref:     "registry.example.com/:tag",
+			wantMsg: "repository is missing",
+		},
+		{
+			name:    "bad scheme separator",
+			ref:     "reg:/bad",
+			wantMsg: `invalid registry "reg:"`,
+		},
+		{
+			name:    "invalid registry",
+			ref:     "-registry.example.com/repo:tag",
+			wantMsg: `invalid registry "-registry.example.com"`,
+		},
+		{
+			name:    "invalid tag chars",
+			ref:     "project/image:tag^1",
+			wantMsg: `invalid tag "tag^1"`,
+		},
+		{
+			name:    "invalid tag leading period",
+			ref:     "project/image:.tag",
+			wantMsg: `invalid tag ".tag"`,
+		},
+		{
+			name:    "invalid tag length",
+			ref:     "project/image:" + strings.Repeat("x", 129),
+			wantMsg: "tag exceeds 128 characters",
+		},
+		{
+			name:    "short sha256 digest",
+			ref:     "project/image@sha256:" + strings.Repeat("a", 63),
+			wantMsg: "sha256 requires 64 hex characters, received 63",
+		},
+		{
+			name:    "long sha512 digest",
+			ref:     "project/image@sha512:" + strings.Repeat("a", 130),
+			wantMsg: "sha512 requires 128 hex characters, received 130",
+		},
+		{
+			name:    "digest missing algorithm",
+			ref:     "project/image@" + strings.Repeat("a", 64),
+			wantMsg: "must be formatted as algorithm:hex",
+		},
+		{
+			name:    "digest invalid hex",
+			ref:     "project/image@sha256:gggg40677a5e245d9ea199eb9b026b1539208a5183621dced7b469f6aa678115",
+			wantMsg: "must be formatted as algorithm:hex",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tc.ref)
+			if err == nil {
+				t.Fatalf("parsing did not fail")
+			}
+			if !errors.Is(err, errs.ErrInvalidReference) {
+				t.Errorf("unexpected error type: %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Errorf("error message mismatch, expected %s, received %v", tc.wantMsg, err)
+			}
+		})
+	}
+}
+
 func TestNewHost(t *testing.T) {
 	t.Parallel()
 	var tt = []struct {
@@ -634,6 +811,10 @@ func TestCommon(t *testing.T) {
 			name: "ref with digest",
 			str:  "docker.io/group/image@" + testDigest,
 		},
+		{
+			name: "ref with tag and digest",
+			str:  "docker.io/library/alpine:3.14@" + testDigest,
+		},
 		{
 			name: "ocidir with tag",
 			str:  "ocidir:///tmp/image:tag",
@@ -642,6 +823,10 @@ func TestCommon(t *testing.T) {
 			name: "ocidir with digest",
 			str:  "ocidir://image@" + testDigest,
 		},
+		{
+			name: "ocidir with tag and digest",
+			str:  "ocidir://image:tag@" + testDigest,
+		},
 	}
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -795,6 +980,106 @@ func TestEqual(t *testing.T) {
 	}
 }
 
+func TestRefEqual(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name      string
+		a, b      string
+		expect    bool
+		canonical string
+	}{
+		{
+			name:      "docker hub short name",
+			a:         "alpine",
+			b:         "docker.io/library/alpine:latest",
+			expect:    true,
+			canonical: "docker.io/library/alpine:latest",
+		},
+		{
+			name:      "docker hub legacy registry",
+			a:         "index.docker.io/group/image:v1",
+			b:         "group/image:v1",
+			expect:    true,
+			canonical: "docker.io/group/image:v1",
+		},
+		{
+			name:      "digest",
+			a:         "example.com/repo@" + testDigest,
+			b:         "example.com/repo@" + testDigest,
+			expect:    true,
+			canonical: "example.com/repo@" + testDigest,
+		},
+		{
+			name:      "different tag",
+			a:         "example.com/repo:v1",
+			b:         "example.com/repo:v2",
+			expect:    false,
+			canonical: "example.com/repo:v1",
+		},
+		{
+			name:      "tag and digest",
+			a:         "example.com/repo:v1@" + testDigest,
+			b:         "example.com/repo@" + testDigest,
+			expect:    false,
+			canonical: "example.com/repo:v1@" + testDigest,
+		},
+		{
+			name:      "different registry",
+			a:         "example.com/repo:v1",
+			b:         "example.org/repo:v1",
+			expect:    false,
+			canonical: "example.com/repo:v1",
+		},
+		{
+			name:      "ocidir path",
+			a:         "ocidir://./testdata/dir/../image:v1",
+			b:         "ocidir://testdata/image:v1",
+			expect:    true,
+			canonical: "ocidir://testdata/image:v1",
+		},
+		{
+			name:      "ocidir and reg",
+			a:         "ocidir://example.com/repo:v1",
+			b:         "example.com/repo:v1",
+			expect:    false,
+			canonical: "ocidir://example.com/repo:v1",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := New(tc.a)
+			if err != nil {
+				t.Fatalf("failed to parse %s: %v", tc.a, err)
+			}
+			b, err := New(tc.b)
+			if err != nil {
+				t.Fatalf("failed to parse %s: %v", tc.b, err)
+			}
+			if a.Equal(b) != tc.expect || b.Equal(a) != tc.expect {
+				t.Errorf("equal mismatch, expected %t, %s, %s", tc.expect, tc.a, tc.b)
+			}
+			if cn := a.CanonicalName(); cn != tc.canonical {
+				t.Errorf("canonical name mismatch, expected %s, received %s", tc.canonical, cn)
+			}
+			if tc.expect && a.CanonicalName() != b.CanonicalName() {
+				t.Errorf("canonical name differs, %s, %s", a.CanonicalName(), b.CanonicalName())
+			}
+		})
+	}
+	// references constructed without New are normalized
+	r := Ref{Scheme: "reg", Repository: "alpine"}
+	rParse, err := New("alpine")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if !r.Equal(rParse) {
+		t.Errorf("unparsed ref is not equal to the parsed ref")
+	}
+	if rn := r.Normalize(); rn.Reference != "docker.io/library/alpine:latest" {
+		t.Errorf("normalized reference mismatch, received %s", rn.Reference)
+	}
+}
+
 func TestIsSet(t *testing.T) {
 	t.Parallel()
 	tt := []struct {
@@ -87,6 +87,27 @@ func WithRaw(raw []byte) Opts {
 	}
 }
 
+// Append extends a repository list with another page of results.
+func (rl *RepoList) Append(add *RepoList) error {
+	if rl.host != add.host || rl.mt != add.mt {
+		return fmt.Errorf("unable to append, lists are incompatible")
+	}
+	if add.orig != nil {
+		rl.orig = add.orig
+	}
+	if add.rawHeader != nil {
+		rl.rawHeader = add.rawHeader
+	}
+	rl.Repositories = append(rl.Repositories, add.Repositories...)
+	// regenerate the body to include the combined list
+	body, err := json.Marshal(rl.RepoRegistryList)
+	if err != nil {
+		return err
+	}
+	rl.rawBody = body
+	return nil
+}
+
 // RepoRegistryList is a list of repositories from the _catalog API
 type RepoRegistryList struct {
 	Repositories []string `json:"repositories"`
@@ -120,6 +141,16 @@ func (rl RepoRegistryList) GetRepos() ([]string, error) {
 	return rl.Repositories, nil
 }
 
+// GetLast returns the last repository in the list.
+// This is used with [github.com/regclient/regclient/scheme.WithRepoLast] to resume a listing.
+// An empty string is returned for an empty list.
+func (rl RepoRegistryList) GetLast() string {
+	if len(rl.Repositories) == 0 {
+		return ""
+	}
+	return rl.Repositories[len(rl.Repositories)-1]
+}
+
 // MarshalPretty is used for printPretty template formatting
 func (rl RepoRegistryList) MarshalPretty() ([]byte, error) {
 	sort.Slice(rl.Repositories, func(i, j int) bool {
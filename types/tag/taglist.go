@@ -103,6 +103,13 @@ func New(opts ...Opts) (*List, error) {
 			return nil, fmt.Errorf("%w: media type: %s, reference: %s", errs.ErrUnsupportedMediaType, conf.mt, conf.ref.CommonName())
 		}
 	}
+	// listings without a name, e.g. from an OCI Layout, use the repository or path of the ref
+	if tl.Name == "" {
+		tl.Name = conf.ref.Repository
+		if tl.Name == "" {
+			tl.Name = conf.ref.Path
+		}
+	}
 	tl.tagCommon = tc
 
 	return &tl, nil
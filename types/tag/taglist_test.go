@@ -23,6 +23,8 @@ func TestNew(t *testing.T) {
 		t.Fatalf("failed to parse URL: %v", err)
 	}
 	registryRef, _ := ref.New("localhost:5000/regclient/test")
+	layoutRef, _ := ref.New("ocidir://testdata/layout")
+	layoutTags := []string{"v1", "v2"}
 	registryRepoName := "regclient/test"
 	registryRaw := []byte(fmt.Sprintf(`{"name":"%s","tags":["%s"]}`, registryRepoName,
 		strings.Join(registryTags, `","`)))
@@ -192,6 +194,16 @@ func TestNew(t *testing.T) {
 			gcrChildren:  gcrChild,
 			gcrManifests: gcrManifests,
 		},
+		{
+			name: "Layout",
+			opts: []Opts{
+				WithRef(layoutRef),
+				WithMT("application/vnd.oci.image.index.v1+json"),
+				WithTags(layoutTags),
+			},
+			repoName: "testdata/layout",
+			tags:     layoutTags,
+		},
 		{
 			name: "Unknown MT",
 			opts: []Opts{